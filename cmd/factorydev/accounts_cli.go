@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/sshconfig"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func runAccountsCLI(args []string) {
+	runGroup("accounts", args, []subcommand{
+		{"list", "Lista as contas SSH", accountsList},
+		{"apply", "Aplica o bloco da conta no ~/.ssh/config", accountsApply},
+		{"test", "Testa a conexão SSH da conta (ssh -T)", accountsTest},
+		{"resolve", "Mostra a configuração efetiva de um host (como ssh -G)", accountsResolve},
+	})
+}
+
+func accountsList(args []string) {
+	fs := newCLIFlags("accounts list", true)
+	fs.parse(args, "", 0, 0)
+	state := loadState(fs.open())
+	if *fs.json {
+		printJSON(state.Accounts)
+		return
+	}
+	t := newTable("HOST ALIAS", "NOME", "HOSTNAME", "CHAVE", "ID")
+	for _, acc := range state.Accounts {
+		key := "-"
+		if k := state.FindKey(acc.KeyID); k != nil {
+			key = k.Alias
+		} else if acc.IdentityFile != "" {
+			key = acc.IdentityFile
+		}
+		t.row(acc.HostAlias, acc.Name, acc.HostName, key, acc.ID)
+	}
+	t.flush()
+}
+
+func accountsApply(args []string) {
+	fs := newCLIFlags("accounts apply", false)
+	all := fs.Bool("all", false, "Aplica todas as contas")
+	fs.parse(args, "<id|host-alias|nome>", 0, 1)
+	if *all == (fs.NArg() == 1) {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	a := fs.open()
+	state := loadState(a)
+	accounts := state.Accounts
+	if !*all {
+		acc := findAccountArg(state, fs.Arg(0))
+		accounts = []storage.Account{acc}
+	}
+	for _, acc := range accounts {
+		// Resolver IdentityFile e certificado pelo Key Manager
+		acc = state.ResolveKeyFiles(acc)
+		if err := ssh.ApplyAccount(acc, a.Paths()); err != nil {
+			log.Fatalf("aplicar %s: %v", acc.HostAlias, err)
+		}
+		fmt.Printf("Aplicado: Host %s\n", acc.HostAlias)
+	}
+}
+
+func accountsTest(args []string) {
+	fs := newCLIFlags("accounts test", false)
+	fs.parse(args, "<id|host-alias|nome>", 1, 1)
+	state := loadState(fs.open())
+	acc := findAccountArg(state, fs.Arg(0))
+
+	output, err := ssh.TestConnection(acc.HostAlias)
+	fmt.Print(output)
+	if err != nil {
+		fail("falha no teste ssh de %s: %v", acc.HostAlias, err)
+	}
+}
+
+// accountsResolve avalia o ~/.ssh/config, com os Include, para o host dado.
+// Com --sources mostra o arquivo e a linha de cada valor.
+func accountsResolve(args []string) {
+	fs := newCLIFlags("accounts resolve", true)
+	sources := fs.Bool("sources", false, "Mostra a origem (arquivo:linha) de cada valor")
+	fs.parse(args, "<host>", 1, 1)
+	paths, err := config.NewPaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg, err := sshconfig.Load(paths.UserSSHConfig())
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := cfg.Evaluate(fs.Arg(0))
+	if *fs.json {
+		printJSON(opts.Settings())
+		return
+	}
+	if !*sources {
+		fmt.Print(opts)
+		return
+	}
+	t := newTable("CHAVE", "VALOR", "ORIGEM")
+	for _, s := range opts.Settings() {
+		src := s.Source
+		if src == "" {
+			src = "(padrão)"
+		}
+		t.row(s.Key, strings.Join(s.Values, " "), src)
+	}
+	t.flush()
+}
+
+// findAccountArg localiza a conta por ID, host alias ou nome.
+func findAccountArg(state *storage.State, ref string) storage.Account {
+	for _, acc := range state.Accounts {
+		if acc.ID == ref || acc.HostAlias == ref || acc.Name == ref {
+			return acc
+		}
+	}
+	fail("conta não encontrada: %s", ref)
+	return storage.Account{}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/seuusuario/factorydev/internal/auth"
+	"github.com/seuusuario/factorydev/internal/config"
+)
+
+func runAuthCLI(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "uso: factorydev auth token|rotate-token|password [--clear]")
+		os.Exit(2)
+	}
+	paths, err := config.NewPaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.EnsureDirectories(paths); err != nil {
+		log.Fatal(err)
+	}
+	mgr, err := auth.Load(paths.Auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch args[0] {
+	case "token":
+		fmt.Println(mgr.Token())
+	case "rotate-token":
+		if err := mgr.RotateToken(); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Novo token (sessões abertas foram encerradas):")
+		fmt.Println(mgr.Token())
+	case "password":
+		fs := flag.NewFlagSet("factorydev auth password", flag.ExitOnError)
+		clear := fs.Bool("clear", false, "Remove a senha")
+		_ = fs.Parse(args[1:])
+		if *clear {
+			if err := mgr.SetPassword(""); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println("Senha removida.")
+			return
+		}
+		pass := readSecret("Nova senha: ")
+		if readSecret("Confirmar senha: ") != pass {
+			log.Fatal("as senhas não conferem")
+		}
+		if err := mgr.SetPassword(pass); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("Senha definida. Ela é exigida no login quando o servidor escuta fora do loopback.")
+	default:
+		fmt.Fprintf(os.Stderr, "subcomando desconhecido: %s\n", args[0])
+		os.Exit(2)
+	}
+}
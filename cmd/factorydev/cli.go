@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+var errNothingImported = errors.New("nada foi importado")
+
+// subcommand é um nó da árvore de comandos da CLI (`factorydev keys list`).
+type subcommand struct {
+	name  string
+	usage string
+	run   func(args []string)
+}
+
+// runGroup despacha args[0] para o subcomando correspondente.
+func runGroup(group string, args []string, cmds []subcommand) {
+	if len(args) > 0 {
+		for _, c := range cmds {
+			if c.name == args[0] {
+				c.run(args[1:])
+				return
+			}
+		}
+		if args[0] != "help" && args[0] != "-h" && args[0] != "--help" {
+			fmt.Fprintf(os.Stderr, "subcomando desconhecido: %s %s\n\n", group, args[0])
+		}
+	}
+	fmt.Fprintf(os.Stderr, "uso: factorydev %s <subcomando> [flags]\n\n", group)
+	for _, c := range cmds {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", c.name, c.usage)
+	}
+	os.Exit(2)
+}
+
+// cliFlags reúne as flags comuns aos subcomandos que abrem o state.
+type cliFlags struct {
+	*flag.FlagSet
+	backend   *string
+	workspace *string
+	json      *bool
+}
+
+// newCLIFlags cria o FlagSet com --storage e --workspace; listJSON adiciona
+// --json aos comandos de listagem.
+func newCLIFlags(name string, listJSON bool) *cliFlags {
+	fs := flag.NewFlagSet("factorydev "+name, flag.ExitOnError)
+	c := &cliFlags{
+		FlagSet:   fs,
+		backend:   fs.String("storage", "", "Backend de persistência: json|sqlite (default: config.json)"),
+		workspace: fs.String("workspace", "", "Workspace (default: o último ativo)"),
+		json:      new(bool),
+	}
+	if listJSON {
+		fs.BoolVar(c.json, "json", false, "Saída em JSON")
+	}
+	return c
+}
+
+// parse lê as flags e confere a quantidade de argumentos posicionais.
+func (c *cliFlags) parse(args []string, positional string, min, max int) {
+	c.Usage = func() {
+		fmt.Fprintf(os.Stderr, "uso: %s [flags] %s\n", c.Name(), positional)
+		c.PrintDefaults()
+	}
+	_ = c.Parse(args)
+	if c.NArg() < min || (max >= 0 && c.NArg() > max) {
+		c.Usage()
+		os.Exit(2)
+	}
+}
+
+func (c *cliFlags) open() *app.App {
+	return openCLIApp(*c.backend, *c.workspace)
+}
+
+// loadState carrega o state ou encerra com erro.
+func loadState(a *app.App) *storage.State {
+	state, err := a.Storage().LoadState()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return state
+}
+
+// printJSON escreve v indentado no stdout.
+func printJSON(v any) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// table imprime colunas alinhadas no stdout.
+type table struct {
+	w *tabwriter.Writer
+}
+
+func newTable(headers ...string) *table {
+	t := &table{w: tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)}
+	t.row(headers...)
+	return t
+}
+
+func (t *table) row(cols ...string) {
+	fmt.Fprintln(t.w, strings.Join(cols, "\t"))
+}
+
+func (t *table) flush() {
+	_ = t.w.Flush()
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "sim"
+	}
+	return "não"
+}
+
+// orDash troca valores vazios por "-" nas tabelas.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// fail imprime a mensagem no stderr e encerra com código 1.
+func fail(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(1)
+}
+
+func newID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
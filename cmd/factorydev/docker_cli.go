@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	idocker "github.com/seuusuario/factorydev/internal/docker"
+)
+
+func runDockerCLI(args []string) {
+	runGroup("docker", args, []subcommand{
+		{"ps", "Lista os containers", dockerPS},
+		{"templates", "Lista ou lança templates de container", runDockerTemplatesCLI},
+	})
+}
+
+func runDockerTemplatesCLI(args []string) {
+	runGroup("docker templates", args, []subcommand{
+		{"list", "Lista os templates disponíveis", dockerTemplatesList},
+		{"launch", "Cria e inicia um container a partir de um template", dockerTemplatesLaunch},
+	})
+}
+
+// dockerClient conecta ao daemon ou encerra com erro.
+func dockerClient() *idocker.Client {
+	cli, err := idocker.New()
+	if err != nil || !cli.Available() {
+		fail("Docker não disponível: verifique se o daemon está rodando")
+	}
+	return cli
+}
+
+func dockerPS(args []string) {
+	fs := flag.NewFlagSet("factorydev docker ps", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Saída em JSON")
+	running := fs.Bool("running", false, "Somente containers em execução")
+	_ = fs.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	containers, err := dockerClient().ListContainers(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *running {
+		filtered := containers[:0]
+		for _, c := range containers {
+			if c.State == "running" {
+				filtered = append(filtered, c)
+			}
+		}
+		containers = filtered
+	}
+	if *asJSON {
+		printJSON(containers)
+		return
+	}
+	t := newTable("ID", "NOME", "IMAGEM", "STATUS", "PORTAS")
+	for _, c := range containers {
+		id := c.ID
+		if len(id) > 12 {
+			id = id[:12]
+		}
+		t.row(id, c.Name, c.Image, c.Status, orDash(c.Ports))
+	}
+	t.flush()
+}
+
+func dockerTemplatesList(args []string) {
+	fs := flag.NewFlagSet("factorydev docker templates list", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "Saída em JSON")
+	_ = fs.Parse(args)
+	if *asJSON {
+		printJSON(idocker.Templates)
+		return
+	}
+	t := newTable("ID", "NOME", "IMAGEM", "PORTA")
+	for _, tpl := range idocker.Templates {
+		t.row(tpl.ID, tpl.Name, tpl.Image, orDash(tpl.DefaultPort))
+	}
+	t.flush()
+}
+
+// envFlags acumula --env repetido.
+type envFlags []string
+
+func (e *envFlags) String() string     { return strings.Join(*e, ",") }
+func (e *envFlags) Set(v string) error { *e = append(*e, v); return nil }
+
+func dockerTemplatesLaunch(args []string) {
+	fs := flag.NewFlagSet("factorydev docker templates launch", flag.ExitOnError)
+	name := fs.String("name", "", "Nome do container (default: <template>-<id>)")
+	image := fs.String("image", "", "Imagem (default: a do template)")
+	port := fs.String("port", "", "Mapeamento host:container (default: o do template)")
+	restart := fs.String("restart", "", "Política de restart: no|always|unless-stopped|on-failure")
+	var env envFlags
+	fs.Var(&env, "env", "Variável KEY=VALUE (repetível; substitui as do template)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "uso: factorydev docker templates launch [flags] <template>")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		fail("informe o ID do template (factorydev docker templates list)")
+	}
+
+	tpl, ok := idocker.FindTemplate(fs.Arg(0))
+	if !ok {
+		fail("template não encontrado: %s", fs.Arg(0))
+	}
+	spec := idocker.ContainerSpec{
+		Name:          *name,
+		Image:         tpl.Image,
+		Env:           tpl.DefaultEnv,
+		Ports:         idocker.PortBindings(tpl.DefaultPort),
+		RestartPolicy: *restart,
+	}
+	if spec.Name == "" {
+		spec.Name = tpl.ID + "-" + newID()[:8]
+	}
+	if *image != "" {
+		spec.Image = *image
+	}
+	if *port != "" {
+		spec.Ports = idocker.PortBindings(*port)
+	}
+	if len(env) > 0 {
+		spec.Env = env
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	id, err := dockerClient().CreateAndStart(ctx, spec)
+	if err != nil {
+		fail("erro ao lançar container: %v", err)
+	}
+	if len(id) > 12 {
+		id = id[:12]
+	}
+	fmt.Printf("Container '%s' lançado (%s)\n", spec.Name, id)
+}
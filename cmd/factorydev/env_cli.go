@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/seuusuario/factorydev/internal/shell"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+func runEnvCLI(args []string) {
+	runGroup("env", args, []subcommand{
+		{"export", "Gera o arquivo .env de um conjunto de variáveis", envExport},
+	})
+}
+
+func runAliasesCLI(args []string) {
+	runGroup("aliases", args, []subcommand{
+		{"sync", "Regenera ~/.fdev/aliases.sh e o source nos shells", aliasesSync},
+	})
+}
+
+func envExport(args []string) {
+	fs := newCLIFlags("env export", false)
+	out := fs.String("out", "", "Arquivo de saída; \"-\" escreve no stdout (default: ~/.fdev/envs/<id>.env)")
+	fs.parse(args, "<id|nome>", 1, 1)
+
+	a := fs.open()
+	state := loadState(a)
+	var found *storage.EnvFile
+	for i := range state.EnvFiles {
+		if state.EnvFiles[i].ID == fs.Arg(0) || state.EnvFiles[i].Name == fs.Arg(0) {
+			found = &state.EnvFiles[i]
+			break
+		}
+	}
+	if found == nil {
+		fail("arquivo .env não encontrado: %s", fs.Arg(0))
+	}
+
+	vars, err := a.Vault.RevealMap(found.Variables)
+	if errors.Is(err, vault.ErrLocked) {
+		unlockVaultCLI(a)
+		vars, err = a.Vault.RevealMap(found.Variables)
+	}
+	if err != nil {
+		log.Fatalf("revelar variáveis: %v", err)
+	}
+
+	switch *out {
+	case "-":
+		fmt.Print(shell.RenderEnv(vars))
+	case "":
+		path, err := shell.WriteEnv(a.Paths().Envs, found.ID, vars)
+		if err != nil {
+			log.Fatalf("exportar: %v", err)
+		}
+		fmt.Printf("Exportado em %s\n", path)
+	default:
+		if err := os.WriteFile(*out, []byte(shell.RenderEnv(vars)), 0o600); err != nil {
+			log.Fatalf("exportar: %v", err)
+		}
+		fmt.Printf("Exportado em %s\n", *out)
+	}
+}
+
+func aliasesSync(args []string) {
+	fs := newCLIFlags("aliases sync", false)
+	fs.parse(args, "", 0, 0)
+	a := fs.open()
+	state := loadState(a)
+	path, err := shell.SyncAliases(state.Aliases, a.Paths())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d alias(es) gravados em %s\n", len(state.Aliases), path)
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func runKeysCLI(args []string) {
+	runGroup("keys", args, []subcommand{
+		{"list", "Lista as chaves gerenciadas", keysList},
+		{"generate", "Gera um novo par de chaves", keysGenerate},
+		{"import", "Importa chaves de um diretório (ex: ~/.ssh)", keysImport},
+	})
+}
+
+func keysList(args []string) {
+	fs := newCLIFlags("keys list", true)
+	fs.parse(args, "", 0, 0)
+	state := loadState(fs.open())
+	if *fs.json {
+		printJSON(state.Keys)
+		return
+	}
+	t := newTable("ALIAS", "NOME", "TIPO", "PROTEGIDA", "ORIGEM", "ID")
+	for _, k := range state.Keys {
+		typ := k.Type
+		if k.Bits > 0 {
+			typ += " " + strconv.Itoa(k.Bits)
+		}
+		t.row(k.Alias, k.Name, typ, yesNo(k.Protected), k.Source, k.ID)
+	}
+	t.flush()
+}
+
+func keysGenerate(args []string) {
+	fs := newCLIFlags("keys generate", false)
+	name := fs.String("name", "", "Nome da chave (obrigatório)")
+	alias := fs.String("alias", "", "Alias/diretório em ~/.fdev/keys (default: derivado do nome)")
+	keyType := fs.String("type", "ed25519", "Tipo: ed25519|rsa|ecdsa")
+	bits := fs.Int("bits", 0, "Tamanho para rsa (2048|3072|4096) ou ecdsa (256|384|521)")
+	comment := fs.String("comment", "", "Comentário da chave pública")
+	protect := fs.Bool("passphrase", false, "Pede uma passphrase para proteger a chave")
+	fs.parse(args, "", 0, 0)
+
+	a := fs.open()
+	if *alias == "" {
+		*alias = storage.SanitizeAlias(*name)
+	}
+	var passphrase []byte
+	if *protect {
+		p := readSecret("Passphrase da chave: ")
+		if readSecret("Confirmar passphrase: ") != p {
+			log.Fatal("as passphrases não conferem")
+		}
+		passphrase = []byte(p)
+	}
+
+	k := storage.Key{
+		ID:        newID(),
+		Name:      strings.TrimSpace(*name),
+		Alias:     *alias,
+		Type:      *keyType,
+		Bits:      *bits,
+		Comment:   *comment,
+		Protected: len(passphrase) > 0,
+		Source:    "generated",
+		CreatedAt: time.Now(),
+	}
+	if errs := storage.ValidateKey(k); len(errs) > 0 {
+		for _, e := range errs {
+			fmt.Printf("%s: %s\n", e.Field, e.Message)
+		}
+		fail("chave inválida")
+	}
+	for _, existing := range loadState(a).Keys {
+		if existing.Alias == k.Alias {
+			fail("alias já existe: %s", k.Alias)
+		}
+	}
+
+	result, err := ssh.GenerateKeyFull(k.Alias, k.Comment, k.Type, k.Bits, passphrase, a.Paths())
+	if err != nil {
+		log.Fatalf("gerar chave: %v", err)
+	}
+	k.PrivateKeyPath = result.PrivateKeyPath
+	k.PublicKeyPath = result.PublicKeyPath
+
+	err = a.Audit().WithRoute("cli keys generate").Update(func(state *storage.State) error {
+		state.Keys = append(state.Keys, k)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Chave %s criada: %s\n", k.Alias, k.PublicKeyPath)
+}
+
+func keysImport(args []string) {
+	fs := newCLIFlags("keys import", false)
+	names := fs.String("names", "", "Arquivos a importar, separados por vírgula (default: todos)")
+	fs.parse(args, "<diretório>", 1, 1)
+
+	a := fs.open()
+	candidates, err := ssh.ScanDir(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	selected := map[string]bool{}
+	for _, n := range splitList(*names) {
+		selected[strings.TrimSpace(n)] = true
+	}
+
+	var imported []storage.Key
+	err = a.Audit().WithRoute("cli keys import").Update(func(state *storage.State) error {
+		for _, c := range candidates {
+			if len(selected) > 0 && !selected[c.Name] {
+				continue
+			}
+			alias := state.UniqueKeyAlias(storage.SanitizeAlias(c.Name))
+			privDest, pubDest, err := ssh.CopyKeyPair(c, alias, a.Paths())
+			if err != nil {
+				a.Logger.Warn("falha ao copiar chave", "name", c.Name, "err", err)
+				continue
+			}
+			k := storage.Key{
+				ID:             newID(),
+				Name:           c.Name,
+				Alias:          alias,
+				Type:           c.Type,
+				Bits:           c.Bits,
+				Protected:      c.Protected,
+				PrivateKeyPath: privDest,
+				PublicKeyPath:  pubDest,
+				Source:         "imported",
+				OriginalPath:   c.PrivatePath,
+				CreatedAt:      time.Now(),
+			}
+			state.Keys = append(state.Keys, k)
+			imported = append(imported, k)
+		}
+		if len(imported) == 0 {
+			return errNothingImported
+		}
+		return nil
+	})
+	if errors.Is(err, errNothingImported) {
+		fail("nenhuma chave foi importada de %s", fs.Arg(0))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, k := range imported {
+		fmt.Printf("Importada: %s (%s)\n", k.Alias, k.OriginalPath)
+	}
+}
@@ -5,18 +5,23 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/exec"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/auth"
 	"github.com/seuusuario/factorydev/internal/config"
 	"github.com/seuusuario/factorydev/internal/doctor"
 	"github.com/seuusuario/factorydev/internal/handler"
+	"github.com/seuusuario/factorydev/internal/instance"
 )
 
 var Version = "dev"
@@ -27,6 +32,39 @@ func main() {
 		case "doctor":
 			runDoctorCLI()
 			return
+		case "export":
+			runExportCLI(os.Args[2:])
+			return
+		case "import":
+			runImportCLI(os.Args[2:])
+			return
+		case "auth":
+			runAuthCLI(os.Args[2:])
+			return
+		case "keys":
+			runKeysCLI(os.Args[2:])
+			return
+		case "accounts":
+			runAccountsCLI(os.Args[2:])
+			return
+		case "repos":
+			runReposCLI(os.Args[2:])
+			return
+		case "servers":
+			runServersCLI(os.Args[2:])
+			return
+		case "docker":
+			runDockerCLI(os.Args[2:])
+			return
+		case "env":
+			runEnvCLI(os.Args[2:])
+			return
+		case "aliases":
+			runAliasesCLI(os.Args[2:])
+			return
+		case "service":
+			runServiceCLI(os.Args[2:])
+			return
 		case "version":
 			fmt.Printf("FactoryDev %s\n", Version)
 			return
@@ -37,6 +75,24 @@ func main() {
 
 func runServer() {
 	cfg := config.ParseFlags(os.Args[1:])
+
+	paths, err := config.NewPaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := config.EnsureDirectories(paths); err != nil {
+		log.Fatal(err)
+	}
+	release, running, err := instance.Acquire(paths.Base, instance.Info{PID: os.Getpid(), URL: baseURL(cfg), StartedAt: time.Now()})
+	if errors.Is(err, instance.ErrRunning) {
+		openRunning(cfg, paths, running)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer release()
+
 	log.Printf("FactoryDev iniciando em %s", cfg.Addr())
 
 	a, err := app.New(cfg)
@@ -45,10 +101,14 @@ func runServer() {
 	}
 
 	h := handler.New(a)
+	go a.Scheduler.Run(context.Background())
 	srv := &http.Server{
 		Addr:    cfg.Addr(),
 		Handler: h.Routes(),
 	}
+	// Shutdown não fecha conexões assumidas (WebSocket); os shells do
+	// terminal embutido são encerrados aqui.
+	srv.RegisterOnShutdown(a.Terminals.CloseAll)
 
 	go func() {
 		a.Logger.Info("servidor iniciado", "addr", cfg.Addr())
@@ -57,17 +117,18 @@ func runServer() {
 		}
 	}()
 
-	host := cfg.Host
-	if host == "0.0.0.0" || host == "" {
-		host = "127.0.0.1"
+	serverURL := accessURL(a, cfg)
+	log.Printf("Acesse: %s", serverURL)
+	if !isLoopback(cfg.Host) && !a.Auth.HasPassword() {
+		a.Logger.Warn("servidor exposto fora do loopback sem senha; defina uma com: factorydev auth password", "host", cfg.Host)
 	}
-	serverURL := fmt.Sprintf("http://%s:%d/", host, cfg.Port)
 
 	// Webview nativo: bloqueia a main thread (requisito Cocoa/GTK).
 	// Quando webview está ativo, não roda systray (ambos precisam da
-	// main thread no macOS — são mutuamente exclusivos).
-	if hasWebview() {
-		a.Logger.Info("abrindo janela nativa", "url", serverURL)
+	// main thread no macOS — são mutuamente exclusivos). Com
+	// --open-browser=false (serviço) o servidor roda sem janela.
+	if hasWebview() && cfg.OpenBrowser {
+		a.Logger.Info("abrindo janela nativa", "addr", cfg.Addr())
 		go func() {
 			quit := make(chan os.Signal, 1)
 			webviewSignalNotify(quit)
@@ -75,6 +136,7 @@ func runServer() {
 			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 			defer cancel()
 			_ = srv.Shutdown(ctx)
+			release()
 			os.Exit(0)
 		}()
 		runWebview(serverURL, cfg.Debug) // bloqueia na main thread
@@ -86,7 +148,7 @@ func runServer() {
 		go runSystray(serverURL)
 	}
 	if cfg.OpenBrowser {
-		go openBrowser(a, cfg)
+		go openBrowser(a.Logger, cfg, serverURL)
 	}
 
 	quit := make(chan os.Signal, 1)
@@ -100,17 +162,58 @@ func runServer() {
 	}
 }
 
-func openBrowser(a *app.App, cfg *config.Config) {
-	time.Sleep(250 * time.Millisecond)
+// baseURL é o endereço local da interface, sem token.
+func baseURL(cfg *config.Config) string {
 	host := cfg.Host
 	if host == "0.0.0.0" || host == "" {
 		host = "127.0.0.1"
 	}
-	url := fmt.Sprintf("http://%s:%d/", host, cfg.Port)
+	return "http://" + net.JoinHostPort(host, strconv.Itoa(cfg.Port))
+}
+
+// accessURL é a URL de entrada com o token de acesso, que abre a sessão no
+// navegador sem passar pela tela de login.
+func accessURL(a *app.App, cfg *config.Config) string {
+	return tokenURL(baseURL(cfg), a.Auth.Token())
+}
+
+func tokenURL(base, token string) string {
+	return fmt.Sprintf("%s/?%s=%s", base, auth.TokenParam, token)
+}
+
+// openRunning abre a interface da instância que já está no ar (ex: o
+// serviço do usuário) em vez de subir um segundo servidor na porta ocupada.
+func openRunning(cfg *config.Config, paths *config.Paths, running instance.Info) {
+	if running.URL == "" {
+		log.Fatal("o FactoryDev já está em execução, mas o endereço não foi registrado em instance.json")
+	}
+	mgr, err := auth.Load(paths.Auth)
+	if err != nil {
+		log.Fatal(err)
+	}
+	url := tokenURL(running.URL, mgr.Token())
+	log.Printf("FactoryDev já está em execução (pid %d) em %s", running.PID, running.URL)
+	if !cfg.OpenBrowser {
+		log.Printf("Acesse: %s", url)
+		return
+	}
+	if hasWebview() {
+		runWebview(url, cfg.Debug)
+		return
+	}
+	openBrowser(slog.Default(), cfg, url)
+}
+
+func isLoopback(host string) bool {
+	return auth.Policy{BindHost: host}.Loopback()
+}
+
+func openBrowser(logger *slog.Logger, cfg *config.Config, url string) {
+	time.Sleep(250 * time.Millisecond)
 
 	if runtime.GOOS == "linux" {
 		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
-			a.Logger.Warn("sem sessão gráfica; não foi possível abrir navegador automaticamente", "url", url)
+			logger.Warn("sem sessão gráfica; não foi possível abrir navegador automaticamente", "url", url)
 			log.Printf("Abra manualmente: %s", url)
 			return
 		}
@@ -153,7 +256,7 @@ func openBrowser(a *app.App, cfg *config.Config) {
 		}
 		candidates = append(candidates, []string{"rundll32", "url.dll,FileProtocolHandler", url})
 	default:
-		a.Logger.Info("sistema não suportado para auto-open", "os", runtime.GOOS)
+		logger.Info("sistema não suportado para auto-open", "os", runtime.GOOS)
 		log.Printf("Abra manualmente: %s", url)
 		return
 	}
@@ -165,23 +268,23 @@ func openBrowser(a *app.App, cfg *config.Config) {
 			continue
 		}
 
-		a.Logger.Debug("tentando abrir navegador", "cmd", c[0], "args", c[1:])
+		logger.Debug("tentando abrir navegador", "cmd", c[0], "args", c[1:])
 
 		cmd := exec.Command(c[0], c[1:]...)
 		out, err := cmd.CombinedOutput()
 		if err != nil {
 			if len(out) > 0 {
-				a.Logger.Warn("falha ao abrir com comando", "cmd", c[0], "err", err, "output", string(out))
+				logger.Warn("falha ao abrir com comando", "cmd", c[0], "err", err, "output", string(out))
 			} else {
-				a.Logger.Warn("falha ao abrir com comando", "cmd", c[0], "err", err)
+				logger.Warn("falha ao abrir com comando", "cmd", c[0], "err", err)
 			}
 			openErr = errors.Join(openErr, err)
 			continue
 		}
-		a.Logger.Info("navegador aberto automaticamente", "url", url, "cmd", c[0])
+		logger.Info("navegador aberto automaticamente", "url", url, "cmd", c[0])
 		return
 	}
-	a.Logger.Warn("falha ao abrir navegador automaticamente", "err", openErr, "url", url)
+	logger.Warn("falha ao abrir navegador automaticamente", "err", openErr, "url", url)
 	log.Printf("Abra manualmente: %s", url)
 }
 
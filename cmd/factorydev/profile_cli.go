@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/profile"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+	"golang.org/x/term"
+)
+
+// profilePassEnv permite informar a passphrase do arquivo sem prompt
+// (scripts de provisionamento).
+const profilePassEnv = "FDEV_PROFILE_PASSPHRASE"
+
+func runExportCLI(args []string) {
+	fs := flag.NewFlagSet("factorydev export", flag.ExitOnError)
+	out := fs.String("out", "", "Arquivo de saída (default: factorydev-<data>"+profile.FileExt+")")
+	sections := fs.String("sections", "", "Seções separadas por vírgula: "+strings.Join(profile.AllSections(), ","))
+	backend := fs.String("storage", "", "Backend de persistência: json|sqlite (default: config.json)")
+	workspace := fs.String("workspace", "", "Workspace (default: o último ativo)")
+	_ = fs.Parse(args)
+
+	a := openCLIApp(*backend, *workspace)
+	secs, err := profile.ParseSections(splitList(*sections))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *out == "" {
+		*out = "factorydev-" + time.Now().Format("20060102-150405") + profile.FileExt
+	}
+
+	pass := os.Getenv(profilePassEnv)
+	if pass == "" {
+		pass = readSecret("Passphrase do arquivo: ")
+		if readSecret("Confirmar passphrase: ") != pass {
+			log.Fatal("as passphrases não conferem")
+		}
+	}
+
+	state, err := a.Storage().LoadState()
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts := profile.ExportOptions{
+		Sections:      secs,
+		Passphrase:    pass,
+		Secrets:       a.Vault,
+		GitConfigPath: filepath.Join(a.Paths().Home, ".gitconfig"),
+	}
+	data, err := profile.Export(state, a.Paths(), opts)
+	if errors.Is(err, vault.ErrLocked) {
+		unlockVaultCLI(a)
+		data, err = profile.Export(state, a.Paths(), opts)
+	}
+	if err != nil {
+		log.Fatalf("exportar perfil: %v", err)
+	}
+	if err := os.WriteFile(*out, data, 0o600); err != nil {
+		log.Fatalf("gravar %s: %v", *out, err)
+	}
+	fmt.Printf("Perfil exportado para %s (%s)\n", *out, strings.Join(secs, ", "))
+}
+
+func runImportCLI(args []string) {
+	fs := flag.NewFlagSet("factorydev import", flag.ExitOnError)
+	sections := fs.String("sections", "", "Seções a importar, separadas por vírgula (default: todas do arquivo)")
+	overwrite := fs.Bool("overwrite", false, "Substitui registros existentes em caso de conflito")
+	applySSH := fs.Bool("apply-ssh", true, "Reaplica os blocos do ~/.ssh/config das contas importadas")
+	applyIncludeIf := fs.Bool("apply-includeif", true, "Recria as regras includeIf no ~/.gitconfig")
+	backend := fs.String("storage", "", "Backend de persistência: json|sqlite (default: config.json)")
+	workspace := fs.String("workspace", "", "Workspace (default: o último ativo)")
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "uso: factorydev import [flags] <arquivo"+profile.FileExt+">")
+		fs.PrintDefaults()
+		os.Exit(2)
+	}
+
+	a := openCLIApp(*backend, *workspace)
+	secs, err := profile.ParseSections(splitList(*sections))
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	pass := os.Getenv(profilePassEnv)
+	if pass == "" {
+		pass = readSecret("Passphrase do arquivo: ")
+	}
+	bundle, err := profile.Open(data, pass)
+	if err != nil {
+		log.Fatalf("abrir perfil: %v", err)
+	}
+
+	// O vault local sela os segredos importados; desbloqueia antes de abrir
+	// a transação para não prender o lock do state esperando o prompt.
+	if a.Vault.Initialized() && a.Vault.Locked() {
+		unlockVaultCLI(a)
+	}
+
+	opts := profile.ImportOptions{Sections: secs, Overwrite: *overwrite, Secrets: a.Vault}
+	var (
+		res   *profile.Result
+		saved *storage.State
+	)
+	err = a.Audit().WithRoute("cli import").Update(func(state *storage.State) error {
+		var err error
+		res, err = profile.Merge(state, bundle, a.Paths(), opts)
+		saved = state
+		return err
+	})
+	if err != nil {
+		log.Fatalf("importar perfil: %v", err)
+	}
+
+	if *applySSH && len(res.Accounts) > 0 {
+		if err := profile.ApplySSHConfig(saved, res.Accounts, a.Paths()); err != nil {
+			fmt.Fprintf(os.Stderr, "aviso: %v\n", err)
+		}
+	}
+	if *applyIncludeIf && bundle.Manifest.Has(profile.SectionIncludeIf) && containsSection(secs, profile.SectionIncludeIf) {
+		c, err := profile.ApplyIncludeIf(bundle, filepath.Join(a.Paths().Home, ".gitconfig"), a.Paths(), *overwrite)
+		res.Counts[profile.SectionIncludeIf] = c
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "aviso: %v\n", err)
+		}
+	}
+
+	fmt.Printf("Perfil de %s importado:\n", bundle.Manifest.Hostname)
+	for _, line := range res.Summary() {
+		fmt.Println("  " + line)
+	}
+}
+
+// openCLIApp inicializa o App para os subcomandos de linha de comando, com a
+// mesma configuração (config.json + FDEV_*) do servidor.
+func openCLIApp(backend, workspace string) *app.App {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if backend != "" {
+		cfg.Storage = backend
+	}
+	if workspace != "" {
+		cfg.Workspace = workspace
+	}
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	a, err := app.New(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return a
+}
+
+func unlockVaultCLI(a *app.App) {
+	if err := a.Vault.Unlock(readSecret("Passphrase do vault: ")); err != nil {
+		log.Fatalf("desbloquear vault: %v", err)
+	}
+}
+
+// readSecret lê uma linha do terminal sem eco. Fora de um TTY lê do stdin.
+func readSecret(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return string(b)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		log.Fatal("passphrase não informada")
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+func splitList(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func containsSection(sections []string, id string) bool {
+	for _, s := range sections {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	igit "github.com/seuusuario/factorydev/internal/git"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func runReposCLI(args []string) {
+	runGroup("repos", args, []subcommand{
+		{"list", "Lista os repositórios cadastrados", reposList},
+		{"clone", "Clona um repositório com a chave de uma conta", reposClone},
+		{"pull-all", "Executa git pull em todos os repositórios", reposPullAll},
+		{"status", "Mostra branch e alterações de cada repositório", reposStatus},
+	})
+}
+
+func reposList(args []string) {
+	fs := newCLIFlags("repos list", true)
+	fs.parse(args, "", 0, 0)
+	state := loadState(fs.open())
+	if *fs.json {
+		printJSON(state.Repositories)
+		return
+	}
+	t := newTable("NOME", "CONTA", "CAMINHO", "ID")
+	for _, repo := range state.Repositories {
+		t.row(repo.Name, orDash(accountName(state, repo.AccountID)), repo.LocalPath, repo.ID)
+	}
+	t.flush()
+}
+
+func reposClone(args []string) {
+	fs := newCLIFlags("repos clone", false)
+	accountRef := fs.String("account", "", "Conta (ID, host alias ou nome) cuja chave será usada")
+	fs.parse(args, "<url> <diretório>", 2, 2)
+	if *accountRef == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	rawURL, destDir := fs.Arg(0), fs.Arg(1)
+
+	a := fs.open()
+	state := loadState(a)
+	account := findAccountArg(state, *accountRef)
+	identityPath := state.AccountKeyPath(account, a.Paths().Home)
+	if identityPath == "" {
+		fail("a conta %s não possui chave privada", account.HostAlias)
+	}
+	if _, err := os.Stat(identityPath); err != nil {
+		fail("chave privada não encontrada em: %s", identityPath)
+	}
+	sshURL, err := a.GitService.BuildSSHURL(rawURL, account.HostAlias)
+	if err != nil {
+		fail("URL de repositório inválida: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	output, err := a.GitService.CloneRepo(ctx, sshURL, destDir, identityPath)
+	fmt.Print(output)
+	if err != nil {
+		fail("falha no clone: %v", err)
+	}
+
+	repo := storage.Repository{
+		ID:        newID(),
+		AccountID: account.ID,
+		Name:      igit.RepoNameFromURL(rawURL),
+		URL:       rawURL,
+		LocalPath: destDir,
+		ClonedAt:  time.Now(),
+	}
+	err = a.Audit().WithRoute("cli repos clone").Update(func(st *storage.State) error {
+		st.Repositories = append(st.Repositories, repo)
+		return nil
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Clonado em %s\n", destDir)
+}
+
+// pullResult é o resultado de um repositório em `repos pull-all --json`.
+type pullResult struct {
+	Repo   string `json:"repo"`
+	Path   string `json:"path"`
+	OK     bool   `json:"ok"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func reposPullAll(args []string) {
+	fs := newCLIFlags("repos pull-all", true)
+	force := fs.Bool("force", false, "Descarta alterações locais (reset para o remoto)")
+	fs.parse(args, "", 0, 0)
+
+	a := fs.open()
+	state := loadState(a)
+	keyMap := make(map[string]string, len(state.Accounts))
+	for _, acc := range state.Accounts {
+		keyMap[acc.ID] = state.AccountKeyPath(acc, a.Paths().Home)
+	}
+
+	results := make([]pullResult, len(state.Repositories))
+	var wg sync.WaitGroup
+	for i, repo := range state.Repositories {
+		wg.Add(1)
+		go func(idx int, r storage.Repository) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
+			defer cancel()
+
+			svc := igit.NewService()
+			var output string
+			var err error
+			if *force {
+				output, err = svc.PullForce(ctx, r.LocalPath, keyMap[r.AccountID])
+			} else {
+				output, err = svc.Pull(ctx, r.LocalPath, keyMap[r.AccountID])
+			}
+			results[idx] = pullResult{Repo: r.Name, Path: r.LocalPath, OK: err == nil, Output: output}
+			if err != nil {
+				results[idx].Error = err.Error()
+			}
+		}(i, repo)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, res := range results {
+		if !res.OK {
+			failed++
+		}
+	}
+	if *fs.json {
+		printJSON(results)
+	} else {
+		t := newTable("REPOSITÓRIO", "RESULTADO")
+		for _, res := range results {
+			status := "ok"
+			if !res.OK {
+				status = "erro: " + res.Error
+			}
+			t.row(res.Repo, status)
+		}
+		t.flush()
+	}
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// repoStatus é uma linha de `repos status --json`.
+type repoStatus struct {
+	Repo string `json:"repo"`
+	Path string `json:"path"`
+	igit.RepoStatus
+}
+
+func reposStatus(args []string) {
+	fs := newCLIFlags("repos status", true)
+	fs.parse(args, "", 0, 0)
+	state := loadState(fs.open())
+
+	out := make([]repoStatus, len(state.Repositories))
+	var wg sync.WaitGroup
+	for i, repo := range state.Repositories {
+		wg.Add(1)
+		go func(idx int, r storage.Repository) {
+			defer wg.Done()
+			out[idx] = repoStatus{Repo: r.Name, Path: r.LocalPath, RepoStatus: igit.Status(r.LocalPath)}
+		}(i, repo)
+	}
+	wg.Wait()
+
+	if *fs.json {
+		printJSON(out)
+		return
+	}
+	t := newTable("REPOSITÓRIO", "BRANCH", "STATUS", "CAMINHO")
+	for _, s := range out {
+		t.row(s.Repo, s.Branch, s.Summary, s.Path)
+	}
+	t.flush()
+}
+
+func accountName(state *storage.State, id string) string {
+	for _, acc := range state.Accounts {
+		if acc.ID == id {
+			return acc.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func runServersCLI(args []string) {
+	runGroup("servers", args, []subcommand{
+		{"list", "Lista os servidores cadastrados", serversList},
+		{"test", "Testa a conexão SSH com um servidor", serversTest},
+		{"send", "Envia um arquivo para o servidor (scp)", serversSend},
+	})
+}
+
+func serversList(args []string) {
+	fs := newCLIFlags("servers list", true)
+	fs.parse(args, "", 0, 0)
+	state := loadState(fs.open())
+	if *fs.json {
+		printJSON(state.Servers)
+		return
+	}
+	t := newTable("NOME", "DESTINO", "CHAVE", "TAGS", "ID")
+	for _, s := range state.Servers {
+		key := "-"
+		if k := state.FindKey(s.KeyID); k != nil {
+			key = k.Alias
+		}
+		port := s.Port
+		if port == 0 {
+			port = 22
+		}
+		dest := s.User + "@" + s.Host + ":" + strconv.Itoa(port)
+		t.row(s.Name, dest, key, orDash(strings.Join(s.Tags, ",")), s.ID)
+	}
+	t.flush()
+}
+
+func serversTest(args []string) {
+	fs := newCLIFlags("servers test", false)
+	fs.parse(args, "<id|nome>", 1, 1)
+	state := loadState(fs.open())
+	target, name := serverTargetArg(state, fs.Arg(0))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	out, err := ssh.TestServer(ctx, target)
+	if out != "" {
+		fmt.Println(out)
+	}
+	if err != nil {
+		fail("falha na conexão com %s: %v", name, err)
+	}
+	fmt.Printf("Conexão SSH com %s bem-sucedida\n", name)
+}
+
+func serversSend(args []string) {
+	fs := newCLIFlags("servers send", false)
+	fs.parse(args, "<id|nome> <arquivo local> <destino remoto>", 3, 3)
+	state := loadState(fs.open())
+	target, name := serverTargetArg(state, fs.Arg(0))
+	if _, err := os.Stat(fs.Arg(1)); err != nil {
+		fail("arquivo local: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	out, err := ssh.SendFile(ctx, target, fs.Arg(1), fs.Arg(2))
+	if out != "" {
+		fmt.Println(out)
+	}
+	if err != nil {
+		fail("erro no envio para %s: %v", name, err)
+	}
+	fmt.Printf("Arquivo enviado para %s:%s\n", name, fs.Arg(2))
+}
+
+// serverTargetArg localiza o servidor por ID ou nome e resolve a chave.
+func serverTargetArg(state *storage.State, ref string) (ssh.Target, string) {
+	for _, s := range state.Servers {
+		if s.ID != ref && s.Name != ref {
+			continue
+		}
+		t := ssh.Target{User: s.User, Host: s.Host, Port: s.Port}
+		if k := state.FindKey(s.KeyID); k != nil {
+			t.KeyPath = k.PrivateKeyPath
+		}
+		return t, s.Name
+	}
+	fail("servidor não encontrado: %s", ref)
+	return ssh.Target{}, ""
+}
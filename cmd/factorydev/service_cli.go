@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/service"
+)
+
+func runServiceCLI(args []string) {
+	runGroup("service", args, []subcommand{
+		{"install", "Instala e inicia o serviço do usuário (flags extras vão para o servidor)", serviceInstall},
+		{"uninstall", "Para e remove o serviço", serviceUninstall},
+		{"status", "Mostra o estado do serviço", serviceStatus},
+		{"logs", "Mostra os logs do serviço [-f] [-n linhas]", serviceLogs},
+	})
+}
+
+func newService(extraArgs []string) *service.Service {
+	paths, err := config.NewPaths()
+	if err != nil {
+		log.Fatal(err)
+	}
+	s, err := service.New(paths.Logs, extraArgs...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return s
+}
+
+func serviceInstall(args []string) {
+	s := newService(args)
+	if err := s.Install(); err != nil {
+		log.Fatal(err)
+	}
+	path, _ := s.Path()
+	fmt.Printf("Serviço instalado em %s e iniciado.\n", path)
+	fmt.Println("Abra a interface com: factorydev (ou factorydev auth token para a URL com token).")
+}
+
+func serviceUninstall(args []string) {
+	s := newService(nil)
+	if err := s.Uninstall(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Serviço removido.")
+}
+
+func serviceStatus(args []string) {
+	out, err := newService(nil).Status()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Print(out)
+}
+
+func serviceLogs(args []string) {
+	fs := flag.NewFlagSet("factorydev service logs", flag.ExitOnError)
+	follow := fs.Bool("f", false, "Acompanha novas linhas")
+	lines := fs.Int("n", 100, "Quantidade de linhas")
+	_ = fs.Parse(args)
+
+	cmd, err := newService(nil).LogsCommand(*lines, *follow)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		log.Fatal(err)
+	}
+}
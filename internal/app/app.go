@@ -3,19 +3,84 @@ package app
 import (
 	"fmt"
 	"log/slog"
+	"sync"
+	"time"
 
+	"github.com/seuusuario/factorydev/internal/auth"
+	"github.com/seuusuario/factorydev/internal/command"
 	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/events"
 	"github.com/seuusuario/factorydev/internal/git"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/privilege"
+	"github.com/seuusuario/factorydev/internal/scheduler"
+	fdevssh "github.com/seuusuario/factorydev/internal/ssh"
 	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/terminal"
+	"github.com/seuusuario/factorydev/internal/vault"
 )
 
+// VaultIdleTimeout é o tempo sem uso após o qual o vault volta a bloquear.
+const VaultIdleTimeout = 15 * time.Minute
+
+// JobTTL é por quanto tempo um job terminado continua em memória; depois
+// disso só aparece no histórico.
+const JobTTL = time.Hour
+
 type App struct {
-	Config     *config.Config
-	Storage    storage.Storage
 	Logger     *slog.Logger
-	Paths      *config.Paths
 	SSHService *SSHService
 	GitService *git.Service
+	Vault      *vault.Vault
+	// Auth guarda o token de acesso e a senha da interface web.
+	Auth *auth.Manager
+	// Jobs executa e registra as operações em segundo plano.
+	Jobs *jobs.Manager
+	// Terminals guarda as sessões do terminal embutido (shell local, SSH e
+	// docker exec) e as gravações delas.
+	Terminals *terminal.Manager
+	// Events leva jobs, status do Docker e métricas ao stream SSE.
+	Events *events.Bus
+	// Scheduler dispara os agendamentos do workspace ativo; as ações são
+	// registradas pelo handler e o loop é iniciado por quem sobe o servidor.
+	Scheduler *scheduler.Scheduler
+	// Commands executa os comandos de sistema de todos os pacotes, com
+	// histórico e dry-run (ver internal/command).
+	Commands *command.System
+	// Privilege eleva os comandos de administrador, pedindo a senha pela
+	// interface quando o sudo exige.
+	Privilege *privilege.Runner
+
+	basePaths *config.Paths
+	wsMu      sync.Mutex // serializa trocas de workspace
+
+	// cfg é trocado inteiro por UpdateConfig (copy-on-write), nunca
+	// alterado no lugar.
+	cfgMu sync.RWMutex
+	cfg   *config.Config
+
+	// cur é o workspace aberto; Workspace, Paths, Storage e Audit o leem
+	// sob curMu. Uma troca substitui o ponteiro inteiro.
+	curMu sync.RWMutex
+	cur   *openWorkspace
+}
+
+// Config devolve a configuração em vigor. O valor é compartilhado entre
+// requisições e não deve ser alterado: mudanças passam por UpdateConfig.
+func (a *App) Config() *config.Config {
+	a.cfgMu.RLock()
+	defer a.cfgMu.RUnlock()
+	return a.cfg
+}
+
+// UpdateConfig aplica fn a uma cópia da configuração e põe a cópia em vigor.
+// Quem leu a anterior continua com ela, sem ver uma alteração pela metade.
+func (a *App) UpdateConfig(fn func(*config.Config)) {
+	a.cfgMu.Lock()
+	defer a.cfgMu.Unlock()
+	next := *a.cfg
+	fn(&next)
+	a.cfg = &next
 }
 
 type SSHService struct{}
@@ -36,17 +101,81 @@ func New(cfg *config.Config) (*App, error) {
 		return nil, fmt.Errorf("inicializar logger: %w", err)
 	}
 
-	st := storage.NewJSONStorage(paths.State)
-	if _, err := st.LoadState(); err != nil {
-		return nil, fmt.Errorf("carregar state inicial: %w", err)
+	authMgr, err := auth.Load(paths.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("carregar credenciais: %w", err)
+	}
+
+	if cfg.BackupRetention > 0 {
+		fdevssh.SetBackupRetention(cfg.BackupRetention)
 	}
 
-	return &App{
-		Config:     cfg,
-		Storage:    st,
+	a := &App{
+		cfg:        cfg,
 		Logger:     logger,
-		Paths:      paths,
 		SSHService: &SSHService{},
 		GitService: git.NewService(),
-	}, nil
+		// O vault é global: segredos selados continuam válidos quando
+		// registros são movidos entre workspaces.
+		Vault:     vault.New(paths.Vault, VaultIdleTimeout),
+		Auth:      authMgr,
+		Jobs:      jobs.New(paths.Jobs, JobTTL),
+		Events:    events.New(),
+		Terminals: terminal.New(paths.Recordings),
+		basePaths: paths,
+	}
+	a.Jobs.Events = a.Events
+	a.Commands = command.NewSystem(command.NewHistory(command.DefaultHistorySize))
+	a.Commands.SetDryRun(cfg.DryRun)
+	command.SetDefault(a.Commands)
+	a.Privilege = privilege.New(a.Events, cfg.Privilege, paths.Base)
+	privilege.SetDefault(a.Privilege)
+	a.Jobs.Hold = a.Hold
+	a.Scheduler = scheduler.New(a.Jobs, a.HoldStorage, logger)
+
+	reg, err := config.LoadWorkspaces(paths.Base)
+	if err != nil {
+		return nil, err
+	}
+	name := cfg.Workspace
+	if name == "" {
+		name = reg.Active
+	}
+	ws, ok := reg.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", config.ErrWorkspaceNotFound, name)
+	}
+	wsPaths, rec, err := a.OpenWorkspace(ws)
+	if err != nil {
+		return nil, err
+	}
+	a.cur = &openWorkspace{ws: ws, paths: wsPaths, rec: rec}
+	return a, nil
+}
+
+// openStorage escolhe o backend de persistência conforme cfg.Storage. No
+// SQLite, um banco recém-criado importa o state.json existente uma única vez.
+func openStorage(cfg *config.Config, paths *config.Paths, logger *slog.Logger) (storage.Storage, error) {
+	if cfg.Storage != "sqlite" {
+		return storage.NewJSONStorage(paths.State), nil
+	}
+
+	st, err := storage.NewSQLiteStorage(paths.StateDB)
+	if err != nil {
+		return nil, fmt.Errorf("abrir sqlite: %w", err)
+	}
+	empty, err := st.Empty()
+	if err != nil {
+		return nil, err
+	}
+	if empty {
+		imported, err := st.ImportJSON(paths.State)
+		if err != nil {
+			return nil, fmt.Errorf("importar state.json: %w", err)
+		}
+		if imported {
+			logger.Info("state.json importado para sqlite", "db", paths.StateDB, "backup", paths.State+".bak")
+		}
+	}
+	return st, nil
 }
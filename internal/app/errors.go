@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	fdevssh "github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/vault"
 )
 
 type AppError struct {
@@ -36,6 +37,10 @@ func FriendlyMessage(err error) string {
 		return "Arquivo não encontrado"
 	case errors.Is(err, fdevssh.ErrKeyExists):
 		return "Chave já existe para este alias. Deseja sobrescrever?"
+	case errors.Is(err, vault.ErrLocked):
+		return "Vault bloqueado: desbloqueie para acessar os segredos"
+	case errors.Is(err, vault.ErrWrongPassphrase):
+		return "Passphrase do vault incorreta"
 	case strings.Contains(s, "no reachable address"):
 		return "Não foi possível conectar ao host"
 	default:
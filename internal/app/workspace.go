@@ -0,0 +1,427 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/seuusuario/factorydev/internal/audit"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// openWorkspace é um workspace aberto como ativo. users conta os usos em
+// andamento (requisições, jobs, avaliações do scheduler); o storage só é
+// fechado depois que todos terminam.
+type openWorkspace struct {
+	ws    config.Workspace
+	paths *config.Paths
+	rec   *audit.Recorder
+	users sync.WaitGroup
+}
+
+// hold marca um uso do workspace aberto e o devolve.
+func (a *App) hold() (*openWorkspace, func()) {
+	a.curMu.RLock()
+	defer a.curMu.RUnlock()
+	a.cur.users.Add(1)
+	return a.cur, sync.OnceFunc(a.cur.users.Done)
+}
+
+func (a *App) active() *openWorkspace {
+	a.curMu.RLock()
+	defer a.curMu.RUnlock()
+	return a.cur
+}
+
+// Workspace devolve o workspace aberto.
+func (a *App) Workspace() config.Workspace { return a.active().ws }
+
+// Paths devolve os caminhos do workspace aberto.
+func (a *App) Paths() *config.Paths { return a.active().paths }
+
+// Storage devolve o storage do workspace aberto. Quem o guarda além de uma
+// chamada deve usar HoldStorage, para que uma troca de workspace não o
+// feche no meio do uso.
+func (a *App) Storage() storage.Storage { return a.active().rec }
+
+// Audit é o mesmo storage de Storage; expõe WithRoute e Undo.
+func (a *App) Audit() *audit.Recorder { return a.active().rec }
+
+// HoldStorage devolve o storage do workspace aberto e marca um uso dele:
+// uma troca de workspace só o fecha depois que release for chamada.
+func (a *App) HoldStorage() (st storage.Storage, release func()) {
+	cur, release := a.hold()
+	return cur.rec, release
+}
+
+// HoldWorkspace fixa o workspace aberto para quem grava nele depois da
+// requisição (jobs): os caminhos e o storage devolvidos continuam os dele
+// mesmo após uma troca, que só o fecha depois que release for chamada.
+func (a *App) HoldWorkspace() (paths *config.Paths, rec *audit.Recorder, release func()) {
+	cur, release := a.hold()
+	return cur.paths, cur.rec, release
+}
+
+// Hold é HoldStorage sem o storage, para quem só precisa mantê-lo aberto
+// (requisições HTTP e jobs).
+func (a *App) Hold() (release func()) {
+	_, release = a.HoldStorage()
+	return release
+}
+
+// Workspaces retorna o registro de workspaces.
+func (a *App) Workspaces() (*config.Workspaces, error) {
+	return config.LoadWorkspaces(a.basePaths.Base)
+}
+
+// OpenWorkspace abre o storage do workspace ws (criando diretórios se
+// preciso) sem torná-lo ativo. O chamador deve fechar o Recorder retornado
+// quando ele não for o storage do App.
+func (a *App) OpenWorkspace(ws config.Workspace) (*config.Paths, *audit.Recorder, error) {
+	paths := a.basePaths.ForWorkspace(ws)
+	if err := config.EnsureDirectories(paths); err != nil {
+		return nil, nil, fmt.Errorf("garantir diretórios: %w", err)
+	}
+	st, err := openStorage(a.Config(), paths, a.Logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	rec := audit.NewRecorder(st, audit.NewLog(paths.Audit), a.Logger)
+	if _, err := st.LoadState(); err != nil {
+		_ = rec.Close()
+		return nil, nil, fmt.Errorf("carregar state do workspace %s: %w", ws.Name, err)
+	}
+	return paths, rec, nil
+}
+
+// CreateWorkspace registra e inicializa um novo workspace.
+func (a *App) CreateWorkspace(ws config.Workspace) error {
+	a.wsMu.Lock()
+	defer a.wsMu.Unlock()
+	reg, err := a.Workspaces()
+	if err != nil {
+		return err
+	}
+	if err := reg.Add(ws); err != nil {
+		return err
+	}
+	ws, _ = reg.Get(ws.Name)
+	_, rec, err := a.OpenWorkspace(ws)
+	if err != nil {
+		return err
+	}
+	_ = rec.Close()
+	return reg.Save(a.basePaths.Base)
+}
+
+// RemoveWorkspace tira o workspace do registro. Os arquivos em
+// ~/.fdev/workspaces/<nome> são mantidos para recuperação manual.
+func (a *App) RemoveWorkspace(name string) error {
+	a.wsMu.Lock()
+	defer a.wsMu.Unlock()
+	if name == a.Workspace().Name {
+		return errors.New("troque de workspace antes de remover o ativo")
+	}
+	reg, err := a.Workspaces()
+	if err != nil {
+		return err
+	}
+	if err := reg.Remove(name); err != nil {
+		return err
+	}
+	return reg.Save(a.basePaths.Base)
+}
+
+// SwitchWorkspace troca o workspace ativo em tempo de execução e o grava
+// como padrão para a próxima inicialização.
+func (a *App) SwitchWorkspace(name string) error {
+	a.wsMu.Lock()
+	defer a.wsMu.Unlock()
+	reg, err := a.Workspaces()
+	if err != nil {
+		return err
+	}
+	ws, ok := reg.Get(name)
+	if !ok {
+		return fmt.Errorf("%w: %s", config.ErrWorkspaceNotFound, name)
+	}
+	paths, rec, err := a.OpenWorkspace(ws)
+	if err != nil {
+		return err
+	}
+
+	a.curMu.Lock()
+	old := a.cur
+	a.cur = &openWorkspace{ws: ws, paths: paths, rec: rec}
+	a.curMu.Unlock()
+	go func() {
+		old.users.Wait()
+		if err := old.rec.Close(); err != nil {
+			a.Logger.Warn("fechar storage do workspace", "workspace", old.ws.Name, "err", err)
+		}
+	}()
+	a.Logger.Info("workspace ativo", "workspace", name)
+
+	reg.Active = name
+	return reg.Save(a.basePaths.Base)
+}
+
+// ── Transferência entre workspaces ─────────────────────────────
+
+// TransferOptions descreve uma cópia (ou movimentação) de registros do
+// workspace ativo para outro.
+type TransferOptions struct {
+	Target string
+	Entity string // nome do EntitySet (keys, accounts, servers...)
+	IDs    []string
+	Move   bool
+	Route  string // origem registrada no audit log
+}
+
+// TransferResult resume a transferência.
+type TransferResult struct {
+	Copied  int
+	Keys    int      // chaves copiadas como dependência
+	Skipped []string // motivo por registro ignorado
+}
+
+// TransferEntities copia os registros para o workspace Target. Chaves
+// referenciadas (keyId) que não existem no destino são copiadas junto, com os
+// arquivos quando o destino tem diretório de chaves próprio. Com Move, os
+// registros copiados são removidos da origem; chaves ainda em uso na origem
+// não são movidas.
+func (a *App) TransferEntities(opts TransferOptions) (*TransferResult, error) {
+	cur, release := a.hold()
+	defer release()
+	if opts.Target == cur.ws.Name {
+		return nil, errors.New("origem e destino são o mesmo workspace")
+	}
+	reg, err := a.Workspaces()
+	if err != nil {
+		return nil, err
+	}
+	ws, ok := reg.Get(opts.Target)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", config.ErrWorkspaceNotFound, opts.Target)
+	}
+	srcPaths, srcStore := cur.paths, cur.rec
+	dstPaths, dstRec, err := a.OpenWorkspace(ws)
+	if err != nil {
+		return nil, err
+	}
+	defer dstRec.Close()
+
+	src, err := srcStore.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	srcSet := storage.EntitySetByName(src, opts.Entity)
+	if srcSet == nil {
+		return nil, fmt.Errorf("entidade desconhecida: %s", opts.Entity)
+	}
+	records, _, err := srcSet.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	srcKeys := make(map[string]storage.Key, len(src.Keys))
+	for _, k := range src.Keys {
+		srcKeys[k.ID] = k
+	}
+
+	res := &TransferResult{}
+	var moved []string
+	err = dstRec.WithRoute(opts.Route).Update(func(dst *storage.State) error {
+		dstSet := storage.EntitySetByName(dst, opts.Entity)
+		existing, _, err := dstSet.Snapshot()
+		if err != nil {
+			return err
+		}
+		dstKeys := map[string]bool{}
+		for _, k := range dst.Keys {
+			dstKeys[k.ID] = true
+		}
+		// Caminhos de chave reescritos, para ajustar identityFile.
+		rewritten := map[string]string{}
+
+		copyKey := func(k storage.Key) error {
+			nk, err := copyKeyFiles(k, srcPaths, dstPaths)
+			if err != nil {
+				return err
+			}
+			if nk.PrivateKeyPath != k.PrivateKeyPath {
+				rewritten[k.PrivateKeyPath] = nk.PrivateKeyPath
+			}
+			dst.Keys = append(dst.Keys, nk)
+			dstKeys[k.ID] = true
+			return nil
+		}
+
+		for _, id := range opts.IDs {
+			data, ok := records[id]
+			if !ok {
+				res.Skipped = append(res.Skipped, id+": não encontrado")
+				continue
+			}
+			if _, ok := existing[id]; ok {
+				res.Skipped = append(res.Skipped, id+": já existe no destino")
+				continue
+			}
+
+			if opts.Entity == "keys" {
+				k := srcKeys[id]
+				if opts.Move && keyReferenced(src, id) {
+					res.Skipped = append(res.Skipped, k.Alias+": chave em uso no workspace atual")
+					continue
+				}
+				if err := copyKey(k); err != nil {
+					return err
+				}
+				res.Copied++
+				moved = append(moved, id)
+				continue
+			}
+
+			if keyID := jsonString(data, "keyId"); keyID != "" && !dstKeys[keyID] {
+				if k, ok := srcKeys[keyID]; ok {
+					if err := copyKey(k); err != nil {
+						return err
+					}
+					res.Keys++
+				}
+			}
+			if old := jsonString(data, "identityFile"); rewritten[old] != "" {
+				if data, err = setJSONString(data, "identityFile", rewritten[old]); err != nil {
+					return err
+				}
+			}
+			if err := dstSet.Put(data); err != nil {
+				return err
+			}
+			res.Copied++
+			moved = append(moved, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Move && len(moved) > 0 {
+		err := srcStore.WithRoute(opts.Route).Update(func(st *storage.State) error {
+			set := storage.EntitySetByName(st, opts.Entity)
+			for _, id := range moved {
+				set.Remove(id)
+			}
+			return nil
+		})
+		if err != nil {
+			return res, fmt.Errorf("copiado para %s, mas falhou ao remover da origem: %w", opts.Target, err)
+		}
+		if opts.Entity == "keys" && srcPaths.Keys != dstPaths.Keys {
+			for _, id := range moved {
+				if dir := srcPaths.KeyDir(srcKeys[id].Alias); dir != "" && isUnder(srcKeys[id].PrivateKeyPath, srcPaths.Keys) {
+					_ = os.RemoveAll(dir)
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// copyKeyFiles copia os arquivos da chave para o diretório de chaves do
+// destino quando ele é diferente do de origem. Chaves fora do diretório
+// gerenciado (importadas por referência) mantêm o caminho original.
+func copyKeyFiles(k storage.Key, src, dst *config.Paths) (storage.Key, error) {
+	if src.Keys == dst.Keys || !isUnder(k.PrivateKeyPath, src.Keys) {
+		return k, nil
+	}
+	dir := dst.KeyDir(k.Alias)
+	if dir == "" {
+		return k, fmt.Errorf("alias de chave inválido: %s", k.Alias)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		return k, fmt.Errorf("já existe uma chave %s no destino", k.Alias)
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return k, err
+	}
+	for _, f := range []struct {
+		path *string
+		perm os.FileMode
+	}{{&k.PrivateKeyPath, 0o600}, {&k.PublicKeyPath, 0o644}} {
+		if *f.path == "" {
+			continue
+		}
+		target := filepath.Join(dir, filepath.Base(*f.path))
+		if err := copyFile(*f.path, target, f.perm); err != nil {
+			return k, fmt.Errorf("copiar %s: %w", *f.path, err)
+		}
+		*f.path = target
+	}
+	return k, nil
+}
+
+func keyReferenced(st *storage.State, keyID string) bool {
+	for _, a := range st.Accounts {
+		if a.KeyID == keyID {
+			return true
+		}
+	}
+	for _, s := range st.Servers {
+		if s.KeyID == keyID {
+			return true
+		}
+	}
+	for _, i := range st.Identities {
+		if i.KeyID == keyID {
+			return true
+		}
+	}
+	return false
+}
+
+func isUnder(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && !strings.HasPrefix(rel, "..")
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func jsonString(data json.RawMessage, field string) string {
+	var m map[string]any
+	if json.Unmarshal(data, &m) != nil {
+		return ""
+	}
+	s, _ := m[field].(string)
+	return s
+}
+
+func setJSONString(data json.RawMessage, field, value string) (json.RawMessage, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	m[field] = value
+	return json.Marshal(m)
+}
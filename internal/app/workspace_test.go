@@ -0,0 +1,121 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func TestWorkspaceTransferMovesAccountWithKey(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := New(&config.Config{Storage: "json"})
+	if err != nil {
+		t.Fatalf("new app: %v", err)
+	}
+
+	keyDir := a.Paths().KeyDir("work")
+	if err := os.MkdirAll(keyDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	priv := filepath.Join(keyDir, "id_ed25519")
+	if err := os.WriteFile(priv, []byte("private"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(priv+".pub", []byte("public"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = a.Storage().Update(func(s *storage.State) error {
+		s.Keys = append(s.Keys, storage.Key{ID: "k1", Alias: "work", PrivateKeyPath: priv, PublicKeyPath: priv + ".pub"})
+		s.Accounts = append(s.Accounts, storage.Account{ID: "a1", Name: "Work", HostAlias: "gh-work", KeyID: "k1", IdentityFile: priv})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+
+	if err := a.CreateWorkspace(config.Workspace{Name: "acme", OwnKeys: true}); err != nil {
+		t.Fatalf("create workspace: %v", err)
+	}
+	res, err := a.TransferEntities(TransferOptions{Target: "acme", Entity: "accounts", IDs: []string{"a1"}, Move: true})
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if res.Copied != 1 || res.Keys != 1 {
+		t.Fatalf("resultado inesperado: %+v", res)
+	}
+
+	src, err := a.Storage().LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(src.Accounts) != 0 || len(src.Keys) != 1 {
+		t.Fatalf("origem: %d contas, %d chaves", len(src.Accounts), len(src.Keys))
+	}
+
+	if err := a.SwitchWorkspace("acme"); err != nil {
+		t.Fatalf("switch: %v", err)
+	}
+	dst, err := a.Storage().LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dst.Accounts) != 1 || len(dst.Keys) != 1 {
+		t.Fatalf("destino: %d contas, %d chaves", len(dst.Accounts), len(dst.Keys))
+	}
+	want := filepath.Join(a.Paths().Keys, "work", "id_ed25519")
+	if dst.Keys[0].PrivateKeyPath != want || dst.Accounts[0].IdentityFile != want {
+		t.Fatalf("caminho da chave não reescrito: %s / %s", dst.Keys[0].PrivateKeyPath, dst.Accounts[0].IdentityFile)
+	}
+	if data, err := os.ReadFile(want); err != nil || string(data) != "private" {
+		t.Fatalf("arquivo da chave não copiado: %v", err)
+	}
+
+	reg, err := a.Workspaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reg.Active != "acme" {
+		t.Fatalf("workspace ativo não persistido: %s", reg.Active)
+	}
+}
+
+// TestSwitchWorkspaceWaitsForHolders troca de workspace com o storage ainda
+// em uso: ele só é fechado depois de liberado.
+func TestSwitchWorkspaceWaitsForHolders(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := New(&config.Config{Storage: "sqlite"})
+	if err != nil {
+		t.Fatalf("new app: %v", err)
+	}
+	if err := a.CreateWorkspace(config.Workspace{Name: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+
+	old, release := a.HoldStorage()
+	if err := a.SwitchWorkspace("acme"); err != nil {
+		t.Fatalf("switch: %v", err)
+	}
+	if a.Workspace().Name != "acme" || a.Storage() == old {
+		t.Fatalf("workspace ativo = %s", a.Workspace().Name)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, err := old.LoadState(); err != nil {
+		t.Fatalf("storage fechado ainda em uso: %v", err)
+	}
+
+	release()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := old.LoadState(); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("storage antigo não foi fechado depois de liberado")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,190 @@
+// Package audit registra cada mutação feita pela camada de storage num log
+// append-only (~/.fdev/audit.jsonl), com snapshot antes/depois do registro, e
+// permite desfazer criações, alterações e remoções.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operações registradas.
+const (
+	OpCreate = "create"
+	OpUpdate = "update"
+	OpDelete = "delete"
+)
+
+var (
+	ErrEntryNotFound = errors.New("entrada de auditoria não encontrada")
+	ErrAlreadyUndone = errors.New("operação já foi desfeita")
+	ErrConflict      = errors.New("registro foi alterado depois desta operação")
+)
+
+// Entry é uma linha do audit log.
+type Entry struct {
+	ID       string          `json:"id"`
+	Time     time.Time       `json:"time"`
+	Entity   string          `json:"entity"`
+	EntityID string          `json:"entityId"`
+	Op       string          `json:"op"`
+	Label    string          `json:"label,omitempty"` // nome legível do registro
+	Before   json.RawMessage `json:"before,omitempty"`
+	After    json.RawMessage `json:"after,omitempty"`
+	Route    string          `json:"route,omitempty"`
+	UndoOf   string          `json:"undoOf,omitempty"` // entrada desfeita por esta
+}
+
+// Filter seleciona entradas na listagem. Campos vazios não filtram.
+type Filter struct {
+	Entity string
+	Op     string
+	Query  string // busca em ID, label e rota
+	Limit  int
+}
+
+// Log é o arquivo append-only de entradas, uma por linha em JSON.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Append grava as entradas no fim do arquivo.
+func (l *Log) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("serializar auditoria: %w", err)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("abrir audit log: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("gravar audit log: %w", err)
+	}
+	return f.Sync()
+}
+
+// All lê todas as entradas, da mais antiga para a mais recente. Linhas
+// corrompidas (gravação interrompida) são ignoradas.
+func (l *Log) All() ([]Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.Open(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("abrir audit log: %w", err)
+	}
+	defer f.Close()
+
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	for sc.Scan() {
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out, sc.Err()
+}
+
+// List retorna as entradas que casam com f, da mais recente para a mais
+// antiga, e o conjunto de IDs já desfeitos.
+func (l *Log) List(f Filter) ([]Entry, map[string]bool, error) {
+	all, err := l.All()
+	if err != nil {
+		return nil, nil, err
+	}
+	undone := undoneSet(all)
+	q := strings.ToLower(strings.TrimSpace(f.Query))
+
+	var out []Entry
+	for i := len(all) - 1; i >= 0; i-- {
+		e := all[i]
+		if f.Entity != "" && e.Entity != f.Entity {
+			continue
+		}
+		if f.Op != "" && e.Op != f.Op {
+			continue
+		}
+		if q != "" && !strings.Contains(strings.ToLower(e.EntityID+" "+e.Label+" "+e.Route), q) {
+			continue
+		}
+		out = append(out, e)
+		if f.Limit > 0 && len(out) >= f.Limit {
+			break
+		}
+	}
+	return out, undone, nil
+}
+
+// Entities retorna os nomes de entidade presentes no log, ordenados.
+func (l *Log) Entities() ([]string, error) {
+	all, err := l.All()
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	var out []string
+	for _, e := range all {
+		if !seen[e.Entity] {
+			seen[e.Entity] = true
+			out = append(out, e.Entity)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func undoneSet(all []Entry) map[string]bool {
+	undone := map[string]bool{}
+	for _, e := range all {
+		if e.UndoOf != "" {
+			undone[e.UndoOf] = true
+		}
+	}
+	return undone
+}
+
+// Label extrai um nome legível do snapshot de um registro.
+func Label(data json.RawMessage) string {
+	if len(data) == 0 {
+		return ""
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	for _, k := range []string{"name", "alias", "hostAlias", "email", "url"} {
+		if s, ok := fields[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
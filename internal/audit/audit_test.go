@@ -0,0 +1,119 @@
+package audit
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func newTestRecorder(t *testing.T) *Recorder {
+	dir := t.TempDir()
+	st := storage.NewJSONStorage(filepath.Join(dir, "state.json"))
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	return NewRecorder(st, NewLog(filepath.Join(dir, "audit.jsonl")), logger)
+}
+
+func latest(t *testing.T, r *Recorder) Entry {
+	entries, _, err := r.Log().List(Filter{Limit: 1})
+	if err != nil || len(entries) == 0 {
+		t.Fatalf("list: %v (%d entradas)", err, len(entries))
+	}
+	return entries[0]
+}
+
+func aliases(t *testing.T, r *Recorder) []storage.ShellAlias {
+	st, err := r.LoadState()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	return st.Aliases
+}
+
+func TestRecordAndUndo(t *testing.T) {
+	rec := newTestRecorder(t)
+	view := rec.WithRoute("POST /tools/aliases")
+
+	if err := view.Update(func(s *storage.State) error {
+		s.Aliases = append(s.Aliases, storage.ShellAlias{ID: "a1", Name: "ll", Command: "ls -l"})
+		return nil
+	}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	created := latest(t, rec)
+	if created.Op != OpCreate || created.EntityID != "a1" || created.Label != "ll" || created.Route != "POST /tools/aliases" {
+		t.Fatalf("entrada de criação inesperada: %+v", created)
+	}
+
+	if err := rec.Update(func(s *storage.State) error {
+		s.Aliases[0].Command = "ls -la"
+		return nil
+	}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	updated := latest(t, rec)
+	if updated.Op != OpUpdate {
+		t.Fatalf("want update got %s", updated.Op)
+	}
+
+	// Criar por cima de um registro alterado é conflito.
+	if _, err := rec.Undo(created.ID); !errors.Is(err, ErrConflict) {
+		t.Fatalf("want ErrConflict got %v", err)
+	}
+
+	if _, err := rec.Undo(updated.ID); err != nil {
+		t.Fatalf("undo update: %v", err)
+	}
+	if got := aliases(t, rec); len(got) != 1 || got[0].Command != "ls -l" {
+		t.Fatalf("undo update não restaurou: %+v", got)
+	}
+	if _, err := rec.Undo(updated.ID); !errors.Is(err, ErrAlreadyUndone) {
+		t.Fatalf("want ErrAlreadyUndone got %v", err)
+	}
+
+	if err := rec.Update(func(s *storage.State) error {
+		s.Aliases = nil
+		return nil
+	}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	deleted := latest(t, rec)
+	if deleted.Op != OpDelete {
+		t.Fatalf("want delete got %s", deleted.Op)
+	}
+	if _, err := rec.Undo(deleted.ID); err != nil {
+		t.Fatalf("undo delete: %v", err)
+	}
+	if got := aliases(t, rec); len(got) != 1 || got[0].Name != "ll" {
+		t.Fatalf("undo delete não reinseriu: %+v", got)
+	}
+
+	_, undone, err := rec.Log().List(Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !undone[updated.ID] || !undone[deleted.ID] || undone[created.ID] {
+		t.Fatalf("conjunto de desfeitos inesperado: %v", undone)
+	}
+}
+
+func TestUpdateErrorIsNotRecorded(t *testing.T) {
+	rec := newTestRecorder(t)
+	err := rec.Update(func(s *storage.State) error {
+		s.Aliases = append(s.Aliases, storage.ShellAlias{ID: "a1", Name: "ll"})
+		return errors.New("falhou")
+	})
+	if err == nil {
+		t.Fatal("esperava erro")
+	}
+	all, err := rec.Log().All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 0 {
+		t.Fatalf("want 0 entradas got %d", len(all))
+	}
+}
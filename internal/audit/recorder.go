@@ -0,0 +1,218 @@
+package audit
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// skipEntities não entram no audit log: o histórico de requisições da API e
+// o de execuções agendadas já são, eles próprios, logs.
+var skipEntities = map[string]bool{"api_history": true, "schedule_runs": true}
+
+// Recorder envolve um storage.Storage e registra no Log as diferenças entre
+// o state antes e depois de cada gravação.
+type Recorder struct {
+	inner  storage.Storage
+	log    *Log
+	logger *slog.Logger
+	route  string
+}
+
+var _ storage.Storage = (*Recorder)(nil)
+
+func NewRecorder(inner storage.Storage, log *Log, logger *slog.Logger) *Recorder {
+	return &Recorder{inner: inner, log: log, logger: logger}
+}
+
+// Log retorna o audit log usado pelo recorder.
+func (r *Recorder) Log() *Log { return r.log }
+
+// WithRoute retorna uma visão do storage que anota as entradas com a rota
+// (ou comando) que originou a mutação.
+func (r *Recorder) WithRoute(route string) *Recorder {
+	c := *r
+	c.route = route
+	return &c
+}
+
+func (r *Recorder) LoadState() (*storage.State, error) {
+	return r.inner.LoadState()
+}
+
+func (r *Recorder) SaveState(st *storage.State) error {
+	before, err := r.inner.LoadState()
+	if err != nil {
+		return err
+	}
+	if err := r.inner.SaveState(st); err != nil {
+		return err
+	}
+	r.record(before, st, "")
+	return nil
+}
+
+func (r *Recorder) Update(fn func(*storage.State) error) error {
+	var before, after *storage.State
+	err := r.inner.Update(func(st *storage.State) error {
+		before = st.Clone()
+		if err := fn(st); err != nil {
+			return err
+		}
+		after = st
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	r.record(before, after, "")
+	return nil
+}
+
+// Undo desfaz a entrada id: remove o registro criado, restaura o snapshot
+// anterior de uma alteração ou reinsere o registro removido. Se o registro
+// mudou depois da operação, retorna ErrConflict.
+func (r *Recorder) Undo(id string) (Entry, error) {
+	all, err := r.log.All()
+	if err != nil {
+		return Entry{}, err
+	}
+	var target *Entry
+	for i := range all {
+		if all[i].ID == id {
+			target = &all[i]
+			break
+		}
+	}
+	if target == nil {
+		return Entry{}, ErrEntryNotFound
+	}
+	if undoneSet(all)[id] {
+		return *target, ErrAlreadyUndone
+	}
+
+	var before, after *storage.State
+	err = r.inner.Update(func(st *storage.State) error {
+		set := storage.EntitySetByName(st, target.Entity)
+		if set == nil {
+			return fmt.Errorf("entidade desconhecida: %s", target.Entity)
+		}
+		current, _, err := set.Snapshot()
+		if err != nil {
+			return err
+		}
+		cur, exists := current[target.EntityID]
+
+		before = st.Clone()
+		switch target.Op {
+		case OpCreate:
+			if exists && !sameJSON(cur, target.After) {
+				return ErrConflict
+			}
+			set.Remove(target.EntityID)
+		case OpUpdate:
+			if !exists || !sameJSON(cur, target.After) {
+				return ErrConflict
+			}
+			if err := set.Put(target.Before); err != nil {
+				return err
+			}
+		case OpDelete:
+			if exists {
+				return ErrConflict
+			}
+			if err := set.Put(target.Before); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("operação não suportada: %s", target.Op)
+		}
+		after = st
+		return nil
+	})
+	if err != nil {
+		return *target, err
+	}
+	r.record(before, after, id)
+	return *target, nil
+}
+
+// record calcula o diff e grava as entradas. Falhas no audit log não
+// desfazem a mutação: só são registradas no log da aplicação.
+func (r *Recorder) record(before, after *storage.State, undoOf string) {
+	entries, err := diff(before, after)
+	if err != nil {
+		r.logger.Warn("falha ao calcular auditoria", "err", err)
+		return
+	}
+	now := time.Now()
+	for i := range entries {
+		entries[i].ID = newID()
+		entries[i].Time = now
+		entries[i].Route = r.route
+		entries[i].UndoOf = undoOf
+	}
+	if err := r.log.Append(entries...); err != nil {
+		r.logger.Warn("falha ao gravar auditoria", "err", err)
+	}
+}
+
+// diff compara os dois states entidade a entidade.
+func diff(before, after *storage.State) ([]Entry, error) {
+	bSets, aSets := storage.EntitySets(before), storage.EntitySets(after)
+	var out []Entry
+	for i := range aSets {
+		name := aSets[i].Name()
+		if skipEntities[name] {
+			continue
+		}
+		old, oldOrder, err := bSets[i].Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		cur, curOrder, err := aSets[i].Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range oldOrder {
+			data, ok := cur[id]
+			switch {
+			case !ok:
+				out = append(out, Entry{Entity: name, EntityID: id, Op: OpDelete, Label: Label(old[id]), Before: old[id]})
+			case !sameJSON(old[id], data):
+				out = append(out, Entry{Entity: name, EntityID: id, Op: OpUpdate, Label: Label(data), Before: old[id], After: data})
+			}
+		}
+		for _, id := range curOrder {
+			if _, ok := old[id]; !ok {
+				out = append(out, Entry{Entity: name, EntityID: id, Op: OpCreate, Label: Label(cur[id]), After: cur[id]})
+			}
+		}
+	}
+	return out, nil
+}
+
+func sameJSON(a, b json.RawMessage) bool {
+	return bytes.Equal(a, b)
+}
+
+func newID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// Close fecha o storage envolvido, se ele mantiver recursos abertos (SQLite).
+func (r *Recorder) Close() error {
+	if c, ok := r.inner.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+// Package auth protege a interface web: token de acesso gerado na primeira
+// execução, sessão em cookie assinado, CSRF (double submit) nas requisições
+// que alteram estado, validação de Host/Origin contra DNS rebinding e senha
+// opcional para binds fora do loopback.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	SessionCookie = "fdev_session"
+	CSRFCookie    = "fdev_csrf"
+	CSRFHeader    = "X-CSRF-Token"
+	CSRFField     = "csrf_token"
+	TokenParam    = "token"
+
+	// SessionTTL é a validade de uma sessão de navegador.
+	SessionTTL = 7 * 24 * time.Hour
+
+	minPasswordLen = 8
+)
+
+var (
+	ErrBadCredentials = errors.New("credenciais inválidas")
+	ErrWeakPassword   = fmt.Errorf("a senha precisa ter ao menos %d caracteres", minPasswordLen)
+)
+
+// file é o conteúdo de ~/.fdev/auth.json.
+type file struct {
+	Token        string    `json:"token"`  // token de acesso (URL ?token=)
+	Secret       string    `json:"secret"` // chave HMAC dos cookies
+	PasswordSalt string    `json:"passwordSalt,omitempty"`
+	PasswordHash string    `json:"passwordHash,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Manager guarda as credenciais e valida sessões.
+type Manager struct {
+	path string
+	mu   sync.RWMutex
+	data file
+}
+
+// Load lê o arquivo de credenciais ou cria um novo com token aleatório.
+func Load(path string) (*Manager, error) {
+	m := &Manager{path: path}
+	raw, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		m.data = file{Token: randomHex(24), Secret: randomHex(32), CreatedAt: time.Now()}
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("ler %s: %w", path, err)
+	default:
+		if err := json.Unmarshal(raw, &m.data); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		if m.data.Token == "" || m.data.Secret == "" {
+			return nil, fmt.Errorf("%s incompleto: remova o arquivo para gerar novas credenciais", path)
+		}
+	}
+	return m, nil
+}
+
+// Token retorna o token de acesso.
+func (m *Manager) Token() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.data.Token
+}
+
+// HasPassword informa se há senha configurada.
+func (m *Manager) HasPassword() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.data.PasswordHash != ""
+}
+
+// RotateToken gera novo token e novo segredo, invalidando todas as sessões.
+func (m *Manager) RotateToken() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data.Token = randomHex(24)
+	m.data.Secret = randomHex(32)
+	return m.save()
+}
+
+// SetPassword define (ou, com senha vazia, remove) a senha de login.
+func (m *Manager) SetPassword(password string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if password == "" {
+		m.data.PasswordSalt, m.data.PasswordHash = "", ""
+		return m.save()
+	}
+	if len(password) < minPasswordLen {
+		return ErrWeakPassword
+	}
+	salt := randomHex(16)
+	hash, err := hashPassword(password, salt)
+	if err != nil {
+		return err
+	}
+	m.data.PasswordSalt, m.data.PasswordHash = salt, hash
+	return m.save()
+}
+
+// CheckToken compara em tempo constante com o token de acesso.
+func (m *Manager) CheckToken(token string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(m.Token())) == 1
+}
+
+// CheckPassword valida a senha de login.
+func (m *Manager) CheckPassword(password string) bool {
+	m.mu.RLock()
+	salt, want := m.data.PasswordSalt, m.data.PasswordHash
+	m.mu.RUnlock()
+	if want == "" || password == "" {
+		return false
+	}
+	got, err := hashPassword(password, salt)
+	return err == nil && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+// NewSession retorna o valor do cookie de sessão: expiração e assinatura.
+func (m *Manager) NewSession() string {
+	exp := strconv.FormatInt(time.Now().Add(SessionTTL).Unix(), 10)
+	return exp + "." + m.sign("session:"+exp)
+}
+
+// ValidSession confere assinatura e expiração do cookie.
+func (m *Manager) ValidSession(value string) bool {
+	exp, sig, ok := strings.Cut(value, ".")
+	if !ok {
+		return false
+	}
+	n, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || time.Now().Unix() > n {
+		return false
+	}
+	return hmac.Equal([]byte(sig), []byte(m.sign("session:"+exp)))
+}
+
+// CSRFToken é derivado da sessão, então não precisa de estado no servidor.
+func (m *Manager) CSRFToken(session string) string {
+	return m.sign("csrf:" + session)
+}
+
+func (m *Manager) sign(msg string) string {
+	m.mu.RLock()
+	key := m.data.Secret
+	m.mu.RUnlock()
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(msg))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (m *Manager) save() error {
+	raw, err := json.MarshalIndent(m.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := m.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return fmt.Errorf("gravar credenciais: %w", err)
+	}
+	return os.Rename(tmp, m.path)
+}
+
+func hashPassword(password, salt string) (string, error) {
+	key, err := scrypt.Key([]byte(password), []byte(salt), 1<<15, 8, 1, 32)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("gerar aleatório: %v", err))
+	}
+	return hex.EncodeToString(buf)
+}
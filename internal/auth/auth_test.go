@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T, p Policy) (*Manager, http.Handler) {
+	t.Helper()
+	m, err := Load(filepath.Join(t.TempDir(), "auth.json"))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	return m, m.Middleware(p)(ok)
+}
+
+func TestTokenLoginAndCSRF(t *testing.T) {
+	m, h := newTestServer(t, Policy{BindHost: "127.0.0.1"})
+
+	// Sem sessão: redireciona para o login.
+	req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:7331/tools/keys", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther || !strings.HasPrefix(rec.Header().Get("Location"), LoginPath) {
+		t.Fatalf("sem sessão: code=%d location=%q", rec.Code, rec.Header().Get("Location"))
+	}
+
+	// ?token= abre a sessão e remove o token da URL.
+	req = httptest.NewRequest(http.MethodGet, "http://127.0.0.1:7331/tools/keys?token="+m.Token(), nil)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != "/tools/keys" {
+		t.Fatalf("token: code=%d location=%q", rec.Code, rec.Header().Get("Location"))
+	}
+	var session, csrf *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		switch c.Name {
+		case SessionCookie:
+			session = c
+		case CSRFCookie:
+			csrf = c
+		}
+	}
+	if session == nil || csrf == nil {
+		t.Fatalf("cookies ausentes: %v", rec.Result().Cookies())
+	}
+
+	post := func(withCSRF bool, origin string) int {
+		req := httptest.NewRequest(http.MethodPost, "http://127.0.0.1:7331/tools/keys", nil)
+		req.AddCookie(session)
+		if withCSRF {
+			req.Header.Set(CSRFHeader, csrf.Value)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := post(false, ""); code != http.StatusForbidden {
+		t.Fatalf("POST sem CSRF: code=%d", code)
+	}
+	if code := post(true, ""); code != http.StatusOK {
+		t.Fatalf("POST com CSRF: code=%d", code)
+	}
+	if code := post(true, "http://evil.example"); code != http.StatusForbidden {
+		t.Fatalf("POST cross-origin: code=%d", code)
+	}
+}
+
+func TestHostAndBearer(t *testing.T) {
+	m, h := newTestServer(t, Policy{BindHost: "127.0.0.1", AllowedHosts: []string{"fdev.lan"}})
+
+	for host, want := range map[string]int{
+		"rebind.example:7331": http.StatusForbidden,
+		"fdev.lan:7331":       http.StatusOK,
+		"localhost:7331":      http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "http://"+host+"/api/v1/keys", nil)
+		req.Header.Set("Authorization", "Bearer "+m.Token())
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Fatalf("host %s: code=%d, esperado %d", host, rec.Code, want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://127.0.0.1:7331/api/v1/keys", nil)
+	req.Header.Set("Authorization", "Bearer errado")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("bearer inválido: code=%d", rec.Code)
+	}
+}
+
+func TestPasswordRequiredOutsideLoopback(t *testing.T) {
+	m, h := newTestServer(t, Policy{BindHost: "0.0.0.0"})
+	if err := m.SetPassword("curta"); err != ErrWeakPassword {
+		t.Fatalf("senha curta: %v", err)
+	}
+	if err := m.SetPassword("segredo-forte"); err != nil {
+		t.Fatalf("set password: %v", err)
+	}
+	if !m.CheckPassword("segredo-forte") || m.CheckPassword("outra-senha") {
+		t.Fatalf("CheckPassword inconsistente")
+	}
+
+	// Com senha e bind externo, o token da URL não basta.
+	req := httptest.NewRequest(http.MethodGet, "http://10.0.0.5:7331/?token="+m.Token(), nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if len(rec.Result().Cookies()) != 0 {
+		t.Fatalf("sessão aberta sem senha: %v", rec.Result().Cookies())
+	}
+
+	old := m.NewSession()
+	if err := m.RotateToken(); err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+	if m.ValidSession(old) {
+		t.Fatalf("sessão antiga continua válida após rotacionar o token")
+	}
+}
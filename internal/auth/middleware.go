@@ -0,0 +1,240 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// LoginPath é a página de login; recebe ?next= com o destino original.
+const LoginPath = "/login"
+
+// Policy configura o middleware para o bind atual.
+type Policy struct {
+	BindHost     string
+	AllowedHosts []string // nomes extras aceitos no Host (proxy reverso, DNS local)
+	Public       []string // prefixos acessíveis sem sessão
+}
+
+// Loopback informa se o bind só aceita conexões locais.
+func (p Policy) Loopback() bool {
+	if p.BindHost == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(p.BindHost)
+	return ip != nil && ip.IsLoopback()
+}
+
+// RequirePassword informa se o login exige a senha: só quando há senha
+// configurada e o servidor escuta fora do loopback.
+func (m *Manager) RequirePassword(p Policy) bool {
+	return m.HasPassword() && !p.Loopback()
+}
+
+// Middleware aplica, nesta ordem: validação de Host, Origin em métodos
+// que alteram estado, autenticação (Bearer, ?token= ou cookie) e CSRF.
+func (m *Manager) Middleware(p Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !p.hostAllowed(r.Host) {
+				http.Error(w, "host não permitido", http.StatusForbidden)
+				return
+			}
+			unsafe := !isSafeMethod(r.Method)
+			if unsafe && !sameOrigin(r) {
+				http.Error(w, "origem não permitida", http.StatusForbidden)
+				return
+			}
+			if p.public(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Clientes de linha de comando: o token não é enviado
+			// automaticamente pelo navegador, então dispensa CSRF.
+			if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				if m.CheckToken(strings.TrimSpace(bearer)) {
+					next.ServeHTTP(w, r)
+					return
+				}
+				http.Error(w, "token inválido", http.StatusUnauthorized)
+				return
+			}
+
+			if tok := r.URL.Query().Get(TokenParam); tok != "" && r.Method == http.MethodGet {
+				if m.CheckToken(tok) && !m.RequirePassword(p) {
+					m.StartSession(w, r)
+					http.Redirect(w, r, withoutToken(r.URL), http.StatusSeeOther)
+					return
+				}
+				redirectToLogin(w, r)
+				return
+			}
+
+			c, err := r.Cookie(SessionCookie)
+			if err != nil || !m.ValidSession(c.Value) {
+				redirectToLogin(w, r)
+				return
+			}
+			if unsafe && !m.validCSRF(r, c.Value) {
+				http.Error(w, "token CSRF inválido — recarregue a página", http.StatusForbidden)
+				return
+			}
+			if _, err := r.Cookie(CSRFCookie); err != nil {
+				m.setCSRFCookie(w, r, c.Value)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// StartSession grava os cookies de sessão e CSRF.
+func (m *Manager) StartSession(w http.ResponseWriter, r *http.Request) {
+	session := m.NewSession()
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    session,
+		Path:     "/",
+		MaxAge:   int(SessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	m.setCSRFCookie(w, r, session)
+}
+
+// EndSession apaga os cookies.
+func EndSession(w http.ResponseWriter) {
+	for _, name := range []string{SessionCookie, CSRFCookie} {
+		http.SetCookie(w, &http.Cookie{Name: name, Value: "", Path: "/", MaxAge: -1})
+	}
+}
+
+// setCSRFCookie publica o token CSRF num cookie legível pelo JavaScript, que
+// o devolve no header X-CSRF-Token (double submit).
+func (m *Manager) setCSRFCookie(w http.ResponseWriter, r *http.Request, session string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CSRFCookie,
+		Value:    m.CSRFToken(session),
+		Path:     "/",
+		MaxAge:   int(SessionTTL.Seconds()),
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+func (m *Manager) validCSRF(r *http.Request, session string) bool {
+	got := r.Header.Get(CSRFHeader)
+	if got == "" {
+		// Formulários sem HTMX; não força o parse de multipart aqui.
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+			got = r.FormValue(CSRFField)
+		}
+	}
+	want := m.CSRFToken(session)
+	return got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(want)) == 1
+}
+
+func (p Policy) public(path string) bool {
+	for _, prefix := range p.Public {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hostAllowed barra DNS rebinding: um domínio qualquer apontando para o
+// loopback não pode falar com o servidor. IPs literais, localhost, o host do
+// bind, o hostname da máquina e AllowedHosts são aceitos.
+func (p Policy) hostAllowed(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.Trim(host, "[]"))
+	if host == "" {
+		return false
+	}
+	if net.ParseIP(host) != nil || host == "localhost" || host == strings.ToLower(p.BindHost) {
+		return true
+	}
+	for _, h := range p.AllowedHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	if p.Loopback() {
+		return false
+	}
+	if name, err := os.Hostname(); err == nil {
+		name = strings.ToLower(name)
+		if host == name || host == name+".local" {
+			return true
+		}
+	}
+	return false
+}
+
+// sameOrigin rejeita requisições cross-site: Origin (quando presente) tem de
+// apontar para o próprio Host, e Sec-Fetch-Site não pode ser cross-site.
+func sameOrigin(r *http.Request) bool {
+	if r.Header.Get("Sec-Fetch-Site") == "cross-site" {
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if origin == "null" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+func redirectToLogin(w http.ResponseWriter, r *http.Request) {
+	target := LoginPath
+	if r.Method == http.MethodGet {
+		target += "?next=" + url.QueryEscape(withoutToken(r.URL))
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", target)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+		http.Redirect(w, r, target, http.StatusSeeOther)
+		return
+	}
+	http.Error(w, "não autenticado", http.StatusUnauthorized)
+}
+
+func withoutToken(u *url.URL) string {
+	q := u.Query()
+	q.Del(TokenParam)
+	out := u.Path
+	if enc := q.Encode(); enc != "" {
+		out += "?" + enc
+	}
+	return out
+}
+
+// SafeNext garante que o redirecionamento pós-login fique no próprio site.
+func SafeNext(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
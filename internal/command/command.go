@@ -0,0 +1,128 @@
+// Package command centraliza a execução de programas externos (git, ssh,
+// scp, ufw, gerenciadores de pacote, ip route, hostnamectl) e a gravação de
+// arquivos de configuração do sistema (ssh_config, gitconfig, units de
+// serviço). Tudo passa pelo Executor padrão, que registra cada comando no
+// histórico e, em dry-run, só descreve o que faria. Nos testes ele é trocado
+// por um Fake.
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Cmd descreve um comando a executar.
+type Cmd struct {
+	Name string
+	Args []string
+	Dir  string
+	// Env é acrescentado ao ambiente do processo atual.
+	Env    []string
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// ReadOnly marca consultas (status, listagens, testes de conexão), que
+	// rodam mesmo em dry-run.
+	ReadOnly bool
+}
+
+// String devolve a linha de comando como seria digitada no shell, com as
+// variáveis de Env na frente. Valores de variáveis terminadas em PASSWORD
+// aparecem como ***.
+func (c Cmd) String() string {
+	parts := make([]string, 0, len(c.Env)+len(c.Args)+1)
+	for _, e := range c.Env {
+		k, v, _ := strings.Cut(e, "=")
+		if strings.HasSuffix(k, "PASSWORD") {
+			parts = append(parts, k+"=***")
+			continue
+		}
+		parts = append(parts, k+"="+Quote(v))
+	}
+	parts = append(parts, Quote(c.Name))
+	for _, a := range c.Args {
+		parts = append(parts, Quote(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Quote protege s com aspas simples quando necessário.
+func Quote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(s, " \t\n'\"\\$`!*?[]{}()<>|&;#~") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Executor executa comandos e grava arquivos.
+type Executor interface {
+	// Run executa c, esperando o fim. Em dry-run, comandos que não são
+	// ReadOnly não rodam: a linha de comando vai para c.Stdout (se houver)
+	// e Run devolve nil.
+	Run(ctx context.Context, c Cmd) error
+	// WriteFile grava data em path de forma atômica (arquivo temporário +
+	// rename), criando o diretório se preciso.
+	WriteFile(path string, data []byte, perm os.FileMode) error
+	// DryRun informa se o executor está apenas simulando.
+	DryRun() bool
+}
+
+var (
+	mu  sync.RWMutex
+	def Executor = NewSystem(NewHistory(DefaultHistorySize))
+)
+
+// Default devolve o executor usado pelos pacotes.
+func Default() Executor {
+	mu.RLock()
+	defer mu.RUnlock()
+	return def
+}
+
+// SetDefault troca o executor padrão e devolve uma função que restaura o
+// anterior (útil em testes).
+func SetDefault(e Executor) (restore func()) {
+	mu.Lock()
+	prev := def
+	def = e
+	mu.Unlock()
+	return func() { SetDefault(prev) }
+}
+
+// Run executa c no executor padrão.
+func Run(ctx context.Context, c Cmd) error {
+	return Default().Run(ctx, c)
+}
+
+// Output executa c e devolve o stdout, como exec.Cmd.Output.
+func Output(ctx context.Context, c Cmd) ([]byte, error) {
+	var out bytes.Buffer
+	c.Stdout = &out
+	err := Default().Run(ctx, c)
+	return out.Bytes(), err
+}
+
+// CombinedOutput executa c e devolve stdout e stderr juntos.
+func CombinedOutput(ctx context.Context, c Cmd) ([]byte, error) {
+	var out bytes.Buffer
+	c.Stdout, c.Stderr = &out, &out
+	err := Default().Run(ctx, c)
+	return out.Bytes(), err
+}
+
+// WriteFile grava o arquivo pelo executor padrão.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Default().WriteFile(path, data, perm)
+}
+
+// DryRun informa se o executor padrão está em dry-run.
+func DryRun() bool {
+	return Default().DryRun()
+}
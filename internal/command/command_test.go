@@ -0,0 +1,64 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSystemHistoryAndDryRun(t *testing.T) {
+	s := NewSystem(NewHistory(10))
+	ctx := context.Background()
+
+	var out bytes.Buffer
+	if err := s.Run(ctx, Cmd{Name: "sh", Args: []string{"-c", "echo oi; exit 3"}, Stdout: &out}); err == nil {
+		t.Fatal("esperava erro com exit 3")
+	}
+	if out.String() != "oi\n" {
+		t.Fatalf("saída = %q", out.String())
+	}
+
+	s.SetDryRun(true)
+	out.Reset()
+	if err := s.Run(ctx, Cmd{Name: "git", Args: []string{"clone", "git@github.com:eu/app.git", "/tmp/meu app"}, Stdout: &out}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "[dry-run] git clone git@github.com:eu/app.git '/tmp/meu app'\n" {
+		t.Fatalf("saída do dry-run = %q", out.String())
+	}
+	out.Reset()
+	if err := s.Run(ctx, Cmd{Name: "echo", Args: []string{"consulta"}, Stdout: &out, ReadOnly: true}); err != nil || out.String() != "consulta\n" {
+		t.Fatalf("consulta em dry-run: %q, %v", out.String(), err)
+	}
+	path := filepath.Join(t.TempDir(), "config")
+	if err := s.WriteFile(path, []byte("Host x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("dry-run gravou o arquivo")
+	}
+
+	recs := s.History().List(false)
+	if len(recs) != 3 {
+		t.Fatalf("registros sem consultas = %d", len(recs))
+	}
+	if w := recs[0]; w.Kind != "write" || !w.DryRun || w.Bytes != 7 {
+		t.Fatalf("escrita = %+v", w)
+	}
+	if first := recs[2]; first.ExitCode != 3 || first.DryRun || first.Command != `sh -c 'echo oi; exit 3'` {
+		t.Fatalf("primeiro comando = %+v", first)
+	}
+	if len(s.History().List(true)) != 4 {
+		t.Fatal("consulta não registrada")
+	}
+
+	s.SetDryRun(false)
+	if err := s.WriteFile(path, []byte("Host x\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "Host x\n" {
+		t.Fatalf("arquivo = %q", data)
+	}
+}
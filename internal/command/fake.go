@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Fake é um Executor para testes: não executa nada, registra os comandos e
+// os arquivos e responde conforme On.
+type Fake struct {
+	mu        sync.Mutex
+	Cmds      []Cmd
+	Files     map[string][]byte
+	responses []fakeResponse
+	dryRun    bool
+}
+
+type fakeResponse struct {
+	prefix string
+	output string
+	err    error
+}
+
+// NewFake cria um Fake em que todo comando sai com sucesso e sem saída.
+func NewFake() *Fake {
+	return &Fake{Files: map[string][]byte{}}
+}
+
+// On define a resposta dos comandos cuja linha (Cmd.String) começa com
+// prefix. A resposta mais recente vence.
+func (f *Fake) On(prefix, output string, err error) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses = append(f.responses, fakeResponse{prefix, output, err})
+	return f
+}
+
+// SetDryRun faz o Fake se declarar em dry-run.
+func (f *Fake) SetDryRun(on bool) {
+	f.mu.Lock()
+	f.dryRun = on
+	f.mu.Unlock()
+}
+
+func (f *Fake) DryRun() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.dryRun
+}
+
+// Commands devolve as linhas de comando executadas, em ordem.
+func (f *Fake) Commands() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]string, len(f.Cmds))
+	for i, c := range f.Cmds {
+		out[i] = c.String()
+	}
+	return out
+}
+
+func (f *Fake) Run(_ context.Context, c Cmd) error {
+	f.mu.Lock()
+	line := c.String()
+	f.Cmds = append(f.Cmds, c)
+	var resp fakeResponse
+	for i := len(f.responses) - 1; i >= 0; i-- {
+		if strings.HasPrefix(line, f.responses[i].prefix) {
+			resp = f.responses[i]
+			break
+		}
+	}
+	f.mu.Unlock()
+
+	if resp.output != "" {
+		var w io.Writer = c.Stdout
+		if w == nil {
+			w = c.Stderr
+		}
+		if w != nil {
+			fmt.Fprint(w, resp.output)
+		}
+	}
+	return resp.err
+}
+
+func (f *Fake) WriteFile(path string, data []byte, _ os.FileMode) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Files[path] = append([]byte(nil), data...)
+	return nil
+}
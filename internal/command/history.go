@@ -0,0 +1,89 @@
+package command
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize é quantos registros o histórico guarda.
+const DefaultHistorySize = 1000
+
+// Record é um comando executado (ou simulado) ou um arquivo gravado.
+type Record struct {
+	Time time.Time `json:"time"`
+	// Kind é "exec" ou "write".
+	Kind string `json:"kind"`
+	// Command é a linha de comando, ou o caminho do arquivo gravado.
+	Command  string        `json:"command"`
+	Dir      string        `json:"dir,omitempty"`
+	ExitCode int           `json:"exitCode"`
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
+	DryRun   bool          `json:"dryRun,omitempty"`
+	ReadOnly bool          `json:"readOnly,omitempty"`
+	Bytes    int           `json:"bytes,omitempty"` // tamanho gravado (Kind "write")
+}
+
+// History guarda os registros mais recentes em memória. Consultas
+// (ReadOnly) ficam numa fila separada, para que o polling das páginas não
+// empurre para fora os comandos que alteraram o sistema.
+type History struct {
+	mu      sync.Mutex
+	max     int
+	changes []Record
+	queries []Record
+}
+
+// NewHistory cria um histórico com até max registros de cada tipo.
+func NewHistory(max int) *History {
+	return &History{max: max}
+}
+
+// Add acrescenta r, descartando o mais antigo quando cheio.
+func (h *History) Add(r Record) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	list := &h.changes
+	if r.ReadOnly {
+		list = &h.queries
+	}
+	*list = append(*list, r)
+	if n := len(*list); n > h.max {
+		*list = append([]Record(nil), (*list)[n-h.max:]...)
+	}
+}
+
+// List devolve os registros do mais recente ao mais antigo. Consultas só
+// entram com withQueries.
+func (h *History) List(withQueries bool) []Record {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := make([]Record, 0, len(h.changes)+len(h.queries))
+	for i := len(h.changes) - 1; i >= 0; i-- {
+		out = append(out, h.changes[i])
+	}
+	if withQueries {
+		for i := len(h.queries) - 1; i >= 0; i-- {
+			out = append(out, h.queries[i])
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.After(out[j].Time) })
+	return out
+}
+
+// Clear apaga o histórico.
+func (h *History) Clear() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	h.changes, h.queries = nil, nil
+	h.mu.Unlock()
+}
@@ -0,0 +1,115 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// System executa os comandos de verdade.
+type System struct {
+	history *History
+	dryRun  atomic.Bool
+}
+
+// NewSystem cria o executor real, registrando em history (pode ser nil).
+func NewSystem(history *History) *System {
+	return &System{history: history}
+}
+
+// History devolve o histórico do executor.
+func (s *System) History() *History {
+	return s.history
+}
+
+// SetDryRun liga ou desliga a simulação.
+func (s *System) SetDryRun(on bool) {
+	s.dryRun.Store(on)
+}
+
+func (s *System) DryRun() bool {
+	return s.dryRun.Load()
+}
+
+func (s *System) Run(ctx context.Context, c Cmd) error {
+	rec := Record{Time: time.Now(), Kind: "exec", Command: c.String(), Dir: c.Dir, ReadOnly: c.ReadOnly}
+	if s.DryRun() && !c.ReadOnly {
+		rec.DryRun = true
+		s.history.Add(rec)
+		if c.Stdout != nil {
+			fmt.Fprintf(c.Stdout, "[dry-run] %s\n", rec.Command)
+		}
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd.Dir = c.Dir
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = c.Stdin, c.Stdout, c.Stderr
+	err := cmd.Run()
+
+	rec.Duration = time.Since(rec.Time)
+	if err != nil {
+		rec.Error = err.Error()
+		rec.ExitCode = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			rec.ExitCode = exitErr.ExitCode()
+		}
+	}
+	s.history.Add(rec)
+	return err
+}
+
+func (s *System) WriteFile(path string, data []byte, perm os.FileMode) error {
+	rec := Record{Time: time.Now(), Kind: "write", Command: path, Bytes: len(data)}
+	if s.DryRun() {
+		rec.DryRun = true
+		s.history.Add(rec)
+		return nil
+	}
+	err := writeFileAtomic(path, data, perm)
+	rec.Duration = time.Since(rec.Time)
+	if err != nil {
+		rec.Error, rec.ExitCode = err.Error(), -1
+	}
+	s.history.Add(rec)
+	return err
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Chmod(path, perm)
+}
@@ -1,42 +1,129 @@
 package config
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"time"
 )
 
+// Config reúne as opções da aplicação. A ordem de precedência é: valores
+// padrão, ~/.fdev/config.json, variáveis FDEV_* e, por último, flags.
 type Config struct {
-	Host        string
-	Port        int
-	Debug       bool
-	OpenBrowser bool
-	WindowMode  string
-	FDevDir     string
-	Systray     bool
+	Host        string `json:"host"`
+	Port        int    `json:"port"`
+	Debug       bool   `json:"debug"`
+	OpenBrowser bool   `json:"openBrowser"`
+	WindowMode  string `json:"windowMode"`
+	FDevDir     string `json:"-"`
+	Systray     bool   `json:"systray"`
+	Storage     string `json:"storage"` // "json" | "sqlite"
+	Workspace   string `json:"-"`       // vazio = último workspace ativo
+	// DryRun inicia com os comandos de sistema e as gravações de arquivos
+	// de configuração apenas simulados (ver internal/command).
+	DryRun bool `json:"dryRun"`
+	// Privilege escolhe como elevar comandos de administrador: "auto"
+	// (sudo, ou pkexec se não houver sudo), "sudo" ou "pkexec".
+	Privilege string `json:"privilege"`
+
+	// Raízes sugeridas no scan de repositórios; vazio = home do usuário.
+	ScanRoots []string `json:"scanRoots,omitempty"`
+	// Diretórios ignorados no scan; vazio = git.DefaultScanExcludes.
+	ScanExcludes      []string `json:"scanExcludes,omitempty"`
+	ContainerLogTail  int      `json:"containerLogTail"`  // linhas de log de container
+	DBRowLimit        int      `json:"dbRowLimit"`        // linhas por query no Database
+	APITimeoutSeconds int      `json:"apiTimeoutSeconds"` // timeout do API client
+	BackupRetention   int      `json:"backupRetention"`   // backups de ssh_config mantidos
+
+	// Nomes extras aceitos no header Host (além de IPs, localhost e o
+	// hostname da máquina), para acesso via proxy reverso ou DNS local.
+	AllowedHosts []string `json:"allowedHosts,omitempty"`
+}
+
+// Defaults retorna a configuração padrão.
+func Defaults() *Config {
+	return &Config{
+		Host:              "127.0.0.1",
+		Port:              7331,
+		OpenBrowser:       true,
+		WindowMode:        "app",
+		Systray:           true,
+		Storage:           "json",
+		Privilege:         "auto",
+		ContainerLogTail:  200,
+		DBRowLimit:        500,
+		APITimeoutSeconds: 30,
+		BackupRetention:   10,
+	}
 }
 
+// ParseFlags carrega config.json e o ambiente e aplica as flags por cima.
 func ParseFlags(args []string) *Config {
+	cfg, err := Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	fs := flag.NewFlagSet("factorydev", flag.ExitOnError)
-	cfg := &Config{}
-	fs.StringVar(&cfg.Host, "host", "127.0.0.1", "Endereço de escuta")
-	fs.IntVar(&cfg.Port, "port", 7331, "Porta HTTP (1024-65535)")
-	fs.BoolVar(&cfg.Debug, "debug", false, "Modo debug")
-	fs.BoolVar(&cfg.OpenBrowser, "open-browser", true, "Abre navegador automaticamente ao iniciar o servidor")
-	fs.StringVar(&cfg.WindowMode, "window-mode", "app", "Modo de abertura: app|browser")
-	fs.BoolVar(&cfg.Systray, "systray", true, "Exibe ícone na bandeja do sistema")
+	fs.StringVar(&cfg.Host, "host", cfg.Host, "Endereço de escuta")
+	fs.IntVar(&cfg.Port, "port", cfg.Port, "Porta HTTP (1024-65535)")
+	fs.BoolVar(&cfg.Debug, "debug", cfg.Debug, "Modo debug")
+	fs.BoolVar(&cfg.OpenBrowser, "open-browser", cfg.OpenBrowser, "Abre navegador automaticamente ao iniciar o servidor")
+	fs.StringVar(&cfg.WindowMode, "window-mode", cfg.WindowMode, "Modo de abertura: app|browser")
+	fs.BoolVar(&cfg.Systray, "systray", cfg.Systray, "Exibe ícone na bandeja do sistema")
+	fs.StringVar(&cfg.Storage, "storage", cfg.Storage, "Backend de persistência: json|sqlite")
+	fs.StringVar(&cfg.Workspace, "workspace", cfg.Workspace, "Workspace a abrir (default: o último ativo)")
+	fs.BoolVar(&cfg.DryRun, "dry-run", cfg.DryRun, "Simula comandos de sistema e gravações de configuração sem executá-los")
+	fs.StringVar(&cfg.Privilege, "privilege", cfg.Privilege, "Elevação de comandos de administrador: auto|sudo|pkexec")
 	_ = fs.Parse(args)
 
-	if cfg.Port < 1024 || cfg.Port > 65535 {
-		log.Fatalf("porta inválida: %d", cfg.Port)
-	}
-	if cfg.WindowMode != "app" && cfg.WindowMode != "browser" {
-		log.Fatalf("window-mode inválido: %s (use app ou browser)", cfg.WindowMode)
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
 	}
-
 	return cfg
 }
 
+// Validate confere os valores que o resto da aplicação assume válidos.
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Port < 1024 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("porta inválida: %d", c.Port))
+	}
+	if c.WindowMode != "app" && c.WindowMode != "browser" {
+		errs = append(errs, fmt.Errorf("window-mode inválido: %s (use app ou browser)", c.WindowMode))
+	}
+	if c.Storage != "json" && c.Storage != "sqlite" {
+		errs = append(errs, fmt.Errorf("storage inválido: %s (use json ou sqlite)", c.Storage))
+	}
+	switch c.Privilege {
+	case "", "auto", "sudo", "pkexec":
+	default:
+		errs = append(errs, fmt.Errorf("privilege inválido: %s (use auto, sudo ou pkexec)", c.Privilege))
+	}
+	if c.ContainerLogTail < 1 {
+		errs = append(errs, fmt.Errorf("containerLogTail deve ser maior que zero"))
+	}
+	if c.DBRowLimit < 1 {
+		errs = append(errs, fmt.Errorf("dbRowLimit deve ser maior que zero"))
+	}
+	if c.APITimeoutSeconds < 1 {
+		errs = append(errs, fmt.Errorf("apiTimeoutSeconds deve ser maior que zero"))
+	}
+	if c.BackupRetention < 1 {
+		errs = append(errs, fmt.Errorf("backupRetention deve ser maior que zero"))
+	}
+	return errors.Join(errs...)
+}
+
 func (c *Config) Addr() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
 }
+
+// APITimeout é o timeout das requisições do API client.
+func (c *Config) APITimeout() time.Duration {
+	if c.APITimeoutSeconds < 1 {
+		return 30 * time.Second
+	}
+	return time.Duration(c.APITimeoutSeconds) * time.Second
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Load monta a configuração a partir dos padrões, de ~/.fdev/config.json e
+// das variáveis FDEV_*.
+func Load() (*Config, error) {
+	path, err := ConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplyEnv(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ConfigFilePath é o caminho do config.json.
+func ConfigFilePath() (string, error) {
+	paths, err := NewPaths()
+	if err != nil {
+		return "", err
+	}
+	return paths.ConfigFile, nil
+}
+
+// LoadFile lê o arquivo sobre os valores padrão. Arquivo ausente não é erro.
+func LoadFile(path string) (*Config, error) {
+	cfg := Defaults()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ler %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// SaveFile grava cfg em path de forma atômica.
+func SaveFile(path string, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("gravar %s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
+
+// EnvVar associa uma variável de ambiente ao campo que ela sobrescreve.
+type EnvVar struct {
+	Name  string
+	Field string // nome do campo no config.json
+	apply func(c *Config, v string) error
+}
+
+// EnvVars lista as variáveis FDEV_* aceitas.
+var EnvVars = []EnvVar{
+	{"FDEV_HOST", "host", func(c *Config, v string) error { c.Host = v; return nil }},
+	{"FDEV_PORT", "port", func(c *Config, v string) error { return setInt(&c.Port, v) }},
+	{"FDEV_DEBUG", "debug", func(c *Config, v string) error { return setBool(&c.Debug, v) }},
+	{"FDEV_OPEN_BROWSER", "openBrowser", func(c *Config, v string) error { return setBool(&c.OpenBrowser, v) }},
+	{"FDEV_WINDOW_MODE", "windowMode", func(c *Config, v string) error { c.WindowMode = v; return nil }},
+	{"FDEV_SYSTRAY", "systray", func(c *Config, v string) error { return setBool(&c.Systray, v) }},
+	{"FDEV_STORAGE", "storage", func(c *Config, v string) error { c.Storage = v; return nil }},
+	{"FDEV_WORKSPACE", "workspace", func(c *Config, v string) error { c.Workspace = v; return nil }},
+	{"FDEV_DRY_RUN", "dryRun", func(c *Config, v string) error { return setBool(&c.DryRun, v) }},
+	{"FDEV_PRIVILEGE", "privilege", func(c *Config, v string) error { c.Privilege = v; return nil }},
+	{"FDEV_SCAN_ROOTS", "scanRoots", func(c *Config, v string) error { c.ScanRoots = splitEnvList(v); return nil }},
+	{"FDEV_SCAN_EXCLUDES", "scanExcludes", func(c *Config, v string) error { c.ScanExcludes = splitEnvList(v); return nil }},
+	{"FDEV_CONTAINER_LOG_TAIL", "containerLogTail", func(c *Config, v string) error { return setInt(&c.ContainerLogTail, v) }},
+	{"FDEV_DB_ROW_LIMIT", "dbRowLimit", func(c *Config, v string) error { return setInt(&c.DBRowLimit, v) }},
+	{"FDEV_API_TIMEOUT", "apiTimeoutSeconds", setAPITimeout},
+	{"FDEV_BACKUP_RETENTION", "backupRetention", func(c *Config, v string) error { return setInt(&c.BackupRetention, v) }},
+	{"FDEV_ALLOWED_HOSTS", "allowedHosts", func(c *Config, v string) error { c.AllowedHosts = splitEnvList(v); return nil }},
+}
+
+// ApplyEnv sobrescreve cfg com as variáveis FDEV_* definidas.
+func ApplyEnv(cfg *Config) error {
+	for _, e := range EnvVars {
+		v, ok := os.LookupEnv(e.Name)
+		if !ok || strings.TrimSpace(v) == "" {
+			continue
+		}
+		if err := e.apply(cfg, strings.TrimSpace(v)); err != nil {
+			return fmt.Errorf("%s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// EnvOverrides retorna, por campo, a variável de ambiente que o sobrescreve.
+func EnvOverrides() map[string]string {
+	out := map[string]string{}
+	for _, e := range EnvVars {
+		if v, ok := os.LookupEnv(e.Name); ok && strings.TrimSpace(v) != "" {
+			out[e.Field] = e.Name
+		}
+	}
+	return out
+}
+
+func setInt(dst *int, v string) error {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("número inválido: %q", v)
+	}
+	*dst = n
+	return nil
+}
+
+func setBool(dst *bool, v string) error {
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("booleano inválido: %q", v)
+	}
+	*dst = b
+	return nil
+}
+
+// setAPITimeout aceita segundos ("45") ou uma duração Go ("45s", "2m").
+func setAPITimeout(c *Config, v string) error {
+	if n, err := strconv.Atoi(v); err == nil {
+		c.APITimeoutSeconds = n
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("duração inválida: %q", v)
+	}
+	c.APITimeoutSeconds = int(d / time.Second)
+	return nil
+}
+
+// splitEnvList aceita itens separados por vírgula ou pelo separador de
+// PATH do sistema.
+func splitEnvList(v string) []string {
+	fields := strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == os.PathListSeparator
+	})
+	out := fields[:0]
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
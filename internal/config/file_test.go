@@ -0,0 +1,49 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadFileAndEnvPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("load sem arquivo: %v", err)
+	}
+	if cfg.Port != 7331 || cfg.DBRowLimit != 500 {
+		t.Fatalf("padrões não aplicados: %+v", cfg)
+	}
+
+	cfg.Port = 8000
+	cfg.APITimeoutSeconds = 5
+	if err := SaveFile(path, cfg); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	t.Setenv("FDEV_PORT", "9000")
+	t.Setenv("FDEV_API_TIMEOUT", "2m")
+	t.Setenv("FDEV_SCAN_ROOTS", "/a, /b")
+	cfg, err = LoadFile(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.Port != 8000 || cfg.ContainerLogTail != 200 {
+		t.Fatalf("arquivo não lido sobre os padrões: %+v", cfg)
+	}
+	if err := ApplyEnv(cfg); err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	if cfg.Port != 9000 || cfg.APITimeoutSeconds != 120 || len(cfg.ScanRoots) != 2 || cfg.ScanRoots[1] != "/b" {
+		t.Fatalf("env não aplicado: %+v", cfg)
+	}
+	if EnvOverrides()["port"] != "FDEV_PORT" {
+		t.Fatalf("override de porta não reportado")
+	}
+
+	t.Setenv("FDEV_DB_ROW_LIMIT", "muitas")
+	if err := ApplyEnv(cfg); err == nil {
+		t.Fatal("esperava erro para FDEV_DB_ROW_LIMIT inválido")
+	}
+}
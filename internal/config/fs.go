@@ -12,6 +12,7 @@ func EnsureDirectories(paths *Paths) error {
 		perm fs.FileMode
 	}{
 		{paths.Base, 0o700},
+		{paths.WorkspaceDir(), 0o700},
 		{paths.Keys, 0o700},
 		{paths.Logs, 0o755},
 		{paths.Backups, 0o755},
@@ -15,7 +15,23 @@ type Paths struct {
 	Backups string
 	Envs    string
 	State   string
+	StateDB string
+	Vault   string
+	Audit   string
 	Home    string
+
+	ConfigFile string
+	Auth       string
+	// Jobs é o histórico de jobs em segundo plano (global, não por workspace).
+	Jobs string
+	// Recordings guarda as gravações do terminal embutido (global).
+	Recordings string
+
+	// Workspace é o nome do workspace ativo (ver ForWorkspace).
+	Workspace string
+	// SSHInclude, quando preenchido, recebe os blocos FDEV no lugar do
+	// ~/.ssh/config, que passa apenas a incluí-lo.
+	SSHInclude string
 }
 
 // validAlias permite letras minúsculas, números, ponto, hífen e underscore.
@@ -36,7 +52,16 @@ func NewPaths() (*Paths, error) {
 		Backups: filepath.Join(base, "backups"),
 		Envs:    filepath.Join(base, "envs"),
 		State:   filepath.Join(base, "state.json"),
+		StateDB: filepath.Join(base, "state.db"),
+		Vault:   filepath.Join(base, "vault.json"),
+		Audit:   filepath.Join(base, "audit.jsonl"),
 		Home:    home,
+
+		ConfigFile: filepath.Join(base, "config.json"),
+		Auth:       filepath.Join(base, "auth.json"),
+		Jobs:       filepath.Join(base, "jobs.jsonl"),
+		Recordings: filepath.Join(base, "recordings"),
+		Workspace:  DefaultWorkspace,
 	}, nil
 }
 
@@ -92,7 +117,17 @@ func (p *Paths) SSHDir() string {
 	return filepath.Join(p.Home, ".ssh")
 }
 
+// SSHConfig é o arquivo onde os blocos FDEV são gravados: o include do
+// workspace, quando configurado, ou o ~/.ssh/config.
 func (p *Paths) SSHConfig() string {
+	if p.SSHInclude != "" {
+		return p.SSHInclude
+	}
+	return p.UserSSHConfig()
+}
+
+// UserSSHConfig é sempre o ~/.ssh/config do usuário.
+func (p *Paths) UserSSHConfig() string {
 	return filepath.Join(p.SSHDir(), "config")
 }
 
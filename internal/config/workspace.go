@@ -0,0 +1,150 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultWorkspace usa os caminhos históricos (~/.fdev/state.json,
+// ~/.fdev/keys e o próprio ~/.ssh/config).
+const DefaultWorkspace = "default"
+
+// Workspace é um conjunto isolado de registros (state próprio). Chaves e
+// blocos do ssh_config podem, opcionalmente, ficar separados também.
+type Workspace struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	OwnKeys     bool      `json:"ownKeys,omitempty"`    // chaves em workspaces/<nome>/keys
+	SSHInclude  bool      `json:"sshInclude,omitempty"` // blocos FDEV em arquivo incluído no ~/.ssh/config
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// Workspaces é o registro persistido em ~/.fdev/workspaces.json.
+type Workspaces struct {
+	Active string      `json:"active"`
+	Items  []Workspace `json:"items"`
+}
+
+var (
+	ErrWorkspaceNotFound = errors.New("workspace não encontrado")
+	ErrWorkspaceExists   = errors.New("workspace já existe")
+)
+
+func workspacesFile(base string) string {
+	return filepath.Join(base, "workspaces.json")
+}
+
+// LoadWorkspaces lê o registro. O workspace default sempre existe, mesmo
+// sem arquivo.
+func LoadWorkspaces(base string) (*Workspaces, error) {
+	ws := &Workspaces{}
+	data, err := os.ReadFile(workspacesFile(base))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("ler workspaces: %w", err)
+	default:
+		if err := json.Unmarshal(data, ws); err != nil {
+			return nil, fmt.Errorf("parse workspaces: %w", err)
+		}
+	}
+	if _, ok := ws.Get(DefaultWorkspace); !ok {
+		ws.Items = append([]Workspace{{Name: DefaultWorkspace, Description: "Workspace padrão"}}, ws.Items...)
+	}
+	if _, ok := ws.Get(ws.Active); !ok {
+		ws.Active = DefaultWorkspace
+	}
+	return ws, nil
+}
+
+// Save grava o registro de forma atômica.
+func (w *Workspaces) Save(base string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := workspacesFile(base)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("gravar workspaces: %w", err)
+	}
+	return os.Rename(tmp, path)
+}
+
+func (w *Workspaces) Get(name string) (Workspace, bool) {
+	for _, ws := range w.Items {
+		if ws.Name == name {
+			return ws, true
+		}
+	}
+	return Workspace{}, false
+}
+
+// Add registra um novo workspace. O nome segue a mesma regra dos aliases de
+// chave, pois vira nome de diretório.
+func (w *Workspaces) Add(ws Workspace) error {
+	if !validAlias.MatchString(ws.Name) {
+		return fmt.Errorf("nome de workspace inválido: %q (use letras minúsculas, números, '.', '-' ou '_')", ws.Name)
+	}
+	if _, ok := w.Get(ws.Name); ok {
+		return ErrWorkspaceExists
+	}
+	if ws.CreatedAt.IsZero() {
+		ws.CreatedAt = time.Now()
+	}
+	w.Items = append(w.Items, ws)
+	return nil
+}
+
+// Remove tira o workspace do registro. Os arquivos em disco são mantidos.
+func (w *Workspaces) Remove(name string) error {
+	if name == DefaultWorkspace {
+		return errors.New("o workspace default não pode ser removido")
+	}
+	for i, ws := range w.Items {
+		if ws.Name == name {
+			w.Items = append(w.Items[:i], w.Items[i+1:]...)
+			if w.Active == name {
+				w.Active = DefaultWorkspace
+			}
+			return nil
+		}
+	}
+	return ErrWorkspaceNotFound
+}
+
+// ForWorkspace retorna os caminhos de p apontando para o workspace ws (p pode
+// ser de qualquer workspace). O default mantém os caminhos históricos; os
+// demais ficam em ~/.fdev/workspaces/<nome>/.
+func (p *Paths) ForWorkspace(ws Workspace) *Paths {
+	out := *p
+	out.Workspace = ws.Name
+	out.SSHInclude = ""
+	out.Keys = filepath.Join(p.Base, "keys")
+	out.State = filepath.Join(p.Base, "state.json")
+	out.StateDB = filepath.Join(p.Base, "state.db")
+	out.Audit = filepath.Join(p.Base, "audit.jsonl")
+	if ws.Name == DefaultWorkspace {
+		return &out
+	}
+	dir := filepath.Join(p.Base, "workspaces", ws.Name)
+	out.State = filepath.Join(dir, "state.json")
+	out.StateDB = filepath.Join(dir, "state.db")
+	out.Audit = filepath.Join(dir, "audit.jsonl")
+	if ws.OwnKeys {
+		out.Keys = filepath.Join(dir, "keys")
+	}
+	if ws.SSHInclude {
+		out.SSHInclude = filepath.Join(dir, "ssh_config")
+	}
+	return &out
+}
+
+// WorkspaceDir é o diretório onde ficam o state e o audit log do workspace.
+func (p *Paths) WorkspaceDir() string {
+	return filepath.Dir(p.State)
+}
@@ -12,8 +12,20 @@ import (
 	"github.com/seuusuario/factorydev/internal/storage"
 )
 
-// Open abre uma conexão com o banco usando os dados de DBConnection.
-func Open(c storage.DBConnection) (*sql.DB, error) {
+// SecretResolver decifra campos selados pelo vault (ex: DBConnection.Password).
+type SecretResolver interface {
+	Reveal(string) (string, error)
+}
+
+// Open abre uma conexão com o banco usando os dados de DBConnection. A senha
+// é resolvida via secrets, já que pode estar selada no state.
+func Open(c storage.DBConnection, secrets SecretResolver) (*sql.DB, error) {
+	password, err := secrets.Reveal(c.Password)
+	if err != nil {
+		return nil, fmt.Errorf("resolver senha: %w", err)
+	}
+	c.Password = password
+
 	dsn, err := buildDSN(c)
 	if err != nil {
 		return nil, err
@@ -186,8 +198,8 @@ type QueryResult struct {
 	Error    string
 }
 
-// RunQuery executa uma query e retorna os resultados (máx 500 rows).
-func RunQuery(db *sql.DB, query string) QueryResult {
+// RunQuery executa uma query e retorna no máximo limit linhas.
+func RunQuery(db *sql.DB, query string, limit int) QueryResult {
 	rows, err := db.Query(query)
 	if err != nil {
 		return QueryResult{Error: err.Error()}
@@ -206,7 +218,7 @@ func RunQuery(db *sql.DB, query string) QueryResult {
 		scanArgs[i] = &values[i]
 	}
 
-	for rows.Next() && len(result) < 500 {
+	for rows.Next() && len(result) < limit {
 		if err := rows.Scan(scanArgs...); err != nil {
 			return QueryResult{Columns: columns, Error: err.Error()}
 		}
@@ -80,12 +80,12 @@ type Client struct {
 }
 
 type ContainerInfo struct {
-	ID      string
-	Name    string
-	Image   string
-	Status  string
-	State   string // running, exited, etc
-	Ports   string
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Image   string `json:"image"`
+	Status  string `json:"status"`
+	State   string `json:"state"` // running, exited, etc
+	Ports   string `json:"ports"`
 }
 
 type ImageInfo struct {
@@ -227,6 +227,16 @@ func (c *Client) RemoveImage(ctx context.Context, id string) error {
 	return err
 }
 
+// PruneImages remove as imagens sem tag (dangling) e devolve quantas foram
+// removidas e o espaço liberado, em MB.
+func (c *Client) PruneImages(ctx context.Context) (int, float64, error) {
+	rep, err := c.cli.ImagesPrune(ctx, filters.NewArgs(filters.Arg("dangling", "true")))
+	if err != nil {
+		return 0, 0, err
+	}
+	return len(rep.ImagesDeleted), float64(rep.SpaceReclaimed) / 1024 / 1024, nil
+}
+
 func (c *Client) PullImage(ctx context.Context, ref string) (string, error) {
 	rc, err := c.cli.ImagePull(ctx, ref, image.PullOptions{})
 	if err != nil {
@@ -237,6 +247,44 @@ func (c *Client) PullImage(ctx context.Context, ref string) (string, error) {
 	return string(data), nil
 }
 
+// pullMessage é uma linha do stream JSON devolvido por ImagePull.
+type pullMessage struct {
+	ID       string `json:"id"`
+	Status   string `json:"status"`
+	Progress string `json:"progress"`
+	Error    string `json:"error"`
+}
+
+// PullImageTo baixa a imagem escrevendo o andamento em out, uma linha por
+// mensagem do daemon ("id: status progresso").
+func (c *Client) PullImageTo(ctx context.Context, ref string, out io.Writer) error {
+	rc, err := c.cli.ImagePull(ctx, ref, image.PullOptions{})
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	dec := json.NewDecoder(rc)
+	for {
+		var m pullMessage
+		if err := dec.Decode(&m); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if m.Error != "" {
+			return fmt.Errorf("%s", m.Error)
+		}
+		line := m.Status
+		if m.ID != "" {
+			line = m.ID + ": " + line
+		}
+		if m.Progress != "" {
+			line += " " + m.Progress
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
 func (c *Client) CreateAndStart(ctx context.Context, spec ContainerSpec) (string, error) {
 	portBindings := nat.PortMap{}
 	exposedPorts := nat.PortSet{}
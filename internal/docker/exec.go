@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// ExecShell é um shell interativo dentro de um container (docker exec -it).
+type ExecShell struct {
+	cli  *Client
+	id   string
+	conn types.HijackedResponse
+	once sync.Once
+}
+
+// OpenShell executa um shell com TTY no container: bash quando existir,
+// senão sh.
+func (c *Client) OpenShell(ctx context.Context, containerID string, cols, rows int) (*ExecShell, error) {
+	created, err := c.cli.ContainerExecCreate(ctx, containerID, container.ExecOptions{
+		Tty:          true,
+		AttachStdin:  true,
+		AttachStdout: true,
+		AttachStderr: true,
+		Env:          []string{"TERM=xterm-256color"},
+		Cmd:          []string{"/bin/sh", "-c", "if command -v bash >/dev/null 2>&1; then exec bash -l; else exec sh -l; fi"},
+		ConsoleSize:  &[2]uint{uint(rows), uint(cols)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("docker exec: %w", err)
+	}
+	conn, err := c.cli.ContainerExecAttach(ctx, created.ID, container.ExecAttachOptions{
+		Tty:         true,
+		ConsoleSize: &[2]uint{uint(rows), uint(cols)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("docker exec attach: %w", err)
+	}
+	return &ExecShell{cli: c, id: created.ID, conn: conn}, nil
+}
+
+// Read devolve a saída crua: com TTY o Docker não multiplexa os streams.
+func (s *ExecShell) Read(b []byte) (int, error)  { return s.conn.Reader.Read(b) }
+func (s *ExecShell) Write(b []byte) (int, error) { return s.conn.Conn.Write(b) }
+
+func (s *ExecShell) Resize(cols, rows int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.cli.cli.ContainerExecResize(ctx, s.id, container.ResizeOptions{Width: uint(cols), Height: uint(rows)})
+}
+
+// Wait consulta o código de saída; chamado depois que a saída termina.
+func (s *ExecShell) Wait() (int, error) {
+	s.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for {
+		info, err := s.cli.cli.ContainerExecInspect(ctx, s.id)
+		if err != nil {
+			return -1, err
+		}
+		if !info.Running {
+			return info.ExitCode, nil
+		}
+		select {
+		case <-ctx.Done():
+			// Conexão fechada pelo usuário com o shell ainda vivo.
+			return -1, nil
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// Close fecha a conexão; o shell recebe EOF na entrada e termina.
+func (s *ExecShell) Close() error {
+	s.once.Do(s.conn.Close)
+	return nil
+}
@@ -0,0 +1,58 @@
+package docker
+
+import "strings"
+
+// Template define um container pré-configurado, lançado pela tela Docker ou
+// por `factorydev docker templates launch`.
+type Template struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Image       string   `json:"image"`
+	Description string   `json:"description"`
+	DefaultEnv  []string `json:"defaultEnv,omitempty"`
+	DefaultPort string   `json:"defaultPort,omitempty"` // "host:container"
+}
+
+// Templates são os containers oferecidos por padrão.
+var Templates = []Template{
+	{ID: "postgres16", Name: "PostgreSQL 16", Image: "postgres:16-alpine",
+		Description: "Banco de dados relacional PostgreSQL",
+		DefaultEnv:  []string{"POSTGRES_PASSWORD=postgres", "POSTGRES_DB=app"},
+		DefaultPort: "5432:5432"},
+	{ID: "mysql8", Name: "MySQL 8", Image: "mysql:8",
+		Description: "Banco de dados relacional MySQL",
+		DefaultEnv:  []string{"MYSQL_ROOT_PASSWORD=mysql", "MYSQL_DATABASE=app"},
+		DefaultPort: "3306:3306"},
+	{ID: "redis7", Name: "Redis 7", Image: "redis:7-alpine",
+		Description: "Cache e message broker Redis",
+		DefaultEnv:  nil,
+		DefaultPort: "6379:6379"},
+	{ID: "mongo7", Name: "MongoDB 7", Image: "mongo:7",
+		Description: "Banco de dados orientado a documentos MongoDB",
+		DefaultEnv:  []string{"MONGO_INITDB_ROOT_USERNAME=root", "MONGO_INITDB_ROOT_PASSWORD=mongo"},
+		DefaultPort: "27017:27017"},
+	{ID: "adminer", Name: "Adminer", Image: "adminer:latest",
+		Description: "Interface web para gerenciar bancos de dados",
+		DefaultEnv:  nil,
+		DefaultPort: "8080:8080"},
+}
+
+// FindTemplate retorna o template com o ID dado.
+func FindTemplate(id string) (Template, bool) {
+	for _, t := range Templates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// PortBindings converte "host:container" no mapa container→host usado em
+// ContainerSpec.Ports. Valores sem ":" são ignorados.
+func PortBindings(mapping string) map[string]string {
+	ports := map[string]string{}
+	if host, container, ok := strings.Cut(strings.TrimSpace(mapping), ":"); ok {
+		ports[container] = host
+	}
+	return ports
+}
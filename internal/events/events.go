@@ -0,0 +1,117 @@
+// Package events é o barramento interno de notificações em tempo real: jobs
+// publicam linhas de log e mudanças de estado, e os feeds de Docker e métricas
+// do sistema publicam snapshots. O endpoint SSE repassa os eventos ao
+// navegador.
+package events
+
+import "sync"
+
+// Tópicos conhecidos. Eventos de um job específico usam JobTopic(id).
+const (
+	TopicJobs   = "jobs"
+	TopicDocker = "docker"
+	TopicSystem = "system"
+	// TopicPrivilege avisa quando os pedidos de senha de administrador mudam.
+	TopicPrivilege = "privilege"
+)
+
+// JobTopic é o tópico com o log e o estado de um job.
+func JobTopic(id string) string { return "job:" + id }
+
+// Event é uma notificação. HTML, quando presente, é o fragmento já
+// renderizado que a interface troca no lugar do elemento inscrito.
+type Event struct {
+	Topic string `json:"topic"`
+	Type  string `json:"type"` // ex: "log", "update", "done", "status", "metrics"
+	Data  any    `json:"data,omitempty"`
+	HTML  string `json:"html,omitempty"`
+}
+
+// subBuffer é a fila por assinante; eventos além disso são descartados para
+// que um cliente lento não trave quem publica.
+const subBuffer = 256
+
+type subscriber struct {
+	topics map[string]bool
+	ch     chan Event
+}
+
+// Bus distribui eventos por tópico.
+type Bus struct {
+	mu       sync.Mutex
+	subs     map[*subscriber]struct{}
+	retained map[string]Event
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[*subscriber]struct{}), retained: make(map[string]Event)}
+}
+
+// Publish entrega e aos assinantes do tópico sem bloquear.
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.publishLocked(e)
+}
+
+// Retain publica e guarda o evento como o último estado do tópico, entregue a
+// quem assinar depois (ex: status do Docker, que só muda de vez em quando).
+func (b *Bus) Retain(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.retained[e.Topic] = e
+	b.publishLocked(e)
+}
+
+func (b *Bus) publishLocked(e Event) {
+	for s := range b.subs {
+		if !s.topics[e.Topic] {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+		}
+	}
+}
+
+// Subscribe assina os tópicos; o canal recebe primeiro os eventos retidos.
+// A função devolvida cancela a assinatura e fecha o canal.
+func (b *Bus) Subscribe(topics ...string) (<-chan Event, func()) {
+	s := &subscriber{topics: make(map[string]bool, len(topics)), ch: make(chan Event, subBuffer)}
+	for _, t := range topics {
+		s.topics[t] = true
+	}
+	b.mu.Lock()
+	for _, t := range topics {
+		if e, ok := b.retained[t]; ok {
+			s.ch <- e
+		}
+	}
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subs, s)
+			b.mu.Unlock()
+			close(s.ch)
+		})
+	}
+}
+
+// Subscribers conta quantos assinantes o tópico tem; os feeds param quando
+// ninguém está olhando.
+func (b *Bus) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for s := range b.subs {
+		if s.topics[topic] {
+			n++
+		}
+	}
+	return n
+}
@@ -1,10 +1,15 @@
 package firewall
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/privilege"
 )
 
 // Status representa o estado do firewall.
@@ -12,6 +17,9 @@ type Status struct {
 	Engine  string // "ufw", "firewalld", "pf", "none"
 	Enabled bool
 	Rules   []Rule
+	// NeedsAuth indica que o status só pode ser lido como administrador e
+	// ainda não há senha em memória (o ufw exige root até para consultar).
+	NeedsAuth bool
 }
 
 // Rule representa uma regra de firewall.
@@ -23,6 +31,21 @@ type Rule struct {
 	Raw    string
 }
 
+// admin executa como administrador um comando que altera o firewall;
+// reason aparece no pedido de senha.
+func admin(ctx context.Context, reason, name string, args ...string) error {
+	out, err := privilege.CombinedOutput(ctx, reason, command.Cmd{Name: name, Args: args})
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%v: %s", err, string(out))
+	}
+	return err
+}
+
+// query executa uma consulta, que roda mesmo em dry-run.
+func query(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return command.CombinedOutput(ctx, command.Cmd{Name: name, Args: args, ReadOnly: true})
+}
+
 // Detect retorna o engine de firewall disponível.
 func Detect() string {
 	if runtime.GOOS == "darwin" {
@@ -41,18 +64,21 @@ func Detect() string {
 }
 
 // GetStatus retorna o estado atual do firewall.
-func GetStatus() Status {
+func GetStatus(ctx context.Context) Status {
 	engine := Detect()
 	s := Status{Engine: engine}
 
 	switch engine {
 	case "pf":
-		out, err := exec.Command("/usr/libexec/ApplicationFirewall/socketfilterfw", "--getglobalstate").CombinedOutput()
+		out, err := query(ctx, "/usr/libexec/ApplicationFirewall/socketfilterfw", "--getglobalstate")
 		if err == nil {
 			s.Enabled = strings.Contains(string(out), "enabled")
 		}
 	case "ufw":
-		out, err := exec.Command("sudo", "ufw", "status").CombinedOutput()
+		out, err := privilege.QueryOutput(ctx, command.Cmd{Name: "ufw", Args: []string{"status"}})
+		if errors.Is(err, privilege.ErrPasswordRequired) {
+			s.NeedsAuth = true
+		}
 		if err == nil {
 			lines := strings.Split(string(out), "\n")
 			for _, l := range lines {
@@ -63,7 +89,7 @@ func GetStatus() Status {
 			s.Rules = parseUFWRules(lines)
 		}
 	case "firewalld":
-		out, err := exec.Command("firewall-cmd", "--state").CombinedOutput()
+		out, err := query(ctx, "firewall-cmd", "--state")
 		if err == nil {
 			s.Enabled = strings.TrimSpace(string(out)) == "running"
 		}
@@ -73,7 +99,11 @@ func GetStatus() Status {
 }
 
 // Toggle liga/desliga o firewall.
-func Toggle(enable bool) error {
+func Toggle(ctx context.Context, enable bool) error {
+	reason := "Desativar o firewall"
+	if enable {
+		reason = "Ativar o firewall"
+	}
 	engine := Detect()
 	switch engine {
 	case "pf":
@@ -81,52 +111,34 @@ func Toggle(enable bool) error {
 		if enable {
 			state = "on"
 		}
-		out, err := exec.Command("sudo", "/usr/libexec/ApplicationFirewall/socketfilterfw", "--setglobalstate", state).CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("%v: %s", err, string(out))
-		}
+		return admin(ctx, reason, "/usr/libexec/ApplicationFirewall/socketfilterfw", "--setglobalstate", state)
 	case "ufw":
 		action := "disable"
 		if enable {
 			action = "enable"
 		}
-		out, err := exec.Command("sudo", "ufw", "--force", action).CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("%v: %s", err, string(out))
-		}
+		return admin(ctx, reason, "ufw", "--force", action)
 	case "firewalld":
 		if enable {
-			out, err := exec.Command("sudo", "systemctl", "start", "firewalld").CombinedOutput()
-			if err != nil {
-				return fmt.Errorf("%v: %s", err, string(out))
-			}
-		} else {
-			out, err := exec.Command("sudo", "systemctl", "stop", "firewalld").CombinedOutput()
-			if err != nil {
-				return fmt.Errorf("%v: %s", err, string(out))
-			}
+			return admin(ctx, reason, "systemctl", "start", "firewalld")
 		}
-	default:
-		return fmt.Errorf("nenhum firewall detectado")
+		return admin(ctx, reason, "systemctl", "stop", "firewalld")
 	}
-	return nil
+	return fmt.Errorf("nenhum firewall detectado")
 }
 
 // AllowPort adiciona uma regra allow.
-func AllowPort(port, proto string) error {
+func AllowPort(ctx context.Context, port, proto string) error {
+	reason := "Liberar a porta " + port + "/" + proto + " no firewall"
 	engine := Detect()
 	switch engine {
 	case "ufw":
-		out, err := exec.Command("sudo", "ufw", "allow", port+"/"+proto).CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("%v: %s", err, string(out))
-		}
+		return admin(ctx, reason, "ufw", "allow", port+"/"+proto)
 	case "firewalld":
-		out, err := exec.Command("sudo", "firewall-cmd", "--add-port="+port+"/"+proto, "--permanent").CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("%v: %s", err, string(out))
+		if err := admin(ctx, reason, "firewall-cmd", "--add-port="+port+"/"+proto, "--permanent"); err != nil {
+			return err
 		}
-		_ = exec.Command("sudo", "firewall-cmd", "--reload").Run()
+		_ = admin(ctx, reason, "firewall-cmd", "--reload")
 	default:
 		return fmt.Errorf("operação não suportada para engine: %s", engine)
 	}
@@ -134,18 +146,12 @@ func AllowPort(port, proto string) error {
 }
 
 // DenyPort adiciona uma regra deny.
-func DenyPort(port, proto string) error {
+func DenyPort(ctx context.Context, port, proto string) error {
 	engine := Detect()
-	switch engine {
-	case "ufw":
-		out, err := exec.Command("sudo", "ufw", "deny", port+"/"+proto).CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("%v: %s", err, string(out))
-		}
-	default:
+	if engine != "ufw" {
 		return fmt.Errorf("operação não suportada para engine: %s", engine)
 	}
-	return nil
+	return admin(ctx, "Bloquear a porta "+port+"/"+proto+" no firewall", "ufw", "deny", port+"/"+proto)
 }
 
 func parseUFWRules(lines []string) []Rule {
@@ -1,15 +1,17 @@
 package git
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
 )
 
 type Service struct{}
@@ -60,27 +62,63 @@ func (s *Service) BuildSSHURL(rawURL, hostAlias string) (string, error) {
 }
 
 func (s *Service) CloneRepo(ctx context.Context, sshURL, destDir, identityFile string) (string, error) {
+	var out bytes.Buffer
+	err := s.cloneRepo(ctx, sshURL, destDir, identityFile, &out, false)
+	return out.String(), err
+}
+
+// CloneRepoTo é o CloneRepo que escreve a saída do git (com --progress) em
+// out à medida que ela é produzida.
+func (s *Service) CloneRepoTo(ctx context.Context, sshURL, destDir, identityFile string, out io.Writer) error {
+	return s.cloneRepo(ctx, sshURL, destDir, identityFile, out, true)
+}
+
+func (s *Service) cloneRepo(ctx context.Context, sshURL, destDir, identityFile string, out io.Writer, progress bool) error {
 	target, err := expandHome(destDir)
 	if err != nil {
-		return "", err
+		return err
 	}
 	if strings.TrimSpace(target) == "" {
-		return "", fmt.Errorf("diretório de destino é obrigatório")
+		return fmt.Errorf("diretório de destino é obrigatório")
 	}
 	target, err = resolveCloneTarget(target, sshURL)
 	if err != nil {
-		return "", err
+		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
-		return "", fmt.Errorf("criar diretório base: %w", err)
+	if !command.DryRun() {
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("criar diretório base: %w", err)
+		}
 	}
 
-	cmd := exec.CommandContext(ctx, "git", "clone", sshURL, target)
-	cmd.Env = append(os.Environ(),
-		"GIT_SSH_COMMAND=ssh -i "+identityFile+" -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new",
-	)
-	out, err := cmd.CombinedOutput()
-	return string(out), err
+	args := []string{"clone"}
+	if progress {
+		args = append(args, "--progress")
+	}
+	return runTo(ctx, command.Cmd{
+		Name: "git",
+		Args: append(args, sshURL, target),
+		Env:  []string{sshCommandEnv(identityFile)},
+	}, out)
+}
+
+// sshCommandEnv força a chave da conta nas operações remotas do git.
+func sshCommandEnv(identityFile string) string {
+	return "GIT_SSH_COMMAND=ssh -i " + identityFile + " -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new"
+}
+
+// runTo executa c enviando stdout e stderr para out.
+func runTo(ctx context.Context, c command.Cmd, out io.Writer) error {
+	c.Stdout, c.Stderr = out, out
+	return command.Run(ctx, c)
+}
+
+// remoteEnv é o ambiente das operações remotas: a chave da conta, se houver.
+func remoteEnv(identityFile string) []string {
+	if identityFile == "" {
+		return nil
+	}
+	return []string{sshCommandEnv(identityFile)}
 }
 
 func resolveCloneTarget(destDir, sshURL string) (string, error) {
@@ -166,42 +204,36 @@ type CommitEntry struct {
 
 // Pull executa git pull no repositório local.
 func (s *Service) Pull(ctx context.Context, localPath, identityFile string) (string, error) {
-	cmd := exec.CommandContext(ctx, "git", "-C", localPath, "pull")
-	if identityFile != "" {
-		cmd.Env = append(os.Environ(),
-			"GIT_SSH_COMMAND=ssh -i "+identityFile+" -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new",
-		)
-	}
-	out, err := cmd.CombinedOutput()
-	return string(out), err
+	var out bytes.Buffer
+	err := s.PullTo(ctx, localPath, identityFile, &out)
+	return out.String(), err
+}
+
+// PullTo é o Pull que escreve a saída em out à medida que ela é produzida.
+func (s *Service) PullTo(ctx context.Context, localPath, identityFile string, out io.Writer) error {
+	return runTo(ctx, command.Cmd{Name: "git", Args: []string{"-C", localPath, "pull"}, Env: remoteEnv(identityFile)}, out)
 }
 
 // PullForce executa git fetch --all + git reset --hard @{u},
 // descartando mudanças locais e sincronizando com o upstream.
 func (s *Service) PullForce(ctx context.Context, localPath, identityFile string) (string, error) {
-	var env []string
-	if identityFile != "" {
-		env = append(os.Environ(),
-			"GIT_SSH_COMMAND=ssh -i "+identityFile+" -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new",
-		)
-	}
+	var out bytes.Buffer
+	err := s.PullForceTo(ctx, localPath, identityFile, &out)
+	return out.String(), err
+}
 
-	fetchCmd := exec.CommandContext(ctx, "git", "-C", localPath, "fetch", "--all")
-	if len(env) > 0 {
-		fetchCmd.Env = env
-	}
-	fetchOut, fetchErr := fetchCmd.CombinedOutput()
-	if fetchErr != nil {
-		return string(fetchOut), fmt.Errorf("fetch: %w", fetchErr)
+// PullForceTo é o PullForce com a saída escrita em out.
+func (s *Service) PullForceTo(ctx context.Context, localPath, identityFile string, out io.Writer) error {
+	fetch := command.Cmd{Name: "git", Args: []string{"-C", localPath, "fetch", "--all"}, Env: remoteEnv(identityFile)}
+	if err := runTo(ctx, fetch, out); err != nil {
+		return fmt.Errorf("fetch: %w", err)
 	}
 
-	resetCmd := exec.CommandContext(ctx, "git", "-C", localPath, "reset", "--hard", "@{u}")
-	resetOut, resetErr := resetCmd.CombinedOutput()
-	combined := string(fetchOut) + string(resetOut)
-	if resetErr != nil {
-		return combined, fmt.Errorf("reset: %w", resetErr)
+	reset := command.Cmd{Name: "git", Args: []string{"-C", localPath, "reset", "--hard", "@{u}"}}
+	if err := runTo(ctx, reset, out); err != nil {
+		return fmt.Errorf("reset: %w", err)
 	}
-	return combined, nil
+	return nil
 }
 
 // BranchInfo representa um branch local com status de sincronização.
@@ -215,9 +247,11 @@ type BranchInfo struct {
 
 // ListBranches lista os branches locais com status ahead/behind do upstream.
 func (s *Service) ListBranches(ctx context.Context, localPath string) ([]BranchInfo, error) {
-	cmd := exec.CommandContext(ctx, "git", "-C", localPath, "branch", "-vv",
-		"--format=%(refname:short)|%(HEAD)|%(upstream:short)|%(upstream:track)")
-	out, err := cmd.Output()
+	out, err := command.Output(ctx, command.Cmd{
+		Name:     "git",
+		Args:     []string{"-C", localPath, "branch", "-vv", "--format=%(refname:short)|%(HEAD)|%(upstream:short)|%(upstream:track)"},
+		ReadOnly: true,
+	})
 	if err != nil {
 		return nil, fmt.Errorf("git branch: %w", err)
 	}
@@ -276,8 +310,7 @@ func parseTrackNum(track, dir string) int {
 
 // CheckoutBranch faz checkout de um branch existente.
 func (s *Service) CheckoutBranch(ctx context.Context, localPath, branch string) error {
-	cmd := exec.CommandContext(ctx, "git", "-C", localPath, "checkout", branch)
-	out, err := cmd.CombinedOutput()
+	out, err := command.CombinedOutput(ctx, command.Cmd{Name: "git", Args: []string{"-C", localPath, "checkout", branch}})
 	if err != nil {
 		return fmt.Errorf("%s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -286,8 +319,7 @@ func (s *Service) CheckoutBranch(ctx context.Context, localPath, branch string)
 
 // NewBranch cria e faz checkout de um novo branch.
 func (s *Service) NewBranch(ctx context.Context, localPath, branch string) error {
-	cmd := exec.CommandContext(ctx, "git", "-C", localPath, "checkout", "-b", branch)
-	out, err := cmd.CombinedOutput()
+	out, err := command.CombinedOutput(ctx, command.Cmd{Name: "git", Args: []string{"-C", localPath, "checkout", "-b", branch}})
 	if err != nil {
 		return fmt.Errorf("%s: %w", strings.TrimSpace(string(out)), err)
 	}
@@ -301,8 +333,7 @@ func (s *Service) GetLog(ctx context.Context, localPath string, n, offset int) (
 		fmt.Sprintf("-n %d", n),
 		fmt.Sprintf("--skip=%d", offset),
 	}
-	cmd := exec.CommandContext(ctx, "git", args...)
-	out, err := cmd.Output()
+	out, err := command.Output(ctx, command.Cmd{Name: "git", Args: args, ReadOnly: true})
 	if err != nil {
 		return nil, err
 	}
@@ -334,8 +365,7 @@ func min(a, b int) int {
 
 // GetRepoGitConfig retorna as configurações git locais do repositório.
 func (s *Service) GetRepoGitConfig(localPath string) (map[string]string, error) {
-	cmd := exec.Command("git", "-C", localPath, "config", "--list", "--local")
-	out, err := cmd.Output()
+	out, err := command.Output(context.Background(), command.Cmd{Name: "git", Args: []string{"-C", localPath, "config", "--list", "--local"}, ReadOnly: true})
 	if err != nil {
 		// Sem config local não é erro
 		return map[string]string{}, nil
@@ -352,8 +382,7 @@ func (s *Service) GetRepoGitConfig(localPath string) (map[string]string, error)
 
 // SetRepoGitConfig define um valor de config git local no repositório.
 func (s *Service) SetRepoGitConfig(localPath, key, value string) error {
-	cmd := exec.Command("git", "-C", localPath, "config", key, value)
-	out, err := cmd.CombinedOutput()
+	out, err := command.CombinedOutput(context.Background(), command.Cmd{Name: "git", Args: []string{"-C", localPath, "config", key, value}})
 	if err != nil {
 		return fmt.Errorf("git config %s: %s", key, strings.TrimSpace(string(out)))
 	}
@@ -1,9 +1,13 @@
 package git
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/seuusuario/factorydev/internal/command"
 )
 
 func TestBuildSSHURL(t *testing.T) {
@@ -53,3 +57,20 @@ func TestResolveCloneTargetExistingEmptyDir(t *testing.T) {
 		t.Fatalf("want %q got %q", base, got)
 	}
 }
+
+func TestCloneDryRunCreatesNoDirectory(t *testing.T) {
+	fake := command.NewFake()
+	fake.SetDryRun(true)
+	defer command.SetDefault(fake)()
+
+	target := filepath.Join(t.TempDir(), "work", "repo")
+	if err := NewService().CloneRepoTo(context.Background(), "git@gh-work:org/repo.git", target, "/tmp/id", io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Dir(target)); !os.IsNotExist(err) {
+		t.Fatalf("diretório base criado em dry-run: %v", err)
+	}
+	if cmds := fake.Commands(); len(cmds) != 1 {
+		t.Fatalf("comandos = %v", cmds)
+	}
+}
@@ -0,0 +1,64 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+)
+
+// RepoStatus resume o estado da working tree de um repositório local.
+type RepoStatus struct {
+	Branch     string `json:"branch"`
+	Summary    string `json:"summary"` // "limpo", "3 alteração(ões)"...
+	Clean      bool   `json:"clean"`
+	Accessible bool   `json:"accessible"`
+}
+
+// Status lê branch atual e `git status --short` de localPath.
+func Status(localPath string) RepoStatus {
+	if _, err := os.Stat(localPath); err != nil {
+		return RepoStatus{Branch: "?", Summary: "caminho não encontrado"}
+	}
+	st := RepoStatus{Accessible: true}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+	defer cancel()
+
+	branchOut, err := gitQuery(ctx, localPath, "branch", "--show-current")
+	if err == nil {
+		st.Branch = strings.TrimSpace(string(branchOut))
+	}
+	if st.Branch == "" {
+		hashOut, _ := gitQuery(ctx, localPath, "rev-parse", "--short", "HEAD")
+		st.Branch = "HEAD:" + strings.TrimSpace(string(hashOut))
+	}
+
+	statusOut, err := gitQuery(ctx, localPath, "status", "--short")
+	if err != nil {
+		st.Summary = "erro ao ler"
+		return st
+	}
+	if statusStr := strings.TrimSpace(string(statusOut)); statusStr == "" {
+		st.Clean = true
+		st.Summary = "limpo"
+	} else {
+		st.Summary = fmt.Sprintf("%d alteração(ões)", len(strings.Split(statusStr, "\n")))
+	}
+	return st
+}
+
+// gitQuery roda um comando git de leitura em localPath e devolve o stdout.
+func gitQuery(ctx context.Context, localPath string, args ...string) ([]byte, error) {
+	return command.Output(ctx, command.Cmd{Name: "git", Args: append([]string{"-C", localPath}, args...), ReadOnly: true})
+}
+
+// RepoNameFromURL retorna o nome do repositório (último segmento, sem .git).
+func RepoNameFromURL(rawURL string) string {
+	rawURL = strings.TrimSuffix(rawURL, ".git")
+	parts := strings.Split(rawURL, "/")
+	return parts[len(parts)-1]
+}
@@ -2,16 +2,17 @@ package gitconfig
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
 )
 
 // SetGlobalValue define um valor no gitconfig global via git config --global.
 func SetGlobalValue(key, value string) error {
-	cmd := exec.Command("git", "config", "--global", key, value)
-	out, err := cmd.CombinedOutput()
+	out, err := command.CombinedOutput(context.Background(), command.Cmd{Name: "git", Args: []string{"config", "--global", key, value}})
 	if err != nil {
 		return fmt.Errorf("git config --global %s: %s", key, strings.TrimSpace(string(out)))
 	}
@@ -31,14 +32,12 @@ func AddIncludeIf(globalPath string, rule IncludeIfRule) error {
 		}
 	}
 
-	f, err := os.OpenFile(globalPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-	if err != nil {
+	current, err := os.ReadFile(globalPath)
+	if err != nil && !os.IsNotExist(err) {
 		return fmt.Errorf("abrir gitconfig: %w", err)
 	}
-	defer f.Close()
-
 	block := fmt.Sprintf("\n[includeIf %q]\n\tpath = %s\n", rule.Pattern, rule.IncludePath)
-	if _, err := f.WriteString(block); err != nil {
+	if err := command.WriteFile(globalPath, append(current, block...), 0o644); err != nil {
 		return fmt.Errorf("escrever includeIf: %w", err)
 	}
 	return nil
@@ -89,16 +88,9 @@ func RemoveIncludeIf(globalPath string, pattern string) error {
 		}
 	}
 
-	tmp, err := os.CreateTemp("", "gitconfig-*")
-	if err != nil {
-		return err
-	}
-	defer os.Remove(tmp.Name())
-
+	var buf strings.Builder
 	for _, l := range out {
-		fmt.Fprintln(tmp, l)
+		buf.WriteString(l + "\n")
 	}
-	tmp.Close()
-
-	return os.Rename(tmp.Name(), globalPath)
+	return command.WriteFile(globalPath, []byte(buf.String()), 0o644)
 }
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/audit"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+// activityLimit é o máximo de entradas exibidas por página.
+const activityLimit = 200
+
+type activityRow struct {
+	audit.Entry
+	BeforeJSON string
+	AfterJSON  string
+	Undone     bool
+	CanUndo    bool
+}
+
+// GET /tools/activity
+func (h *Handler) ActivityPage(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if h.app.Audit() == nil {
+		h.operationError(w, "Auditoria indisponível", http.StatusServiceUnavailable)
+		return
+	}
+	q := r.URL.Query()
+	filter := audit.Filter{
+		Entity: q.Get("entity"),
+		Op:     q.Get("op"),
+		Query:  q.Get("q"),
+		Limit:  activityLimit,
+	}
+
+	log := h.app.Audit().Log()
+	entries, undone, err := log.List(filter)
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	entities, err := log.Entities()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+
+	rows := make([]activityRow, len(entries))
+	for i, e := range entries {
+		rows[i] = activityRow{
+			Entry:      e,
+			BeforeJSON: prettyJSON(e.Before),
+			AfterJSON:  prettyJSON(e.After),
+			Undone:     undone[e.ID],
+			CanUndo:    !undone[e.ID] && e.UndoOf == "",
+		}
+	}
+
+	payload := map[string]any{
+		"Rows":     rows,
+		"Entities": entities,
+		"Ops":      []string{audit.OpCreate, audit.OpUpdate, audit.OpDelete},
+		"Filter":   filter,
+		"Limit":    activityLimit,
+		"LogPath":  h.app.Paths().Audit,
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "activity/list.html", payload)
+		return
+	}
+	h.render(w, "activity/list.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "activity",
+		ContentTpl: "activity/list.html",
+		Data:       payload,
+	})
+}
+
+// POST /tools/activity/{id}/undo
+func (h *Handler) UndoActivity(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if h.app.Audit() == nil {
+		h.operationError(w, "Auditoria indisponível", http.StatusServiceUnavailable)
+		return
+	}
+	rec := h.app.Audit().WithRoute(r.Method + " " + r.URL.Path)
+	e, err := rec.Undo(chi.URLParam(r, "id"))
+	switch {
+	case err == nil:
+	case errors.Is(err, audit.ErrEntryNotFound):
+		h.operationError(w, "Entrada não encontrada", http.StatusNotFound)
+		return
+	case errors.Is(err, audit.ErrAlreadyUndone):
+		h.errorToast(w, "Esta operação já foi desfeita")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	case errors.Is(err, audit.ErrConflict):
+		h.operationError(w, "Não é possível desfazer: o registro foi alterado depois desta operação", http.StatusConflict)
+		return
+	case errors.Is(err, vault.ErrLocked):
+		h.vaultError(w, err)
+		return
+	default:
+		h.app.Logger.Error("desfazer operação", "id", chi.URLParam(r, "id"), "err", err)
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+
+	msg := "Operação desfeita"
+	if e.Label != "" {
+		msg += ": " + e.Label
+	}
+	h.successToast(w, msg)
+}
+
+// ── Helpers ───────────────────────────────────────────────────
+
+func prettyJSON(data json.RawMessage) string {
+	if len(data) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return string(data)
+	}
+	return buf.String()
+}
@@ -0,0 +1,169 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// agentIdentityView é uma chave do agent com a chave gerenciada
+// correspondente, quando houver.
+type agentIdentityView struct {
+	ssh.AgentIdentity
+	ManagedName string
+}
+
+// GET /tools/keys/agent
+// Painel do ssh-agent, carregado dentro do Key Manager.
+func (h *Handler) AgentPanel(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	managed := make(map[string]string)
+	for _, k := range state.Keys {
+		if fp, err := ssh.PublicKeyFingerprint(k.PublicKeyPath); err == nil {
+			managed[fp] = k.Name
+		}
+	}
+
+	data := map[string]any{"Socket": ssh.AgentSocket()}
+	identities, err := ssh.ListAgent()
+	if err != nil {
+		data["Error"] = err.Error()
+	}
+	views := make([]agentIdentityView, 0, len(identities))
+	for _, id := range identities {
+		views = append(views, agentIdentityView{AgentIdentity: id, ManagedName: managed[id.Fingerprint]})
+	}
+	data["Identities"] = views
+	h.render(w, "keys/agent.html", data)
+}
+
+// GET /tools/keys/{id}/agent
+func (h *Handler) AddToAgentDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	k, ok := h.findKey(w, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+	h.renderDrawer(w, "Adicionar ao ssh-agent", "keys/agent-drawer.html", map[string]any{
+		"Key":    k,
+		"Errors": map[string]string{},
+	})
+}
+
+// POST /tools/keys/{id}/agent
+// Decifra a chave com a passphrase informada e a adiciona ao agent, com
+// tempo de vida (em minutos) e confirmação a cada uso opcionais.
+func (h *Handler) AddKeyToAgent(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	k, ok := h.findKey(w, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	errs := map[string]string{}
+	var opts ssh.AgentOptions
+	if v := strings.TrimSpace(r.FormValue("lifetime")); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes < 0 {
+			errs["lifetime"] = "informe os minutos como número inteiro"
+		}
+		opts.Lifetime = time.Duration(minutes) * time.Minute
+	}
+	opts.Confirm = r.FormValue("confirm") == "on"
+
+	if len(errs) == 0 {
+		comment := k.Comment
+		if comment == "" {
+			comment = k.Name
+		}
+		err := ssh.AddToAgent(k.PrivateKeyPath, []byte(r.FormValue("passphrase")), comment, opts)
+		switch {
+		case errors.Is(err, ssh.ErrPassphraseRequired):
+			errs["passphrase"] = "passphrase obrigatória para esta chave"
+		case errors.Is(err, ssh.ErrNoAgent):
+			h.operationError(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		case err != nil:
+			errs["passphrase"] = app.FriendlyMessage(err)
+		}
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderDrawer(w, "Adicionar ao ssh-agent", "keys/agent-drawer.html", map[string]any{
+			"Key":      k,
+			"Errors":   errs,
+			"Lifetime": r.FormValue("lifetime"),
+			"Confirm":  opts.Confirm,
+		})
+		return
+	}
+
+	h.app.Logger.Info("chave adicionada ao ssh-agent", "key", k.Alias, "lifetime", opts.Lifetime, "confirm", opts.Confirm)
+	h.successToast(w, "Chave "+k.Name+" carregada no ssh-agent")
+}
+
+// POST /tools/keys/agent/remove
+func (h *Handler) RemoveAgentKey(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	if err := ssh.RemoveFromAgent(r.FormValue("fingerprint")); err != nil {
+		h.operationError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.successToast(w, "Chave removida do ssh-agent")
+}
+
+// POST /tools/keys/agent/clear
+func (h *Handler) ClearAgent(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := ssh.RemoveAllFromAgent(); err != nil {
+		h.operationError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	h.successToast(w, "Todas as chaves foram removidas do ssh-agent")
+}
+
+// findKey carrega a chave id do state ou responde com erro.
+func (h *Handler) findKey(w http.ResponseWriter, id string) (storage.Key, bool) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return storage.Key{}, false
+	}
+	idx := findKeyIndex(state.Keys, id)
+	if idx < 0 {
+		h.operationError(w, "Chave não encontrada", http.StatusNotFound)
+		return storage.Key{}, false
+	}
+	return state.Keys[idx], true
+}
+
+// agentFingerprints devolve os fingerprints carregados no agent; vazio
+// quando não há agent.
+func agentFingerprints() map[string]bool {
+	out := make(map[string]bool)
+	identities, _ := ssh.ListAgent()
+	for _, id := range identities {
+		out[id.Fingerprint] = true
+	}
+	return out
+}
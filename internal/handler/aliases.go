@@ -1,10 +1,7 @@
 package handler
 
 import (
-	"bufio"
-	"fmt"
 	"net/http"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -12,13 +9,14 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/shell"
 	"github.com/seuusuario/factorydev/internal/storage"
 )
 
 // GET /tools/aliases
 func (h *Handler) ListAliases(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -30,7 +28,7 @@ func (h *Handler) ListAliases(w http.ResponseWriter, r *http.Request) {
 
 	payload := map[string]any{
 		"Aliases":   aliases,
-		"AliasFile": filepath.Join(h.app.Paths.Base, "aliases.sh"),
+		"AliasFile": filepath.Join(h.app.Paths().Base, "aliases.sh"),
 	}
 	if r.Header.Get("HX-Request") == "true" {
 		h.render(w, "aliases/list.html", payload)
@@ -72,27 +70,23 @@ func (h *Handler) CreateAlias(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
-	// Verificar duplicata
-	for _, existing := range state.Aliases {
-		if existing.Name == a.Name {
-			h.errorToast(w, "Alias já existe: "+a.Name)
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			return
+	var saved *storage.State
+	err := h.store(r).Update(func(state *storage.State) error {
+		// Verificar duplicata
+		for _, existing := range state.Aliases {
+			if existing.Name == a.Name {
+				return validationFailed("Alias já existe: " + a.Name)
+			}
 		}
-	}
-
-	state.Aliases = append(state.Aliases, a)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		state.Aliases = append(state.Aliases, a)
+		saved = state
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
-	h.syncAliasFile(state)
+	h.syncAliasFile(saved)
 	h.successToast(w, "Alias criado!")
 }
 
@@ -100,7 +94,7 @@ func (h *Handler) CreateAlias(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditAliasDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -139,23 +133,23 @@ func (h *Handler) UpdateAlias(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	for i := range state.Aliases {
-		if state.Aliases[i].ID == id {
-			state.Aliases[i].Name = a.Name
-			state.Aliases[i].Command = a.Command
-			break
+	var saved *storage.State
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.Aliases {
+			if state.Aliases[i].ID == id {
+				state.Aliases[i].Name = a.Name
+				state.Aliases[i].Command = a.Command
+				saved = state
+				return nil
+			}
 		}
-	}
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Alias não encontrado")
 		return
 	}
-	h.syncAliasFile(state)
+	h.syncAliasFile(saved)
 	h.successToast(w, "Alias atualizado!")
 }
 
@@ -163,28 +157,22 @@ func (h *Handler) UpdateAlias(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteAlias(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := -1
-	for i, a := range state.Aliases {
-		if a.ID == id {
-			idx = i
-			break
+	var saved *storage.State
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i, a := range state.Aliases {
+			if a.ID == id {
+				state.Aliases = append(state.Aliases[:i], state.Aliases[i+1:]...)
+				saved = state
+				return nil
+			}
 		}
-	}
-	if idx < 0 {
-		h.operationError(w, "Alias não encontrado", http.StatusNotFound)
-		return
-	}
-	state.Aliases = append(state.Aliases[:idx], state.Aliases[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Alias não encontrado")
 		return
 	}
-	h.syncAliasFile(state)
+	h.syncAliasFile(saved)
 	h.successToast(w, "Alias removido!")
 }
 
@@ -199,54 +187,7 @@ func parseAliasForm(r *http.Request) storage.ShellAlias {
 
 // syncAliasFile regenera ~/.fdev/aliases.sh e garante source nos shells.
 func (h *Handler) syncAliasFile(state *storage.State) {
-	aliasPath := filepath.Join(h.app.Paths.Base, "aliases.sh")
-
-	// Gera conteúdo
-	var sb strings.Builder
-	sb.WriteString("# Gerado automaticamente pelo FactoryDev — não edite manualmente\n")
-	for _, a := range state.Aliases {
-		// Escapa aspas simples no comando
-		cmd := strings.ReplaceAll(a.Command, "'", "'\\''")
-		sb.WriteString(fmt.Sprintf("alias %s='%s'\n", a.Name, cmd))
-	}
-
-	if err := os.WriteFile(aliasPath, []byte(sb.String()), 0o644); err != nil {
+	if _, err := shell.SyncAliases(state.Aliases, h.app.Paths()); err != nil {
 		h.app.Logger.Error("erro ao gravar aliases.sh", "err", err)
-		return
-	}
-
-	// Garante source nos shells
-	sourceLine := fmt.Sprintf("source %q", aliasPath)
-	for _, rc := range []string{
-		filepath.Join(h.app.Paths.Home, ".zshrc"),
-		filepath.Join(h.app.Paths.Home, ".bashrc"),
-	} {
-		ensureSourceLine(rc, sourceLine)
-	}
-}
-
-// ensureSourceLine adiciona a linha de source se não existir no arquivo rc.
-func ensureSourceLine(rcPath, sourceLine string) {
-	f, err := os.Open(rcPath)
-	if err != nil {
-		// Arquivo não existe — cria com a linha
-		_ = os.WriteFile(rcPath, []byte("# Added by FactoryDev\n"+sourceLine+"\n"), 0o644)
-		return
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		if strings.Contains(scanner.Text(), sourceLine) {
-			return // já existe
-		}
-	}
-
-	// Append
-	af, err := os.OpenFile(rcPath, os.O_APPEND|os.O_WRONLY, 0o644)
-	if err != nil {
-		return
 	}
-	defer af.Close()
-	_, _ = af.WriteString("\n# Added by FactoryDev\n" + sourceLine + "\n")
 }
@@ -0,0 +1,342 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+// APIPrefix é a raiz da API JSON versionada.
+const APIPrefix = "/api/v1"
+
+// maxAPIBody limita o corpo das requisições JSON.
+const maxAPIBody = 1 << 20
+
+// apiCollection é uma coleção do state exposta em /api/v1/<path>. A API usa
+// as mesmas validações e o mesmo tratamento de segredos dos handlers HTMX.
+type apiCollection interface {
+	mount(h *Handler, r chi.Router)
+	describe() apiCollectionDoc
+}
+
+// apiResource implementa list/get/create/update/delete para um slice do
+// State. Coleções com regras próprias de criação (chaves, que exigem gerar
+// arquivos) desligam o CRUD genérico e registram ações.
+type apiResource[T any] struct {
+	path    string // segmento da URL, ex: "env-files"
+	schema  string // nome do schema no OpenAPI
+	summary string
+	items   func(*storage.State) *[]T
+	id      func(*T) *string
+
+	// prepare normaliza e valida o registro dentro do Update; old é nil na
+	// criação. Deve preencher datas e campos derivados.
+	prepare func(h *Handler, st *storage.State, v, old *T) error
+	// seal/reveal cifram e decifram os segredos do registro (vault).
+	seal   func(h *Handler, v *T) error
+	reveal func(h *Handler, v *T) error
+	// unmask troca os segredos mascarados (GET com o vault bloqueado) pelo
+	// valor gravado em old; sem valor gravado, recusa a máscara.
+	unmask func(v, old *T) error
+	// beforeDelete pode vetar a remoção (ex: chave em uso).
+	beforeDelete func(st *storage.State, id string) error
+	// drop substitui a remoção genérica quando o registro leva outros
+	// junto (ex: certificados da chave); false = não encontrado.
+	drop func(st *storage.State, id string) bool
+	// afterWrite roda após qualquer escrita bem-sucedida.
+	afterWrite func(h *Handler, st *storage.State)
+
+	noCreate bool
+	noUpdate bool
+	actions  []apiAction
+}
+
+// apiAction é uma rota extra de uma coleção, ex: POST /accounts/{id}/apply.
+type apiAction struct {
+	method  string
+	path    string // relativo à coleção, ex: "/{id}/apply"
+	summary string
+	request any // exemplo do corpo (para o OpenAPI); nil = sem corpo
+	respond any // exemplo da resposta
+	status  int // status de sucesso (default 200)
+	handler func(h *Handler) http.HandlerFunc
+}
+
+func (res *apiResource[T]) mount(h *Handler, r chi.Router) {
+	base := "/" + res.path
+	r.Get(base, res.list(h))
+	r.Get(base+"/{id}", res.get(h))
+	if !res.noCreate {
+		r.Post(base, res.create(h))
+	}
+	if !res.noUpdate {
+		r.Put(base+"/{id}", res.update(h))
+	}
+	r.Delete(base+"/{id}", res.remove(h))
+	for _, a := range res.actions {
+		r.Method(a.method, base+a.path, a.handler(h))
+	}
+}
+
+func (res *apiResource[T]) list(h *Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := h.app.Storage().LoadState()
+		if err != nil {
+			h.apiFailed(w, err, "")
+			return
+		}
+		items := *res.items(state)
+		out := make([]T, len(items))
+		for i := range items {
+			out[i] = items[i]
+			res.present(h, &out[i])
+		}
+		writeJSON(w, http.StatusOK, out)
+	}
+}
+
+func (res *apiResource[T]) get(h *Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state, err := h.app.Storage().LoadState()
+		if err != nil {
+			h.apiFailed(w, err, "")
+			return
+		}
+		item := res.find(state, chi.URLParam(r, "id"))
+		if item == nil {
+			apiError(w, http.StatusNotFound, res.notFound())
+			return
+		}
+		v := *item
+		res.present(h, &v)
+		writeJSON(w, http.StatusOK, v)
+	}
+}
+
+func (res *apiResource[T]) create(h *Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var v T
+		if !decodeJSON(w, r, &v) {
+			return
+		}
+		*res.id(&v) = newID()
+		res.write(h, w, r, http.StatusCreated, &v, false)
+	}
+}
+
+func (res *apiResource[T]) update(h *Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var v T
+		if !decodeJSON(w, r, &v) {
+			return
+		}
+		*res.id(&v) = chi.URLParam(r, "id")
+		res.write(h, w, r, http.StatusOK, &v, true)
+	}
+}
+
+// write grava o registro, novo ou substituindo o existente. Os segredos são
+// selados dentro da transação, depois de recuperar os que voltaram
+// mascarados; com o vault bloqueado, só passam valores já selados.
+func (res *apiResource[T]) write(h *Handler, w http.ResponseWriter, r *http.Request, status int, v *T, replace bool) {
+	var saved *storage.State
+	err := h.store(r).Update(func(st *storage.State) error {
+		items := res.items(st)
+		var old *T
+		idx := -1
+		if replace {
+			for i := range *items {
+				if *res.id(&(*items)[i]) == *res.id(v) {
+					idx, old = i, &(*items)[i]
+					break
+				}
+			}
+			if old == nil {
+				return errNotFound
+			}
+		}
+		if res.unmask != nil {
+			if err := res.unmask(v, old); err != nil {
+				return err
+			}
+		}
+		if res.seal != nil {
+			if err := res.seal(h, v); err != nil {
+				return err
+			}
+		}
+		if res.prepare != nil {
+			if err := res.prepare(h, st, v, old); err != nil {
+				return err
+			}
+		}
+		if idx >= 0 {
+			(*items)[idx] = *v
+		} else {
+			*items = append(*items, *v)
+		}
+		saved = st
+		return nil
+	})
+	if err != nil {
+		h.apiFailed(w, err, res.notFound())
+		return
+	}
+	if res.afterWrite != nil {
+		res.afterWrite(h, saved)
+	}
+	res.present(h, v)
+	writeJSON(w, status, v)
+}
+
+func (res *apiResource[T]) remove(h *Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := chi.URLParam(r, "id")
+		var saved *storage.State
+		err := h.store(r).Update(func(st *storage.State) error {
+			if res.beforeDelete != nil {
+				if err := res.beforeDelete(st, id); err != nil {
+					return err
+				}
+			}
+			if res.drop != nil {
+				if !res.drop(st, id) {
+					return errNotFound
+				}
+				saved = st
+				return nil
+			}
+			items := res.items(st)
+			for i := range *items {
+				if *res.id(&(*items)[i]) == id {
+					*items = append((*items)[:i], (*items)[i+1:]...)
+					saved = st
+					return nil
+				}
+			}
+			return errNotFound
+		})
+		if err != nil {
+			h.apiFailed(w, err, res.notFound())
+			return
+		}
+		if res.afterWrite != nil {
+			res.afterWrite(h, saved)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// present decifra os segredos para a resposta. Com o vault bloqueado os
+// valores selados saem mascarados, como nas listagens da interface.
+func (res *apiResource[T]) present(h *Handler, v *T) {
+	if res.reveal == nil {
+		return
+	}
+	_ = res.reveal(h, v)
+}
+
+func (res *apiResource[T]) find(st *storage.State, id string) *T {
+	items := res.items(st)
+	for i := range *items {
+		if *res.id(&(*items)[i]) == id {
+			return &(*items)[i]
+		}
+	}
+	return nil
+}
+
+func (res *apiResource[T]) notFound() string {
+	return res.schema + " não encontrado"
+}
+
+func (res *apiResource[T]) describe() apiCollectionDoc {
+	return apiCollectionDoc{
+		Path:     res.path,
+		Schema:   res.schema,
+		Summary:  res.summary,
+		Type:     reflect.TypeOf((*T)(nil)).Elem(),
+		NoCreate: res.noCreate,
+		NoUpdate: res.noUpdate,
+		Actions:  res.actions,
+	}
+}
+
+// ── Respostas ─────────────────────────────────────────────────
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// apiErrorBody é o corpo de toda resposta de erro da API.
+type apiErrorBody struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+func apiError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, apiErrorBody{Error: msg})
+}
+
+// fieldErrors aborta um Update com erros de validação por campo.
+type fieldErrors map[string]string
+
+func (e fieldErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for field, msg := range e {
+		parts = append(parts, field+": "+msg)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// apiFailed é o equivalente JSON de updateFailed.
+func (h *Handler) apiFailed(w http.ResponseWriter, err error, notFoundMsg string) {
+	var (
+		vf     validationFailed
+		fields fieldErrors
+		conf   apiConflict
+	)
+	switch {
+	case errors.As(err, &fields):
+		writeJSON(w, http.StatusUnprocessableEntity, apiErrorBody{Error: "dados inválidos", Fields: fields})
+	case errors.As(err, &vf):
+		apiError(w, http.StatusUnprocessableEntity, string(vf))
+	case errors.As(err, &conf):
+		apiError(w, http.StatusConflict, string(conf))
+	case errors.Is(err, errNotFound):
+		apiError(w, http.StatusNotFound, notFoundMsg)
+	case errors.Is(err, vault.ErrLocked):
+		apiError(w, http.StatusLocked, "vault bloqueado — desbloqueie em Vault para acessar os segredos")
+	default:
+		h.app.Logger.Error("erro na API", "err", err)
+		apiError(w, http.StatusInternalServerError, app.FriendlyMessage(err))
+	}
+}
+
+// apiConflict aborta um Update quando o estado atual impede a operação.
+type apiConflict string
+
+func (e apiConflict) Error() string { return string(e) }
+
+// decodeJSON lê o corpo; em caso de erro já responde 400.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAPIBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		apiError(w, http.StatusBadRequest, fmt.Sprintf("JSON inválido: %v", err))
+		return false
+	}
+	return true
+}
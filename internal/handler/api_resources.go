@@ -0,0 +1,641 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	igit "github.com/seuusuario/factorydev/internal/git"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/scheduler"
+	"github.com/seuusuario/factorydev/internal/shell"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+// apiRoutes monta /api/v1. A autenticação (Bearer ou sessão + CSRF) vem do
+// middleware global.
+func (h *Handler) apiRoutes(r chi.Router) {
+	r.Get("/openapi.json", h.OpenAPISpec)
+	for _, c := range apiCollections {
+		c.mount(h, r)
+	}
+	r.Get("/jobs", h.APIListJobs)
+	r.Get("/jobs/{id}", h.APIGetJob)
+	r.Post("/jobs/{id}/cancel", h.APICancelJob)
+}
+
+// apiCollections é a lista de coleções expostas, na ordem do OpenAPI.
+var apiCollections = []apiCollection{
+	&apiResource[storage.Key]{
+		path: "keys", schema: "Key", summary: "Chaves SSH do Key Manager",
+		items:    func(s *storage.State) *[]storage.Key { return &s.Keys },
+		id:       func(v *storage.Key) *string { return &v.ID },
+		noCreate: true, noUpdate: true,
+		beforeDelete: func(st *storage.State, id string) error {
+			var usedBy []string
+			for _, a := range st.Accounts {
+				if a.KeyID == id {
+					usedBy = append(usedBy, a.Name)
+				}
+			}
+			if len(usedBy) > 0 {
+				return apiConflict("Chave em uso por: " + strings.Join(usedBy, ", "))
+			}
+			return nil
+		},
+		drop: removeKey,
+		actions: []apiAction{{
+			method: http.MethodPost, path: "", status: http.StatusCreated,
+			summary: "Gera um novo par de chaves",
+			request: apiKeyRequest{}, respond: storage.Key{},
+			handler: func(h *Handler) http.HandlerFunc { return h.apiCreateKey },
+		}},
+	},
+	&apiResource[storage.Certificate]{
+		path: "certificates", schema: "Certificate", summary: "Certificados OpenSSH emitidos pelas CAs do Key Manager",
+		items:    func(s *storage.State) *[]storage.Certificate { return &s.Certificates },
+		id:       func(v *storage.Certificate) *string { return &v.ID },
+		noCreate: true, noUpdate: true,
+	},
+	&apiResource[storage.KeyRotation]{
+		path: "key-rotations", schema: "KeyRotation", summary: "Rotações de chave feitas pelo Key Manager",
+		items:    func(s *storage.State) *[]storage.KeyRotation { return &s.KeyRotations },
+		id:       func(v *storage.KeyRotation) *string { return &v.ID },
+		noCreate: true, noUpdate: true,
+	},
+	&apiResource[storage.Account]{
+		path: "accounts", schema: "Account", summary: "Contas SSH (blocos do ~/.ssh/config)",
+		items: func(s *storage.State) *[]storage.Account { return &s.Accounts },
+		id:    func(v *storage.Account) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.Account) error {
+			v.UpdatedAt = time.Now()
+			v.CreatedAt = v.UpdatedAt
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			if errs := storage.Validate(*v, st.Accounts); len(errs) > 0 {
+				return fieldErrors(mapValidation(errs))
+			}
+			if v.KeyID != "" {
+				k := st.FindKey(v.KeyID)
+				if k == nil {
+					return fieldErrors{"keyId": "chave não encontrada"}
+				}
+				// Compat com ApplySSHConfig
+				v.IdentityFile = k.PrivateKeyPath
+			}
+			return nil
+		},
+		actions: []apiAction{
+			{
+				method: http.MethodPost, path: "/{id}/apply",
+				summary: "Aplica o bloco da conta no ~/.ssh/config",
+				respond: apiResult{},
+				handler: func(h *Handler) http.HandlerFunc { return h.apiApplyAccount },
+			},
+			{
+				method: http.MethodPost, path: "/{id}/test",
+				summary: "Testa a conexão SSH da conta (ssh -T)",
+				respond: apiResult{},
+				handler: func(h *Handler) http.HandlerFunc { return h.apiTestAccount },
+			},
+		},
+	},
+	&apiResource[storage.Repository]{
+		path: "repositories", schema: "Repository", summary: "Repositórios locais",
+		items: func(s *storage.State) *[]storage.Repository { return &s.Repositories },
+		id:    func(v *storage.Repository) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.Repository) error {
+			v.Name, v.LocalPath = strings.TrimSpace(v.Name), strings.TrimSpace(v.LocalPath)
+			if v.LocalPath == "" {
+				return fieldErrors{"localPath": "obrigatório"}
+			}
+			if v.Name == "" {
+				v.Name = igit.RepoNameFromURL(v.LocalPath)
+			}
+			if v.AccountID != "" {
+				if _, ok := findAccountByID(st.Accounts, v.AccountID); !ok {
+					return fieldErrors{"accountId": "conta não encontrada"}
+				}
+			}
+			if old != nil {
+				v.ClonedAt = old.ClonedAt
+			} else if v.ClonedAt.IsZero() {
+				v.ClonedAt = time.Now()
+			}
+			return nil
+		},
+		actions: []apiAction{
+			{
+				method: http.MethodGet, path: "/{id}/status",
+				summary: "Branch atual e alterações pendentes",
+				respond: igit.RepoStatus{},
+				handler: func(h *Handler) http.HandlerFunc { return h.apiRepoStatus },
+			},
+			{
+				method: http.MethodPost, path: "/pull-all", status: http.StatusAccepted,
+				summary: "Inicia git pull em todos os repositórios (job)",
+				request: apiPullAllRequest{}, respond: jobs.Job{},
+				handler: func(h *Handler) http.HandlerFunc { return h.apiPullAll },
+			},
+		},
+	},
+	&apiResource[storage.Server]{
+		path: "servers", schema: "Server", summary: "Servidores SSH",
+		items: func(s *storage.State) *[]storage.Server { return &s.Servers },
+		id:    func(v *storage.Server) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.Server) error {
+			if v.Port == 0 {
+				v.Port = 22
+			}
+			if msg := validateServer(*v); msg != "" {
+				return validationFailed(msg)
+			}
+			if v.KeyID != "" && st.FindKey(v.KeyID) == nil {
+				return fieldErrors{"keyId": "chave não encontrada"}
+			}
+			v.CreatedAt = time.Now()
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+		actions: []apiAction{{
+			method: http.MethodPost, path: "/{id}/test",
+			summary: "Testa a conexão SSH com o servidor",
+			respond: apiResult{},
+			handler: func(h *Handler) http.HandlerFunc { return h.apiTestServer },
+		}},
+	},
+	&apiResource[storage.GitIdentity]{
+		path: "identities", schema: "GitIdentity", summary: "Identidades Git",
+		items: func(s *storage.State) *[]storage.GitIdentity { return &s.Identities },
+		id:    func(v *storage.GitIdentity) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.GitIdentity) error {
+			v.Name, v.Email = strings.TrimSpace(v.Name), strings.TrimSpace(v.Email)
+			if v.Name == "" || v.Email == "" {
+				return validationFailed("Nome e e-mail são obrigatórios")
+			}
+			v.CreatedAt = time.Now()
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+	},
+	&apiResource[storage.EnvFile]{
+		path: "env-files", schema: "EnvFile", summary: "Arquivos .env (valores selados no vault)",
+		items: func(s *storage.State) *[]storage.EnvFile { return &s.EnvFiles },
+		id:    func(v *storage.EnvFile) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.EnvFile) error {
+			if errs := storage.ValidateEnvFile(*v); len(errs) > 0 {
+				return fieldErrors(mapValidation(errs))
+			}
+			v.UpdatedAt = time.Now()
+			v.CreatedAt = v.UpdatedAt
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+		seal: func(h *Handler, v *storage.EnvFile) error { return h.sealEnvFile(v) },
+		unmask: func(v, old *storage.EnvFile) error {
+			var prev map[string]string
+			if old != nil {
+				prev = old.Variables
+			}
+			return keepMaskedMap("variables", v.Variables, prev)
+		},
+		reveal: func(h *Handler, v *storage.EnvFile) error {
+			return revealOrMaskMap(h, &v.Variables)
+		},
+		actions: []apiAction{{
+			method: http.MethodPost, path: "/{id}/export",
+			summary: "Grava o arquivo em ~/.fdev/envs/<id>.env",
+			respond: apiResult{},
+			handler: func(h *Handler) http.HandlerFunc { return h.apiExportEnv },
+		}},
+	},
+	&apiResource[storage.ShellAlias]{
+		path: "aliases", schema: "ShellAlias", summary: "Aliases de shell (~/.fdev/aliases.sh)",
+		items: func(s *storage.State) *[]storage.ShellAlias { return &s.Aliases },
+		id:    func(v *storage.ShellAlias) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.ShellAlias) error {
+			v.Name, v.Command = strings.TrimSpace(v.Name), strings.TrimSpace(v.Command)
+			if errs := storage.ValidateShellAlias(*v); len(errs) > 0 {
+				return fieldErrors(mapValidation(errs))
+			}
+			for _, existing := range st.Aliases {
+				if existing.Name == v.Name && existing.ID != v.ID {
+					return validationFailed("Alias já existe: " + v.Name)
+				}
+			}
+			v.CreatedAt = time.Now()
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+		afterWrite: func(h *Handler, st *storage.State) { h.syncAliasFile(st) },
+	},
+	&apiResource[storage.APICollection]{
+		path: "api-collections", schema: "APICollection", summary: "Collections do API Client",
+		items: func(s *storage.State) *[]storage.APICollection { return &s.APICollections },
+		id:    func(v *storage.APICollection) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.APICollection) error {
+			if strings.TrimSpace(v.Name) == "" {
+				return validationFailed("Nome é obrigatório")
+			}
+			if v.AuthType == "" {
+				v.AuthType = "none"
+			}
+			v.CreatedAt = time.Now()
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+		seal: func(h *Handler, v *storage.APICollection) error { return h.sealCollection(v) },
+		unmask: func(v, old *storage.APICollection) error {
+			var prev map[string]string
+			if old != nil {
+				prev = old.AuthData
+			}
+			return keepMaskedMap("authData", v.AuthData, prev)
+		},
+		reveal: func(h *Handler, v *storage.APICollection) error {
+			return revealOrMaskMap(h, &v.AuthData)
+		},
+	},
+	&apiResource[storage.DBConnection]{
+		path: "db-connections", schema: "DBConnection", summary: "Conexões do Database Browser",
+		items: func(s *storage.State) *[]storage.DBConnection { return &s.DBConnections },
+		id:    func(v *storage.DBConnection) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.DBConnection) error {
+			if strings.TrimSpace(v.Name) == "" {
+				return validationFailed("Nome é obrigatório")
+			}
+			switch v.Driver {
+			case "sqlite", "postgres", "mysql":
+			default:
+				return fieldErrors{"driver": "use sqlite, postgres ou mysql"}
+			}
+			v.CreatedAt = time.Now()
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+		seal: func(h *Handler, v *storage.DBConnection) error { return h.sealDBConnection(v) },
+		unmask: func(v, old *storage.DBConnection) error {
+			if v.Password != secretMask {
+				return nil
+			}
+			if old == nil || old.Password == "" {
+				return fieldErrors{"password": "valor mascarado sem segredo gravado"}
+			}
+			v.Password = old.Password
+			return nil
+		},
+		reveal: func(h *Handler, v *storage.DBConnection) error {
+			if err := h.revealDBConnection(v); err != nil && vault.IsSealed(v.Password) {
+				v.Password = secretMask
+			}
+			return nil
+		},
+	},
+	&apiResource[storage.MCPServer]{
+		path: "mcp-servers", schema: "MCPServer", summary: "Servidores MCP",
+		items: func(s *storage.State) *[]storage.MCPServer { return &s.MCPServers },
+		id:    func(v *storage.MCPServer) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.MCPServer) error {
+			if strings.TrimSpace(v.Name) == "" || strings.TrimSpace(v.Command) == "" {
+				return validationFailed("Nome e comando são obrigatórios")
+			}
+			v.CreatedAt = time.Now()
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+		seal: func(h *Handler, v *storage.MCPServer) error { return h.sealMCPServer(v) },
+		unmask: func(v, old *storage.MCPServer) error {
+			var prev map[string]string
+			if old != nil {
+				prev = old.Env
+			}
+			return keepMaskedMap("env", v.Env, prev)
+		},
+		reveal: func(h *Handler, v *storage.MCPServer) error {
+			return revealOrMaskMap(h, &v.Env)
+		},
+	},
+	&apiResource[storage.Schedule]{
+		path: "schedules", schema: "Schedule", summary: "Agendamentos (expressões cron)",
+		items: func(s *storage.State) *[]storage.Schedule { return &s.Schedules },
+		id:    func(v *storage.Schedule) *string { return &v.ID },
+		prepare: func(h *Handler, st *storage.State, v, old *storage.Schedule) error {
+			v.Name, v.Cron = strings.TrimSpace(v.Name), strings.TrimSpace(v.Cron)
+			if errs := h.app.Scheduler.Validate(*v); len(errs) > 0 {
+				return fieldErrors(mapValidation(errs))
+			}
+			v.UpdatedAt = time.Now()
+			v.CreatedAt = v.UpdatedAt
+			if old != nil {
+				v.CreatedAt = old.CreatedAt
+			}
+			return nil
+		},
+		actions: []apiAction{{
+			method: http.MethodPost, path: "/{id}/run",
+			summary: "Executa o agendamento agora; a saída fica no job",
+			respond: storage.ScheduleRun{},
+			status:  http.StatusAccepted,
+			handler: func(h *Handler) http.HandlerFunc { return h.apiRunSchedule },
+		}},
+	},
+}
+
+// revealOrMaskMap decifra m no lugar; com o vault bloqueado mascara os
+// valores selados.
+func revealOrMaskMap(h *Handler, m *map[string]string) error {
+	revealed, err := h.app.Vault.RevealMap(*m)
+	if err != nil {
+		*m = maskSealed(*m)
+		return err
+	}
+	*m = revealed
+	return nil
+}
+
+// keepMaskedMap devolve a cada valor mascarado de m o segredo gravado em old:
+// a máscara veio de uma leitura com o vault bloqueado e não é um valor novo.
+// Sem segredo gravado para a chave, a máscara é recusada.
+func keepMaskedMap(field string, m, old map[string]string) error {
+	for k, v := range m {
+		if v != secretMask {
+			continue
+		}
+		prev, ok := old[k]
+		if !ok || prev == "" {
+			return fieldErrors{field + "." + k: "valor mascarado sem segredo gravado"}
+		}
+		m[k] = prev
+	}
+	return nil
+}
+
+// ── Ações ─────────────────────────────────────────────────────
+
+// apiResult é a resposta das ações síncronas.
+type apiResult struct {
+	OK     bool   `json:"ok"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Path   string `json:"path,omitempty"`
+}
+
+// apiKeyRequest é o corpo de POST /api/v1/keys.
+type apiKeyRequest struct {
+	Name       string `json:"name"`
+	Alias      string `json:"alias,omitempty"`
+	Type       string `json:"type,omitempty"` // default ed25519
+	Bits       int    `json:"bits,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// POST /api/v1/keys
+func (h *Handler) apiCreateKey(w http.ResponseWriter, r *http.Request) {
+	var req apiKeyRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	alias := strings.TrimSpace(req.Alias)
+	if alias == "" {
+		alias = storage.SanitizeAlias(name)
+	}
+	if req.Type == "" {
+		req.Type = "ed25519"
+	}
+	k := storage.Key{
+		ID:        newID(),
+		Name:      name,
+		Alias:     alias,
+		Type:      req.Type,
+		Bits:      req.Bits,
+		Comment:   strings.TrimSpace(req.Comment),
+		Protected: req.Passphrase != "",
+		Source:    "generated",
+		CreatedAt: time.Now(),
+	}
+	k, errs, err := h.createKey(r, k, []byte(req.Passphrase))
+	if len(errs) > 0 {
+		err = fieldErrors(errs)
+	}
+	if err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	if h.app.Commands.DryRun() {
+		// Nada foi gravado: devolve a chave simulada sem 201.
+		writeJSON(w, http.StatusOK, k)
+		return
+	}
+	writeJSON(w, http.StatusCreated, k)
+}
+
+// POST /api/v1/accounts/{id}/apply
+func (h *Handler) apiApplyAccount(w http.ResponseWriter, r *http.Request) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	acc, ok := findAccountByID(state.Accounts, chi.URLParam(r, "id"))
+	if !ok {
+		apiError(w, http.StatusNotFound, "Conta não encontrada")
+		return
+	}
+	acc = state.ResolveKeyFiles(acc)
+	if err := ssh.ApplyAccount(acc, h.app.Paths()); err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	writeJSON(w, http.StatusOK, apiResult{OK: true, Path: h.app.Paths().SSHConfig()})
+}
+
+// POST /api/v1/accounts/{id}/test
+func (h *Handler) apiTestAccount(w http.ResponseWriter, r *http.Request) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	acc, ok := findAccountByID(state.Accounts, chi.URLParam(r, "id"))
+	if !ok {
+		apiError(w, http.StatusNotFound, "Conta não encontrada")
+		return
+	}
+	output, err := ssh.TestConnection(acc.HostAlias)
+	res := apiResult{OK: err == nil, Output: output}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// GET /api/v1/repositories/{id}/status
+func (h *Handler) apiRepoStatus(w http.ResponseWriter, r *http.Request) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	for _, repo := range state.Repositories {
+		if repo.ID == chi.URLParam(r, "id") {
+			writeJSON(w, http.StatusOK, igit.Status(repo.LocalPath))
+			return
+		}
+	}
+	apiError(w, http.StatusNotFound, "Repositório não encontrado")
+}
+
+// apiPullAllRequest é o corpo (opcional) de POST /repositories/pull-all.
+type apiPullAllRequest struct {
+	Force bool `json:"force"`
+}
+
+// POST /api/v1/repositories/pull-all
+func (h *Handler) apiPullAll(w http.ResponseWriter, r *http.Request) {
+	var req apiPullAllRequest
+	if r.ContentLength != 0 && !decodeJSON(w, r, &req) {
+		return
+	}
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, h.startPullAll(state, req.Force))
+}
+
+// POST /api/v1/servers/{id}/test
+func (h *Handler) apiTestServer(w http.ResponseWriter, r *http.Request) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	srv, keyPath := findServerAndKey(state, chi.URLParam(r, "id"))
+	if srv == nil {
+		apiError(w, http.StatusNotFound, "Servidor não encontrado")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+	out, err := ssh.TestServer(ctx, serverTarget(srv, keyPath))
+	res := apiResult{OK: err == nil, Output: out}
+	if err != nil {
+		res.Error = "Falha na conexão: " + err.Error()
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// POST /api/v1/schedules/{id}/run
+func (h *Handler) apiRunSchedule(w http.ResponseWriter, r *http.Request) {
+	run, err := h.app.Scheduler.RunNow(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, scheduler.ErrRunning):
+		apiError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, scheduler.ErrNotFound):
+		apiError(w, http.StatusNotFound, "Agendamento não encontrado")
+	case err != nil && run.ID == "":
+		h.apiFailed(w, err, "")
+	case err != nil:
+		writeJSON(w, http.StatusUnprocessableEntity, apiErrorBody{Error: err.Error()})
+	default:
+		writeJSON(w, http.StatusAccepted, run)
+	}
+}
+
+// POST /api/v1/env-files/{id}/export
+func (h *Handler) apiExportEnv(w http.ResponseWriter, r *http.Request) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.apiFailed(w, err, "")
+		return
+	}
+	for _, env := range state.EnvFiles {
+		if env.ID != chi.URLParam(r, "id") {
+			continue
+		}
+		vars, err := h.app.Vault.RevealMap(env.Variables)
+		if err != nil {
+			h.apiFailed(w, err, "")
+			return
+		}
+		path, err := shell.WriteEnv(h.app.Paths().Envs, env.ID, vars)
+		if err != nil {
+			h.apiFailed(w, err, "")
+			return
+		}
+		writeJSON(w, http.StatusOK, apiResult{OK: true, Path: path})
+		return
+	}
+	apiError(w, http.StatusNotFound, "Arquivo .env não encontrado")
+}
+
+// ── Jobs ──────────────────────────────────────────────────────
+
+// GET /api/v1/jobs — jobs em memória; ?history=1 inclui o histórico.
+func (h *Handler) APIListJobs(w http.ResponseWriter, r *http.Request) {
+	list := h.app.Jobs.List()
+	if r.URL.Query().Get("history") != "" {
+		seen := make(map[string]bool, len(list))
+		for _, j := range list {
+			seen[j.ID] = true
+		}
+		history, err := h.app.Jobs.History(jobsHistoryLimit)
+		if err != nil {
+			h.apiFailed(w, err, "")
+			return
+		}
+		for _, j := range history {
+			if !seen[j.ID] {
+				list = append(list, j)
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GET /api/v1/jobs/{id}
+func (h *Handler) APIGetJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.app.Jobs.Get(chi.URLParam(r, "id"))
+	if !ok {
+		apiError(w, http.StatusNotFound, "Job não encontrado")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// POST /api/v1/jobs/{id}/cancel
+func (h *Handler) APICancelJob(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	switch err := h.app.Jobs.Cancel(id); {
+	case errors.Is(err, jobs.ErrNotFound):
+		apiError(w, http.StatusNotFound, "Job não encontrado")
+	case errors.Is(err, jobs.ErrFinished):
+		apiError(w, http.StatusConflict, "O job já terminou")
+	default:
+		job, _ := h.app.Jobs.Get(id)
+		writeJSON(w, http.StatusAccepted, job)
+	}
+}
@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func newAPITestServer(t *testing.T) (http.Handler, string) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	return New(a).Routes(), a.Auth.Token()
+}
+
+func apiDo(t *testing.T, srv http.Handler, token, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Host = "127.0.0.1:7777"
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIIdentityCRUD(t *testing.T) {
+	srv, token := newAPITestServer(t)
+
+	if rec := apiDo(t, srv, "", "GET", "/api/v1/identities", ""); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("sem token: status %d", rec.Code)
+	}
+
+	rec := apiDo(t, srv, token, "POST", "/api/v1/identities", `{"name":"Hugo"}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("sem e-mail: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = apiDo(t, srv, token, "POST", "/api/v1/identities", `{"name":"Hugo","email":"hugo@example.com"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	var created storage.GitIdentity
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil || created.ID == "" {
+		t.Fatalf("create: resposta inválida %s (%v)", rec.Body, err)
+	}
+
+	rec = apiDo(t, srv, token, "PUT", "/api/v1/identities/"+created.ID, `{"name":"Hugo A","email":"hugo@example.com"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = apiDo(t, srv, token, "GET", "/api/v1/identities", "")
+	var list []storage.GitIdentity
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 1 || list[0].Name != "Hugo A" || !list[0].CreatedAt.Equal(created.CreatedAt) {
+		t.Fatalf("list: %+v", list)
+	}
+
+	if rec := apiDo(t, srv, token, "DELETE", "/api/v1/identities/"+created.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d", rec.Code)
+	}
+	if rec := apiDo(t, srv, token, "GET", "/api/v1/identities/"+created.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("get após delete: status %d", rec.Code)
+	}
+}
+
+func TestAPIOpenAPIDocument(t *testing.T) {
+	srv, token := newAPITestServer(t)
+	rec := apiDo(t, srv, token, "GET", "/api/v1/openapi.json", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d", rec.Code)
+	}
+	var doc struct {
+		OpenAPI    string                    `json:"openapi"`
+		Paths      map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("openapi.json inválido: %v", err)
+	}
+	if doc.OpenAPI == "" || doc.Paths["/api/v1/keys"]["post"] == nil || doc.Paths["/api/v1/keys/{id}"]["put"] != nil {
+		t.Fatalf("paths inesperados: %v", doc.Paths["/api/v1/keys"])
+	}
+	for _, name := range []string{"Key", "Account", "EnvFile", "MCPServer", "Job", "Error"} {
+		if doc.Components.Schemas[name] == nil {
+			t.Errorf("schema %s ausente", name)
+		}
+	}
+}
+
+// TestAPIMaskedSecretRoundTrip lê um .env com o vault bloqueado e devolve o
+// mesmo corpo num PUT: a máscara não substitui o segredo gravado.
+func TestAPIMaskedSecretRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	srv, token := New(a).Routes(), a.Auth.Token()
+	if err := a.Vault.Init("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := apiDo(t, srv, token, "POST", "/api/v1/env-files", `{"name":"api","variables":{"TOKEN":"abc"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d, body %s", rec.Code, rec.Body)
+	}
+	var created storage.EnvFile
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Vault.Lock()
+	rec = apiDo(t, srv, token, "GET", "/api/v1/env-files/"+created.ID, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), secretMask) {
+		t.Fatalf("get bloqueado: status %d, body %s", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	if rec := apiDo(t, srv, token, "PUT", "/api/v1/env-files/"+created.ID, body); rec.Code != http.StatusOK {
+		t.Fatalf("put bloqueado: status %d, body %s", rec.Code, rec.Body)
+	}
+	if err := a.Vault.Unlock("correct horse"); err != nil {
+		t.Fatal(err)
+	}
+	if rec := apiDo(t, srv, token, "PUT", "/api/v1/env-files/"+created.ID, body); rec.Code != http.StatusOK {
+		t.Fatalf("put desbloqueado: status %d, body %s", rec.Code, rec.Body)
+	}
+
+	rec = apiDo(t, srv, token, "GET", "/api/v1/env-files/"+created.ID, "")
+	var got storage.EnvFile
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Variables["TOKEN"] != "abc" {
+		t.Fatalf("TOKEN = %q após GET+PUT", got.Variables["TOKEN"])
+	}
+
+	rec = apiDo(t, srv, token, "POST", "/api/v1/env-files", `{"name":"novo","variables":{"TOKEN":"`+secretMask+`"}}`)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("máscara na criação: status %d, body %s", rec.Code, rec.Body)
+	}
+}
+
+// TestAPIDeleteKeyRemovesCertificates remove a chave pela API: os
+// certificados emitidos para ela saem junto, os das outras ficam.
+func TestAPIDeleteKeyRemovesCertificates(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	srv, token := New(a).Routes(), a.Auth.Token()
+	err = a.Storage().Update(func(st *storage.State) error {
+		st.Keys = []storage.Key{{ID: "k1", Alias: "ci"}, {ID: "k2", Alias: "dev"}}
+		st.Certificates = []storage.Certificate{{ID: "c1", KeyID: "k1"}, {ID: "c2", KeyID: "k1"}, {ID: "c3", KeyID: "k2"}}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := apiDo(t, srv, token, "DELETE", "/api/v1/keys/k1", ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete: status %d, body %s", rec.Code, rec.Body)
+	}
+	if rec := apiDo(t, srv, token, "DELETE", "/api/v1/keys/k1", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("delete repetido: status %d", rec.Code)
+	}
+	state, err := a.Storage().LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Keys) != 1 || len(state.Certificates) != 1 || state.Certificates[0].ID != "c3" {
+		t.Fatalf("keys=%+v certificates=%+v", state.Keys, state.Certificates)
+	}
+}
@@ -13,12 +13,13 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/seuusuario/factorydev/internal/app"
 	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
 )
 
 // GET /tools/api
 func (h *Handler) APIDashboard(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -78,6 +79,10 @@ func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
 	c := parseCollectionForm(r)
 	c.ID = newID()
 	c.CreatedAt = time.Now()
+	if err := h.sealCollection(&c); err != nil {
+		h.vaultError(w, err)
+		return
+	}
 
 	if strings.TrimSpace(c.Name) == "" {
 		h.errorToast(w, "Nome é obrigatório")
@@ -85,14 +90,12 @@ func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
+	err := h.store(r).Update(func(state *storage.State) error {
+		state.APICollections = append(state.APICollections, c)
+		return nil
+	})
 	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	state.APICollections = append(state.APICollections, c)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "Collection criada!")
@@ -102,7 +105,7 @@ func (h *Handler) CreateCollection(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditCollectionDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -118,12 +121,17 @@ func (h *Handler) EditCollectionDrawer(w http.ResponseWriter, r *http.Request) {
 		h.operationError(w, "Collection não encontrada", http.StatusNotFound)
 		return
 	}
-	envJSON := envVarsToJSON(found.EnvVars)
-	authJSON, _ := json.Marshal(found.AuthData)
+	col, err := h.revealCollection(*found)
+	if err != nil {
+		h.vaultError(w, err)
+		return
+	}
+	envJSON := envVarsToJSON(col.EnvVars)
+	authJSON, _ := json.Marshal(col.AuthData)
 	h.renderDrawer(w, "Editar Collection", "apiclient/collection-drawer.html", map[string]any{
 		"SubmitURL":  "/tools/api/collections/" + id,
 		"IsEdit":     true,
-		"Collection": found,
+		"Collection": col,
 		"EnvJSON":    envJSON,
 		"AuthJSON":   string(authJSON),
 	})
@@ -138,22 +146,24 @@ func (h *Handler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	c := parseCollectionForm(r)
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	for i := range state.APICollections {
-		if state.APICollections[i].ID == id {
-			state.APICollections[i].Name = c.Name
-			state.APICollections[i].AuthType = c.AuthType
-			state.APICollections[i].AuthData = c.AuthData
-			state.APICollections[i].EnvVars = c.EnvVars
-			break
+	if err := h.sealCollection(&c); err != nil {
+		h.vaultError(w, err)
+		return
+	}
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.APICollections {
+			if state.APICollections[i].ID == id {
+				state.APICollections[i].Name = c.Name
+				state.APICollections[i].AuthType = c.AuthType
+				state.APICollections[i].AuthData = c.AuthData
+				state.APICollections[i].EnvVars = c.EnvVars
+				return nil
+			}
 		}
-	}
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Collection não encontrada")
 		return
 	}
 	h.successToast(w, "Collection atualizada!")
@@ -163,33 +173,30 @@ func (h *Handler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := -1
-	for i, c := range state.APICollections {
-		if c.ID == id {
-			idx = i
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		idx := -1
+		for i, c := range state.APICollections {
+			if c.ID == id {
+				idx = i
+				break
+			}
 		}
-	}
-	if idx < 0 {
-		h.operationError(w, "Collection não encontrada", http.StatusNotFound)
-		return
-	}
-	state.APICollections = append(state.APICollections[:idx], state.APICollections[idx+1:]...)
-	// Remover endpoints da collection
-	var kept []storage.APIEndpoint
-	for _, e := range state.APIEndpoints {
-		if e.CollectionID != id {
-			kept = append(kept, e)
+		if idx < 0 {
+			return errNotFound
 		}
-	}
-	state.APIEndpoints = kept
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		state.APICollections = append(state.APICollections[:idx], state.APICollections[idx+1:]...)
+		// Remover endpoints da collection
+		var kept []storage.APIEndpoint
+		for _, e := range state.APIEndpoints {
+			if e.CollectionID != id {
+				kept = append(kept, e)
+			}
+		}
+		state.APIEndpoints = kept
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Collection não encontrada")
 		return
 	}
 	h.successToast(w, "Collection removida!")
@@ -199,7 +206,7 @@ func (h *Handler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) NewEndpointDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	collectionID := r.URL.Query().Get("collectionId")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -231,14 +238,12 @@ func (h *Handler) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
+	err := h.store(r).Update(func(state *storage.State) error {
+		state.APIEndpoints = append(state.APIEndpoints, ep)
+		return nil
+	})
 	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	state.APIEndpoints = append(state.APIEndpoints, ep)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "Endpoint criado!")
@@ -248,7 +253,7 @@ func (h *Handler) CreateEndpoint(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditEndpointDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -283,25 +288,23 @@ func (h *Handler) UpdateEndpoint(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	ep := parseEndpointForm(r)
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	for i := range state.APIEndpoints {
-		if state.APIEndpoints[i].ID == id {
-			state.APIEndpoints[i].Name = ep.Name
-			state.APIEndpoints[i].CollectionID = ep.CollectionID
-			state.APIEndpoints[i].Method = ep.Method
-			state.APIEndpoints[i].URL = ep.URL
-			state.APIEndpoints[i].Headers = ep.Headers
-			state.APIEndpoints[i].Body = ep.Body
-			state.APIEndpoints[i].UpdatedAt = time.Now()
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.APIEndpoints {
+			if state.APIEndpoints[i].ID == id {
+				state.APIEndpoints[i].Name = ep.Name
+				state.APIEndpoints[i].CollectionID = ep.CollectionID
+				state.APIEndpoints[i].Method = ep.Method
+				state.APIEndpoints[i].URL = ep.URL
+				state.APIEndpoints[i].Headers = ep.Headers
+				state.APIEndpoints[i].Body = ep.Body
+				state.APIEndpoints[i].UpdatedAt = time.Now()
+				return nil
+			}
 		}
-	}
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Endpoint não encontrado")
 		return
 	}
 	h.successToast(w, "Endpoint atualizado!")
@@ -311,25 +314,17 @@ func (h *Handler) UpdateEndpoint(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := -1
-	for i, e := range state.APIEndpoints {
-		if e.ID == id {
-			idx = i
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i, e := range state.APIEndpoints {
+			if e.ID == id {
+				state.APIEndpoints = append(state.APIEndpoints[:i], state.APIEndpoints[i+1:]...)
+				return nil
+			}
 		}
-	}
-	if idx < 0 {
-		h.operationError(w, "Endpoint não encontrado", http.StatusNotFound)
-		return
-	}
-	state.APIEndpoints = append(state.APIEndpoints[:idx], state.APIEndpoints[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Endpoint não encontrado")
 		return
 	}
 	h.successToast(w, "Endpoint removido!")
@@ -339,7 +334,7 @@ func (h *Handler) DeleteEndpoint(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) SendRequest(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -366,7 +361,7 @@ func (h *Handler) SendRequest(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	result := executeHTTPRequest(*ep, col)
+	result := executeHTTPRequest(*ep, col, h.app.Vault, h.app.Config().APITimeout())
 
 	// Salvar no history
 	hist := storage.APIRequestHistory{
@@ -378,11 +373,13 @@ func (h *Handler) SendRequest(w http.ResponseWriter, r *http.Request) {
 		ResponseTime: result.DurationMs,
 		RequestedAt:  time.Now(),
 	}
-	state.APIHistory = append(state.APIHistory, hist)
-	if len(state.APIHistory) > 100 {
-		state.APIHistory = state.APIHistory[len(state.APIHistory)-100:]
-	}
-	_ = h.app.Storage.SaveState(state)
+	_ = h.store(r).Update(func(state *storage.State) error {
+		state.APIHistory = append(state.APIHistory, hist)
+		if len(state.APIHistory) > 100 {
+			state.APIHistory = state.APIHistory[len(state.APIHistory)-100:]
+		}
+		return nil
+	})
 
 	h.render(w, "apiclient/response.html", map[string]any{
 		"Result": result,
@@ -411,7 +408,7 @@ func (h *Handler) SendAdHocRequest(w http.ResponseWriter, r *http.Request) {
 		ep.Method = "GET"
 	}
 
-	result := executeHTTPRequest(ep, nil)
+	result := executeHTTPRequest(ep, nil, h.app.Vault, h.app.Config().APITimeout())
 	h.render(w, "apiclient/response.html", map[string]any{
 		"Result": result,
 	})
@@ -420,7 +417,7 @@ func (h *Handler) SendAdHocRequest(w http.ResponseWriter, r *http.Request) {
 // GET /tools/api/history
 func (h *Handler) APIRequestHistory(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -444,9 +441,20 @@ type httpResult struct {
 	IsJSON      bool
 }
 
-func executeHTTPRequest(ep storage.APIEndpoint, col *storage.APICollection) httpResult {
+func executeHTTPRequest(ep storage.APIEndpoint, col *storage.APICollection, secrets *vault.Vault, timeout time.Duration) httpResult {
 	result := httpResult{Method: ep.Method}
 
+	// Credenciais da collection ficam seladas no state
+	var auth map[string]string
+	if col != nil {
+		var err error
+		auth, err = secrets.RevealMap(col.AuthData)
+		if err != nil {
+			result.Error = fmt.Sprintf("Erro ao ler credenciais: %v", err)
+			return result
+		}
+	}
+
 	// Substituir variáveis {{VAR}} com envVars da collection
 	url := ep.URL
 	body := ep.Body
@@ -484,19 +492,19 @@ func executeHTTPRequest(ep storage.APIEndpoint, col *storage.APICollection) http
 	if col != nil {
 		switch col.AuthType {
 		case "bearer":
-			if token := col.AuthData["token"]; token != "" {
+			if token := auth["token"]; token != "" {
 				req.Header.Set("Authorization", "Bearer "+token)
 			}
 		case "basic":
-			if user := col.AuthData["username"]; user != "" {
-				req.SetBasicAuth(user, col.AuthData["password"])
+			if user := auth["username"]; user != "" {
+				req.SetBasicAuth(user, auth["password"])
 			}
 		case "apikey":
-			headerName := col.AuthData["headerName"]
+			headerName := auth["headerName"]
 			if headerName == "" {
 				headerName = "X-API-Key"
 			}
-			if key := col.AuthData["headerValue"]; key != "" {
+			if key := auth["headerValue"]; key != "" {
 				req.Header.Set(headerName, key)
 			}
 		}
@@ -506,7 +514,7 @@ func executeHTTPRequest(ep storage.APIEndpoint, col *storage.APICollection) http
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: timeout}
 	start := time.Now()
 	resp, err := client.Do(req)
 	result.DurationMs = time.Since(start).Milliseconds()
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/auth"
+	"github.com/seuusuario/factorydev/web"
+)
+
+// authPolicy descreve o bind atual para o middleware de autenticação.
+func (h *Handler) authPolicy() auth.Policy {
+	return auth.Policy{
+		BindHost:     h.app.Config().Host,
+		AllowedHosts: h.app.Config().AllowedHosts,
+		Public:       []string{auth.LoginPath, "/health", "/assets/"},
+	}
+}
+
+// GET /login
+func (h *Handler) LoginPage(w http.ResponseWriter, r *http.Request) {
+	h.renderLogin(w, r, "", http.StatusOK)
+}
+
+// POST /login
+func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.renderLogin(w, r, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	mgr := h.app.Auth
+	password := r.FormValue("password")
+	token := strings.TrimSpace(r.FormValue("token"))
+
+	ok := mgr.CheckPassword(password)
+	if !ok && !mgr.RequirePassword(h.authPolicy()) {
+		ok = mgr.CheckToken(token)
+	}
+	if !ok {
+		h.app.Logger.Warn("login recusado", "remote", r.RemoteAddr)
+		h.renderLogin(w, r, "Credenciais inválidas", http.StatusUnauthorized)
+		return
+	}
+	mgr.StartSession(w, r)
+	http.Redirect(w, r, auth.SafeNext(r.FormValue("next")), http.StatusSeeOther)
+}
+
+// POST /logout
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	auth.EndSession(w)
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Redirect", auth.LoginPath)
+		return
+	}
+	http.Redirect(w, r, auth.LoginPath, http.StatusSeeOther)
+}
+
+func (h *Handler) renderLogin(w http.ResponseWriter, r *http.Request, msg string, status int) {
+	t, err := template.New("root").ParseFS(web.FS, "templates/login.html")
+	if err != nil {
+		h.app.Logger.Error("erro parse template", "err", err)
+		http.Error(w, "Erro ao renderizar página", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = t.ExecuteTemplate(w, "login.html", map[string]any{
+		"Error":           msg,
+		"Next":            auth.SafeNext(r.FormValue("next")),
+		"RequirePassword": h.app.Auth.RequirePassword(h.authPolicy()),
+		"HasPassword":     h.app.Auth.HasPassword(),
+	})
+}
@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// certExpiringSoon é a antecedência com que um certificado aparece como
+// "expirando" no Key Manager.
+const certExpiringSoon = 7 * 24 * time.Hour
+
+// certView é um certificado com os nomes das chaves e o estado de validade.
+type certView struct {
+	storage.Certificate
+	KeyName string
+	CAName  string
+	Status  string // "valid" | "expiring" | "expired" | "pending"
+}
+
+type signFormData struct {
+	CA         storage.Key
+	Keys       []storage.Key
+	KeyID      string
+	Type       string
+	Identity   string
+	Principals string
+	Validity   string
+	Options    string
+	Extensions []string
+	AllExts    []string
+	Errors     map[string]string
+}
+
+// POST /tools/keys/{id}/ca
+// Marca ou desmarca a chave como autoridade certificadora.
+func (h *Handler) ToggleKeyCA(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	id := chi.URLParam(r, "id")
+	var k storage.Key
+	err := h.store(r).Update(func(state *storage.State) error {
+		idx := findKeyIndex(state.Keys, id)
+		if idx < 0 {
+			return errNotFound
+		}
+		state.Keys[idx].CA = !state.Keys[idx].CA
+		k = state.Keys[idx]
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Chave não encontrada")
+		return
+	}
+	if k.CA {
+		h.successToast(w, k.Name+" agora é uma CA")
+		return
+	}
+	h.successToast(w, k.Name+" deixou de ser CA")
+}
+
+// GET /tools/keys/{id}/sign
+func (h *Handler) SignKeyDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	ca := state.FindKey(chi.URLParam(r, "id"))
+	if ca == nil || !ca.CA {
+		h.operationError(w, "CA não encontrada", http.StatusNotFound)
+		return
+	}
+	h.renderDrawer(w, "Assinar chave — "+ca.Name, "keys/sign-drawer.html", signFormData{
+		CA:         *ca,
+		Keys:       signableKeys(state, ca.ID),
+		Type:       ssh.CertTypeUser,
+		Validity:   "52w",
+		Extensions: ssh.DefaultUserExtensions,
+		AllExts:    ssh.DefaultUserExtensions,
+		Errors:     map[string]string{},
+	})
+}
+
+// POST /tools/keys/{id}/sign
+// Emite um certificado para outra chave gerenciada e registra no state.
+func (h *Handler) SignKey(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	ca := state.FindKey(chi.URLParam(r, "id"))
+	if ca == nil || !ca.CA {
+		h.operationError(w, "CA não encontrada", http.StatusNotFound)
+		return
+	}
+
+	form := signFormData{
+		CA:         *ca,
+		Keys:       signableKeys(state, ca.ID),
+		KeyID:      r.FormValue("keyId"),
+		Type:       r.FormValue("type"),
+		Identity:   strings.TrimSpace(r.FormValue("identity")),
+		Principals: strings.TrimSpace(r.FormValue("principals")),
+		Validity:   strings.TrimSpace(r.FormValue("validity")),
+		Options:    r.FormValue("options"),
+		Extensions: r.Form["extensions"],
+		AllExts:    ssh.DefaultUserExtensions,
+		Errors:     map[string]string{},
+	}
+	target := state.FindKey(form.KeyID)
+	if target == nil || target.ID == ca.ID {
+		form.Errors["keyId"] = "selecione a chave a assinar"
+	}
+	if form.Type != ssh.CertTypeUser && form.Type != ssh.CertTypeHost {
+		form.Errors["type"] = "tipo inválido"
+	}
+	principals := splitPrincipals(form.Principals)
+	if len(principals) == 0 {
+		form.Errors["principals"] = "informe ao menos um principal (usuário ou hostname)"
+	}
+	validity, err := ssh.ParseValidity(form.Validity)
+	if err != nil {
+		form.Errors["validity"] = err.Error()
+	}
+	options, err := parseCriticalOptions(form.Options)
+	if err != nil {
+		form.Errors["options"] = err.Error()
+	}
+
+	now := time.Now()
+	cert := storage.Certificate{
+		ID:              newID(),
+		CAKeyID:         ca.ID,
+		Type:            form.Type,
+		Identity:        form.Identity,
+		Principals:      principals,
+		ValidAfter:      now.Add(-5 * time.Minute).Truncate(time.Second), // tolera relógios adiantados
+		CriticalOptions: options,
+		CreatedAt:       now,
+	}
+	if validity > 0 {
+		cert.ValidBefore = now.Add(validity).Truncate(time.Second)
+	}
+	if form.Type == ssh.CertTypeUser {
+		cert.Extensions = make(map[string]string)
+		for _, e := range form.Extensions {
+			if slices.Contains(ssh.DefaultUserExtensions, e) {
+				cert.Extensions[e] = ""
+			}
+		}
+	}
+
+	if len(form.Errors) == 0 {
+		cert.KeyID = target.ID
+		if cert.Identity == "" {
+			cert.Identity = target.Name
+		}
+		cert.Serial = state.NextCertificateSerial()
+		cert.Path, err = ssh.SignCertificate(ssh.SignRequest{
+			CAKeyPath:       ca.PrivateKeyPath,
+			CAPassphrase:    []byte(r.FormValue("passphrase")),
+			PublicKeyPath:   target.PublicKeyPath,
+			Type:            cert.Type,
+			Identity:        cert.Identity,
+			Serial:          cert.Serial,
+			Principals:      cert.Principals,
+			ValidAfter:      cert.ValidAfter,
+			ValidBefore:     cert.ValidBefore,
+			CriticalOptions: cert.CriticalOptions,
+			Extensions:      cert.Extensions,
+		})
+		switch {
+		case errors.Is(err, ssh.ErrPassphraseRequired):
+			form.Errors["passphrase"] = "passphrase da CA obrigatória"
+		case err != nil:
+			form.Errors["passphrase"] = app.FriendlyMessage(err)
+		}
+	}
+	if len(form.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderDrawer(w, "Assinar chave — "+ca.Name, "keys/sign-drawer.html", form)
+		return
+	}
+	if h.app.Commands.DryRun() {
+		h.successToast(w, "Dry-run: o certificado para "+target.Name+" não foi gravado")
+		return
+	}
+
+	if err := h.store(r).Update(func(state *storage.State) error {
+		state.Certificates = append(state.Certificates, cert)
+		return nil
+	}); err != nil {
+		h.updateFailed(w, err, "")
+		return
+	}
+	h.app.Logger.Info("certificado emitido", "ca", ca.Alias, "key", target.Alias, "serial", cert.Serial, "path", cert.Path)
+	msg := fmt.Sprintf("Certificado #%d emitido para %s", cert.Serial, target.Name)
+	if cert.Type == ssh.CertTypeUser {
+		msg += ". Reaplique o SSH config das contas para usar o CertificateFile"
+	}
+	h.successToast(w, msg)
+}
+
+// DELETE /tools/keys/certs/{id}
+// Remove o registro do certificado; o arquivo -cert.pub é mantido.
+func (h *Handler) DeleteCertificate(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	id := chi.URLParam(r, "id")
+	err := h.store(r).Update(func(state *storage.State) error {
+		idx := slices.IndexFunc(state.Certificates, func(c storage.Certificate) bool { return c.ID == id })
+		if idx < 0 {
+			return errNotFound
+		}
+		state.Certificates = slices.Delete(state.Certificates, idx, idx+1)
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Certificado não encontrado")
+		return
+	}
+	h.successToast(w, "Certificado removido do histórico")
+}
+
+// ── Helpers ──────────────────────────────────────────────────────
+
+// certViews monta a lista de certificados com os nomes das chaves,
+// do mais recente para o mais antigo.
+func certViews(state *storage.State, now time.Time) []certView {
+	names := make(map[string]string, len(state.Keys))
+	for _, k := range state.Keys {
+		names[k.ID] = k.Name
+	}
+	out := make([]certView, 0, len(state.Certificates))
+	for _, c := range state.Certificates {
+		out = append(out, certView{Certificate: c, KeyName: names[c.KeyID], CAName: names[c.CAKeyID], Status: certStatus(c, now)})
+	}
+	slices.Reverse(out)
+	return out
+}
+
+func certStatus(c storage.Certificate, now time.Time) string {
+	switch {
+	case now.Before(c.ValidAfter):
+		return "pending"
+	case !c.ValidAt(now):
+		return "expired"
+	case !c.ValidBefore.IsZero() && c.ValidBefore.Sub(now) < certExpiringSoon:
+		return "expiring"
+	}
+	return "valid"
+}
+
+// signableKeys são as chaves que a CA pode assinar: todas menos ela.
+func signableKeys(state *storage.State, caID string) []storage.Key {
+	var out []storage.Key
+	for _, k := range state.Keys {
+		if k.ID != caID {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// splitPrincipals aceita principals separados por vírgula ou espaço.
+func splitPrincipals(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\t' })
+}
+
+// parseCriticalOptions lê uma opção por linha no formato nome=valor
+// (ex: force-command=/usr/bin/backup, source-address=10.0.0.0/8).
+func parseCriticalOptions(s string) (map[string]string, error) {
+	out := make(map[string]string)
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("opção inválida %q: use nome=valor", line)
+		}
+		out[name] = strings.TrimSpace(value)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// GET /tools/commands
+func (h *Handler) CommandsPage(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	queries := r.URL.Query().Get("queries") == "1"
+	payload := map[string]any{
+		"DryRun":  h.app.Commands.DryRun(),
+		"Queries": queries,
+		"Records": h.app.Commands.History().List(queries),
+		// Privilege mostra como os comandos de administrador são elevados.
+		"Privilege": h.app.Privilege.Status(r.Context()),
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "commands/list.html", payload)
+		return
+	}
+	h.render(w, "commands/list.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "commands",
+		ContentTpl: "commands/list.html",
+		Data:       payload,
+	})
+}
+
+// POST /tools/commands/dry-run — liga/desliga o dry-run até o próximo
+// restart (o valor inicial vem de --dry-run / config.json).
+func (h *Handler) ToggleDryRun(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	on := r.FormValue("enabled") == "on"
+	h.app.Commands.SetDryRun(on)
+	h.app.Logger.Info("dry-run alterado", "enabled", on)
+
+	msg := "Dry-run desligado: os comandos voltam a ser executados"
+	if on {
+		msg = "Dry-run ligado: comandos e gravações de configuração só serão simulados"
+	}
+	w.Header().Set("HX-Trigger", fmt.Sprintf(`{"showToast":{"msg":%q,"type":"success"},"refreshList":true,"dryRunChanged":true}`, msg))
+}
+
+// POST /tools/commands/clear
+func (h *Handler) ClearCommandHistory(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.app.Commands.History().Clear()
+	h.successToast(w, "Histórico de comandos limpo")
+}
+
+// GET /tools/commands/badge — aviso de dry-run na barra lateral.
+func (h *Handler) DryRunBadge(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.render(w, "partials/dry-run-badge.html", map[string]any{"DryRun": h.app.Commands.DryRun()})
+}
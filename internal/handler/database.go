@@ -2,6 +2,7 @@ package handler
 
 import (
 	"database/sql"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/seuusuario/factorydev/internal/dbclient"
 	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
 )
 
 // Pool de conexões ativas — gerenciado pelo handler.
@@ -19,7 +21,7 @@ var (
 	dbConnsMu sync.Mutex
 )
 
-func getDBConn(conn storage.DBConnection) (*sql.DB, error) {
+func getDBConn(conn storage.DBConnection, secrets dbclient.SecretResolver) (*sql.DB, error) {
 	dbConnsMu.Lock()
 	defer dbConnsMu.Unlock()
 
@@ -30,7 +32,7 @@ func getDBConn(conn storage.DBConnection) (*sql.DB, error) {
 		db.Close()
 		delete(dbConns, conn.ID)
 	}
-	db, err := dbclient.Open(conn)
+	db, err := dbclient.Open(conn, secrets)
 	if err != nil {
 		return nil, err
 	}
@@ -47,11 +49,22 @@ func closeDBConn(id string) {
 	}
 }
 
+// closeAllDBConns fecha todo o pool — usado ao bloquear o vault, já que as
+// conexões abertas carregam senhas decifradas.
+func closeAllDBConns() {
+	dbConnsMu.Lock()
+	defer dbConnsMu.Unlock()
+	for id, db := range dbConns {
+		db.Close()
+		delete(dbConns, id)
+	}
+}
+
 // ── Dashboard ────────────────────────────────────────────────────
 
 func (h *Handler) DBDashboard(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	st, err := h.app.Storage.LoadState()
+	st, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
 		return
@@ -71,7 +84,7 @@ func (h *Handler) DBDashboard(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) NewDBConnectionDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	h.renderDrawer(w, "Nova Conexão", "database/connection-drawer.html", map[string]any{
 		"Conn": storage.DBConnection{Port: 5432, Driver: "postgres"},
 		"Keys": st.Keys,
@@ -83,15 +96,17 @@ func (h *Handler) CreateDBConnection(w http.ResponseWriter, r *http.Request) {
 	conn := parseDBConnForm(r)
 	conn.ID = newID()
 	conn.CreatedAt = time.Now()
-
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
+	if err := h.sealDBConnection(&conn); err != nil {
+		h.vaultError(w, err)
 		return
 	}
-	st.DBConnections = append(st.DBConnections, conn)
-	if err := h.app.Storage.SaveState(st); err != nil {
-		h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
+
+	err := h.store(r).Update(func(st *storage.State) error {
+		st.DBConnections = append(st.DBConnections, conn)
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "Conexão criada com sucesso")
@@ -100,9 +115,13 @@ func (h *Handler) CreateDBConnection(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditDBConnectionDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	for _, c := range st.DBConnections {
 		if c.ID == id {
+			if err := h.revealDBConnection(&c); err != nil {
+				h.vaultError(w, err)
+				return
+			}
 			h.renderDrawer(w, "Editar Conexão", "database/connection-drawer.html", map[string]any{
 				"Conn": c,
 				"Keys": st.Keys,
@@ -116,59 +135,61 @@ func (h *Handler) EditDBConnectionDrawer(w http.ResponseWriter, r *http.Request)
 func (h *Handler) UpdateDBConnection(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
+	updated := parseDBConnForm(r)
+	if err := h.sealDBConnection(&updated); err != nil {
+		h.vaultError(w, err)
 		return
 	}
-	for i, c := range st.DBConnections {
-		if c.ID == id {
-			updated := parseDBConnForm(r)
-			updated.ID = c.ID
-			updated.CreatedAt = c.CreatedAt
-			st.DBConnections[i] = updated
-			closeDBConn(id) // fecha conexão antiga do pool
-			if err := h.app.Storage.SaveState(st); err != nil {
-				h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
-				return
+	err := h.store(r).Update(func(st *storage.State) error {
+		for i, c := range st.DBConnections {
+			if c.ID == id {
+				updated.ID = c.ID
+				updated.CreatedAt = c.CreatedAt
+				st.DBConnections[i] = updated
+				return nil
 			}
-			h.successToast(w, "Conexão atualizada")
-			return
 		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Conexão não encontrada")
+		return
 	}
-	h.operationError(w, "Conexão não encontrada", http.StatusNotFound)
+	closeDBConn(id) // fecha conexão antiga do pool
+	h.successToast(w, "Conexão atualizada")
 }
 
 func (h *Handler) DeleteDBConnection(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
-		return
-	}
 	closeDBConn(id)
-	for i, c := range st.DBConnections {
-		if c.ID == id {
-			st.DBConnections = append(st.DBConnections[:i], st.DBConnections[i+1:]...)
-			if err := h.app.Storage.SaveState(st); err != nil {
-				h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
-				return
+	err := h.store(r).Update(func(st *storage.State) error {
+		for i, c := range st.DBConnections {
+			if c.ID == id {
+				st.DBConnections = append(st.DBConnections[:i], st.DBConnections[i+1:]...)
+				return nil
 			}
-			h.successToast(w, "Conexão removida")
-			return
 		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Conexão não encontrada")
+		return
 	}
-	h.operationError(w, "Conexão não encontrada", http.StatusNotFound)
+	h.successToast(w, "Conexão removida")
 }
 
 func (h *Handler) TestDBConnection(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	for _, c := range st.DBConnections {
 		if c.ID == id {
-			db, err := dbclient.Open(c)
+			db, err := dbclient.Open(c, h.app.Vault)
+			if errors.Is(err, vault.ErrLocked) {
+				h.vaultError(w, err)
+				return
+			}
 			if err != nil {
 				h.errorToast(w, "Falha: "+err.Error())
 				w.WriteHeader(http.StatusUnprocessableEntity)
@@ -187,7 +208,7 @@ func (h *Handler) TestDBConnection(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DBListTables(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	var conn storage.DBConnection
 	found := false
 	for _, c := range st.DBConnections {
@@ -202,7 +223,11 @@ func (h *Handler) DBListTables(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, err := getDBConn(conn)
+	db, err := getDBConn(conn, h.app.Vault)
+	if errors.Is(err, vault.ErrLocked) {
+		h.vaultError(w, err)
+		return
+	}
 	if err != nil {
 		h.operationError(w, "Erro ao conectar: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -226,7 +251,7 @@ func (h *Handler) DBDescribeTable(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
 	table := chi.URLParam(r, "table")
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	var conn storage.DBConnection
 	found := false
 	for _, c := range st.DBConnections {
@@ -241,7 +266,11 @@ func (h *Handler) DBDescribeTable(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, err := getDBConn(conn)
+	db, err := getDBConn(conn, h.app.Vault)
+	if errors.Is(err, vault.ErrLocked) {
+		h.vaultError(w, err)
+		return
+	}
 	if err != nil {
 		h.operationError(w, "Erro ao conectar: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -272,7 +301,7 @@ func (h *Handler) DBRunQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	var conn storage.DBConnection
 	found := false
 	for _, c := range st.DBConnections {
@@ -287,13 +316,17 @@ func (h *Handler) DBRunQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, err := getDBConn(conn)
+	db, err := getDBConn(conn, h.app.Vault)
+	if errors.Is(err, vault.ErrLocked) {
+		h.vaultError(w, err)
+		return
+	}
 	if err != nil {
 		h.operationError(w, "Erro ao conectar: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	result := dbclient.RunQuery(db, query)
+	result := dbclient.RunQuery(db, query, h.app.Config().DBRowLimit)
 	h.render(w, "database/results.html", map[string]any{
 		"Conn":   conn,
 		"Query":  query,
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"os/exec"
@@ -11,41 +12,9 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	idocker "github.com/seuusuario/factorydev/internal/docker"
+	"github.com/seuusuario/factorydev/internal/jobs"
 )
 
-// ContainerTemplate define um template pré-configurado de container.
-type ContainerTemplate struct {
-	ID          string
-	Name        string
-	Image       string
-	Description string
-	DefaultEnv  []string
-	DefaultPort string
-}
-
-var containerTemplates = []ContainerTemplate{
-	{ID: "postgres16", Name: "PostgreSQL 16", Image: "postgres:16-alpine",
-		Description: "Banco de dados relacional PostgreSQL",
-		DefaultEnv:  []string{"POSTGRES_PASSWORD=postgres", "POSTGRES_DB=app"},
-		DefaultPort: "5432:5432"},
-	{ID: "mysql8", Name: "MySQL 8", Image: "mysql:8",
-		Description: "Banco de dados relacional MySQL",
-		DefaultEnv:  []string{"MYSQL_ROOT_PASSWORD=mysql", "MYSQL_DATABASE=app"},
-		DefaultPort: "3306:3306"},
-	{ID: "redis7", Name: "Redis 7", Image: "redis:7-alpine",
-		Description: "Cache e message broker Redis",
-		DefaultEnv:  nil,
-		DefaultPort: "6379:6379"},
-	{ID: "mongo7", Name: "MongoDB 7", Image: "mongo:7",
-		Description: "Banco de dados orientado a documentos MongoDB",
-		DefaultEnv:  []string{"MONGO_INITDB_ROOT_USERNAME=root", "MONGO_INITDB_ROOT_PASSWORD=mongo"},
-		DefaultPort: "27017:27017"},
-	{ID: "adminer", Name: "Adminer", Image: "adminer:latest",
-		Description: "Interface web para gerenciar bancos de dados",
-		DefaultEnv:  nil,
-		DefaultPort: "8080:8080"},
-}
-
 func newDockerClient() (*idocker.Client, error) {
 	return idocker.New()
 }
@@ -76,22 +45,26 @@ func (h *Handler) DockerDashboard(w http.ResponseWriter, r *http.Request) {
 // GET /tools/docker/status — partial com status do daemon para o header
 func (h *Handler) DockerStatusPartial(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	h.render(w, "docker/status-header.html", dockerStatusData(ctx))
+}
+
+// dockerStatusData monta os dados de docker/status-header.html; também usado
+// pelo feed SSE do status.
+func dockerStatusData(ctx context.Context) map[string]any {
 	cli, err := newDockerClient()
 	if err != nil || !cli.Available() {
-		h.render(w, "docker/status-header.html", map[string]any{"Available": false})
-		return
+		return map[string]any{"Available": false}
 	}
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
 	info, err := cli.GetDaemonInfo(ctx)
 	if err != nil {
-		h.render(w, "docker/status-header.html", map[string]any{"Available": false})
-		return
+		return map[string]any{"Available": false}
 	}
-	h.render(w, "docker/status-header.html", map[string]any{
+	return map[string]any{
 		"Available": true,
 		"Info":      info,
-	})
+	}
 }
 
 // POST /tools/docker/start — inicia o Docker Desktop (macOS/Windows) ou mostra instrução Linux
@@ -182,7 +155,7 @@ func (h *Handler) ContainerLogs(w http.ResponseWriter, r *http.Request) {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	logs, err := cli.GetLogs(ctx, id, 200)
+	logs, err := cli.GetLogs(ctx, id, h.app.Config().ContainerLogTail)
 	if err != nil {
 		h.operationError(w, "Erro ao buscar logs: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -223,7 +196,7 @@ func (h *Handler) ContainerDetail(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if tab == "logs" {
-		logs, _ := cli.GetLogs(ctx, id, 200)
+		logs, _ := cli.GetLogs(ctx, id, h.app.Config().ContainerLogTail)
 		payload["Logs"] = logs
 	}
 
@@ -279,33 +252,17 @@ func (h *Handler) StartPullImage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job := &DockerJob{ID: newID()}
-	h.dockerMu.Lock()
-	h.dockerJobs[job.ID] = job
-	h.dockerMu.Unlock()
-
-	go func() {
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "docker-pull",
+		Title:   "docker pull " + ref,
+		Timeout: 10 * time.Minute,
+	}, func(run *jobs.Run) error {
 		cli, err := newDockerClient()
 		if err != nil {
-			h.dockerMu.Lock()
-			job.Done, job.OK = true, false
-			job.Error = "Docker não disponível"
-			h.dockerMu.Unlock()
-			return
-		}
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
-		output, pullErr := cli.PullImage(ctx, ref)
-		h.dockerMu.Lock()
-		job.Output = output
-		if pullErr != nil {
-			job.Done, job.OK = true, false
-			job.Error = pullErr.Error()
-		} else {
-			job.Done, job.OK = true, true
+			return errors.New("Docker não disponível")
 		}
-		h.dockerMu.Unlock()
-	}()
+		return cli.PullImageTo(run.Context(), ref, run)
+	})
 
 	h.render(w, "docker/images.html", map[string]any{
 		"PullJobID": job.ID,
@@ -318,15 +275,9 @@ func (h *Handler) PullImageJobStatus(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	jobID := chi.URLParam(r, "id")
 
-	h.dockerMu.Lock()
-	job, ok := h.dockerJobs[jobID]
-	var done, jobOK bool
-	var errMsg, output string
-	if ok {
-		done, jobOK = job.Done, job.OK
-		errMsg, output = job.Error, job.Output
-	}
-	h.dockerMu.Unlock()
+	job, ok := h.app.Jobs.Get(jobID)
+	done, jobOK := job.Done(), job.OK()
+	errMsg, output := job.Error, job.Output
 
 	if !ok {
 		h.render(w, "docker/images.html", map[string]any{
@@ -356,7 +307,7 @@ func (h *Handler) PullImageJobStatus(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) TemplateDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	h.renderDrawer(w, "Lançar Container", "docker/template-drawer.html", map[string]any{
-		"Templates": containerTemplates,
+		"Templates": idocker.Templates,
 	})
 }
 
@@ -392,19 +343,11 @@ func (h *Handler) LaunchTemplate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	ports := map[string]string{}
-	if portStr != "" {
-		parts := strings.SplitN(portStr, ":", 2)
-		if len(parts) == 2 {
-			ports[parts[1]] = parts[0]
-		}
-	}
-
 	spec := idocker.ContainerSpec{
 		Name:          name,
 		Image:         imgRef,
 		Env:           envVars,
-		Ports:         ports,
+		Ports:         idocker.PortBindings(portStr),
 		RestartPolicy: restart,
 	}
 
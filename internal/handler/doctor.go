@@ -8,7 +8,7 @@ import (
 
 func (h *Handler) Doctor(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	checks := doctor.RunDoctor(h.app.Paths)
+	checks := doctor.RunDoctor(h.app.Paths())
 	if r.Header.Get("HX-Request") == "true" {
 		h.render(w, "doctor.html", map[string]any{"Checks": checks})
 		return
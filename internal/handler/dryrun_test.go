@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/profile"
+	"github.com/seuusuario/factorydev/internal/shell"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// TestDryRunLeavesFilesUntouched passa por cada gravação de arquivo fora do
+// state (aliases, .env, settings do Claude, chaves, pacote de perfil) com o
+// executor em dry-run: nada é criado nem alterado no disco.
+func TestDryRunLeavesFilesUntouched(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	prev := command.Default()
+	t.Cleanup(func() { command.SetDefault(prev) })
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+
+	// Uma chave real, gravada antes de ligar o dry-run.
+	key, err := ssh.GenerateKeyFull("real", "dev@host", "ed25519", 0, nil, a.Paths())
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := map[string]string{
+		filepath.Join(home, ".zshrc"):                   "export A=1\n",
+		filepath.Join(home, ".claude", "settings.json"): "{}\n",
+		key.PublicKeyPath:                               "pub antiga\n",
+	}
+	for path, content := range existing {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.Commands.SetDryRun(true)
+
+	if _, err := shell.SyncAliases([]storage.ShellAlias{{Name: "gs", Command: "git status"}}, a.Paths()); err != nil {
+		t.Fatalf("aliases: %v", err)
+	}
+	if _, err := shell.WriteEnv(a.Paths().Envs, "e1", map[string]string{"TOKEN": "abc"}); err != nil {
+		t.Fatalf("env: %v", err)
+	}
+	if _, err := ssh.GenerateKeyFull("nova", "dev@host", "rsa", 2048, nil, a.Paths()); err != nil {
+		t.Fatalf("gerar chave: %v", err)
+	}
+	if err := ssh.RegenPublicKey(key.PrivateKeyPath, key.PublicKeyPath, nil); err != nil {
+		t.Fatalf("regen pub: %v", err)
+	}
+
+	err = a.Storage().Update(func(st *storage.State) error {
+		st.MCPServers = []storage.MCPServer{{ID: "m1", Name: "fs", Command: "npx", Enabled: true}}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	New(a).SyncToClaudeCode(rec, httptest.NewRequest("POST", "/tools/mcp/servers/sync", nil))
+	if rec.Code != 200 {
+		t.Fatalf("sync mcp = %d %s", rec.Code, rec.Body)
+	}
+
+	form := url.Values{"name": {"Gerada"}, "alias": {"gerada"}, "keyType": {"ed25519"}}
+	req := httptest.NewRequest("POST", "/tools/keys", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec = httptest.NewRecorder()
+	New(a).CreateKey(rec, req)
+	if rec.Code != 200 {
+		t.Fatalf("criar chave = %d %s", rec.Code, rec.Body)
+	}
+	if state, err := a.Storage().LoadState(); err != nil || len(state.Keys) != 0 {
+		t.Fatalf("chave gravada no state em dry-run: %v %v", state.Keys, err)
+	}
+
+	bundle := &profile.Bundle{
+		Manifest: profile.Manifest{
+			Home:     "/home/outro",
+			Sections: []string{profile.SectionKeys},
+			Keys:     []storage.Key{{ID: "k2", Alias: "importada", PrivateKeyPath: "/home/outro/.fdev/keys/importada/id_ed25519"}},
+			IncludeIf: []profile.IncludeIf{{
+				Pattern:     "gitdir:/home/outro/work/",
+				IncludePath: "/home/outro/.gitconfig-work",
+				Content:     "[user]\n\temail = dev@work\n",
+			}},
+		},
+		Files: map[string][]byte{"keys/importada/id_ed25519": []byte("PRIVATE")},
+	}
+	if _, err := profile.Merge(&storage.State{}, bundle, a.Paths(), profile.ImportOptions{}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if _, err := profile.ApplyIncludeIf(bundle, filepath.Join(home, ".gitconfig"), a.Paths(), false); err != nil {
+		t.Fatalf("includeIf: %v", err)
+	}
+
+	for path, content := range existing {
+		if got, _ := os.ReadFile(path); string(got) != content {
+			t.Errorf("%s alterado em dry-run: %q", path, got)
+		}
+	}
+	for _, path := range []string{
+		shell.AliasFile(a.Paths()),
+		filepath.Join(home, ".bashrc"),
+		filepath.Join(a.Paths().Envs, "e1.env"),
+		a.Paths().KeyDir("nova"),
+		a.Paths().KeyDir("gerada"),
+		a.Paths().KeyDir("importada"),
+		filepath.Join(home, ".gitconfig-work"),
+		filepath.Join(home, ".gitconfig"),
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s criado em dry-run", path)
+		}
+	}
+}
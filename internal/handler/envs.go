@@ -4,21 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/shell"
 	"github.com/seuusuario/factorydev/internal/storage"
 )
 
 // GET /tools/envs
 func (h *Handler) ListEnvs(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -28,8 +27,22 @@ func (h *Handler) ListEnvs(w http.ResponseWriter, r *http.Request) {
 	copy(envs, state.EnvFiles)
 	sort.Slice(envs, func(i, j int) bool { return envs[i].Name < envs[j].Name })
 
+	// Com o vault bloqueado os valores selados aparecem mascarados.
+	locked := h.app.Vault.Locked()
+	for i := range envs {
+		if locked {
+			envs[i].Variables = maskSealed(envs[i].Variables)
+			continue
+		}
+		if err := h.revealEnvFile(&envs[i]); err != nil {
+			h.vaultError(w, err)
+			return
+		}
+	}
+
 	payload := map[string]any{
-		"EnvFiles": envs,
+		"EnvFiles":    envs,
+		"VaultLocked": locked && h.app.Vault.Initialized(),
 	}
 	if r.Header.Get("HX-Request") == "true" {
 		h.render(w, "envs/list.html", payload)
@@ -72,15 +85,17 @@ func (h *Handler) CreateEnv(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
-
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	if err := h.sealEnvFile(&env); err != nil {
+		h.vaultError(w, err)
 		return
 	}
-	state.EnvFiles = append(state.EnvFiles, env)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+
+	err := h.store(r).Update(func(state *storage.State) error {
+		state.EnvFiles = append(state.EnvFiles, env)
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "Arquivo .env criado!")
@@ -90,7 +105,7 @@ func (h *Handler) CreateEnv(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditEnvDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -107,11 +122,16 @@ func (h *Handler) EditEnvDrawer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	varsJSON := envVarsToJSON(found.Variables)
+	env := *found
+	if err := h.revealEnvFile(&env); err != nil {
+		h.vaultError(w, err)
+		return
+	}
+	varsJSON := envVarsToJSON(env.Variables)
 	h.renderDrawer(w, "Editar .env", "envs/env-drawer.html", map[string]any{
 		"SubmitURL": "/tools/envs/" + id,
 		"IsEdit":    true,
-		"Env":       found,
+		"Env":       env,
 		"VarsJSON":  varsJSON,
 	})
 }
@@ -131,23 +151,25 @@ func (h *Handler) UpdateEnv(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
-
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	if err := h.sealEnvFile(&env); err != nil {
+		h.vaultError(w, err)
 		return
 	}
-	for i := range state.EnvFiles {
-		if state.EnvFiles[i].ID == id {
-			state.EnvFiles[i].Name = env.Name
-			state.EnvFiles[i].ProjectPath = env.ProjectPath
-			state.EnvFiles[i].Variables = env.Variables
-			state.EnvFiles[i].UpdatedAt = time.Now()
-			break
+
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.EnvFiles {
+			if state.EnvFiles[i].ID == id {
+				state.EnvFiles[i].Name = env.Name
+				state.EnvFiles[i].ProjectPath = env.ProjectPath
+				state.EnvFiles[i].Variables = env.Variables
+				state.EnvFiles[i].UpdatedAt = time.Now()
+				return nil
+			}
 		}
-	}
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Arquivo .env não encontrado")
 		return
 	}
 	h.successToast(w, "Arquivo .env atualizado!")
@@ -157,25 +179,17 @@ func (h *Handler) UpdateEnv(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteEnv(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := -1
-	for i, e := range state.EnvFiles {
-		if e.ID == id {
-			idx = i
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i, e := range state.EnvFiles {
+			if e.ID == id {
+				state.EnvFiles = append(state.EnvFiles[:i], state.EnvFiles[i+1:]...)
+				return nil
+			}
 		}
-	}
-	if idx < 0 {
-		h.operationError(w, "Arquivo .env não encontrado", http.StatusNotFound)
-		return
-	}
-	state.EnvFiles = append(state.EnvFiles[:idx], state.EnvFiles[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Arquivo .env não encontrado")
 		return
 	}
 	h.successToast(w, "Arquivo .env removido!")
@@ -185,7 +199,7 @@ func (h *Handler) DeleteEnv(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ExportEnvFile(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -202,18 +216,15 @@ func (h *Handler) ExportEnvFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Gera conteúdo KEY=VALUE
-	var lines []string
-	for k, v := range found.Variables {
-		lines = append(lines, fmt.Sprintf("%s=%s", k, v))
+	vars, err := h.app.Vault.RevealMap(found.Variables)
+	if err != nil {
+		h.vaultError(w, err)
+		return
 	}
-	sort.Strings(lines)
-	content := strings.Join(lines, "\n") + "\n"
 
 	// Grava em ~/.fdev/envs/<id>.env
-	envsDir := h.app.Paths.Envs
-	outPath := filepath.Join(envsDir, found.ID+".env")
-	if err := os.WriteFile(outPath, []byte(content), 0o600); err != nil {
+	outPath, err := shell.WriteEnv(h.app.Paths().Envs, found.ID, vars)
+	if err != nil {
 		h.operationError(w, "Erro ao exportar: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
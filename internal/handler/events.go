@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/events"
+	"github.com/seuusuario/factorydev/internal/system"
+	"github.com/seuusuario/factorydev/web"
+)
+
+const (
+	// sseHeartbeat mantém a conexão viva atrás de proxies.
+	sseHeartbeat = 15 * time.Second
+	// feedInterval é o intervalo de amostragem dos feeds de Docker e sistema.
+	feedInterval = 3 * time.Second
+)
+
+// GET /events?topic=jobs&topic=job:<id> — stream Server-Sent Events com os
+// eventos dos tópicos pedidos, um JSON events.Event por mensagem. Os feeds de
+// Docker e métricas só rodam enquanto houver alguém inscrito.
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	topics := r.URL.Query()["topic"]
+	if len(topics) == 0 {
+		http.Error(w, "informe ao menos um topic", http.StatusBadRequest)
+		return
+	}
+	rc := http.NewResponseController(w)
+
+	ch, cancel := h.app.Events.Subscribe(topics...)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	for _, topic := range topics {
+		h.startFeed(topic)
+		// Job que terminou antes da inscrição: avisa já, senão a interface
+		// só descobriria pelo polling de reserva.
+		if id, ok := strings.CutPrefix(topic, "job:"); ok {
+			if job, found := h.app.Jobs.Get(id); found && job.Done() {
+				_ = writeSSE(w, events.Event{Topic: topic, Type: "done", Data: job})
+			}
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(sseHeartbeat)
+	defer ping.Stop()
+	for {
+		var err error
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			_, err = fmt.Fprint(w, ": ping\n\n")
+		case e, ok := <-ch:
+			if !ok {
+				return
+			}
+			err = writeSSE(w, e)
+		}
+		if err == nil {
+			err = rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func writeSSE(w http.ResponseWriter, e events.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
+
+// startFeed liga o feed do tópico, se ele tiver um e ainda não estiver rodando.
+func (h *Handler) startFeed(topic string) {
+	var snapshot func(context.Context) events.Event
+	switch topic {
+	case events.TopicDocker:
+		snapshot = h.dockerStatusEvent
+	case events.TopicSystem:
+		snapshot = h.systemMetricsEvent
+	default:
+		return
+	}
+	h.feedMu.Lock()
+	defer h.feedMu.Unlock()
+	if h.feeds[topic] {
+		return
+	}
+	if h.feeds == nil {
+		h.feeds = make(map[string]bool)
+	}
+	h.feeds[topic] = true
+	go h.runFeed(topic, snapshot)
+}
+
+// runFeed publica o snapshot sempre que ele muda e para quando o tópico fica
+// sem inscritos.
+func (h *Handler) runFeed(topic string, snapshot func(context.Context) events.Event) {
+	var last string
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), feedInterval)
+		e := snapshot(ctx)
+		cancel()
+		if e.HTML != last {
+			h.app.Events.Retain(e)
+			last = e.HTML
+		}
+		time.Sleep(feedInterval)
+
+		h.feedMu.Lock()
+		if h.app.Events.Subscribers(topic) == 0 {
+			delete(h.feeds, topic)
+			h.feedMu.Unlock()
+			return
+		}
+		h.feedMu.Unlock()
+	}
+}
+
+func (h *Handler) dockerStatusEvent(ctx context.Context) events.Event {
+	data := dockerStatusData(ctx)
+	return events.Event{
+		Topic: events.TopicDocker,
+		Type:  "status",
+		Data:  map[string]any{"available": data["Available"]},
+		HTML:  h.renderString("docker/status-header.html", data),
+	}
+}
+
+func (h *Handler) systemMetricsEvent(ctx context.Context) events.Event {
+	info, _ := system.Gather(ctx)
+	return events.Event{
+		Topic: events.TopicSystem,
+		Type:  "metrics",
+		Data:  info,
+		HTML:  h.renderString("system/widgets.html", map[string]any{"Info": info}),
+	}
+}
+
+// renderString renderiza um partial fora de uma requisição (eventos SSE).
+// Erros são logados e viram string vazia.
+func (h *Handler) renderString(tmpl string, data any) string {
+	t, err := template.New("root").Funcs(tmplFuncs).ParseFS(web.FS, "templates/"+tmpl)
+	if err == nil {
+		var buf bytes.Buffer
+		if err = t.ExecuteTemplate(&buf, tmpl, data); err == nil {
+			return buf.String()
+		}
+	}
+	h.app.Logger.Error("erro render template", "tmpl", tmpl, "err", err)
+	return ""
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/events"
+	"github.com/seuusuario/factorydev/internal/jobs"
+)
+
+func TestEventsStreamsJobLog(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	srv := httptest.NewServer(New(a).Routes())
+	defer srv.Close()
+
+	release := make(chan struct{})
+	job := a.Jobs.Start(jobs.Options{Kind: "teste"}, func(run *jobs.Run) error {
+		<-release
+		run.Log("primeira linha")
+		return nil
+	})
+
+	req, _ := http.NewRequest("GET", srv.URL+"/events?topic="+events.JobTopic(job.ID), nil)
+	req.Header.Set("Authorization", "Bearer "+a.Auth.Token())
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status %d, content-type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	close(release)
+
+	var types []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var e struct {
+			Type string         `json:"type"`
+			Data map[string]any `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			t.Fatalf("evento inválido %q: %v", data, err)
+		}
+		types = append(types, e.Type)
+		if e.Type == "log" && e.Data["line"] != "primeira linha" {
+			t.Fatalf("linha de log = %v", e.Data["line"])
+		}
+		if e.Type == "done" {
+			break
+		}
+	}
+	if got := strings.Join(types, ","); got != "log,done" {
+		t.Fatalf("eventos = %s", got)
+	}
+}
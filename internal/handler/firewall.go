@@ -10,7 +10,7 @@ import (
 // GET /tools/firewall
 func (h *Handler) FirewallDashboard(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	status := firewall.GetStatus()
+	status := firewall.GetStatus(r.Context())
 	payload := map[string]any{
 		"Status": status,
 	}
@@ -34,7 +34,7 @@ func (h *Handler) ToggleFirewall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	enable := r.FormValue("enable") == "true"
-	if err := firewall.Toggle(enable); err != nil {
+	if err := firewall.Toggle(r.Context(), enable); err != nil {
 		h.errorToast(w, "Erro: "+err.Error())
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
@@ -66,9 +66,9 @@ func (h *Handler) AddFirewallRule(w http.ResponseWriter, r *http.Request) {
 
 	var err error
 	if action == "deny" {
-		err = firewall.DenyPort(port, proto)
+		err = firewall.DenyPort(r.Context(), port, proto)
 	} else {
-		err = firewall.AllowPort(port, proto)
+		err = firewall.AllowPort(r.Context(), port, proto)
 	}
 	if err != nil {
 		h.errorToast(w, "Erro: "+err.Error())
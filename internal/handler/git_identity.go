@@ -20,13 +20,13 @@ type identityView struct {
 }
 
 func (h *Handler) globalConfigPath() string {
-	return filepath.Join(h.app.Paths.Home, ".gitconfig")
+	return filepath.Join(h.app.Paths().Home, ".gitconfig")
 }
 
 // GET /tools/git
 func (h *Handler) ListIdentities(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -72,7 +72,7 @@ func (h *Handler) ListIdentities(w http.ResponseWriter, r *http.Request) {
 // GET /tools/git/identities/new
 func (h *Handler) NewIdentityDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -102,21 +102,18 @@ func (h *Handler) CreateIdentity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
-	state.Identities = append(state.Identities, storage.GitIdentity{
-		ID:        newID(),
-		Name:      name,
-		Email:     email,
-		KeyID:     keyID,
-		CreatedAt: time.Now(),
+	err := h.store(r).Update(func(state *storage.State) error {
+		state.Identities = append(state.Identities, storage.GitIdentity{
+			ID:        newID(),
+			Name:      name,
+			Email:     email,
+			KeyID:     keyID,
+			CreatedAt: time.Now(),
+		})
+		return nil
 	})
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "Identidade criada!")
@@ -126,7 +123,7 @@ func (h *Handler) CreateIdentity(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditIdentityDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -169,21 +166,19 @@ func (h *Handler) UpdateIdentity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	for i := range state.Identities {
-		if state.Identities[i].ID == id {
-			state.Identities[i].Name = name
-			state.Identities[i].Email = email
-			state.Identities[i].KeyID = keyID
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.Identities {
+			if state.Identities[i].ID == id {
+				state.Identities[i].Name = name
+				state.Identities[i].Email = email
+				state.Identities[i].KeyID = keyID
+				return nil
+			}
 		}
-	}
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Identidade não encontrada")
 		return
 	}
 	h.successToast(w, "Identidade atualizada!")
@@ -193,25 +188,17 @@ func (h *Handler) UpdateIdentity(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteIdentity(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := -1
-	for i, ident := range state.Identities {
-		if ident.ID == id {
-			idx = i
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i, ident := range state.Identities {
+			if ident.ID == id {
+				state.Identities = append(state.Identities[:i], state.Identities[i+1:]...)
+				return nil
+			}
 		}
-	}
-	if idx < 0 {
-		h.operationError(w, "Identidade não encontrada", http.StatusNotFound)
-		return
-	}
-	state.Identities = append(state.Identities[:idx], state.Identities[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Identidade não encontrada")
 		return
 	}
 	h.successToast(w, "Identidade removida!")
@@ -265,7 +252,7 @@ func (h *Handler) SaveGlobalConfig(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) IncludeIfDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	rules, _ := gitconfig.ListIncludeIf(h.globalConfigPath())
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -326,7 +313,7 @@ func (h *Handler) RemoveIncludeIfRule(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) SigningSetupDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -365,7 +352,7 @@ func (h *Handler) SigningSetupDrawer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ApplySigning(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
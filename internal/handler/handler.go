@@ -2,93 +2,29 @@ package handler
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
 	"runtime/debug"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
 
 	"github.com/seuusuario/factorydev/internal/app"
 )
 
-// CloneJob representa um clone de repositório em andamento ou concluído.
-type CloneJob struct {
-	ID     string
-	Done   bool
-	OK     bool
-	Output string
-	Error  string
-}
-
-// GitOpJob representa uma operação git genérica assíncrona (pull, test SSH, send-file, etc).
-type GitOpJob struct {
-	ID     string
-	Done   bool
-	OK     bool
-	Output string
-	Error  string
-}
-
-// DockerJob representa uma operação Docker assíncrona (pull de imagem, etc).
-type DockerJob struct {
-	ID     string
-	Done   bool
-	OK     bool
-	Output string
-	Error  string
-}
-
-// PullAllResult representa o resultado do pull de um repositório específico.
-type PullAllResult struct {
-	RepoName string
-	Done     bool
-	OK       bool
-	Output   string
-	Error    string
-}
-
-// PullAllJob representa um pull de todos os repositórios gerenciados.
-type PullAllJob struct {
-	ID      string
-	Done    bool
-	Force   bool
-	Total   int
-	Results []PullAllResult
-}
-
 type Handler struct {
-	app       *app.App
-	cloneJobs map[string]*CloneJob
-	cloneMu   sync.Mutex
-	// Pull jobs
-	pullJobs map[string]*GitOpJob
-	pullMu   sync.Mutex
-	// Pull All jobs
-	pullAllJobs map[string]*PullAllJob
-	pullAllMu   sync.Mutex
-	// Server test/connect jobs
-	serverTestJobs map[string]*GitOpJob
-	serverTestMu   sync.Mutex
-	// Send file jobs
-	sendFileJobs map[string]*GitOpJob
-	sendFileMu   sync.Mutex
-	// Docker jobs (pull image)
-	dockerJobs map[string]*DockerJob
-	dockerMu   sync.Mutex
-	// Installer jobs
-	installerJobs map[string]*GitOpJob
-	installerMu   sync.Mutex
+	app *app.App
+
+	feedMu sync.Mutex
+	feeds  map[string]bool // feeds SSE em execução, por tópico (ver events.go)
 }
 
 func New(a *app.App) *Handler {
-	return &Handler{
-		app:            a,
-		cloneJobs:      make(map[string]*CloneJob),
-		pullJobs:       make(map[string]*GitOpJob),
-		pullAllJobs:    make(map[string]*PullAllJob),
-		serverTestJobs: make(map[string]*GitOpJob),
-		sendFileJobs:   make(map[string]*GitOpJob),
-		dockerJobs:     make(map[string]*DockerJob),
-		installerJobs:  make(map[string]*GitOpJob),
-	}
+	h := &Handler{app: a}
+	h.registerScheduleActions()
+	return h
 }
 
 func (h *Handler) Index(w http.ResponseWriter, r *http.Request) {
@@ -100,12 +36,61 @@ func (h *Handler) Health(w http.ResponseWriter, _ *http.Request) {
 	_, _ = w.Write([]byte(`{"status":"ok"}`))
 }
 
+// requestLogger registra as requisições no log da aplicação: as que falharam
+// (status >= 500) como erro, as demais em debug. O request_id liga essas
+// linhas ao "panic capturado" do recoverer na página Logs.
+func (h *Handler) requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := slog.LevelDebug
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			h.app.Logger.Log(r.Context(), level, "requisição",
+				"subsystem", "http",
+				"request_id", middleware.GetReqID(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", status,
+				"duration", time.Since(start).Round(time.Millisecond).String(),
+			)
+		}()
+		next.ServeHTTP(ww, r)
+	})
+}
+
+// holdWorkspace mantém aberto, até o fim da requisição, o storage do
+// workspace ativo quando ela começou (ver App.Hold). Os streams (SSE e
+// WebSocket do terminal) não usam o storage e ficam de fora: segurariam o
+// workspace antigo enquanto a aba estivesse aberta.
+func (h *Handler) holdWorkspace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events" && !strings.HasSuffix(r.URL.Path, "/ws") {
+			defer h.app.Hold()()
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
 func (h *Handler) recoverer(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if rec := recover(); rec != nil {
-				h.app.Logger.Error("panic capturado", "panic", fmt.Sprint(rec), "stack", string(debug.Stack()))
-				h.operationError(w, "Ocorreu um erro inesperado. Verifique os logs.", http.StatusInternalServerError)
+				h.app.Logger.Error("panic capturado",
+					"subsystem", "http",
+					"request_id", middleware.GetReqID(r.Context()),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"panic", fmt.Sprint(rec),
+					"stack", string(debug.Stack()),
+				)
+				h.operationError(w, "Ocorreu um erro inesperado. Veja os detalhes na página Logs.", http.StatusInternalServerError)
 			}
 		}()
 		next.ServeHTTP(w, r)
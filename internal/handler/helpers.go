@@ -3,19 +3,24 @@ package handler
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
 	"path/filepath"
 	"strings"
 
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
 	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
 	"github.com/seuusuario/factorydev/web"
 )
 
 var tmplFuncs = template.FuncMap{
 	"mb":   func(b uint64) float64 { return float64(b) / 1024 / 1024 },
 	"gb":   func(b uint64) float64 { return float64(b) / 1024 / 1024 / 1024 },
+	"kb":   func(b int64) float64 { return float64(b) / 1024 },
 	"join": func(s []string, sep string) string { return strings.Join(s, sep) },
 }
 
@@ -126,6 +131,51 @@ func (h *Handler) operationError(w http.ResponseWriter, msg string, status int)
 	_ = json.NewEncoder(w).Encode(map[string]string{"error": msg})
 }
 
+// store devolve o storage anotado com a rota da requisição, para o audit log.
+func (h *Handler) store(r *http.Request) storage.Storage {
+	return h.app.Audit().WithRoute(r.Method + " " + r.URL.Path)
+}
+
+// holdStore é o store para jobs: fixa o workspace aberto na hora da
+// chamada, e o job grava nele (e nos caminhos dele) mesmo que o usuário
+// troque de workspace no meio. O job chama release ao terminar.
+func (h *Handler) holdStore(r *http.Request) (store storage.Storage, paths *config.Paths, release func()) {
+	paths, rec, release := h.app.HoldWorkspace()
+	return rec.WithRoute(r.Method + " " + r.URL.Path), paths, release
+}
+
+// errNotFound aborta um Storage.Update quando o registro alvo não existe.
+var errNotFound = errors.New("registro não encontrado")
+
+// errResponded aborta um Storage.Update quando a resposta (drawer com erros de
+// validação, por exemplo) já foi escrita dentro do fn.
+var errResponded = errors.New("resposta já enviada")
+
+// validationFailed aborta um Storage.Update com uma mensagem para o usuário.
+type validationFailed string
+
+func (e validationFailed) Error() string { return string(e) }
+
+// updateFailed responde a um erro devolvido por Storage.Update: registro
+// inexistente vira 404 com notFoundMsg, validação vira 422, vault bloqueado
+// vira 423 e o resto 500.
+func (h *Handler) updateFailed(w http.ResponseWriter, err error, notFoundMsg string) {
+	var vf validationFailed
+	switch {
+	case errors.Is(err, errResponded):
+	case errors.As(err, &vf):
+		h.errorToast(w, string(vf))
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	case errors.Is(err, errNotFound):
+		h.operationError(w, notFoundMsg, http.StatusNotFound)
+	case errors.Is(err, vault.ErrLocked):
+		h.vaultError(w, err)
+	default:
+		h.app.Logger.Error("erro ao atualizar state", "err", err)
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	}
+}
+
 // expandHome expande "~/" para o diretório home do usuário.
 func expandHome(path, homedir string) string {
 	if strings.HasPrefix(path, "~/") {
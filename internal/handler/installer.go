@@ -1,15 +1,15 @@
 package handler
 
 import (
-	"context"
 	"fmt"
 	"net/http"
-	"os/exec"
-	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/command"
 	"github.com/seuusuario/factorydev/internal/installer"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/privilege"
 )
 
 // GET /tools/installer
@@ -50,32 +50,24 @@ func (h *Handler) InstallTool(w http.ResponseWriter, r *http.Request) {
 	}
 
 	pm := installer.DetectPM()
-	bin, args := installer.InstallCmd(*tool, pm)
+	bin, args, admin := installer.InstallCmd(*tool, pm)
 	if bin == "" {
 		h.errorToast(w, "Nenhum gerenciador de pacotes detectado")
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
 
-	job := &GitOpJob{ID: newID()}
-	h.installerMu.Lock()
-	h.installerJobs[job.ID] = job
-	h.installerMu.Unlock()
-
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-		defer cancel()
-		out, err := exec.CommandContext(ctx, bin, args...).CombinedOutput()
-		h.installerMu.Lock()
-		job.Output = strings.TrimSpace(string(out))
-		if err != nil {
-			job.Done, job.OK = true, false
-			job.Error = fmt.Sprintf("Erro na instalação: %v", err)
-		} else {
-			job.Done, job.OK = true, true
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "installer",
+		Title:   "Instalação de " + tool.Name,
+		Timeout: 10 * time.Minute,
+		Meta:    map[string]string{"tool": name, "action": "install"},
+	}, func(run *jobs.Run) error {
+		if err := runPackageCmd(run, admin, "Instalar "+tool.Name, bin, args); err != nil {
+			return fmt.Errorf("Erro na instalação: %v", err)
 		}
-		h.installerMu.Unlock()
-	}()
+		return nil
+	})
 
 	h.render(w, "installer/install-progress.html", map[string]any{
 		"ID": job.ID, "Done": false, "ToolName": name, "Action": "install",
@@ -93,32 +85,24 @@ func (h *Handler) UninstallTool(w http.ResponseWriter, r *http.Request) {
 	}
 
 	pm := installer.DetectPM()
-	bin, args := installer.UninstallCmd(*tool, pm)
+	bin, args, admin := installer.UninstallCmd(*tool, pm)
 	if bin == "" {
 		h.errorToast(w, "Nenhum gerenciador de pacotes detectado")
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
 
-	job := &GitOpJob{ID: newID()}
-	h.installerMu.Lock()
-	h.installerJobs[job.ID] = job
-	h.installerMu.Unlock()
-
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
-		out, err := exec.CommandContext(ctx, bin, args...).CombinedOutput()
-		h.installerMu.Lock()
-		job.Output = strings.TrimSpace(string(out))
-		if err != nil {
-			job.Done, job.OK = true, false
-			job.Error = fmt.Sprintf("Erro na remoção: %v", err)
-		} else {
-			job.Done, job.OK = true, true
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "installer",
+		Title:   "Remoção de " + tool.Name,
+		Timeout: 5 * time.Minute,
+		Meta:    map[string]string{"tool": name, "action": "uninstall"},
+	}, func(run *jobs.Run) error {
+		if err := runPackageCmd(run, admin, "Remover "+tool.Name, bin, args); err != nil {
+			return fmt.Errorf("Erro na remoção: %v", err)
 		}
-		h.installerMu.Unlock()
-	}()
+		return nil
+	})
 
 	h.render(w, "installer/install-progress.html", map[string]any{
 		"ID": job.ID, "Done": false, "ToolName": name, "Action": "uninstall",
@@ -132,15 +116,9 @@ func (h *Handler) InstallerJobStatus(w http.ResponseWriter, r *http.Request) {
 	toolName := r.URL.Query().Get("tool")
 	action := r.URL.Query().Get("action")
 
-	h.installerMu.Lock()
-	job, ok := h.installerJobs[jobID]
-	var done, jobOK bool
-	var errMsg, output string
-	if ok {
-		done, jobOK = job.Done, job.OK
-		errMsg, output = job.Error, job.Output
-	}
-	h.installerMu.Unlock()
+	job, ok := h.app.Jobs.Get(jobID)
+	done, jobOK := job.Done(), job.OK()
+	errMsg, output := job.Error, job.Output
 
 	if !ok {
 		h.render(w, "installer/install-progress.html", map[string]any{
@@ -168,6 +146,17 @@ func (h *Handler) InstallerJobStatus(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// runPackageCmd roda o gerenciador de pacotes no job, elevando com o
+// privilege quando preciso; o pedido de senha aparece na interface enquanto
+// o job espera.
+func runPackageCmd(run *jobs.Run, admin bool, reason, bin string, args []string) error {
+	c := command.Cmd{Name: bin, Args: args, Stdout: run, Stderr: run}
+	if admin {
+		return privilege.Run(run.Context(), reason, c)
+	}
+	return command.Run(run.Context(), c)
+}
+
 func findTool(name string) *installer.Tool {
 	for i := range installer.Manifest {
 		if installer.Manifest[i].Name == name {
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/jobs"
+)
+
+// jobsHistoryLimit é o máximo de jobs do histórico exibidos no painel.
+const jobsHistoryLimit = 100
+
+// GET /tools/jobs
+func (h *Handler) JobsPage(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	kind := r.URL.Query().Get("kind")
+
+	history, err := h.app.Jobs.History(jobsHistoryLimit)
+	if err != nil {
+		h.app.Logger.Warn("ler histórico de jobs", "err", err)
+	}
+	// Jobs ainda em memória já aparecem em "Em andamento e recentes".
+	live := h.app.Jobs.List()
+	inMemory := make(map[string]bool, len(live))
+	for _, j := range live {
+		inMemory[j.ID] = true
+	}
+	kinds := map[string]bool{}
+	var rows []jobs.Job
+	for _, j := range history {
+		kinds[j.Kind] = true
+		if !inMemory[j.ID] && (kind == "" || j.Kind == kind) {
+			rows = append(rows, j)
+		}
+	}
+	kindList := make([]string, 0, len(kinds))
+	for k := range kinds {
+		kindList = append(kindList, k)
+	}
+	sort.Strings(kindList)
+
+	payload := map[string]any{
+		"Live":        live,
+		"History":     rows,
+		"Kinds":       kindList,
+		"Kind":        kind,
+		"Limit":       jobsHistoryLimit,
+		"HistoryPath": h.app.Paths().Jobs,
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "jobs/list.html", payload)
+		return
+	}
+	h.render(w, "jobs/list.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "jobs",
+		ContentTpl: "jobs/list.html",
+		Data:       payload,
+	})
+}
+
+// GET /tools/jobs/live — tabela de jobs em memória, atualizada por polling.
+func (h *Handler) JobsLive(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.render(w, "jobs/live.html", map[string]any{"Live": h.app.Jobs.List()})
+}
+
+// GET /tools/jobs/{id}
+func (h *Handler) JobDetail(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	job, ok := h.app.Jobs.Get(chi.URLParam(r, "id"))
+	if !ok {
+		h.operationError(w, "Job não encontrado", http.StatusNotFound)
+		return
+	}
+	h.renderDrawer(w, job.Title, "jobs/detail.html", map[string]any{"Job": job})
+}
+
+// POST /tools/jobs/{id}/cancel
+func (h *Handler) CancelJob(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	switch err := h.app.Jobs.Cancel(chi.URLParam(r, "id")); {
+	case err == nil:
+		h.successToast(w, "Cancelamento solicitado")
+	case errors.Is(err, jobs.ErrNotFound):
+		h.operationError(w, "Job não encontrado", http.StatusNotFound)
+	case errors.Is(err, jobs.ErrFinished):
+		h.errorToast(w, "O job já terminou")
+		w.WriteHeader(http.StatusConflict)
+	default:
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	}
+}
+
+// friendlyErr troca o erro pela mensagem amigável exibida no job; nil
+// continua nil.
+func friendlyErr(err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.New(app.FriendlyMessage(err))
+}
@@ -2,9 +2,11 @@ package handler
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -22,6 +24,14 @@ type keyView struct {
 	PublicKeyContent  string
 	PrivateKeyContent string
 	UsedByAccounts    []string
+	Fingerprint       string
+	InAgent           bool
+	// Certificates são os emitidos para a chave; Issued, os emitidos por
+	// ela quando é CA.
+	Certificates []certView
+	Issued       int
+	// ReplacedByName é o nome da chave que substituiu esta numa rotação.
+	ReplacedByName string
 }
 
 type keyFormData struct {
@@ -31,7 +41,7 @@ type keyFormData struct {
 
 func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -45,6 +55,8 @@ func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	loaded := agentFingerprints()
+	certs := certViews(state, time.Now())
 	views := make([]keyView, 0, len(state.Keys))
 	for _, k := range state.Keys {
 		_, privErr := os.Stat(k.PrivateKeyPath)
@@ -58,6 +70,21 @@ func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
 			b, _ := os.ReadFile(k.PrivateKeyPath)
 			privContent = strings.TrimSpace(string(b))
 		}
+		fp, _ := ssh.PublicKeyFingerprint(k.PublicKeyPath)
+		var keyCerts []certView
+		issued := 0
+		for _, c := range certs {
+			if c.KeyID == k.ID {
+				keyCerts = append(keyCerts, c)
+			}
+			if c.CAKeyID == k.ID {
+				issued++
+			}
+		}
+		var replacedBy string
+		if nk := state.FindKey(k.ReplacedBy); nk != nil {
+			replacedBy = nk.Name
+		}
 		views = append(views, keyView{
 			Key:               k,
 			HasPrivKey:        privErr == nil,
@@ -65,10 +92,15 @@ func (h *Handler) ListKeys(w http.ResponseWriter, r *http.Request) {
 			PublicKeyContent:  pubContent,
 			PrivateKeyContent: privContent,
 			UsedByAccounts:    keyUsage[k.ID],
+			Fingerprint:       fp,
+			InAgent:           fp != "" && loaded[fp],
+			Certificates:      keyCerts,
+			Issued:            issued,
+			ReplacedByName:    replacedBy,
 		})
 	}
 
-	data := map[string]any{"Keys": views}
+	data := map[string]any{"Keys": views, "Certificates": certs, "Rotations": rotationViews(state)}
 	pageData := PageData{
 		Title:      "Key Manager — FactoryDev",
 		ActiveTool: "keys",
@@ -100,7 +132,7 @@ func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request) {
 	name := strings.TrimSpace(r.FormValue("name"))
 	alias := strings.TrimSpace(r.FormValue("alias"))
 	if alias == "" {
-		alias = sanitizeAlias(name)
+		alias = storage.SanitizeAlias(name)
 	}
 	keyType := r.FormValue("keyType")
 	if keyType == "" {
@@ -122,45 +154,21 @@ func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request) {
 		CreatedAt: time.Now(),
 	}
 
-	if errs := storage.ValidateKey(k); len(errs) > 0 {
+	_, errs, err := h.createKey(r, k, passphrase)
+	if len(errs) > 0 {
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		h.renderDrawer(w, "Nova Chave", "keys/key-drawer.html", keyFormData{
 			Key:    k,
-			Errors: mapValidation(errs),
+			Errors: errs,
 		})
 		return
 	}
-
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
-	// Verificar unicidade do alias
-	for _, existing := range state.Keys {
-		if existing.Alias == alias {
-			w.WriteHeader(http.StatusUnprocessableEntity)
-			h.renderDrawer(w, "Nova Chave", "keys/key-drawer.html", keyFormData{
-				Key:    k,
-				Errors: map[string]string{"alias": "alias já existe"},
-			})
-			return
-		}
-	}
-
-	result, err := ssh.GenerateKeyFull(alias, comment, keyType, bits, passphrase, h.app.Paths)
 	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		h.updateFailed(w, err, "")
 		return
 	}
-
-	k.PrivateKeyPath = result.PrivateKeyPath
-	k.PublicKeyPath = result.PublicKeyPath
-
-	state.Keys = append(state.Keys, k)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	if h.app.Commands.DryRun() {
+		h.successToast(w, "Dry-run: a chave "+k.Name+" não foi gravada")
 		return
 	}
 
@@ -170,33 +178,29 @@ func (h *Handler) CreateKey(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteKey(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
-	// Verificar se a chave está em uso
 	var usedBy []string
-	for _, a := range state.Accounts {
-		if a.KeyID == id {
-			usedBy = append(usedBy, a.Name)
+	err := h.store(r).Update(func(state *storage.State) error {
+		// Verificar se a chave está em uso
+		for _, a := range state.Accounts {
+			if a.KeyID == id {
+				usedBy = append(usedBy, a.Name)
+			}
+		}
+		if len(usedBy) > 0 {
+			return errKeyInUse
 		}
-	}
-	if len(usedBy) > 0 {
-		h.operationError(w, "Chave em uso por: "+strings.Join(usedBy, ", "), http.StatusConflict)
-		return
-	}
 
-	idx := findKeyIndex(state.Keys, id)
-	if idx < 0 {
-		h.operationError(w, "Chave não encontrada", http.StatusNotFound)
+		if !removeKey(state, id) {
+			return errNotFound
+		}
+		return nil
+	})
+	if errors.Is(err, errKeyInUse) {
+		h.operationError(w, "Chave em uso por: "+strings.Join(usedBy, ", "), http.StatusConflict)
 		return
 	}
-
-	state.Keys = append(state.Keys[:idx], state.Keys[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	if err != nil {
+		h.updateFailed(w, err, "Chave não encontrada")
 		return
 	}
 
@@ -210,7 +214,7 @@ func (h *Handler) RegenPublicKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -236,7 +240,7 @@ func (h *Handler) ExportKeyBase64(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
 	which := r.URL.Query().Get("which")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -323,72 +327,108 @@ func (h *Handler) ImportKeys(w http.ResponseWriter, r *http.Request) {
 		selectedSet[s] = true
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
 	imported := 0
-	for _, c := range candidates {
-		if !selectedSet[c.Name] {
-			continue
-		}
-		// Alias único baseado no nome do arquivo
-		alias := sanitizeAlias(c.Name)
-		base := alias
-		for i := 2; ; i++ {
-			conflict := false
-			for _, k := range state.Keys {
-				if k.Alias == alias {
-					conflict = true
-					break
-				}
+	err = h.store(r).Update(func(state *storage.State) error {
+		for _, c := range candidates {
+			if !selectedSet[c.Name] {
+				continue
 			}
-			if !conflict {
-				break
+			// Alias único baseado no nome do arquivo
+			alias := state.UniqueKeyAlias(storage.SanitizeAlias(c.Name))
+
+			privDest, pubDest, err := ssh.CopyKeyPair(c, alias, h.app.Paths())
+			if err != nil {
+				h.app.Logger.Warn("falha ao copiar chave", "name", c.Name, "err", err)
+				continue
 			}
-			alias = fmt.Sprintf("%s-%d", base, i)
-		}
 
-		privDest, pubDest, err := ssh.CopyKeyPair(c, alias, h.app.Paths)
-		if err != nil {
-			h.app.Logger.Warn("falha ao copiar chave", "name", c.Name, "err", err)
-			continue
+			k := storage.Key{
+				ID:             newID(),
+				Name:           c.Name,
+				Alias:          alias,
+				Type:           c.Type,
+				Bits:           c.Bits,
+				Protected:      c.Protected,
+				PrivateKeyPath: privDest,
+				PublicKeyPath:  pubDest,
+				Source:         "imported",
+				OriginalPath:   c.PrivatePath,
+				CreatedAt:      time.Now(),
+			}
+			state.Keys = append(state.Keys, k)
+			imported++
 		}
-
-		k := storage.Key{
-			ID:             newID(),
-			Name:           c.Name,
-			Alias:          alias,
-			Type:           c.Type,
-			Bits:           c.Bits,
-			Protected:      c.Protected,
-			PrivateKeyPath: privDest,
-			PublicKeyPath:  pubDest,
-			Source:         "imported",
-			OriginalPath:   c.PrivatePath,
-			CreatedAt:      time.Now(),
+		if imported == 0 {
+			return errNothingImported
 		}
-		state.Keys = append(state.Keys, k)
-		imported++
-	}
-
-	if imported == 0 {
+		return nil
+	})
+	if errors.Is(err, errNothingImported) {
 		h.operationError(w, "Nenhuma chave foi importada", http.StatusInternalServerError)
 		return
 	}
-
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 
 	h.successToast(w, fmt.Sprintf("%d chave(s) importada(s) com sucesso!", imported))
 }
 
+// createKey valida a chave, gera o par de arquivos e grava no state. Erros
+// de validação voltam por campo; os demais em err. Em dry-run nenhum arquivo
+// é gerado e a chave não entra no state.
+func (h *Handler) createKey(r *http.Request, k storage.Key, passphrase []byte) (storage.Key, map[string]string, error) {
+	if errs := storage.ValidateKey(k); len(errs) > 0 {
+		return k, mapValidation(errs), nil
+	}
+
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		return k, nil, err
+	}
+	for _, existing := range state.Keys {
+		if existing.Alias == k.Alias {
+			return k, map[string]string{"alias": "alias já existe"}, nil
+		}
+	}
+
+	result, err := ssh.GenerateKeyFull(k.Alias, k.Comment, k.Type, k.Bits, passphrase, h.app.Paths())
+	if err != nil {
+		return k, nil, err
+	}
+	k.PrivateKeyPath = result.PrivateKeyPath
+	k.PublicKeyPath = result.PublicKeyPath
+	if h.app.Commands.DryRun() {
+		return k, nil, nil
+	}
+
+	err = h.store(r).Update(func(state *storage.State) error {
+		state.Keys = append(state.Keys, k)
+		return nil
+	})
+	return k, nil, err
+}
+
 // ── Helpers ───────────────────────────────────────────────────────
 
+var (
+	errKeyInUse        = errors.New("chave em uso")
+	errNothingImported = errors.New("nada foi importado")
+)
+
+// removeKey tira a chave do state junto com os certificados emitidos para
+// ela, que perdem o sentido sem a chave. Usada pela interface e pela API.
+func removeKey(state *storage.State, id string) bool {
+	idx := findKeyIndex(state.Keys, id)
+	if idx < 0 {
+		return false
+	}
+	state.Keys = append(state.Keys[:idx], state.Keys[idx+1:]...)
+	state.Certificates = slices.DeleteFunc(state.Certificates, func(c storage.Certificate) bool { return c.KeyID == id })
+	return true
+}
+
 func findKeyIndex(keys []storage.Key, id string) int {
 	for i := range keys {
 		if keys[i].ID == id {
@@ -0,0 +1,241 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/knownhosts"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// knownHost é um host gerenciado (servidor ou conta) com suas entradas no
+// known_hosts.
+type knownHost struct {
+	Host    string
+	Port    int
+	Address string
+	Names   []string
+	Entries []knownhosts.Entry
+	// Provider é o provedor Git com fingerprints publicados, se houver.
+	Provider string
+}
+
+func (h *Handler) knownHostsPath() string {
+	return filepath.Join(h.app.Paths().SSHDir(), "known_hosts")
+}
+
+// GET /tools/known-hosts
+func (h *Handler) ListKnownHosts(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	path := h.knownHostsPath()
+	payload := map[string]any{"Path": path}
+	f, err := knownhosts.Load(path)
+	if err != nil {
+		payload["Error"] = app.FriendlyMessage(err)
+		f = &knownhosts.File{}
+	}
+	payload["Hosts"] = managedKnownHosts(state, f)
+	payload["Entries"] = f.Entries
+	payload["Invalid"] = f.Invalid
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "knownhosts/list.html", payload)
+		return
+	}
+	h.render(w, "knownhosts/list.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "knownhosts",
+		ContentTpl: "knownhosts/list.html",
+		Data:       payload,
+	})
+}
+
+// managedKnownHosts agrupa servidores (Host/Port) e contas (HostName na
+// porta 22) por endereço, com as entradas do known_hosts de cada um.
+func managedKnownHosts(state *storage.State, f *knownhosts.File) []knownHost {
+	var hosts []knownHost
+	index := map[string]int{}
+	add := func(host string, port int, name string) {
+		host = strings.TrimSpace(host)
+		if host == "" {
+			return
+		}
+		if port == 0 {
+			port = 22
+		}
+		addr := knownhosts.Address(strings.ToLower(host), port)
+		if i, ok := index[addr]; ok {
+			hosts[i].Names = append(hosts[i].Names, name)
+			return
+		}
+		kh := knownHost{Host: host, Port: port, Address: addr, Names: []string{name}, Entries: f.Lookup(host, port)}
+		if p, ok := knownhosts.ProviderFor(host); ok {
+			kh.Provider = p.Name
+		}
+		index[addr] = len(hosts)
+		hosts = append(hosts, kh)
+	}
+	for _, s := range state.Servers {
+		add(s.Host, s.Port, s.Name)
+	}
+	for _, a := range state.Accounts {
+		add(a.HostName, 22, a.Name)
+	}
+	return hosts
+}
+
+// POST /tools/known-hosts/remove
+// Remove linhas (line) ou todas as chaves de um host (host e port), como o
+// `ssh-keygen -R`.
+func (h *Handler) RemoveKnownHosts(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	path := h.knownHostsPath()
+	f, err := knownhosts.Load(path)
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+
+	var n int
+	if host := strings.TrimSpace(r.FormValue("host")); host != "" {
+		port, _ := strconv.Atoi(r.FormValue("port"))
+		n = f.RemoveHost(host, port)
+	} else {
+		var lines []int
+		for _, v := range r.Form["line"] {
+			if l, err := strconv.Atoi(v); err == nil {
+				lines = append(lines, l)
+			}
+		}
+		n = f.Remove(lines...)
+	}
+	if n == 0 {
+		h.operationError(w, "Nenhuma entrada encontrada", http.StatusNotFound)
+		return
+	}
+	if err := f.Save(path); err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	h.app.Logger.Info("known_hosts: entradas removidas", "count", n)
+	h.successToast(w, strconv.Itoa(n)+" entrada(s) removida(s) do known_hosts")
+}
+
+// GET /tools/known-hosts/verify?host=&port=
+// Busca as chaves de host e compara com os fingerprints do provedor (ou os
+// informados em expected) antes de gravá-las.
+func (h *Handler) VerifyKnownHostDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	host := strings.TrimSpace(r.URL.Query().Get("host"))
+	if host == "" {
+		h.operationError(w, "Informe o host", http.StatusBadRequest)
+		return
+	}
+	port, _ := strconv.Atoi(r.URL.Query().Get("port"))
+	if port == 0 {
+		port = 22
+	}
+	h.renderDrawer(w, "Verificar fingerprint — "+knownhosts.Address(host, port), "knownhosts/verify-drawer.html",
+		h.verifyData(r.Context(), host, port, r.URL.Query().Get("expected")))
+}
+
+// POST /tools/known-hosts/pin
+// Busca as chaves de novo no servidor, confere e substitui as entradas do
+// host pelas chaves verificadas.
+func (h *Handler) PinKnownHost(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	host := strings.TrimSpace(r.FormValue("host"))
+	port, _ := strconv.Atoi(r.FormValue("port"))
+	if port == 0 {
+		port = 22
+	}
+	data := h.verifyData(r.Context(), host, port, r.FormValue("expected"))
+	title := "Verificar fingerprint — " + knownhosts.Address(host, port)
+	fail := func(msg string) {
+		data["Errors"] = map[string]string{"expected": msg}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderDrawer(w, title, "knownhosts/verify-drawer.html", data)
+	}
+	if _, ok := data["Error"]; ok {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderDrawer(w, title, "knownhosts/verify-drawer.html", data)
+		return
+	}
+	keys := data["Keys"].([]knownhosts.Verified)
+	var verified []knownhosts.Verified
+	for _, k := range keys {
+		switch k.Status {
+		case knownhosts.StatusMismatch:
+			fail("a chave " + k.Type() + " não confere com o fingerprint publicado: possível ataque man-in-the-middle")
+			return
+		case knownhosts.StatusVerified:
+			verified = append(verified, k)
+		}
+	}
+	if len(verified) == 0 {
+		fail("nenhuma chave conferiu com o fingerprint esperado")
+		return
+	}
+
+	path := h.knownHostsPath()
+	f, err := knownhosts.Load(path)
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	f.RemoveHost(host, port)
+	hash := r.FormValue("hash") == "on"
+	for _, k := range verified {
+		f.Add(host, port, k.Key, hash)
+	}
+	if err := f.Save(path); err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	h.app.Logger.Info("known_hosts: chaves fixadas", "host", knownhosts.Address(host, port), "keys", len(verified))
+	h.successToast(w, "Fingerprint de "+host+" verificado e gravado no known_hosts")
+}
+
+func (h *Handler) verifyData(ctx context.Context, host string, port int, expected string) map[string]any {
+	data := map[string]any{
+		"Host":     host,
+		"Port":     port,
+		"Expected": expected,
+		"Errors":   map[string]string{},
+	}
+	if p, ok := knownhosts.ProviderFor(host); ok {
+		data["Provider"] = p.Name
+	}
+	if f, err := knownhosts.Load(h.knownHostsPath()); err == nil {
+		data["Current"] = f.Lookup(host, port)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	keys, err := knownhosts.Scan(ctx, host, port)
+	if err != nil {
+		data["Error"] = err.Error()
+		return data
+	}
+	data["Keys"] = knownhosts.Verify(host, keys, strings.FieldsFunc(expected, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' '
+	})...)
+	return data
+}
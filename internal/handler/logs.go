@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/seuusuario/factorydev/internal/logview"
+)
+
+// logsPageSize é a quantidade de registros por página na página Logs.
+const logsPageSize = 100
+
+// logsQuery lê o arquivo e os filtros da query string. O arquivo padrão é
+// o app.log ou, se ele ainda não existir, o mais recente.
+func (h *Handler) logsQuery(r *http.Request) (string, logview.Filter, []logview.File, error) {
+	q := r.URL.Query()
+	files, err := logview.Files(h.app.Paths().Logs)
+	if err != nil {
+		return "", logview.Filter{}, nil, err
+	}
+	file := q.Get("file")
+	if file == "" {
+		file = "app.log"
+		if _, err := os.Stat(filepath.Join(h.app.Paths().Logs, file)); err != nil && len(files) > 0 {
+			file = files[0].Name
+		}
+	}
+	return file, logview.Filter{
+		Level:     q.Get("level"),
+		Query:     q.Get("q"),
+		Subsystem: q.Get("subsystem"),
+		Request:   q.Get("request"),
+	}, files, nil
+}
+
+// logsURL monta a URL de path com o arquivo, os filtros e a página.
+func logsURL(path, file string, f logview.Filter, page int) string {
+	v := url.Values{}
+	for k, s := range map[string]string{"file": file, "level": f.Level, "q": f.Query, "subsystem": f.Subsystem, "request": f.Request} {
+		if s != "" {
+			v.Set(k, s)
+		}
+	}
+	if page > 1 {
+		v.Set("page", strconv.Itoa(page))
+	}
+	if len(v) == 0 {
+		return path
+	}
+	return path + "?" + v.Encode()
+}
+
+// GET /tools/logs
+func (h *Handler) LogsPage(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	file, filter, files, err := h.logsQuery(r)
+	if err != nil {
+		h.operationError(w, "Erro ao listar os logs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Subsistemas de todo o arquivo, para o filtro.
+	recs, _ := logview.Read(h.app.Paths().Logs, file)
+	payload := map[string]any{
+		"Files":      files,
+		"File":       file,
+		"Filter":     filter,
+		"Levels":     logview.Levels,
+		"Subsystems": logview.Paginate(recs, logview.Filter{}, 1, 1).Subsystems,
+		"Dir":        h.app.Paths().Logs,
+		"EntriesURL": logsURL("/tools/logs/entries", file, filter, 1),
+		"ClearURL":   logsURL("/tools/logs", file, logview.Filter{}, 1),
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "logs/page.html", payload)
+		return
+	}
+	h.render(w, "logs/page.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "logs",
+		ContentTpl: "logs/page.html",
+		Data:       payload,
+	})
+}
+
+// GET /tools/logs/entries — tabela de registros. A primeira página se
+// atualiza sozinha enquanto "Acompanhar" estiver marcado.
+func (h *Handler) LogEntries(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	file, filter, _, err := h.logsQuery(r)
+	if err != nil {
+		h.operationError(w, "Erro ao listar os logs: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := map[string]any{"File": file}
+	recs, err := logview.Read(h.app.Paths().Logs, file)
+	if err != nil && !os.IsNotExist(err) {
+		data["Error"] = err.Error()
+	}
+	page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+	p := logview.Paginate(recs, filter, page, logsPageSize)
+	data["Page"] = p
+	data["Self"] = logsURL("/tools/logs/entries", file, filter, p.Page)
+	if p.Page > 1 {
+		data["PrevURL"] = logsURL("/tools/logs/entries", file, filter, p.Page-1)
+	}
+	if p.Page < p.Pages {
+		data["NextURL"] = logsURL("/tools/logs/entries", file, filter, p.Page+1)
+	}
+	h.render(w, "logs/entries.html", data)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+)
+
+func TestLogsLinkPanicToRequest(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	h := New(a)
+
+	boom := middleware.RequestID(h.requestLogger(h.recoverer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("explodiu")
+	}))))
+	rec := httptest.NewRecorder()
+	boom.ServeHTTP(rec, httptest.NewRequest("POST", "/tools/keys", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status do panic = %d", rec.Code)
+	}
+
+	srv := httptest.NewServer(h.Routes())
+	defer srv.Close()
+	get := func(path string) string {
+		req, _ := http.NewRequest("GET", srv.URL+path, nil)
+		req.Header.Set("Authorization", "Bearer "+a.Auth.Token())
+		req.Header.Set("HX-Request", "true")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: %d %s", path, resp.StatusCode, body)
+		}
+		return string(body)
+	}
+
+	body := get("/tools/logs/entries?level=ERROR&subsystem=http")
+	if !strings.Contains(body, "panic capturado") || !strings.Contains(body, "explodiu") {
+		t.Fatalf("panic ausente:\n%s", body)
+	}
+	i := strings.Index(body, "request=")
+	if i < 0 {
+		t.Fatalf("panic sem link para a requisição:\n%s", body)
+	}
+	id := body[i+len("request=") : i+strings.IndexByte(body[i:], '"')]
+
+	// A requisição tem duas linhas: o panic e o registro do status 500.
+	body = get("/tools/logs/entries?request=" + id)
+	if !strings.Contains(body, "2 registros") || !strings.Contains(body, "status</code>=500") {
+		t.Fatalf("registros da requisição %s:\n%s", id, body)
+	}
+	if page := get("/tools/logs"); !strings.Contains(page, "logs-entries") {
+		t.Fatalf("página Logs:\n%s", page)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/command"
 	"github.com/seuusuario/factorydev/internal/storage"
 )
 
@@ -16,7 +17,7 @@ import (
 
 func (h *Handler) MCPDashboard(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	st, err := h.app.Storage.LoadState()
+	st, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
 		return
@@ -48,15 +49,17 @@ func (h *Handler) CreateMCPServer(w http.ResponseWriter, r *http.Request) {
 	srv := parseMCPServerForm(r)
 	srv.ID = newID()
 	srv.CreatedAt = time.Now()
-
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
+	if err := h.sealMCPServer(&srv); err != nil {
+		h.vaultError(w, err)
 		return
 	}
-	st.MCPServers = append(st.MCPServers, srv)
-	if err := h.app.Storage.SaveState(st); err != nil {
-		h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
+
+	err := h.store(r).Update(func(st *storage.State) error {
+		st.MCPServers = append(st.MCPServers, srv)
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "MCP Server criado")
@@ -65,9 +68,13 @@ func (h *Handler) CreateMCPServer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditMCPServerDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	for _, s := range st.MCPServers {
 		if s.ID == id {
+			if err := h.revealMCPServer(&s); err != nil {
+				h.vaultError(w, err)
+				return
+			}
 			h.renderDrawer(w, "Editar MCP Server", "mcp/server-drawer.html", map[string]any{
 				"Server":  s,
 				"EnvJSON": envVarsToJSON(s.Env),
@@ -81,54 +88,52 @@ func (h *Handler) EditMCPServerDrawer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) UpdateMCPServer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
+	updated := parseMCPServerForm(r)
+	if err := h.sealMCPServer(&updated); err != nil {
+		h.vaultError(w, err)
 		return
 	}
-	for i, s := range st.MCPServers {
-		if s.ID == id {
-			updated := parseMCPServerForm(r)
-			updated.ID = s.ID
-			updated.CreatedAt = s.CreatedAt
-			st.MCPServers[i] = updated
-			if err := h.app.Storage.SaveState(st); err != nil {
-				h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
-				return
+	err := h.store(r).Update(func(st *storage.State) error {
+		for i, s := range st.MCPServers {
+			if s.ID == id {
+				updated.ID = s.ID
+				updated.CreatedAt = s.CreatedAt
+				st.MCPServers[i] = updated
+				return nil
 			}
-			h.successToast(w, "MCP Server atualizado")
-			return
 		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Server não encontrado")
+		return
 	}
-	h.operationError(w, "Server não encontrado", http.StatusNotFound)
+	h.successToast(w, "MCP Server atualizado")
 }
 
 func (h *Handler) DeleteMCPServer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
-		return
-	}
-	for i, s := range st.MCPServers {
-		if s.ID == id {
-			st.MCPServers = append(st.MCPServers[:i], st.MCPServers[i+1:]...)
-			if err := h.app.Storage.SaveState(st); err != nil {
-				h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
-				return
+	err := h.store(r).Update(func(st *storage.State) error {
+		for i, s := range st.MCPServers {
+			if s.ID == id {
+				st.MCPServers = append(st.MCPServers[:i], st.MCPServers[i+1:]...)
+				return nil
 			}
-			h.successToast(w, "MCP Server removido")
-			return
 		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Server não encontrado")
+		return
 	}
-	h.operationError(w, "Server não encontrado", http.StatusNotFound)
+	h.successToast(w, "MCP Server removido")
 }
 
 // SyncToClaudeCode grava os MCP servers no ~/.claude/settings.json.
 func (h *Handler) SyncToClaudeCode(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	st, err := h.app.Storage.LoadState()
+	st, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
 		return
@@ -156,21 +161,28 @@ func (h *Handler) SyncToClaudeCode(w http.ResponseWriter, r *http.Request) {
 			entry["args"] = s.Args
 		}
 		if len(s.Env) > 0 {
-			entry["env"] = s.Env
+			// O settings.json do Claude precisa dos valores em texto puro.
+			env, err := h.app.Vault.RevealMap(s.Env)
+			if err != nil {
+				h.vaultError(w, err)
+				return
+			}
+			entry["env"] = env
 		}
 		mcpServers[s.Name] = entry
 	}
 	settings["mcpServers"] = mcpServers
 
-	// Garante que o diretório existe
-	_ = os.MkdirAll(filepath.Dir(settingsPath), 0o700)
-
 	out, err := json.MarshalIndent(settings, "", "  ")
 	if err != nil {
 		h.operationError(w, "Erro ao serializar JSON", http.StatusInternalServerError)
 		return
 	}
-	if err := os.WriteFile(settingsPath, out, 0o644); err != nil {
+	// Garante que o diretório existe (em dry-run nada é criado)
+	if !command.DryRun() {
+		_ = os.MkdirAll(filepath.Dir(settingsPath), 0o700)
+	}
+	if err := command.WriteFile(settingsPath, out, 0o644); err != nil {
 		h.operationError(w, "Erro ao gravar settings: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -192,14 +204,12 @@ func (h *Handler) CreateSkill(w http.ResponseWriter, r *http.Request) {
 	skill.ID = newID()
 	skill.CreatedAt = time.Now()
 
-	st, err := h.app.Storage.LoadState()
+	err := h.store(r).Update(func(st *storage.State) error {
+		st.CustomSkills = append(st.CustomSkills, skill)
+		return nil
+	})
 	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
-		return
-	}
-	st.CustomSkills = append(st.CustomSkills, skill)
-	if err := h.app.Storage.SaveState(st); err != nil {
-		h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "Skill criada")
@@ -208,7 +218,7 @@ func (h *Handler) CreateSkill(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditSkillDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	for _, s := range st.CustomSkills {
 		if s.ID == id {
 			h.renderDrawer(w, "Editar Skill", "mcp/skill-drawer.html", map[string]any{
@@ -223,55 +233,49 @@ func (h *Handler) EditSkillDrawer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) UpdateSkill(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
-		return
-	}
-	for i, s := range st.CustomSkills {
-		if s.ID == id {
-			updated := parseSkillForm(r)
-			updated.ID = s.ID
-			updated.CreatedAt = s.CreatedAt
-			st.CustomSkills[i] = updated
-			if err := h.app.Storage.SaveState(st); err != nil {
-				h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
-				return
+	updated := parseSkillForm(r)
+	err := h.store(r).Update(func(st *storage.State) error {
+		for i, s := range st.CustomSkills {
+			if s.ID == id {
+				updated.ID = s.ID
+				updated.CreatedAt = s.CreatedAt
+				st.CustomSkills[i] = updated
+				return nil
 			}
-			h.successToast(w, "Skill atualizada")
-			return
 		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Skill não encontrada")
+		return
 	}
-	h.operationError(w, "Skill não encontrada", http.StatusNotFound)
+	h.successToast(w, "Skill atualizada")
 }
 
 func (h *Handler) DeleteSkill(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, "Erro ao carregar dados", http.StatusInternalServerError)
-		return
-	}
-	for i, s := range st.CustomSkills {
-		if s.ID == id {
-			st.CustomSkills = append(st.CustomSkills[:i], st.CustomSkills[i+1:]...)
-			if err := h.app.Storage.SaveState(st); err != nil {
-				h.operationError(w, "Erro ao salvar", http.StatusInternalServerError)
-				return
+	err := h.store(r).Update(func(st *storage.State) error {
+		for i, s := range st.CustomSkills {
+			if s.ID == id {
+				st.CustomSkills = append(st.CustomSkills[:i], st.CustomSkills[i+1:]...)
+				return nil
 			}
-			h.successToast(w, "Skill removida")
-			return
 		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Skill não encontrada")
+		return
 	}
-	h.operationError(w, "Skill não encontrada", http.StatusNotFound)
+	h.successToast(w, "Skill removida")
 }
 
 // CopySkillPrompt retorna o prompt da skill para o clipboard (via HX-Trigger).
 func (h *Handler) CopySkillPrompt(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	st, _ := h.app.Storage.LoadState()
+	st, _ := h.app.Storage().LoadState()
 	for _, s := range st.CustomSkills {
 		if s.ID == id {
 			w.Header().Set("Content-Type", "text/plain")
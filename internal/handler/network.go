@@ -8,6 +8,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/privilege"
 )
 
 type connectionView struct {
@@ -61,14 +64,12 @@ func (h *Handler) BlockConnection(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var cmd *exec.Cmd
+	cmd := command.Cmd{Name: "ip", Args: []string{"route", "add", "blackhole", ip}}
 	if runtime.GOOS == "darwin" {
-		cmd = exec.Command("sudo", "pfctl", "-t", "fdev_blocked", "-T", "add", ip)
-	} else {
-		cmd = exec.Command("sudo", "ip", "route", "add", "blackhole", ip)
+		cmd = command.Cmd{Name: "pfctl", Args: []string{"-t", "fdev_blocked", "-T", "add", ip}}
 	}
 
-	out, err := cmd.CombinedOutput()
+	out, err := privilege.CombinedOutput(r.Context(), "Bloquear o IP "+ip, cmd)
 	if err != nil {
 		h.errorToast(w, fmt.Sprintf("Erro ao bloquear %s: %v (%s)", ip, err, strings.TrimSpace(string(out))))
 		w.WriteHeader(http.StatusUnprocessableEntity)
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/jobs"
+)
+
+// apiCollectionDoc descreve uma coleção para o documento OpenAPI.
+type apiCollectionDoc struct {
+	Path     string
+	Schema   string
+	Summary  string
+	Type     reflect.Type
+	NoCreate bool
+	NoUpdate bool
+	Actions  []apiAction
+}
+
+// GET /api/v1/openapi.json
+func (h *Handler) OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, buildOpenAPI(apiCollections))
+}
+
+type jsonObj = map[string]any
+
+// openAPIBuilder acumula os schemas nomeados em components.schemas.
+type openAPIBuilder struct {
+	schemas jsonObj
+}
+
+func buildOpenAPI(cols []apiCollection) jsonObj {
+	b := &openAPIBuilder{schemas: jsonObj{}}
+	b.schemas["Error"] = b.schemaOf(reflect.TypeOf(apiErrorBody{}))
+	errRef := ref("Error")
+
+	paths := jsonObj{}
+	op := func(path, method string, o jsonObj) {
+		if paths[path] == nil {
+			paths[path] = jsonObj{}
+		}
+		paths[path].(jsonObj)[method] = o
+	}
+	idParam := []any{jsonObj{
+		"name": "id", "in": "path", "required": true,
+		"schema": jsonObj{"type": "string"},
+	}}
+
+	for _, c := range cols {
+		d := c.describe()
+		b.schemas[d.Schema] = b.schemaOf(d.Type)
+		item := ref(d.Schema)
+		base := APIPrefix + "/" + d.Path
+		tag := []any{d.Path}
+
+		op(base, "get", jsonObj{
+			"tags": tag, "summary": "Lista: " + d.Summary,
+			"responses": jsonObj{"200": jsonBody("OK", jsonObj{"type": "array", "items": item})},
+		})
+		op(base+"/{id}", "get", jsonObj{
+			"tags": tag, "summary": "Detalhe", "parameters": idParam,
+			"responses": jsonObj{"200": jsonBody("OK", item), "404": jsonBody("Não encontrado", errRef)},
+		})
+		if !d.NoCreate {
+			op(base, "post", jsonObj{
+				"tags": tag, "summary": "Cria", "requestBody": requestBody(item),
+				"responses": jsonObj{"201": jsonBody("Criado", item), "422": jsonBody("Dados inválidos", errRef)},
+			})
+		}
+		if !d.NoUpdate {
+			op(base+"/{id}", "put", jsonObj{
+				"tags": tag, "summary": "Substitui", "parameters": idParam, "requestBody": requestBody(item),
+				"responses": jsonObj{
+					"200": jsonBody("OK", item), "404": jsonBody("Não encontrado", errRef),
+					"422": jsonBody("Dados inválidos", errRef),
+				},
+			})
+		}
+		op(base+"/{id}", "delete", jsonObj{
+			"tags": tag, "summary": "Remove", "parameters": idParam,
+			"responses": jsonObj{"204": jsonObj{"description": "Removido"}, "404": jsonBody("Não encontrado", errRef)},
+		})
+
+		for _, a := range d.Actions {
+			status := a.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			o := jsonObj{
+				"tags": tag, "summary": a.summary,
+				"responses": jsonObj{
+					strconv.Itoa(status): jsonBody("OK", b.inline(a.respond)),
+					"default":            jsonBody("Erro", errRef),
+				},
+			}
+			if strings.Contains(a.path, "{id}") {
+				o["parameters"] = idParam
+			}
+			if a.request != nil {
+				o["requestBody"] = requestBody(b.inline(a.request))
+			}
+			op(base+a.path, strings.ToLower(a.method), o)
+		}
+	}
+
+	b.schemas["Job"] = b.schemaOf(reflect.TypeOf(jobs.Job{}))
+	op(APIPrefix+"/jobs", "get", jsonObj{
+		"tags": []any{"jobs"}, "summary": "Jobs em memória (history=1 inclui o histórico)",
+		"responses": jsonObj{"200": jsonBody("OK", jsonObj{"type": "array", "items": ref("Job")})},
+	})
+	op(APIPrefix+"/jobs/{id}", "get", jsonObj{
+		"tags": []any{"jobs"}, "summary": "Estado de um job", "parameters": idParam,
+		"responses": jsonObj{"200": jsonBody("OK", ref("Job")), "404": jsonBody("Não encontrado", errRef)},
+	})
+	op(APIPrefix+"/jobs/{id}/cancel", "post", jsonObj{
+		"tags": []any{"jobs"}, "summary": "Cancela um job em execução", "parameters": idParam,
+		"responses": jsonObj{
+			"202": jsonBody("Cancelamento solicitado", ref("Job")),
+			"404": jsonBody("Não encontrado", errRef), "409": jsonBody("Job já terminou", errRef),
+		},
+	})
+
+	return jsonObj{
+		"openapi": "3.0.3",
+		"info": jsonObj{
+			"title":       "FactoryDev API",
+			"version":     "1",
+			"description": "API JSON do FactoryDev. Autentique com Authorization: Bearer <token> (factorydev auth token).",
+		},
+		"security": []any{jsonObj{"bearerAuth": []any{}}},
+		"paths":    paths,
+		"components": jsonObj{
+			"schemas": b.schemas,
+			"securitySchemes": jsonObj{
+				"bearerAuth": jsonObj{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+}
+
+// inline devolve o schema do exemplo v; structs nomeadas viram referências.
+func (b *openAPIBuilder) inline(v any) jsonObj {
+	if v == nil {
+		return jsonObj{}
+	}
+	return b.schemaOf(reflect.TypeOf(v))
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf gera o JSON Schema de t a partir das tags json. Structs aninhadas
+// (exceto time.Time) são registradas em components e referenciadas.
+func (b *openAPIBuilder) schemaOf(t reflect.Type) jsonObj {
+	switch {
+	case t == timeType:
+		return jsonObj{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return b.schemaOf(t.Elem())
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return jsonObj{"type": "string"}
+	case reflect.Bool:
+		return jsonObj{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonObj{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return jsonObj{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return jsonObj{"type": "array", "items": b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return jsonObj{"type": "object", "additionalProperties": b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return b.structSchema(t)
+	}
+	return jsonObj{}
+}
+
+func (b *openAPIBuilder) structSchema(t reflect.Type) jsonObj {
+	props := jsonObj{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer || ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && ft != timeType && ft.Name() != "" {
+			if _, ok := b.schemas[ft.Name()]; !ok {
+				b.schemas[ft.Name()] = jsonObj{} // evita recursão
+				b.schemas[ft.Name()] = b.structSchema(ft)
+			}
+			if f.Type.Kind() == reflect.Slice {
+				props[name] = jsonObj{"type": "array", "items": ref(ft.Name())}
+			} else {
+				props[name] = ref(ft.Name())
+			}
+			continue
+		}
+		props[name] = b.schemaOf(f.Type)
+	}
+	return jsonObj{"type": "object", "properties": props}
+}
+
+func ref(name string) jsonObj {
+	return jsonObj{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonBody(desc string, schema jsonObj) jsonObj {
+	return jsonObj{
+		"description": desc,
+		"content":     jsonObj{"application/json": jsonObj{"schema": schema}},
+	}
+}
+
+func requestBody(schema jsonObj) jsonObj {
+	return jsonObj{
+		"required": true,
+		"content":  jsonObj{"application/json": jsonObj{"schema": schema}},
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// GET /tools/keys/{id}/passphrase
+func (h *Handler) PassphraseDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	k, ok := h.findKey(w, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+	h.renderDrawer(w, passphraseTitle(k), "keys/passphrase-drawer.html", map[string]any{
+		"Key":    k,
+		"Backup": ssh.BackupPath(k.PrivateKeyPath),
+		"Errors": map[string]string{},
+	})
+}
+
+// POST /tools/keys/{id}/passphrase
+// Adiciona, troca ou remove (nova passphrase vazia) a passphrase da chave.
+func (h *Handler) ChangeKeyPassphrase(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	k, ok := h.findKey(w, chi.URLParam(r, "id"))
+	if !ok {
+		return
+	}
+
+	current := []byte(r.FormValue("current"))
+	newPass := r.FormValue("new")
+	errs := map[string]string{}
+	if newPass != r.FormValue("confirm") {
+		errs["confirm"] = "as passphrases não conferem"
+	}
+	if newPass == "" && !k.Protected {
+		errs["new"] = "informe a nova passphrase"
+	}
+	var restore func() error
+	if len(errs) == 0 {
+		var err error
+		restore, err = ssh.ChangePassphrase(k.PrivateKeyPath, k.PublicKeyPath, k.Comment, current, []byte(newPass))
+		switch {
+		case errors.Is(err, ssh.ErrPassphraseRequired):
+			errs["current"] = "a chave é protegida: informe a passphrase atual"
+		case errors.Is(err, ssh.ErrWrongPassphrase):
+			errs["current"] = err.Error()
+		case err != nil:
+			errs["new"] = app.FriendlyMessage(err)
+		}
+	}
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderDrawer(w, passphraseTitle(k), "keys/passphrase-drawer.html", map[string]any{
+			"Key":    k,
+			"Backup": ssh.BackupPath(k.PrivateKeyPath),
+			"Errors": errs,
+		})
+		return
+	}
+	if h.app.Commands.DryRun() {
+		h.successToast(w, "Dry-run: a chave "+k.Name+" não foi alterada")
+		return
+	}
+
+	protected := newPass != ""
+	err := h.store(r).Update(func(state *storage.State) error {
+		idx := findKeyIndex(state.Keys, k.ID)
+		if idx < 0 {
+			return errNotFound
+		}
+		state.Keys[idx].Protected = protected
+		return nil
+	})
+	if err != nil {
+		// O arquivo e o registro precisam concordar: volta a chave anterior.
+		if rerr := restore(); rerr != nil {
+			h.app.Logger.Error("falha ao restaurar a chave após erro no state", "key", k.Alias, "err", rerr)
+		}
+		h.updateFailed(w, err, "Chave não encontrada")
+		return
+	}
+	h.app.Logger.Info("passphrase da chave alterada", "key", k.Alias, "protected", protected)
+	switch {
+	case !protected:
+		h.successToast(w, "Passphrase removida de "+k.Name)
+	case k.Protected:
+		h.successToast(w, "Passphrase de "+k.Name+" alterada")
+	default:
+		h.successToast(w, k.Name+" agora é protegida por passphrase")
+	}
+}
+
+func passphraseTitle(k storage.Key) string {
+	if k.Protected {
+		return "Passphrase — " + k.Name
+	}
+	return "Proteger com passphrase — " + k.Name
+}
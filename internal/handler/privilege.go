@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os/user"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/privilege"
+)
+
+// renderPrivilegePrompt desenha o modal de senha de administrador: os
+// pedidos em aberto ou, com unlock, o formulário avulso para deixar a senha
+// em memória antes de precisar dela.
+func (h *Handler) renderPrivilegePrompt(w http.ResponseWriter, unlock bool, errMsg string) {
+	username := ""
+	if u, err := user.Current(); err == nil {
+		username = u.Username
+	}
+	h.render(w, "privilege/prompt.html", map[string]any{
+		"Pending":    h.app.Privilege.Pending(),
+		"Unlock":     unlock,
+		"Error":      errMsg,
+		"User":       username,
+		"TTLMinutes": int(privilege.PasswordTTL.Minutes()),
+	})
+}
+
+// GET /tools/privilege/prompt — recarregado pelo layout a cada evento do
+// tópico privilege.
+func (h *Handler) PrivilegePrompt(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.renderPrivilegePrompt(w, r.URL.Query().Get("unlock") == "1", "")
+}
+
+// POST /tools/privilege/password
+func (h *Handler) ProvidePrivilegePassword(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	unlock := r.FormValue("unlock") == "1"
+	if err := h.app.Privilege.Provide(r.Context(), r.FormValue("password")); err != nil {
+		msg := err.Error()
+		if !errors.Is(err, privilege.ErrBadPassword) && !errors.Is(err, privilege.ErrPasswordRequired) {
+			h.app.Logger.Warn("validar senha de administrador", "subsystem", "privilege", "err", err)
+		}
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderPrivilegePrompt(w, unlock, msg)
+		return
+	}
+	h.app.Logger.Info("senha de administrador fornecida", "subsystem", "privilege")
+	msg := fmt.Sprintf("Senha aceita; fica em memória por %d minutos", int(privilege.PasswordTTL.Minutes()))
+	w.Header().Set("HX-Trigger", fmt.Sprintf(`{"showToast":{"msg":%q,"type":"success"},"refreshList":true}`, msg))
+}
+
+// POST /tools/privilege/{id}/deny
+func (h *Handler) DenyPrivilegeRequest(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := h.app.Privilege.Deny(chi.URLParam(r, "id")); err != nil {
+		h.operationError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.successToastOnly(w, "Pedido de elevação negado")
+}
+
+// POST /tools/privilege/forget
+func (h *Handler) ForgetPrivilegePassword(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.app.Privilege.Forget()
+	h.successToast(w, "Senha de administrador apagada da memória")
+}
@@ -0,0 +1,227 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/profile"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+// profileExportTTL é por quanto tempo o pacote gerado fica disponível para
+// download.
+const profileExportTTL = 10 * time.Minute
+
+// maxProfileUpload limita o tamanho do pacote aceito no import.
+const maxProfileUpload = 32 << 20
+
+type profileExport struct {
+	data      []byte
+	name      string
+	expiresAt time.Time
+}
+
+// profileExports guarda os pacotes gerados até o download (uso único).
+var (
+	profileExports   = map[string]profileExport{}
+	profileExportsMu sync.Mutex
+)
+
+// GET /tools/profile/export
+func (h *Handler) ExportProfileDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.renderDrawer(w, "Exportar Perfil", "profile/export-drawer.html", map[string]any{
+		"Sections": profile.Sections,
+	})
+}
+
+// POST /tools/profile/export
+// Gera o pacote cifrado e devolve um link de download de uso único.
+func (h *Handler) ExportProfile(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	pass := r.FormValue("passphrase")
+	if len(pass) < 8 {
+		h.errorToast(w, "A passphrase deve ter ao menos 8 caracteres")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	if pass != r.FormValue("confirm") {
+		h.errorToast(w, "As passphrases não conferem")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	sections := r.Form["sections"]
+	if len(sections) == 0 {
+		h.errorToast(w, "Selecione ao menos uma seção")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	data, err := profile.Export(state, h.app.Paths(), profile.ExportOptions{
+		Sections:      sections,
+		Passphrase:    pass,
+		Secrets:       h.app.Vault,
+		GitConfigPath: h.globalConfigPath(),
+	})
+	if err != nil {
+		if errors.Is(err, vault.ErrLocked) {
+			h.vaultError(w, err)
+			return
+		}
+		h.app.Logger.Error("falha ao exportar perfil", "err", err)
+		h.operationError(w, "Erro ao exportar perfil: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	token := newID()
+	name := "factorydev-" + time.Now().Format("20060102-150405") + profile.FileExt
+	profileExportsMu.Lock()
+	for k, e := range profileExports {
+		if time.Now().After(e.expiresAt) {
+			delete(profileExports, k)
+		}
+	}
+	profileExports[token] = profileExport{data: data, name: name, expiresAt: time.Now().Add(profileExportTTL)}
+	profileExportsMu.Unlock()
+
+	h.app.Logger.Info("perfil exportado", "sections", sections, "bytes", len(data))
+	h.renderDrawer(w, "Exportar Perfil", "profile/export-result.html", map[string]any{
+		"URL":  "/tools/profile/export/" + token,
+		"Name": name,
+		"Size": len(data),
+	})
+}
+
+// GET /tools/profile/export/{token}
+func (h *Handler) DownloadProfile(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	profileExportsMu.Lock()
+	e, ok := profileExports[token]
+	delete(profileExports, token)
+	profileExportsMu.Unlock()
+	if !ok || time.Now().After(e.expiresAt) {
+		http.Error(w, "Exportação expirada. Gere o perfil novamente.", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", e.name))
+	_, _ = w.Write(e.data)
+}
+
+// GET /tools/profile/import
+func (h *Handler) ImportProfileDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.renderDrawer(w, "Importar Perfil", "profile/import-drawer.html", map[string]any{
+		"Sections": profile.Sections,
+	})
+}
+
+// POST /tools/profile/import
+// Mescla o pacote enviado no state local e, se pedido, reaplica os blocos do
+// ~/.ssh/config e as regras includeIf.
+func (h *Handler) ImportProfile(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseMultipartForm(maxProfileUpload); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	f, _, err := r.FormFile("file")
+	if err != nil {
+		h.errorToast(w, "Selecione o arquivo do perfil")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, maxProfileUpload))
+	if err != nil {
+		h.operationError(w, "Erro ao ler arquivo", http.StatusBadRequest)
+		return
+	}
+
+	bundle, err := profile.Open(data, r.FormValue("passphrase"))
+	switch {
+	case errors.Is(err, vault.ErrWrongPassphrase):
+		h.errorToast(w, "Passphrase incorreta")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	case err != nil:
+		h.errorToast(w, err.Error())
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	overwrite := r.FormValue("overwrite") == "on"
+	opts := profile.ImportOptions{
+		Sections:  r.Form["sections"],
+		Overwrite: overwrite,
+		Secrets:   h.app.Vault,
+	}
+	var (
+		res   *profile.Result
+		saved *storage.State
+	)
+	err = h.store(r).Update(func(state *storage.State) error {
+		var err error
+		res, err = profile.Merge(state, bundle, h.app.Paths(), opts)
+		saved = state
+		return err
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
+		return
+	}
+
+	var warnings []string
+	if r.FormValue("applySSH") == "on" && len(res.Accounts) > 0 {
+		if err := profile.ApplySSHConfig(saved, res.Accounts, h.app.Paths()); err != nil {
+			h.app.Logger.Warn("falha ao reaplicar ssh config", "err", err)
+			warnings = append(warnings, app.FriendlyMessage(err))
+		}
+	}
+	if r.FormValue("applyIncludeIf") == "on" && bundle.Manifest.Has(profile.SectionIncludeIf) && wantsSection(opts.Sections, profile.SectionIncludeIf) {
+		c, err := profile.ApplyIncludeIf(bundle, h.globalConfigPath(), h.app.Paths(), overwrite)
+		res.Counts[profile.SectionIncludeIf] = c
+		if err != nil {
+			h.app.Logger.Warn("falha ao aplicar includeIf", "err", err)
+			warnings = append(warnings, err.Error())
+		}
+	}
+
+	h.app.Logger.Info("perfil importado", "origin", bundle.Manifest.Hostname, "accounts", len(res.Accounts))
+	h.renderDrawer(w, "Importar Perfil", "profile/import-result.html", map[string]any{
+		"Manifest": bundle.Manifest,
+		"Summary":  res.Summary(),
+		"Warnings": warnings,
+	})
+}
+
+// ── Helpers ───────────────────────────────────────────────────────
+
+// wantsSection indica se id está em sections; lista vazia vale todas.
+func wantsSection(sections []string, id string) bool {
+	if len(sections) == 0 {
+		return true
+	}
+	for _, s := range sections {
+		if s == id {
+			return true
+		}
+	}
+	return false
+}
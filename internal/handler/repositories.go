@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -15,6 +14,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/seuusuario/factorydev/internal/app"
 	igit "github.com/seuusuario/factorydev/internal/git"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/pty"
 	"github.com/seuusuario/factorydev/internal/storage"
 )
 
@@ -39,7 +40,7 @@ type repoView struct {
 
 func (h *Handler) Repositories(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -101,7 +102,7 @@ func (h *Handler) Repositories(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) RepoStatus(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -135,7 +136,7 @@ func (h *Handler) NewCloneDrawer(w http.ResponseWriter, r *http.Request) {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
 	}
-	defaultDest := filepath.Join(h.app.Paths.Home, "workspace", "repo")
+	defaultDest := filepath.Join(h.app.Paths().Home, "workspace", "repo")
 	h.renderDrawer(w, "Clonar Repositório", "repos/clone-drawer.html", map[string]any{
 		"Accounts":    accounts,
 		"DefaultDest": defaultDest,
@@ -161,7 +162,7 @@ func (h *Handler) StartCloneJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -173,7 +174,7 @@ func (h *Handler) StartCloneJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	identityPath := resolvePrivateKeyPath(account, state.Keys, h.app.Paths.Home)
+	identityPath := resolvePrivateKeyPath(account, state.Keys, h.app.Paths().Home)
 	if identityPath == "" {
 		h.render(w, "repos/clone-progress.html", map[string]any{
 			"Done": true, "OK": false,
@@ -198,46 +199,40 @@ func (h *Handler) StartCloneJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job := &CloneJob{ID: newID()}
-	h.cloneMu.Lock()
-	h.cloneJobs[job.ID] = job
-	h.cloneMu.Unlock()
-
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
-
-		output, cloneErr := h.app.GitService.CloneRepo(ctx, sshURL, destDir, identityPath)
+	store, _, release := h.holdStore(r)
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "clone",
+		Title:   "Clone " + rawURL,
+		Timeout: 5 * time.Minute,
+		Meta:    map[string]string{"accountId": accountID, "path": destDir},
+	}, func(run *jobs.Run) error {
+		defer release()
+		cloneErr := h.app.GitService.CloneRepoTo(run.Context(), sshURL, destDir, identityPath, run)
 		if cloneErr != nil {
 			h.app.Logger.Error("falha no clone", "alias", account.HostAlias, "err", cloneErr)
-			h.cloneMu.Lock()
-			job.Done, job.OK = true, false
-			job.Output = output
-			job.Error = app.FriendlyMessage(cloneErr)
-			h.cloneMu.Unlock()
-			return
+			return friendlyErr(cloneErr)
+		}
+		if h.app.Commands.DryRun() {
+			run.Log("Dry-run: o repositório não foi registrado")
+			return nil
 		}
 
-		repoName := repoNameFromURL(rawURL)
 		repo := storage.Repository{
 			ID:        newID(),
 			AccountID: accountID,
-			Name:      repoName,
+			Name:      igit.RepoNameFromURL(rawURL),
 			URL:       rawURL,
 			LocalPath: destDir,
 			ClonedAt:  time.Now(),
 		}
-		if st, loadErr := h.app.Storage.LoadState(); loadErr == nil {
+		if saveErr := store.Update(func(st *storage.State) error {
 			st.Repositories = append(st.Repositories, repo)
-			if saveErr := h.app.Storage.SaveState(st); saveErr != nil {
-				h.app.Logger.Warn("falha ao salvar repo no state", "err", saveErr)
-			}
+			return nil
+		}); saveErr != nil {
+			h.app.Logger.Warn("falha ao salvar repo no state", "err", saveErr)
 		}
-
-		h.cloneMu.Lock()
-		job.Done, job.OK = true, true
-		h.cloneMu.Unlock()
-	}()
+		return nil
+	})
 
 	h.render(w, "repos/clone-progress.html", map[string]any{
 		"ID": job.ID, "Done": false,
@@ -248,15 +243,9 @@ func (h *Handler) CloneJobStatus(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
 
-	h.cloneMu.Lock()
-	job, ok := h.cloneJobs[id]
-	var done, jobOK bool
-	var errMsg, output string
-	if ok {
-		done, jobOK = job.Done, job.OK
-		errMsg, output = job.Error, job.Output
-	}
-	h.cloneMu.Unlock()
+	job, ok := h.app.Jobs.Get(id)
+	done, jobOK := job.Done(), job.OK()
+	errMsg, output := job.Error, job.Output
 
 	if !ok {
 		h.render(w, "repos/clone-progress.html", map[string]any{
@@ -287,25 +276,17 @@ func (h *Handler) CloneJobStatus(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteRepository(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := -1
-	for i, repo := range state.Repositories {
-		if repo.ID == id {
-			idx = i
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i, repo := range state.Repositories {
+			if repo.ID == id {
+				state.Repositories = append(state.Repositories[:i], state.Repositories[i+1:]...)
+				return nil
+			}
 		}
-	}
-	if idx < 0 {
-		h.operationError(w, "Repositório não encontrado", http.StatusNotFound)
-		return
-	}
-	state.Repositories = append(state.Repositories[:idx], state.Repositories[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Repositório não encontrado")
 		return
 	}
 	h.repoSuccessToast(w, "Repositório removido com sucesso!")
@@ -320,16 +301,30 @@ type excludeOption struct {
 
 func (h *Handler) ScanReposDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	opts := make([]excludeOption, 0, len(igit.DefaultScanExcludes))
-	for _, d := range igit.DefaultScanExcludes {
+	excludes := h.scanExcludes()
+	opts := make([]excludeOption, 0, len(excludes))
+	for _, d := range excludes {
 		opts = append(opts, excludeOption{Name: d, Default: true})
 	}
+	roots := h.app.Config().ScanRoots
+	if len(roots) == 0 {
+		roots = []string{h.app.Paths().Home}
+	}
 	h.renderDrawer(w, "Escanear Repositórios", "repos/scan-drawer.html", map[string]any{
-		"DefaultPath":    h.app.Paths.Home,
+		"DefaultPath":    roots[0],
+		"ScanRoots":      roots,
 		"ExcludeOptions": opts,
 	})
 }
 
+// scanExcludes retorna os diretórios ignorados configurados ou os padrões.
+func (h *Handler) scanExcludes() []string {
+	if len(h.app.Config().ScanExcludes) > 0 {
+		return h.app.Config().ScanExcludes
+	}
+	return igit.DefaultScanExcludes
+}
+
 func (h *Handler) ValidateScanPath(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	if err := r.ParseForm(); err != nil {
@@ -342,7 +337,7 @@ func (h *Handler) ValidateScanPath(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -355,7 +350,7 @@ func (h *Handler) ValidateScanPath(w http.ResponseWriter, r *http.Request) {
 
 	excludeDirs := r.Form["excludeDirs"]
 	if len(excludeDirs) == 0 {
-		excludeDirs = igit.DefaultScanExcludes
+		excludeDirs = h.scanExcludes()
 	}
 
 	svc := igit.NewService()
@@ -398,33 +393,28 @@ func (h *Handler) ImportScannedRepos(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
-	managedPaths := make(map[string]bool)
-	for _, repo := range state.Repositories {
-		managedPaths[repo.LocalPath] = true
-	}
-
 	imported := 0
-	for _, p := range paths {
-		if managedPaths[p] {
-			continue
+	err := h.store(r).Update(func(state *storage.State) error {
+		managedPaths := make(map[string]bool)
+		for _, repo := range state.Repositories {
+			managedPaths[repo.LocalPath] = true
 		}
-		state.Repositories = append(state.Repositories, storage.Repository{
-			ID:        newID(),
-			Name:      filepath.Base(p),
-			LocalPath: p,
-			ClonedAt:  time.Now(),
-		})
-		imported++
-	}
-
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		for _, p := range paths {
+			if managedPaths[p] {
+				continue
+			}
+			state.Repositories = append(state.Repositories, storage.Repository{
+				ID:        newID(),
+				Name:      filepath.Base(p),
+				LocalPath: p,
+				ClonedAt:  time.Now(),
+			})
+			imported++
+		}
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 
@@ -442,7 +432,7 @@ func (h *Handler) StartPullJob(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -476,27 +466,15 @@ func (h *Handler) StartPullJob(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	job := &GitOpJob{ID: newID()}
-	h.pullMu.Lock()
-	h.pullJobs[job.ID] = job
-	h.pullMu.Unlock()
-
 	localPath := repo.LocalPath
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-		defer cancel()
-		svc := igit.NewService()
-		output, pullErr := svc.Pull(ctx, localPath, identityFile)
-		h.pullMu.Lock()
-		job.Output = output
-		if pullErr != nil {
-			job.Done, job.OK = true, false
-			job.Error = app.FriendlyMessage(pullErr)
-		} else {
-			job.Done, job.OK = true, true
-		}
-		h.pullMu.Unlock()
-	}()
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "pull",
+		Title:   "Pull " + repo.Name,
+		Timeout: 3 * time.Minute,
+		Meta:    map[string]string{"repoId": id},
+	}, func(run *jobs.Run) error {
+		return friendlyErr(igit.NewService().PullTo(run.Context(), localPath, identityFile, run))
+	})
 
 	h.render(w, "repos/pull-progress.html", map[string]any{
 		"ID": job.ID, "Done": false, "RepoID": id,
@@ -508,15 +486,9 @@ func (h *Handler) PullJobStatus(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobId")
 	repoID := r.URL.Query().Get("repoId")
 
-	h.pullMu.Lock()
-	job, ok := h.pullJobs[jobID]
-	var done, jobOK bool
-	var errMsg, output string
-	if ok {
-		done, jobOK = job.Done, job.OK
-		errMsg, output = job.Error, job.Output
-	}
-	h.pullMu.Unlock()
+	job, ok := h.app.Jobs.Get(jobID)
+	done, jobOK := job.Done(), job.OK()
+	errMsg, output := job.Error, job.Output
 
 	if !ok {
 		h.render(w, "repos/pull-progress.html", map[string]any{
@@ -558,7 +530,7 @@ func (h *Handler) NewBranchHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -593,7 +565,7 @@ func (h *Handler) GetRepoTab(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	tab := chi.URLParam(r, "tab")
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -663,7 +635,7 @@ func (h *Handler) CheckoutBranch(w http.ResponseWriter, r *http.Request) {
 		h.errorToast(w, "Nome do branch é obrigatório")
 		return
 	}
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -704,7 +676,7 @@ func (h *Handler) StartPullAllJob(w http.ResponseWriter, r *http.Request) {
 	}
 	force := r.FormValue("force") == "1" || r.FormValue("force") == "true"
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -714,6 +686,19 @@ func (h *Handler) StartPullAllJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	job := h.startPullAll(state, force)
+
+	h.render(w, "repos/pull-all-progress.html", map[string]any{
+		"ID":      job.ID,
+		"Done":    false,
+		"Force":   force,
+		"Results": job.Steps,
+	})
+}
+
+// startPullAll dispara o pull de todos os repositórios do state, em
+// paralelo, como um job com uma etapa por repositório.
+func (h *Handler) startPullAll(state *storage.State, force bool) jobs.Job {
 	// Mapa accountID -> arquivo de identidade
 	keyMap := make(map[string]string)
 	for _, acc := range state.Accounts {
@@ -728,63 +713,55 @@ func (h *Handler) StartPullAllJob(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	job := &PullAllJob{
-		ID:      newID(),
-		Force:   force,
-		Total:   len(state.Repositories),
-		Results: make([]PullAllResult, len(state.Repositories)),
-	}
-	for i, repo := range state.Repositories {
-		job.Results[i] = PullAllResult{RepoName: repo.Name}
-	}
-
-	h.pullAllMu.Lock()
-	h.pullAllJobs[job.ID] = job
-	h.pullAllMu.Unlock()
-
-	for i, repo := range state.Repositories {
-		go func(idx int, r storage.Repository) {
-			identityFile := keyMap[r.AccountID]
-			ctx, cancel := context.WithTimeout(context.Background(), 3*time.Minute)
-			defer cancel()
-
-			svc := igit.NewService()
-			var output string
-			var pullErr error
-			if force {
-				output, pullErr = svc.PullForce(ctx, r.LocalPath, identityFile)
-			} else {
-				output, pullErr = svc.Pull(ctx, r.LocalPath, identityFile)
-			}
-
-			h.pullAllMu.Lock()
-			job.Results[idx].Done = true
-			job.Results[idx].Output = output
-			if pullErr != nil {
-				job.Results[idx].OK = false
-				job.Results[idx].Error = app.FriendlyMessage(pullErr)
-			} else {
-				job.Results[idx].OK = true
-			}
-			allDone := true
-			for _, res := range job.Results {
-				if !res.Done {
-					allDone = false
-					break
+	repos := append([]storage.Repository(nil), state.Repositories...)
+	names := make([]string, len(repos))
+	for i, repo := range repos {
+		names[i] = repo.Name
+	}
+	title := "Pull — todos os repositórios"
+	if force {
+		title = "Pull Force — todos os repositórios"
+	}
+
+	return h.app.Jobs.Start(jobs.Options{
+		Kind:  "pull-all",
+		Title: title,
+		Steps: names,
+		Meta:  map[string]string{"force": strconv.FormatBool(force)},
+	}, func(run *jobs.Run) error {
+		var (
+			wg     sync.WaitGroup
+			mu     sync.Mutex
+			failed int
+		)
+		for i, repo := range repos {
+			wg.Add(1)
+			go func(idx int, r storage.Repository) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(run.Context(), 3*time.Minute)
+				defer cancel()
+
+				svc := igit.NewService()
+				var output string
+				var pullErr error
+				if force {
+					output, pullErr = svc.PullForce(ctx, r.LocalPath, keyMap[r.AccountID])
+				} else {
+					output, pullErr = svc.Pull(ctx, r.LocalPath, keyMap[r.AccountID])
 				}
-			}
-			if allDone {
-				job.Done = true
-			}
-			h.pullAllMu.Unlock()
-		}(i, repo)
-	}
-
-	h.render(w, "repos/pull-all-progress.html", map[string]any{
-		"ID":      job.ID,
-		"Done":    false,
-		"Force":   force,
-		"Results": job.Results,
+				if pullErr != nil {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+				run.FinishStep(idx, output, friendlyErr(pullErr))
+			}(i, repo)
+		}
+		wg.Wait()
+		if failed > 0 {
+			return fmt.Errorf("%d de %d repositórios falharam", failed, len(repos))
+		}
+		return nil
 	})
 }
 
@@ -792,17 +769,8 @@ func (h *Handler) PullAllJobStatus(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
 
-	h.pullAllMu.Lock()
-	job, ok := h.pullAllJobs[id]
-	var done, force bool
-	var results []PullAllResult
-	if ok {
-		done = job.Done
-		force = job.Force
-		results = make([]PullAllResult, len(job.Results))
-		copy(results, job.Results)
-	}
-	h.pullAllMu.Unlock()
+	job, ok := h.app.Jobs.Get(id)
+	done, force, results := job.Done(), job.Meta["force"] == "true", job.Steps
 
 	if !ok {
 		h.operationError(w, "Job não encontrado", http.StatusNotFound)
@@ -810,14 +778,7 @@ func (h *Handler) PullAllJobStatus(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if done {
-		allOK := true
-		for _, res := range results {
-			if !res.OK {
-				allOK = false
-				break
-			}
-		}
-		if allOK {
+		if job.OK() {
 			w.Header().Set("HX-Trigger", `{"showToast":{"msg":"Pull concluído em todos os repositórios!","type":"success"}}`)
 		}
 		w.WriteHeader(286)
@@ -839,7 +800,7 @@ func (h *Handler) SetRepoGitConfigHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -884,11 +845,19 @@ func (h *Handler) SetRepoGitConfigHandler(w http.ResponseWriter, r *http.Request
 	h.successToastOnly(w, "Configuração git local salva!")
 }
 
+// POST /tools/repos/{id}/terminal
+// Abre um shell no diretório do repositório, no terminal embutido. Com
+// native=1, ou sem suporte a PTY neste sistema, abre o emulador de terminal
+// do desktop.
 func (h *Handler) OpenRepoTerminal(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
+	if r.FormValue("native") == "" && pty.Supported() {
+		h.startRepoTerminal(w, r, id, false)
+		return
+	}
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -916,56 +885,18 @@ func (h *Handler) OpenRepoTerminal(w http.ResponseWriter, r *http.Request) {
 // ── Helpers internos ──────────────────────────────────────────────
 
 func fetchRepoStatus(localPath string) (branch, statusShort string, isClean, accessible bool) {
-	if _, err := os.Stat(localPath); err != nil {
-		return "?", "caminho não encontrado", false, false
-	}
-	accessible = true
-
-	ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
-	defer cancel()
-
-	branchOut, err := exec.CommandContext(ctx, "git", "-C", localPath, "branch", "--show-current").Output()
-	if err == nil {
-		branch = strings.TrimSpace(string(branchOut))
-	}
-	if branch == "" {
-		hashOut, _ := exec.CommandContext(ctx, "git", "-C", localPath, "rev-parse", "--short", "HEAD").Output()
-		branch = "HEAD:" + strings.TrimSpace(string(hashOut))
-	}
-
-	statusOut, err := exec.CommandContext(ctx, "git", "-C", localPath, "status", "--short").Output()
-	if err == nil {
-		statusStr := strings.TrimSpace(string(statusOut))
-		if statusStr == "" {
-			isClean = true
-			statusShort = "limpo"
-		} else {
-			n := len(strings.Split(statusStr, "\n"))
-			statusShort = fmt.Sprintf("%d alteração(ões)", n)
-		}
-	} else {
-		statusShort = "erro ao ler"
-	}
-	return
-}
-
-func repoNameFromURL(rawURL string) string {
-	rawURL = strings.TrimSuffix(rawURL, ".git")
-	parts := strings.Split(rawURL, "/")
-	if len(parts) > 0 {
-		return parts[len(parts)-1]
-	}
-	return rawURL
+	st := igit.Status(localPath)
+	return st.Branch, st.Summary, st.Clean, st.Accessible
 }
 
 func (h *Handler) repoAccounts() ([]reposAccountView, error) {
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		return nil, err
 	}
 	out := make([]reposAccountView, 0, len(state.Accounts))
 	for _, a := range state.Accounts {
-		privPath := resolvePrivateKeyPath(a, state.Keys, h.app.Paths.Home)
+		privPath := resolvePrivateKeyPath(a, state.Keys, h.app.Paths().Home)
 		_, err := os.Stat(privPath)
 		out = append(out, reposAccountView{
 			ID:        a.ID,
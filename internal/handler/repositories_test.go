@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// gatedExecutor segura cada comando até gate fechar.
+type gatedExecutor struct {
+	*command.Fake
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (g *gatedExecutor) Run(ctx context.Context, c command.Cmd) error {
+	select {
+	case g.started <- struct{}{}:
+	default:
+	}
+	<-g.gate
+	return g.Fake.Run(ctx, c)
+}
+
+// TestCloneJobKeepsWorkspace troca de workspace com o clone em andamento: o
+// repositório é gravado no workspace em que o job começou.
+func TestCloneJobKeepsWorkspace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	exec := &gatedExecutor{Fake: command.NewFake(), started: make(chan struct{}, 1), gate: make(chan struct{})}
+	defer command.SetDefault(exec)()
+
+	keyPath := filepath.Join(home, "id_work")
+	if err := os.WriteFile(keyPath, []byte("PRIVATE"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err = a.Storage().Update(func(st *storage.State) error {
+		st.Accounts = []storage.Account{{ID: "a1", Name: "work", HostAlias: "github-work", IdentityFile: keyPath}}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := a.Workspace()
+	if err := a.CreateWorkspace(config.Workspace{Name: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+
+	form := url.Values{"accountID": {"a1"}, "repoURL": {"git@github.com:org/api.git"}, "destDir": {filepath.Join(home, "src", "api")}}
+	req := httptest.NewRequest("POST", "/tools/repos/clone", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+	New(a).StartCloneJob(rec, req)
+	if rec.Code != 200 || len(a.Jobs.List()) != 1 {
+		t.Fatalf("clone = %d %s", rec.Code, rec.Body)
+	}
+	job := a.Jobs.List()[0]
+
+	select {
+	case <-exec.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("git clone não foi chamado")
+	}
+	if err := a.SwitchWorkspace("acme"); err != nil {
+		t.Fatalf("switch: %v", err)
+	}
+	close(exec.gate)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if j, err := a.Jobs.Wait(ctx, job.ID); err != nil || !j.OK() {
+		t.Fatalf("job: %v %+v", err, j)
+	}
+
+	state, err := a.Storage().LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Repositories) != 0 {
+		t.Fatalf("repositório gravado no workspace novo: %+v", state.Repositories)
+	}
+	_, old, err := a.OpenWorkspace(first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer old.Close()
+	state, err = old.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Repositories) != 1 || state.Repositories[0].Name != "api" {
+		t.Fatalf("repositórios no workspace original = %+v", state.Repositories)
+	}
+}
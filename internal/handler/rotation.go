@@ -0,0 +1,411 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/gitconfig"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+type rotationFormData struct {
+	Old    storage.Key
+	New    storage.Key
+	Refs   storage.KeyReferences
+	Push   map[string]bool // servidores cujo authorized_keys será atualizado
+	Errors map[string]string
+}
+
+// rotationView é uma rotação com os nomes das chaves envolvidas.
+type rotationView struct {
+	storage.KeyRotation
+	OldName string
+	NewName string
+}
+
+// rotationPlan é o que o job de rotação precisa, copiado do state no início.
+type rotationPlan struct {
+	ID       string
+	Old, New storage.Key
+	Servers  []storage.Server
+	Push     map[string]bool
+}
+
+// GET /tools/keys/{id}/rotate
+func (h *Handler) RotateKeyDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	old := state.FindKey(chi.URLParam(r, "id"))
+	if old == nil || !old.ArchivedAt.IsZero() {
+		h.operationError(w, "Chave não encontrada", http.StatusNotFound)
+		return
+	}
+	refs := state.KeyReferences(old.ID)
+	push := make(map[string]bool, len(refs.Servers))
+	for _, srv := range refs.Servers {
+		push[srv.ID] = true
+	}
+	h.renderDrawer(w, "Rotacionar chave — "+old.Name, "keys/rotate-drawer.html", rotationFormData{
+		Old: *old,
+		New: storage.Key{
+			Name:    old.Name,
+			Alias:   state.UniqueKeyAlias(old.Alias + "-" + time.Now().Format("20060102")),
+			Type:    old.Type,
+			Bits:    old.Bits,
+			Comment: old.Comment,
+		},
+		Refs:   refs,
+		Push:   push,
+		Errors: map[string]string{},
+	})
+}
+
+// POST /tools/keys/{id}/rotate
+// Gera a chave substituta e inicia o job que atualiza os servidores, aponta
+// contas, servidores e identidades para ela, reaplica o ssh config e
+// arquiva a chave antiga.
+func (h *Handler) RotateKey(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	if h.app.Commands.DryRun() {
+		h.errorToast(w, "Desative o modo dry-run para rotacionar chaves: a rotação altera servidores remotos")
+		w.WriteHeader(http.StatusConflict)
+		return
+	}
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	old := state.FindKey(chi.URLParam(r, "id"))
+	if old == nil || !old.ArchivedAt.IsZero() {
+		h.operationError(w, "Chave não encontrada", http.StatusNotFound)
+		return
+	}
+
+	refs := state.KeyReferences(old.ID)
+	push := make(map[string]bool)
+	for _, id := range r.Form["push"] {
+		push[id] = true
+	}
+	passphrase := []byte(r.FormValue("passphrase"))
+	k := storage.Key{
+		ID:        newID(),
+		Name:      strings.TrimSpace(r.FormValue("name")),
+		Alias:     strings.TrimSpace(r.FormValue("alias")),
+		Type:      r.FormValue("keyType"),
+		Bits:      parseBits(r.FormValue("bits")),
+		Comment:   strings.TrimSpace(r.FormValue("comment")),
+		Protected: len(passphrase) > 0,
+		Source:    "generated",
+		CA:        old.CA,
+		CreatedAt: time.Now(),
+	}
+	if k.Alias == "" {
+		k.Alias = storage.SanitizeAlias(k.Name)
+	}
+
+	k, errs, err := h.createKey(r, k, passphrase)
+	if len(errs) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderDrawer(w, "Rotacionar chave — "+old.Name, "keys/rotate-drawer.html", rotationFormData{
+			Old: *old, New: k, Refs: refs, Push: push, Errors: errs,
+		})
+		return
+	}
+	if err != nil {
+		h.updateFailed(w, err, "")
+		return
+	}
+
+	// O job usa ssh em BatchMode: a nova chave protegida só funciona no teste
+	// de login se estiver no agent.
+	if k.Protected && len(refs.Servers) > 0 {
+		if err := ssh.AddToAgent(k.PrivateKeyPath, passphrase, k.Name, ssh.AgentOptions{Lifetime: time.Hour}); err != nil {
+			h.app.Logger.Warn("nova chave não carregada no ssh-agent", "key", k.Alias, "err", err)
+		}
+	}
+
+	plan := rotationPlan{ID: newID(), Old: *old, New: k, Servers: refs.Servers, Push: push}
+	if err := h.store(r).Update(func(state *storage.State) error {
+		state.KeyRotations = append(state.KeyRotations, storage.KeyRotation{
+			ID:        plan.ID,
+			OldKeyID:  old.ID,
+			NewKeyID:  k.ID,
+			Status:    "running",
+			StartedAt: time.Now(),
+		})
+		return nil
+	}); err != nil {
+		h.updateFailed(w, err, "")
+		return
+	}
+
+	steps := make([]string, 0, len(plan.Servers)+1)
+	for _, srv := range plan.Servers {
+		steps = append(steps, "Servidor "+srv.Name)
+	}
+	steps = append(steps, "Contas, identidades e ssh config")
+	store, paths, release := h.holdStore(r)
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:  "key-rotation",
+		Title: "Rotação da chave " + old.Name,
+		Steps: steps,
+		Meta:  map[string]string{"rotationId": plan.ID, "oldKeyId": old.ID, "newKeyId": k.ID},
+	}, func(run *jobs.Run) error {
+		defer release()
+		return h.runRotation(run, store, paths, plan)
+	})
+	h.app.Logger.Info("rotação de chave iniciada", "old", old.Alias, "new", k.Alias, "job", job.ID)
+
+	h.renderDrawer(w, "Rotacionar chave — "+old.Name, "keys/rotate-progress.html", map[string]any{
+		"ID": job.ID, "Done": false, "Title": job.Title, "Steps": job.Steps,
+	})
+}
+
+// GET /tools/keys/rotate-jobs/{jobId}
+func (h *Handler) RotateKeyJobStatus(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	job, ok := h.app.Jobs.Get(chi.URLParam(r, "jobId"))
+	if !ok {
+		h.operationError(w, "Job não encontrado", http.StatusNotFound)
+		return
+	}
+	if job.Done() {
+		// A lista muda mesmo com falhas: a nova chave já existe.
+		msg, typ := "Rotação concluída: a chave antiga foi arquivada", "success"
+		if !job.OK() {
+			msg, typ = job.Error, "error"
+		}
+		w.Header().Set("HX-Trigger", fmt.Sprintf(`{"showToast":{"msg":%q,"type":%q},"refreshList":true}`, msg, typ))
+		w.WriteHeader(286)
+	}
+	h.render(w, "keys/rotate-progress.html", map[string]any{
+		"ID":    job.ID,
+		"Title": job.Title,
+		"Done":  job.Done(),
+		"OK":    job.OK(),
+		"Error": job.Error,
+		"Steps": job.Steps,
+	})
+}
+
+// runRotation é o corpo do job de rotação. Cada servidor marcado recebe a
+// nova chave (conectando com a antiga), testa o login com ela e só então
+// perde a antiga; servidores que falham continuam na chave antiga, que
+// nesse caso não é arquivada.
+func (h *Handler) runRotation(run *jobs.Run, store storage.Storage, paths *config.Paths, plan rotationPlan) error {
+	var steps []storage.RotationStep
+	repoint := make(map[string]bool)
+	for i, srv := range plan.Servers {
+		step := storage.RotationStep{Kind: "server", RefID: srv.ID, Name: srv.Name, Status: "done"}
+		if !plan.Push[srv.ID] {
+			// Sem a nova chave no authorized_keys, o servidor continua com a
+			// antiga; apontá-lo para a nova cortaria o acesso.
+			step.Status, step.Message = "skipped", "authorized_keys não alterado; servidor mantém a chave antiga"
+			steps = append(steps, step)
+			run.FinishStep(i, step.Message, nil)
+			continue
+		}
+		var out bytes.Buffer
+		err := h.rotateServer(run.Context(), srv, plan, &out)
+		switch {
+		case err == nil:
+			step.Message = "nova chave autorizada e testada; chave antiga removida"
+			repoint[srv.ID] = true
+		case errors.Is(err, errOldKeyKept):
+			step.Status, step.Message = "failed", err.Error()
+			repoint[srv.ID] = true
+		default:
+			step.Status, step.Message = "failed", err.Error()
+		}
+		steps = append(steps, step)
+		run.Log("%s: %s", srv.Name, step.Message)
+		var stepErr error
+		if step.Status == "failed" {
+			stepErr = errors.New(step.Message)
+		}
+		run.FinishStep(i, out.String(), stepErr)
+	}
+
+	// Aponta os registros para a nova chave e arquiva a antiga se nada
+	// mais a usa.
+	var (
+		applied    []storage.Account
+		identities []storage.GitIdentity
+		archived   bool
+	)
+	err := store.Update(func(state *storage.State) error {
+		for i := range state.Accounts {
+			if state.Accounts[i].KeyID == plan.Old.ID {
+				state.Accounts[i].KeyID = plan.New.ID
+				state.Accounts[i].UpdatedAt = time.Now()
+				applied = append(applied, state.Accounts[i])
+			}
+		}
+		for i := range state.Servers {
+			if state.Servers[i].KeyID == plan.Old.ID && repoint[state.Servers[i].ID] {
+				state.Servers[i].KeyID = plan.New.ID
+			}
+		}
+		for i := range state.Identities {
+			if state.Identities[i].KeyID == plan.Old.ID {
+				state.Identities[i].KeyID = plan.New.ID
+				identities = append(identities, state.Identities[i])
+			}
+		}
+		for i := range applied {
+			applied[i] = state.ResolveKeyFiles(applied[i])
+		}
+		if idx := findKeyIndex(state.Keys, plan.Old.ID); idx >= 0 && state.KeyReferences(plan.Old.ID).Empty() {
+			state.Keys[idx].ArchivedAt = time.Now()
+			state.Keys[idx].ReplacedBy = plan.New.ID
+			archived = true
+		}
+		return nil
+	})
+	last := len(plan.Servers)
+	if err != nil {
+		run.FinishStep(last, "", err)
+		h.finishRotation(store, plan.ID, run.ID(), steps, "failed")
+		return fmt.Errorf("atualizar o state: %w", err)
+	}
+
+	var out strings.Builder
+	failed := 0
+	for _, a := range applied {
+		step := storage.RotationStep{Kind: "account", RefID: a.ID, Name: a.Name, Status: "done", Message: "bloco FDEV reaplicado"}
+		if err := ssh.ApplyAccount(a, paths); err != nil {
+			step.Status, step.Message = "failed", "reaplicar ssh config: "+app.FriendlyMessage(err)
+			failed++
+		}
+		fmt.Fprintf(&out, "%s (%s): %s\n", a.Name, a.HostAlias, step.Message)
+		steps = append(steps, step)
+	}
+	signing := h.rotateSigningKey(plan)
+	for _, id := range identities {
+		step := storage.RotationStep{Kind: "identity", RefID: id.ID, Name: id.Name, Status: "done", Message: "chave de assinatura atualizada"}
+		if signing != nil {
+			step.Status, step.Message = "failed", signing.Error()
+			failed++
+		}
+		fmt.Fprintf(&out, "%s: %s\n", id.Name, step.Message)
+		steps = append(steps, step)
+	}
+	var stepErr error
+	if failed > 0 {
+		stepErr = fmt.Errorf("%d registro(s) com falha", failed)
+	}
+	run.FinishStep(last, out.String(), stepErr)
+
+	status := rotationStatus(steps, archived)
+	h.finishRotation(store, plan.ID, run.ID(), steps, status)
+	h.app.Logger.Info("rotação de chave concluída", "old", plan.Old.Alias, "new", plan.New.Alias, "status", status)
+	if !archived {
+		return fmt.Errorf("a chave %s continua em uso e não foi arquivada; veja as etapas ignoradas ou com falha", plan.Old.Name)
+	}
+	if status != "completed" {
+		return errors.New("rotação concluída com falhas; veja as etapas")
+	}
+	return nil
+}
+
+// errOldKeyKept indica que a nova chave funciona no servidor, mas a
+// antiga não pôde ser removida do authorized_keys.
+var errOldKeyKept = errors.New("nova chave ativa, mas a antiga continua autorizada")
+
+// rotateServer autoriza a nova chave no servidor, testa o login com ela e
+// remove a antiga.
+func (h *Handler) rotateServer(ctx context.Context, srv storage.Server, plan rotationPlan, out *bytes.Buffer) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+	oldTarget := serverTarget(&srv, plan.Old.PrivateKeyPath)
+	newTarget := serverTarget(&srv, plan.New.PrivateKeyPath)
+	if err := ssh.AuthorizeKeyTo(ctx, oldTarget, plan.New.PublicKeyPath, out); err != nil {
+		return fmt.Errorf("autorizar a nova chave: %v", err)
+	}
+	if err := ssh.TestServerTo(ctx, newTarget, out); err != nil {
+		return fmt.Errorf("login com a nova chave falhou: %v", err)
+	}
+	if err := ssh.RevokeKeyTo(ctx, newTarget, plan.Old.PublicKeyPath, out); err != nil {
+		return fmt.Errorf("%w: %v", errOldKeyKept, err)
+	}
+	return nil
+}
+
+// rotateSigningKey troca o user.signingkey do gitconfig global quando ele
+// aponta para a chave antiga.
+func (h *Handler) rotateSigningKey(plan rotationPlan) error {
+	cfg, _ := gitconfig.ParseGlobalConfig(h.globalConfigPath())
+	if cfg["user.signingkey"] != plan.Old.PublicKeyPath {
+		return nil
+	}
+	return gitconfig.SetGlobalValue("user.signingkey", plan.New.PublicKeyPath)
+}
+
+// finishRotation grava o resultado no registro da rotação.
+func (h *Handler) finishRotation(store storage.Storage, id, jobID string, steps []storage.RotationStep, status string) {
+	err := store.Update(func(state *storage.State) error {
+		idx := slices.IndexFunc(state.KeyRotations, func(kr storage.KeyRotation) bool { return kr.ID == id })
+		if idx < 0 {
+			return errNotFound
+		}
+		kr := &state.KeyRotations[idx]
+		kr.JobID, kr.Steps, kr.Status, kr.FinishedAt = jobID, steps, status, time.Now()
+		return nil
+	})
+	if err != nil {
+		h.app.Logger.Error("gravar rotação de chave", "id", id, "err", err)
+	}
+}
+
+// rotationStatus resume as etapas: "completed" sem falhas e com a chave
+// antiga arquivada, "failed" quando nada deu certo e "partial" no resto.
+func rotationStatus(steps []storage.RotationStep, archived bool) string {
+	failed := 0
+	for _, s := range steps {
+		if s.Status == "failed" {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0 && archived:
+		return "completed"
+	case len(steps) > 0 && failed == len(steps):
+		return "failed"
+	}
+	return "partial"
+}
+
+// rotationViews monta o histórico de rotações, da mais recente para a mais
+// antiga.
+func rotationViews(state *storage.State) []rotationView {
+	names := make(map[string]string, len(state.Keys))
+	for _, k := range state.Keys {
+		names[k.ID] = k.Name
+	}
+	out := make([]rotationView, 0, len(state.KeyRotations))
+	for _, kr := range state.KeyRotations {
+		out = append(out, rotationView{KeyRotation: kr, OldName: names[kr.OldKeyID], NewName: names[kr.NewKeyID]})
+	}
+	slices.Reverse(out)
+	return out
+}
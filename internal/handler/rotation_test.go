@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// TestRunRotation roda a rotação sem servidores marcados (sem rede): contas
+// e identidades passam para a nova chave e o ssh config é reaplicado; o
+// servidor não atualizado fica com a antiga, que só é arquivada quando
+// nada mais a usa.
+func TestRunRotation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	h := New(a)
+
+	keys := make([]storage.Key, 2)
+	for i, alias := range []string{"work", "work-new"} {
+		res, err := ssh.GenerateKeyFull(alias, "dev@host", "ed25519", 0, nil, a.Paths())
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys[i] = storage.Key{ID: alias, Name: alias, Alias: alias, Type: "ed25519", PrivateKeyPath: res.PrivateKeyPath, PublicKeyPath: res.PublicKeyPath}
+	}
+	old, nk := keys[0], keys[1]
+	srv := storage.Server{ID: "s1", Name: "vps", Host: "10.0.0.1", Port: 22, User: "deploy", KeyID: old.ID}
+	err = a.Storage().Update(func(st *storage.State) error {
+		st.Keys = keys
+		st.Accounts = []storage.Account{{ID: "a1", Name: "Work", HostName: "github.com", HostAlias: "gh-work", KeyID: old.ID}}
+		st.Servers = []storage.Server{srv}
+		st.Identities = []storage.GitIdentity{{ID: "i1", Name: "Dev", Email: "dev@example.com", KeyID: old.ID}}
+		st.KeyRotations = []storage.KeyRotation{{ID: "r1", OldKeyID: old.ID, NewKeyID: nk.ID, Status: "running"}, {ID: "r2", OldKeyID: old.ID, NewKeyID: nk.ID, Status: "running"}}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotate := func(plan rotationPlan) jobs.Job {
+		t.Helper()
+		var steps []string
+		for _, s := range plan.Servers {
+			steps = append(steps, s.Name)
+		}
+		steps = append(steps, "contas")
+		job := a.Jobs.Start(jobs.Options{Kind: "key-rotation", Steps: steps}, func(run *jobs.Run) error {
+			return h.runRotation(run, a.Storage(), a.Paths(), plan)
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		job, err := a.Jobs.Wait(ctx, job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return job
+	}
+
+	job := rotate(rotationPlan{ID: "r1", Old: old, New: nk, Servers: []storage.Server{srv}, Push: map[string]bool{}})
+	if job.OK() {
+		t.Fatal("rotação com servidor não atualizado deveria terminar parcial")
+	}
+	st, err := a.Storage().LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Accounts[0].KeyID != nk.ID || st.Identities[0].KeyID != nk.ID {
+		t.Fatalf("registros não apontam para a nova chave: %+v %+v", st.Accounts[0], st.Identities[0])
+	}
+	if st.Servers[0].KeyID != old.ID {
+		t.Fatalf("servidor não atualizado foi apontado para a nova chave: %+v", st.Servers[0])
+	}
+	if k := st.FindKey(old.ID); !k.ArchivedAt.IsZero() {
+		t.Fatalf("chave ainda usada pelo servidor foi arquivada: %+v", k)
+	}
+	kr := st.KeyRotations[0]
+	if kr.Status != "partial" || kr.JobID != job.ID || len(kr.Steps) != 3 || kr.Steps[0].Status != "skipped" {
+		t.Fatalf("registro da rotação: %+v", kr)
+	}
+
+	cfg, err := os.ReadFile(a.Paths().UserSSHConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(cfg), nk.PrivateKeyPath) || strings.Contains(string(cfg), old.PrivateKeyPath+"\n") {
+		t.Fatalf("ssh config não reaplicado:\n%s", cfg)
+	}
+
+	// Sem o servidor, nada mais usa a chave antiga: ela é arquivada.
+	if err := a.Storage().Update(func(st *storage.State) error { st.Servers = nil; return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if job := rotate(rotationPlan{ID: "r2", Old: old, New: nk}); !job.OK() {
+		t.Fatalf("job: %+v", job)
+	}
+	if st, err = a.Storage().LoadState(); err != nil {
+		t.Fatal(err)
+	}
+	if k := st.FindKey(old.ID); k.ArchivedAt.IsZero() || k.ReplacedBy != nk.ID {
+		t.Fatalf("chave antiga não arquivada: %+v", k)
+	}
+	if kr := st.KeyRotations[1]; kr.Status != "completed" {
+		t.Fatalf("registro da rotação: %+v", kr)
+	}
+}
+
+func TestRotationStatus(t *testing.T) {
+	done := storage.RotationStep{Status: "done"}
+	failed := storage.RotationStep{Status: "failed"}
+	cases := []struct {
+		steps    []storage.RotationStep
+		archived bool
+		want     string
+	}{
+		{nil, true, "completed"},
+		{[]storage.RotationStep{done, {Status: "skipped"}}, true, "completed"},
+		{[]storage.RotationStep{done, failed}, false, "partial"},
+		{[]storage.RotationStep{done}, false, "partial"},
+		{[]storage.RotationStep{failed, failed}, false, "failed"},
+	}
+	for _, c := range cases {
+		if got := rotationStatus(c.steps, c.archived); got != c.want {
+			t.Errorf("rotationStatus(%v, %v) = %q, want %q", c.steps, c.archived, got, c.want)
+		}
+	}
+}
@@ -5,12 +5,23 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/seuusuario/factorydev/internal/auth"
 )
 
 func (h *Handler) Routes() http.Handler {
 	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
 	r.Use(middleware.Logger)
+	r.Use(h.requestLogger)
 	r.Use(h.recoverer)
+	r.Use(h.holdWorkspace)
+	if h.app.Auth != nil {
+		r.Use(h.app.Auth.Middleware(h.authPolicy()))
+	}
+
+	r.Get(auth.LoginPath, h.LoginPage)
+	r.Post(auth.LoginPath, h.Login)
+	r.Post("/logout", h.Logout)
 
 	r.Get("/", h.Index)
 	r.Get("/health", h.Health)
@@ -20,6 +31,9 @@ func (h *Handler) Routes() http.Handler {
 	// API utilitários
 	r.Get("/api/scan-summary", h.ScanSummary)
 
+	// API JSON versionada (ver api.go)
+	r.Route(APIPrefix, h.apiRoutes)
+
 	// SSH Accounts
 	r.Get("/tools/ssh/accounts", h.ListAccounts)
 	r.Post("/tools/ssh/accounts", h.CreateAccount)
@@ -42,10 +56,24 @@ func (h *Handler) Routes() http.Handler {
 	r.Post("/tools/keys", h.CreateKey)
 	r.Delete("/tools/keys/{id}", h.DeleteKey)
 	r.Post("/tools/keys/{id}/regen-pub", h.RegenPublicKey)
+	r.Get("/tools/keys/{id}/passphrase", h.PassphraseDrawer)
+	r.Post("/tools/keys/{id}/passphrase", h.ChangeKeyPassphrase)
 	r.Get("/tools/keys/{id}/export", h.ExportKeyBase64)
 	r.Get("/tools/keys/import", h.ImportKeysDrawer)
 	r.Post("/tools/keys/import/validate", h.ValidateImportPath)
 	r.Post("/tools/keys/import", h.ImportKeys)
+	r.Get("/tools/keys/agent", h.AgentPanel)
+	r.Post("/tools/keys/agent/remove", h.RemoveAgentKey)
+	r.Post("/tools/keys/agent/clear", h.ClearAgent)
+	r.Get("/tools/keys/{id}/agent", h.AddToAgentDrawer)
+	r.Post("/tools/keys/{id}/agent", h.AddKeyToAgent)
+	r.Post("/tools/keys/{id}/ca", h.ToggleKeyCA)
+	r.Get("/tools/keys/{id}/sign", h.SignKeyDrawer)
+	r.Post("/tools/keys/{id}/sign", h.SignKey)
+	r.Delete("/tools/keys/certs/{id}", h.DeleteCertificate)
+	r.Get("/tools/keys/{id}/rotate", h.RotateKeyDrawer)
+	r.Post("/tools/keys/{id}/rotate", h.RotateKey)
+	r.Get("/tools/keys/rotate-jobs/{jobId}", h.RotateKeyJobStatus)
 
 	// Repositórios
 	r.Get("/tools/repos", h.Repositories)
@@ -99,6 +127,23 @@ func (h *Handler) Routes() http.Handler {
 	r.Get("/tools/servers/{id}/send-file", h.SendFileDrawer)
 	r.Post("/tools/servers/{id}/send-file", h.StartSendFileJob)
 	r.Get("/tools/servers/send-jobs/{jobId}", h.SendFileJobStatus)
+	r.Get("/tools/servers/exec", h.ExecDrawer)
+	r.Post("/tools/servers/exec", h.StartExecJob)
+	r.Get("/tools/servers/exec-jobs/{jobId}", h.ExecJobStatus)
+
+	// Known Hosts
+	r.Get("/tools/known-hosts", h.ListKnownHosts)
+	r.Post("/tools/known-hosts/remove", h.RemoveKnownHosts)
+	r.Get("/tools/known-hosts/verify", h.VerifyKnownHostDrawer)
+	r.Post("/tools/known-hosts/pin", h.PinKnownHost)
+
+	// Terminal embutido
+	r.Get("/tools/terminal", h.TerminalPage)
+	r.Post("/tools/terminal/sessions", h.CreateTerminalSession)
+	r.Get("/tools/terminal/sessions/{id}/ws", h.TerminalSocket)
+	r.Delete("/tools/terminal/sessions/{id}", h.CloseTerminalSession)
+	r.Get("/tools/terminal/recordings/{name}", h.TerminalRecording)
+	r.Delete("/tools/terminal/recordings/{name}", h.DeleteTerminalRecording)
 
 	// Env Variables
 	r.Get("/tools/envs", h.ListEnvs)
@@ -181,6 +226,72 @@ func (h *Handler) Routes() http.Handler {
 	r.Delete("/tools/mcp/skills/{id}", h.DeleteSkill)
 	r.Post("/tools/mcp/skills/{id}/copy", h.CopySkillPrompt)
 
+	// Vault
+	r.Get("/tools/vault", h.VaultPage)
+	r.Post("/tools/vault/init", h.InitVault)
+	r.Post("/tools/vault/unlock", h.UnlockVault)
+	r.Post("/tools/vault/lock", h.LockVault)
+
+	// Perfil (export/import)
+	r.Get("/tools/profile/export", h.ExportProfileDrawer)
+	r.Post("/tools/profile/export", h.ExportProfile)
+	r.Get("/tools/profile/export/{token}", h.DownloadProfile)
+	r.Get("/tools/profile/import", h.ImportProfileDrawer)
+	r.Post("/tools/profile/import", h.ImportProfile)
+
+	// Workspaces
+	r.Get("/tools/workspaces", h.ListWorkspaces)
+	r.Get("/tools/workspaces/switcher", h.WorkspaceSwitcher)
+	r.Post("/tools/workspaces/switch", h.SwitchWorkspace)
+	r.Get("/tools/workspaces/new", h.NewWorkspaceDrawer)
+	r.Post("/tools/workspaces", h.CreateWorkspace)
+	r.Get("/tools/workspaces/transfer", h.TransferDrawer)
+	r.Post("/tools/workspaces/transfer", h.TransferEntities)
+	r.Delete("/tools/workspaces/{name}", h.DeleteWorkspace)
+
+	// Configurações (config.json)
+	r.Get("/tools/settings", h.SettingsPage)
+	r.Post("/tools/settings", h.SaveSettings)
+
+	// Atividade (audit log)
+	r.Get("/tools/activity", h.ActivityPage)
+	r.Post("/tools/activity/{id}/undo", h.UndoActivity)
+
+	// Jobs em segundo plano
+	r.Get("/tools/jobs", h.JobsPage)
+	r.Get("/tools/jobs/live", h.JobsLive)
+	r.Get("/tools/jobs/{id}", h.JobDetail)
+	r.Post("/tools/jobs/{id}/cancel", h.CancelJob)
+
+	// Agendamentos
+	r.Get("/tools/schedules", h.ListSchedules)
+	r.Post("/tools/schedules", h.CreateSchedule)
+	r.Get("/tools/schedules/new", h.NewScheduleDrawer)
+	r.Get("/tools/schedules/{id}/edit", h.EditScheduleDrawer)
+	r.Post("/tools/schedules/{id}", h.UpdateSchedule)
+	r.Post("/tools/schedules/{id}/toggle", h.ToggleSchedule)
+	r.Post("/tools/schedules/{id}/run", h.RunScheduleNow)
+	r.Delete("/tools/schedules/{id}", h.DeleteSchedule)
+
+	// Comandos de sistema (histórico e dry-run)
+	r.Get("/tools/commands", h.CommandsPage)
+	r.Post("/tools/commands/dry-run", h.ToggleDryRun)
+	r.Post("/tools/commands/clear", h.ClearCommandHistory)
+	r.Get("/tools/commands/badge", h.DryRunBadge)
+
+	// Elevação (senha de administrador)
+	r.Get("/tools/privilege/prompt", h.PrivilegePrompt)
+	r.Post("/tools/privilege/password", h.ProvidePrivilegePassword)
+	r.Post("/tools/privilege/forget", h.ForgetPrivilegePassword)
+	r.Post("/tools/privilege/{id}/deny", h.DenyPrivilegeRequest)
+
+	// Logs do FactoryDev
+	r.Get("/tools/logs", h.LogsPage)
+	r.Get("/tools/logs/entries", h.LogEntries)
+
+	// Stream SSE (jobs, status do Docker, métricas do sistema)
+	r.Get("/events", h.Events)
+
 	// System
 	r.Get("/tools/system", h.SystemDashboard)
 	r.Get("/tools/system/widgets", h.SystemWidgets)
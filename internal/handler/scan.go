@@ -11,11 +11,11 @@ import (
 // Usado pelo sidebar via HTMX polling.
 func (h *Handler) ScanSummary(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
 	}
-	result := scanner.Scan(h.app.Paths, state)
+	result := scanner.Scan(h.app.Paths(), state)
 	h.render(w, "partials/scan-badge.html", result)
 }
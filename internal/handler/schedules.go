@@ -0,0 +1,440 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/scheduler"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// scheduleRunsShown é quantas execuções aparecem no histórico da página.
+const scheduleRunsShown = 50
+
+// registerScheduleActions expõe ao scheduler as operações agendáveis. As
+// ações reaproveitam os mesmos jobs disparados pela interface.
+func (h *Handler) registerScheduleActions() {
+	s := h.app.Scheduler
+	if s == nil {
+		return
+	}
+	s.Register(scheduler.Action{Key: "pull-all", Label: "Pull de todos os repositórios", Start: h.schedulePullAll})
+	s.Register(scheduler.Action{Key: "ssh-backup", Label: "Backup do ssh_config", Start: h.scheduleSSHBackup})
+	s.Register(scheduler.Action{Key: "server-test", Label: "Teste de conexão com servidores", Target: "server", Start: h.scheduleServerTest})
+	s.Register(scheduler.Action{Key: "docker-prune", Label: "Limpeza de imagens Docker sem tag", Start: h.scheduleDockerPrune})
+	s.Register(scheduler.Action{Key: "api-check", Label: "Checagem de endpoints da API", Target: "collection", Start: h.scheduleAPICheck})
+}
+
+func (h *Handler) schedulePullAll(string) (jobs.Job, error) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	if len(state.Repositories) == 0 {
+		return jobs.Job{}, errors.New("nenhum repositório cadastrado")
+	}
+	return h.startPullAll(state, false), nil
+}
+
+func (h *Handler) scheduleSSHBackup(string) (jobs.Job, error) {
+	paths := h.app.Paths()
+	return h.app.Jobs.Start(jobs.Options{
+		Kind:  "ssh-backup",
+		Title: "Backup do ssh_config",
+	}, func(run *jobs.Run) error {
+		if err := ssh.BackupSSHConfig(paths); err != nil {
+			return err
+		}
+		run.Log("Backup de %s gravado em %s", paths.SSHConfig(), paths.Backups)
+		return nil
+	}), nil
+}
+
+// scheduleServerTest testa o servidor alvo ou, sem alvo, todos os
+// servidores, um por etapa.
+func (h *Handler) scheduleServerTest(target string) (jobs.Job, error) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	var servers []storage.Server
+	for _, srv := range state.Servers {
+		if target == "" || srv.ID == target {
+			servers = append(servers, srv)
+		}
+	}
+	if len(servers) == 0 {
+		return jobs.Job{}, errors.New("nenhum servidor para testar")
+	}
+	targets := make([]ssh.Target, len(servers))
+	names := make([]string, len(servers))
+	for i := range servers {
+		_, keyPath := findServerAndKey(state, servers[i].ID)
+		targets[i], names[i] = serverTarget(&servers[i], keyPath), servers[i].Name
+	}
+
+	return h.app.Jobs.Start(jobs.Options{
+		Kind:  "server-test",
+		Title: "Teste SSH agendado (" + fmt.Sprint(len(servers)) + " servidor(es))",
+		Steps: names,
+	}, func(run *jobs.Run) error {
+		failed := 0
+		for i, t := range targets {
+			ctx, cancel := context.WithTimeout(run.Context(), 15*time.Second)
+			out, err := ssh.TestServer(ctx, t)
+			cancel()
+			if err != nil {
+				failed++
+			}
+			run.FinishStep(i, out, err)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d de %d servidores inacessíveis", failed, len(targets))
+		}
+		return nil
+	}), nil
+}
+
+func (h *Handler) scheduleDockerPrune(string) (jobs.Job, error) {
+	cli, err := newDockerClient()
+	if err != nil || !cli.Available() {
+		return jobs.Job{}, errors.New("Docker não disponível")
+	}
+	return h.app.Jobs.Start(jobs.Options{
+		Kind:    "docker-prune",
+		Title:   "Limpeza de imagens Docker",
+		Timeout: 10 * time.Minute,
+	}, func(run *jobs.Run) error {
+		n, mb, err := cli.PruneImages(run.Context())
+		if err != nil {
+			return err
+		}
+		run.Log("%d imagem(ns) removida(s), %.0f MB liberados", n, mb)
+		return nil
+	}), nil
+}
+
+// scheduleAPICheck chama os endpoints da collection alvo (ou de todas) e
+// falha se algum responder com erro ou status >= 400.
+func (h *Handler) scheduleAPICheck(target string) (jobs.Job, error) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		return jobs.Job{}, err
+	}
+	cols := make(map[string]*storage.APICollection, len(state.APICollections))
+	for i := range state.APICollections {
+		cols[state.APICollections[i].ID] = &state.APICollections[i]
+	}
+	var endpoints []storage.APIEndpoint
+	var names []string
+	for _, ep := range state.APIEndpoints {
+		if target == "" || ep.CollectionID == target {
+			endpoints = append(endpoints, ep)
+			names = append(names, ep.Method+" "+ep.Name)
+		}
+	}
+	if len(endpoints) == 0 {
+		return jobs.Job{}, errors.New("nenhum endpoint para checar")
+	}
+	timeout := h.app.Config().APITimeout()
+
+	return h.app.Jobs.Start(jobs.Options{
+		Kind:  "api-check",
+		Title: "Checagem de " + fmt.Sprint(len(endpoints)) + " endpoint(s)",
+		Steps: names,
+	}, func(run *jobs.Run) error {
+		failed := 0
+		for i, ep := range endpoints {
+			if run.Context().Err() != nil {
+				return run.Context().Err()
+			}
+			res := executeHTTPRequest(ep, cols[ep.CollectionID], h.app.Vault, timeout)
+			out := fmt.Sprintf("%s %s → %d (%d ms)", res.Method, res.FinalURL, res.StatusCode, res.DurationMs)
+			var stepErr error
+			switch {
+			case res.Error != "":
+				stepErr = errors.New(res.Error)
+			case res.StatusCode >= 400:
+				stepErr = fmt.Errorf("status %d", res.StatusCode)
+			}
+			if stepErr != nil {
+				failed++
+			}
+			run.FinishStep(i, out, stepErr)
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d de %d endpoints com falha", failed, len(endpoints))
+		}
+		return nil
+	}), nil
+}
+
+type scheduleView struct {
+	storage.Schedule
+	ActionLabel string
+	TargetName  string
+	Next        time.Time
+	Last        storage.ScheduleRun
+	HasLast     bool
+}
+
+type scheduleRunView struct {
+	storage.ScheduleRun
+	Name string
+}
+
+// GET /tools/schedules
+func (h *Handler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+
+	names := make(map[string]string, len(state.Schedules))
+	views := make([]scheduleView, 0, len(state.Schedules))
+	for _, sc := range state.Schedules {
+		names[sc.ID] = sc.Name
+		v := scheduleView{Schedule: sc, ActionLabel: sc.Action, Next: scheduler.NextRun(state, sc)}
+		if a, ok := h.app.Scheduler.Action(sc.Action); ok {
+			v.ActionLabel = a.Label
+		}
+		v.TargetName = scheduleTargetName(state, sc.Target)
+		v.Last, v.HasLast = scheduler.LastRun(state, sc.ID)
+		views = append(views, v)
+	}
+	sort.Slice(views, func(i, j int) bool { return views[i].Name < views[j].Name })
+
+	var runs []scheduleRunView
+	for i := len(state.ScheduleRuns) - 1; i >= 0 && len(runs) < scheduleRunsShown; i-- {
+		run := state.ScheduleRuns[i]
+		runs = append(runs, scheduleRunView{ScheduleRun: run, Name: names[run.ScheduleID]})
+	}
+
+	payload := map[string]any{"Schedules": views, "Runs": runs}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "schedules/list.html", payload)
+		return
+	}
+	h.render(w, "schedules/list.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "schedules",
+		ContentTpl: "schedules/list.html",
+		Data:       payload,
+	})
+}
+
+// scheduleTargetName devolve o nome do servidor ou collection com o ID dado.
+func scheduleTargetName(state *storage.State, id string) string {
+	if id == "" {
+		return ""
+	}
+	for _, s := range state.Servers {
+		if s.ID == id {
+			return s.Name
+		}
+	}
+	for _, c := range state.APICollections {
+		if c.ID == id {
+			return c.Name
+		}
+	}
+	return id
+}
+
+// GET /tools/schedules/new
+func (h *Handler) NewScheduleDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.renderScheduleDrawer(w, r, "Novo agendamento", "/tools/schedules",
+		storage.Schedule{Cron: "0 3 * * *", Enabled: true})
+}
+
+// GET /tools/schedules/{id}/edit
+func (h *Handler) EditScheduleDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	id := chi.URLParam(r, "id")
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	for _, sc := range state.Schedules {
+		if sc.ID == id {
+			h.renderScheduleDrawer(w, r, "Editar agendamento", "/tools/schedules/"+id, sc)
+			return
+		}
+	}
+	h.operationError(w, "Agendamento não encontrado", http.StatusNotFound)
+}
+
+func (h *Handler) renderScheduleDrawer(w http.ResponseWriter, r *http.Request, title, submitURL string, sc storage.Schedule) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	h.renderDrawer(w, title, "schedules/form.html", map[string]any{
+		"SubmitURL":   submitURL,
+		"IsEdit":      sc.ID != "",
+		"Schedule":    sc,
+		"Actions":     h.app.Scheduler.Actions(),
+		"Servers":     state.Servers,
+		"Collections": state.APICollections,
+	})
+}
+
+func parseScheduleForm(r *http.Request) storage.Schedule {
+	sc := storage.Schedule{
+		Name:    strings.TrimSpace(r.FormValue("name")),
+		Action:  r.FormValue("action"),
+		Cron:    strings.TrimSpace(r.FormValue("cron")),
+		Enabled: r.FormValue("enabled") == "on" || r.FormValue("enabled") == "true",
+	}
+	// O formulário tem um seletor por tipo de alvo; vale o da ação escolhida.
+	switch sc.Action {
+	case "server-test":
+		sc.Target = r.FormValue("target_server")
+	case "api-check":
+		sc.Target = r.FormValue("target_collection")
+	}
+	return sc
+}
+
+// POST /tools/schedules
+func (h *Handler) CreateSchedule(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	sc := parseScheduleForm(r)
+	if errs := h.app.Scheduler.Validate(sc); len(errs) > 0 {
+		h.errorToast(w, errs[0].Field+": "+errs[0].Message)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	sc.ID = newID()
+	sc.CreatedAt = time.Now()
+	sc.UpdatedAt = sc.CreatedAt
+	err := h.store(r).Update(func(state *storage.State) error {
+		state.Schedules = append(state.Schedules, sc)
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
+		return
+	}
+	h.successToast(w, "Agendamento criado!")
+}
+
+// POST /tools/schedules/{id}
+func (h *Handler) UpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	id := chi.URLParam(r, "id")
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	sc := parseScheduleForm(r)
+	if errs := h.app.Scheduler.Validate(sc); len(errs) > 0 {
+		h.errorToast(w, errs[0].Field+": "+errs[0].Message)
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.Schedules {
+			if state.Schedules[i].ID == id {
+				sc.ID, sc.CreatedAt, sc.UpdatedAt = id, state.Schedules[i].CreatedAt, time.Now()
+				state.Schedules[i] = sc
+				return nil
+			}
+		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Agendamento não encontrado")
+		return
+	}
+	h.successToast(w, "Agendamento atualizado!")
+}
+
+// POST /tools/schedules/{id}/toggle
+func (h *Handler) ToggleSchedule(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	id := chi.URLParam(r, "id")
+	var enabled bool
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.Schedules {
+			if state.Schedules[i].ID == id {
+				sc := &state.Schedules[i]
+				sc.Enabled = !sc.Enabled
+				// Reativar não dispara as execuções perdidas enquanto esteve
+				// desligado: a contagem recomeça agora.
+				sc.UpdatedAt = time.Now()
+				enabled = sc.Enabled
+				return nil
+			}
+		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Agendamento não encontrado")
+		return
+	}
+	if enabled {
+		h.successToast(w, "Agendamento ativado")
+	} else {
+		h.successToast(w, "Agendamento desativado")
+	}
+}
+
+// POST /tools/schedules/{id}/run
+func (h *Handler) RunScheduleNow(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	run, err := h.app.Scheduler.RunNow(chi.URLParam(r, "id"))
+	switch {
+	case errors.Is(err, scheduler.ErrRunning):
+		h.errorToast(w, "Este agendamento já está em execução")
+		w.WriteHeader(http.StatusConflict)
+	case errors.Is(err, scheduler.ErrNotFound):
+		h.operationError(w, "Agendamento não encontrado", http.StatusNotFound)
+	case err != nil && run.ID == "":
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	case err != nil:
+		// A falha também fica registrada no histórico de execuções.
+		h.errorToast(w, "Falha ao iniciar: "+err.Error())
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	default:
+		h.successToast(w, "Execução iniciada — acompanhe em Jobs")
+	}
+}
+
+// DELETE /tools/schedules/{id}
+func (h *Handler) DeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	id := chi.URLParam(r, "id")
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i, sc := range state.Schedules {
+			if sc.ID == id {
+				state.Schedules = append(state.Schedules[:i], state.Schedules[i+1:]...)
+				return nil
+			}
+		}
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Agendamento não encontrado")
+		return
+	}
+	h.successToast(w, "Agendamento removido!")
+}
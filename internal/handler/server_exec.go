@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// execParallel limita quantos servidores recebem o comando ao mesmo tempo.
+const execParallel = 8
+
+type execFormData struct {
+	Servers  []storage.Server
+	Tags     []string
+	Selected map[string]bool
+	Command  string
+	Timeout  int // segundos
+	Errors   map[string]string
+}
+
+// GET /tools/servers/exec?serverId=
+func (h *Handler) ExecDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	selected := map[string]bool{}
+	for _, id := range r.URL.Query()["serverId"] {
+		selected[id] = true
+	}
+	h.renderDrawer(w, "Executar comando", "servers/exec-drawer.html", execFormData{
+		Servers:  state.Servers,
+		Tags:     serverTags(state.Servers),
+		Selected: selected,
+		Timeout:  60,
+		Errors:   map[string]string{},
+	})
+}
+
+// POST /tools/servers/exec
+// Executa o comando em paralelo nos servidores marcados, com a saída de
+// cada um numa etapa do job.
+func (h *Handler) StartExecJob(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+
+	form := execFormData{
+		Servers:  state.Servers,
+		Tags:     serverTags(state.Servers),
+		Selected: map[string]bool{},
+		Command:  strings.TrimSpace(r.FormValue("command")),
+		Errors:   map[string]string{},
+	}
+	form.Timeout, _ = strconv.Atoi(r.FormValue("timeout"))
+	var servers []storage.Server
+	var targets []ssh.Target
+	for _, id := range r.Form["server"] {
+		srv, keyPath := findServerAndKey(state, id)
+		if srv == nil || form.Selected[id] {
+			continue
+		}
+		form.Selected[id] = true
+		servers = append(servers, *srv)
+		targets = append(targets, serverTarget(srv, keyPath))
+	}
+	if form.Command == "" {
+		form.Errors["command"] = "informe o comando"
+	}
+	if len(servers) == 0 {
+		form.Errors["server"] = "selecione ao menos um servidor"
+	}
+	if form.Timeout < 1 || form.Timeout > 3600 {
+		form.Errors["timeout"] = "entre 1 e 3600 segundos"
+	}
+	if len(form.Errors) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		h.renderDrawer(w, "Executar comando", "servers/exec-drawer.html", form)
+		return
+	}
+
+	names := make([]string, len(servers))
+	for i, s := range servers {
+		names[i] = s.Name
+	}
+	cmd := form.Command
+	timeout := time.Duration(form.Timeout) * time.Second
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "server-exec",
+		Title:   fmt.Sprintf("%s em %d servidor(es)", cmd, len(servers)),
+		Timeout: timeout + 10*time.Second,
+		Steps:   names,
+		Meta:    map[string]string{"command": cmd},
+	}, func(run *jobs.Run) error {
+		return runExec(run, targets, names, cmd, timeout)
+	})
+	h.app.Logger.Info("comando remoto iniciado", "servers", len(servers), "job", job.ID)
+
+	w.Header().Set("HX-Trigger", "closeDrawer")
+	h.render(w, "servers/exec-progress.html", map[string]any{
+		"ID": job.ID, "Title": job.Title, "Steps": job.Steps,
+	})
+}
+
+// runExec é o corpo do job de comando remoto: até execParallel servidores
+// ao mesmo tempo, cada um com seu limite de tempo.
+func runExec(run *jobs.Run, targets []ssh.Target, names []string, cmd string, timeout time.Duration) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var failed []string
+	sem := make(chan struct{}, execParallel)
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			out := run.StepWriter(i)
+			ctx, cancel := context.WithTimeout(run.Context(), timeout)
+			defer cancel()
+			res, err := ssh.Exec(ctx, t, cmd, out, out)
+			if err != nil {
+				mu.Lock()
+				failed = append(failed, names[i])
+				mu.Unlock()
+				run.Log("%s: %v", names[i], err)
+			} else {
+				run.Log("%s: ok em %s", names[i], res.Duration.Round(time.Millisecond))
+			}
+			run.FinishStep(i, "", err)
+		}()
+	}
+	wg.Wait()
+	if len(failed) > 0 {
+		return fmt.Errorf("falhou em %d de %d servidor(es): %s", len(failed), len(targets), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// GET /tools/servers/exec-jobs/{jobId}
+func (h *Handler) ExecJobStatus(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	job, ok := h.app.Jobs.Get(chi.URLParam(r, "jobId"))
+	if !ok {
+		h.operationError(w, "Job não encontrado", http.StatusNotFound)
+		return
+	}
+	if job.Done() {
+		if job.OK() {
+			h.successToastOnly(w, "Comando concluído em todos os servidores")
+		} else {
+			h.errorToast(w, job.Error)
+		}
+		w.WriteHeader(286)
+	}
+	h.render(w, "servers/exec-progress.html", map[string]any{
+		"ID":       job.ID,
+		"Title":    job.Title,
+		"Done":     job.Done(),
+		"OK":       job.OK(),
+		"Error":    job.Error,
+		"Steps":    job.Steps,
+		"Duration": job.Duration(),
+	})
+}
+
+// serverTags lista as tags dos servidores, sem repetição, na ordem em que
+// aparecem.
+func serverTags(servers []storage.Server) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, s := range servers {
+		for _, t := range s.Tags {
+			if !seen[t] {
+				seen[t] = true
+				tags = append(tags, t)
+			}
+		}
+	}
+	return tags
+}
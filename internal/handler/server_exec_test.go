@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// TestRunExec roda o job em dry-run: cada servidor recebe sua etapa, com a
+// própria saída, sem abrir conexão.
+func TestRunExec(t *testing.T) {
+	fake := command.NewFake()
+	fake.SetDryRun(true)
+	defer command.SetDefault(fake)()
+
+	m := jobs.New("", time.Hour)
+	targets := []ssh.Target{{User: "deploy", Host: "10.0.0.1"}, {User: "root", Host: "10.0.0.2"}}
+	names := []string{"web", "db"}
+	j := m.Start(jobs.Options{Kind: "server-exec", Steps: names}, func(run *jobs.Run) error {
+		return runExec(run, targets, names, "uptime", time.Second)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	done, err := m.Wait(ctx, j.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !done.OK() {
+		t.Fatalf("job falhou: %s", done.Error)
+	}
+	for i, s := range done.Steps {
+		if !s.Done || !s.OK || !strings.Contains(s.Output, targets[i].User+"@"+targets[i].Host+": uptime") {
+			t.Fatalf("etapa %s = %+v", names[i], s)
+		}
+	}
+}
+
+func TestServerTags(t *testing.T) {
+	got := serverTags([]storage.Server{{Tags: []string{"prod", "web"}}, {Tags: []string{"prod", "db"}}, {}})
+	if strings.Join(got, ",") != "prod,web,db" {
+		t.Fatalf("tags = %v", got)
+	}
+}
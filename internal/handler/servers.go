@@ -1,10 +1,9 @@
 package handler
 
 import (
-	"context"
 	"fmt"
 	"net/http"
-	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -12,6 +11,8 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/seuusuario/factorydev/internal/app"
 	igit "github.com/seuusuario/factorydev/internal/git"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/ssh"
 	"github.com/seuusuario/factorydev/internal/storage"
 )
 
@@ -24,7 +25,7 @@ type serverView struct {
 // GET /tools/servers
 func (h *Handler) ListServers(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -64,7 +65,7 @@ func (h *Handler) ListServers(w http.ResponseWriter, r *http.Request) {
 // GET /tools/servers/new
 func (h *Handler) NewServerDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -94,14 +95,12 @@ func (h *Handler) CreateServer(w http.ResponseWriter, r *http.Request) {
 	srv.ID = newID()
 	srv.CreatedAt = time.Now()
 
-	state, err := h.app.Storage.LoadState()
+	err := h.store(r).Update(func(state *storage.State) error {
+		state.Servers = append(state.Servers, srv)
+		return nil
+	})
 	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	state.Servers = append(state.Servers, srv)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		h.updateFailed(w, err, "")
 		return
 	}
 	h.successToast(w, "Servidor criado!")
@@ -111,7 +110,7 @@ func (h *Handler) CreateServer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditServerDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -151,25 +150,23 @@ func (h *Handler) UpdateServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	for i := range state.Servers {
-		if state.Servers[i].ID == id {
-			state.Servers[i].Name = srv.Name
-			state.Servers[i].Host = srv.Host
-			state.Servers[i].Port = srv.Port
-			state.Servers[i].User = srv.User
-			state.Servers[i].KeyID = srv.KeyID
-			state.Servers[i].Description = srv.Description
-			state.Servers[i].Tags = srv.Tags
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i := range state.Servers {
+			if state.Servers[i].ID == id {
+				state.Servers[i].Name = srv.Name
+				state.Servers[i].Host = srv.Host
+				state.Servers[i].Port = srv.Port
+				state.Servers[i].User = srv.User
+				state.Servers[i].KeyID = srv.KeyID
+				state.Servers[i].Description = srv.Description
+				state.Servers[i].Tags = srv.Tags
+				return nil
+			}
 		}
-	}
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Servidor não encontrado")
 		return
 	}
 	h.successToast(w, "Servidor atualizado!")
@@ -179,25 +176,17 @@ func (h *Handler) UpdateServer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteServer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := -1
-	for i, s := range state.Servers {
-		if s.ID == id {
-			idx = i
-			break
+	err := h.store(r).Update(func(state *storage.State) error {
+		for i, s := range state.Servers {
+			if s.ID == id {
+				state.Servers = append(state.Servers[:i], state.Servers[i+1:]...)
+				return nil
+			}
 		}
-	}
-	if idx < 0 {
-		h.operationError(w, "Servidor não encontrado", http.StatusNotFound)
-		return
-	}
-	state.Servers = append(state.Servers[:idx], state.Servers[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return errNotFound
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Servidor não encontrado")
 		return
 	}
 	h.successToast(w, "Servidor removido!")
@@ -208,7 +197,7 @@ func (h *Handler) StartTestJob(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -219,41 +208,18 @@ func (h *Handler) StartTestJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job := &GitOpJob{ID: newID()}
-	h.serverTestMu.Lock()
-	h.serverTestJobs[job.ID] = job
-	h.serverTestMu.Unlock()
-
-	port := srv.Port
-	if port == 0 {
-		port = 22
-	}
-	user := srv.User
-	host := srv.Host
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-		defer cancel()
-		args := []string{
-			"-o", "ConnectTimeout=5",
-			"-o", "BatchMode=yes",
-			"-o", "StrictHostKeyChecking=accept-new",
-			"-p", strconv.Itoa(port),
-		}
-		if keyPath != "" {
-			args = append(args, "-i", keyPath)
-		}
-		args = append(args, user+"@"+host, "exit")
-		out, sshErr := exec.CommandContext(ctx, "ssh", args...).CombinedOutput()
-		h.serverTestMu.Lock()
-		job.Output = strings.TrimSpace(string(out))
-		if sshErr != nil {
-			job.Done, job.OK = true, false
-			job.Error = fmt.Sprintf("Falha na conexão: %v", sshErr)
-		} else {
-			job.Done, job.OK = true, true
+	target := serverTarget(srv, keyPath)
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "server-test",
+		Title:   "Teste SSH " + srv.Name,
+		Timeout: 15 * time.Second,
+		Meta:    map[string]string{"serverId": id},
+	}, func(run *jobs.Run) error {
+		if sshErr := ssh.TestServerTo(run.Context(), target, run); sshErr != nil {
+			return fmt.Errorf("Falha na conexão: %v", sshErr)
 		}
-		h.serverTestMu.Unlock()
-	}()
+		return nil
+	})
 
 	h.render(w, "servers/test-progress.html", map[string]any{
 		"ID": job.ID, "Done": false, "ServerID": id,
@@ -266,15 +232,9 @@ func (h *Handler) TestJobStatus(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobId")
 	serverID := r.URL.Query().Get("serverId")
 
-	h.serverTestMu.Lock()
-	job, ok := h.serverTestJobs[jobID]
-	var done, jobOK bool
-	var errMsg, output string
-	if ok {
-		done, jobOK = job.Done, job.OK
-		errMsg, output = job.Error, job.Output
-	}
-	h.serverTestMu.Unlock()
+	job, ok := h.app.Jobs.Get(jobID)
+	done, jobOK := job.Done(), job.OK()
+	errMsg, output := job.Error, job.Output
 
 	if !ok {
 		h.render(w, "servers/test-progress.html", map[string]any{
@@ -298,14 +258,24 @@ func (h *Handler) TestJobStatus(w http.ResponseWriter, r *http.Request) {
 		"OK":       jobOK,
 		"Error":    errMsg,
 		"Output":   output,
+		// Chave de host divergente: a correção é pelo Known Hosts.
+		"HostKeyChanged": done && !jobOK && (strings.Contains(output, "REMOTE HOST IDENTIFICATION HAS CHANGED") ||
+			strings.Contains(output, "Host key verification failed")),
 	})
 }
 
 // POST /tools/servers/{id}/connect
+// Abre uma sessão SSH no terminal embutido; com native=1, no emulador de
+// terminal do desktop.
 func (h *Handler) ConnectServer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	if r.FormValue("native") == "" {
+		h.startServerTerminal(w, r, id, false)
+		return
+	}
+
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -316,20 +286,7 @@ func (h *Handler) ConnectServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	port := srv.Port
-	if port == 0 {
-		port = 22
-	}
-	sshArgs := []string{
-		"-p", strconv.Itoa(port),
-		"-o", "StrictHostKeyChecking=accept-new",
-	}
-	if keyPath != "" {
-		sshArgs = append(sshArgs, "-i", keyPath)
-	}
-	sshArgs = append(sshArgs, srv.User+"@"+srv.Host)
-
-	if err := igit.OpenTerminalWithCmd("ssh", sshArgs...); err != nil {
+	if err := igit.OpenTerminalWithCmd("ssh", serverTarget(srv, keyPath).Args()...); err != nil {
 		h.errorToast(w, err.Error())
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
@@ -341,7 +298,7 @@ func (h *Handler) ConnectServer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) SendFileDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -379,7 +336,7 @@ func (h *Handler) StartSendFileJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -390,34 +347,18 @@ func (h *Handler) StartSendFileJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	job := &GitOpJob{ID: newID()}
-	h.sendFileMu.Lock()
-	h.sendFileJobs[job.ID] = job
-	h.sendFileMu.Unlock()
-
-	port := srv.Port
-	if port == 0 {
-		port = 22
-	}
-	go func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-		defer cancel()
-		args := []string{"-P", strconv.Itoa(port), "-o", "StrictHostKeyChecking=accept-new"}
-		if keyPath != "" {
-			args = append(args, "-i", keyPath)
-		}
-		args = append(args, localPath, srv.User+"@"+srv.Host+":"+remoteDest)
-		out, scpErr := exec.CommandContext(ctx, "scp", args...).CombinedOutput()
-		h.sendFileMu.Lock()
-		job.Output = strings.TrimSpace(string(out))
-		if scpErr != nil {
-			job.Done, job.OK = true, false
-			job.Error = fmt.Sprintf("Erro no envio: %v", scpErr)
-		} else {
-			job.Done, job.OK = true, true
+	target := serverTarget(srv, keyPath)
+	job := h.app.Jobs.Start(jobs.Options{
+		Kind:    "send-file",
+		Title:   fmt.Sprintf("Envio de %s para %s:%s", filepath.Base(localPath), srv.Name, remoteDest),
+		Timeout: 5 * time.Minute,
+		Meta:    map[string]string{"serverId": id},
+	}, func(run *jobs.Run) error {
+		if scpErr := ssh.SendFileTo(run.Context(), target, localPath, remoteDest, run); scpErr != nil {
+			return fmt.Errorf("Erro no envio: %v", scpErr)
 		}
-		h.sendFileMu.Unlock()
-	}()
+		return nil
+	})
 
 	h.render(w, "servers/test-progress.html", map[string]any{
 		"ID": job.ID, "Done": false, "ServerID": id, "IsSend": true,
@@ -430,15 +371,9 @@ func (h *Handler) SendFileJobStatus(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobId")
 	serverID := r.URL.Query().Get("serverId")
 
-	h.sendFileMu.Lock()
-	job, ok := h.sendFileJobs[jobID]
-	var done, jobOK bool
-	var errMsg, output string
-	if ok {
-		done, jobOK = job.Done, job.OK
-		errMsg, output = job.Error, job.Output
-	}
-	h.sendFileMu.Unlock()
+	job, ok := h.app.Jobs.Get(jobID)
+	done, jobOK := job.Done(), job.OK()
+	errMsg, output := job.Error, job.Output
 
 	if !ok {
 		h.render(w, "servers/test-progress.html", map[string]any{
@@ -477,13 +412,10 @@ func parseServerForm(r *http.Request) (storage.Server, string) {
 	desc := strings.TrimSpace(r.FormValue("description"))
 	tagsRaw := strings.TrimSpace(r.FormValue("tags"))
 
-	if name == "" || host == "" || user == "" {
-		return storage.Server{}, "Nome, host e usuário são obrigatórios"
-	}
 	port := 22
 	if portStr != "" {
 		p, err := strconv.Atoi(portStr)
-		if err != nil || p < 1 || p > 65535 {
+		if err != nil {
 			return storage.Server{}, "Porta inválida"
 		}
 		port = p
@@ -497,7 +429,7 @@ func parseServerForm(r *http.Request) (storage.Server, string) {
 		}
 	}
 
-	return storage.Server{
+	srv := storage.Server{
 		Name:        name,
 		Host:        host,
 		Port:        port,
@@ -505,7 +437,22 @@ func parseServerForm(r *http.Request) (storage.Server, string) {
 		KeyID:       keyID,
 		Description: desc,
 		Tags:        tags,
-	}, ""
+	}
+	if msg := validateServer(srv); msg != "" {
+		return storage.Server{}, msg
+	}
+	return srv, ""
+}
+
+// validateServer confere os campos obrigatórios e a porta.
+func validateServer(s storage.Server) string {
+	if s.Name == "" || s.Host == "" || s.User == "" {
+		return "Nome, host e usuário são obrigatórios"
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		return "Porta inválida"
+	}
+	return ""
 }
 
 func findServerAndKey(state *storage.State, serverID string) (*storage.Server, string) {
@@ -528,3 +475,7 @@ func findServerAndKey(state *storage.State, serverID string) (*storage.Server, s
 	}
 	return srv, keyPath
 }
+
+func serverTarget(srv *storage.Server, keyPath string) ssh.Target {
+	return ssh.Target{User: srv.User, Host: srv.Host, Port: srv.Port, KeyPath: keyPath}
+}
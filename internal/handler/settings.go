@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	igit "github.com/seuusuario/factorydev/internal/git"
+	fdevssh "github.com/seuusuario/factorydev/internal/ssh"
+)
+
+// GET /tools/settings
+func (h *Handler) SettingsPage(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	cfg, err := config.LoadFile(h.app.Paths().ConfigFile)
+	if err != nil {
+		// Arquivo inválido: mostra os padrões para que possa ser regravado.
+		h.app.Logger.Warn("config.json inválido", "err", err)
+		cfg = config.Defaults()
+	}
+	excludes := cfg.ScanExcludes
+	if len(excludes) == 0 {
+		excludes = igit.DefaultScanExcludes
+	}
+
+	payload := map[string]any{
+		"Config":       cfg,
+		"Path":         h.app.Paths().ConfigFile,
+		"Overrides":    config.EnvOverrides(),
+		"ScanRoots":    strings.Join(cfg.ScanRoots, "\n"),
+		"ScanExcludes": strings.Join(excludes, "\n"),
+		"Home":         h.app.Paths().Home,
+	}
+	if err != nil {
+		payload["LoadError"] = err.Error()
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "settings/page.html", payload)
+		return
+	}
+	h.render(w, "settings/page.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "settings",
+		ContentTpl: "settings/page.html",
+		Data:       payload,
+	})
+}
+
+// POST /tools/settings
+func (h *Handler) SaveSettings(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	cfg, err := config.LoadFile(h.app.Paths().ConfigFile)
+	if err != nil {
+		cfg = config.Defaults()
+	}
+	// O aviso de reinício compara com o arquivo, não com a configuração em
+	// execução: flags e FDEV_* não contam como mudança.
+	saved := *cfg
+
+	cfg.Host = strings.TrimSpace(r.FormValue("host"))
+	cfg.WindowMode = r.FormValue("windowMode")
+	cfg.Storage = r.FormValue("storage")
+	cfg.Debug = r.FormValue("debug") == "on"
+	cfg.DryRun = r.FormValue("dryRun") == "on"
+	cfg.Privilege = r.FormValue("privilege")
+	cfg.OpenBrowser = r.FormValue("openBrowser") == "on"
+	cfg.Systray = r.FormValue("systray") == "on"
+	cfg.ScanRoots = splitLines(r.FormValue("scanRoots"))
+	cfg.ScanExcludes = splitLines(r.FormValue("scanExcludes"))
+	if slices.Equal(cfg.ScanExcludes, igit.DefaultScanExcludes) {
+		// Mantém o arquivo acompanhando os padrões de versões futuras.
+		cfg.ScanExcludes = nil
+	}
+	for field, dst := range map[string]*int{
+		"port":              &cfg.Port,
+		"containerLogTail":  &cfg.ContainerLogTail,
+		"dbRowLimit":        &cfg.DBRowLimit,
+		"apiTimeoutSeconds": &cfg.APITimeoutSeconds,
+		"backupRetention":   &cfg.BackupRetention,
+	} {
+		n, err := strconv.Atoi(strings.TrimSpace(r.FormValue(field)))
+		if err != nil {
+			h.errorToast(w, "Valor numérico inválido em "+field)
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		*dst = n
+	}
+	if err := cfg.Validate(); err != nil {
+		h.errorToast(w, err.Error())
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	if err := config.SaveFile(h.app.Paths().ConfigFile, cfg); err != nil {
+		h.app.Logger.Error("gravar config.json", "err", err)
+		h.operationError(w, "Erro ao gravar configurações", http.StatusInternalServerError)
+		return
+	}
+
+	h.applyRuntimeSettings(cfg)
+
+	msg := "Configurações salvas"
+	var restart []string
+	for _, f := range restartFields {
+		if f.changed(&saved, cfg) {
+			restart = append(restart, f.label)
+		}
+	}
+	if len(restart) > 0 {
+		msg += " — " + strings.Join(restart, ", ") + " valem após reiniciar"
+	}
+	h.successToastOnly(w, msg)
+}
+
+// restartFields são as opções lidas só na inicialização; o aviso de
+// reinício lista as que mudaram no arquivo.
+var restartFields = []struct {
+	label   string
+	changed func(saved, next *config.Config) bool
+}{
+	{"servidor", func(c, n *config.Config) bool { return c.Host != n.Host }},
+	{"porta", func(c, n *config.Config) bool { return c.Port != n.Port }},
+	{"storage", func(c, n *config.Config) bool { return c.Storage != n.Storage }},
+	{"debug", func(c, n *config.Config) bool { return c.Debug != n.Debug }},
+	{"dry-run inicial", func(c, n *config.Config) bool { return c.DryRun != n.DryRun }},
+	{"elevação de privilégios", func(c, n *config.Config) bool { return c.Privilege != n.Privilege }},
+	{"modo de janela", func(c, n *config.Config) bool { return c.WindowMode != n.WindowMode }},
+	{"ícone na bandeja", func(c, n *config.Config) bool { return c.Systray != n.Systray }},
+	{"abrir o navegador", func(c, n *config.Config) bool { return c.OpenBrowser != n.OpenBrowser }},
+}
+
+// applyRuntimeSettings aplica ao App em execução as opções que não exigem
+// reinício. Campos sobrescritos por FDEV_* mantêm o valor do ambiente.
+func (h *Handler) applyRuntimeSettings(cfg *config.Config) {
+	env := config.EnvOverrides()
+	h.app.UpdateConfig(func(cur *config.Config) {
+		if env["scanRoots"] == "" {
+			cur.ScanRoots = cfg.ScanRoots
+		}
+		if env["scanExcludes"] == "" {
+			cur.ScanExcludes = cfg.ScanExcludes
+		}
+		if env["containerLogTail"] == "" {
+			cur.ContainerLogTail = cfg.ContainerLogTail
+		}
+		if env["dbRowLimit"] == "" {
+			cur.DBRowLimit = cfg.DBRowLimit
+		}
+		if env["apiTimeoutSeconds"] == "" {
+			cur.APITimeoutSeconds = cfg.APITimeoutSeconds
+		}
+		if env["backupRetention"] == "" {
+			cur.BackupRetention = cfg.BackupRetention
+			fdevssh.SetBackupRetention(cfg.BackupRetention)
+		}
+	})
+}
+
+// ── Helpers ───────────────────────────────────────────────────
+
+// splitLines quebra um textarea em itens, aceitando também vírgulas.
+func splitLines(s string) []string {
+	var out []string
+	for _, f := range strings.FieldsFunc(s, func(r rune) bool { return r == '\n' || r == ',' }) {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
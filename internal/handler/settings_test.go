@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+	fdevssh "github.com/seuusuario/factorydev/internal/ssh"
+)
+
+// TestSaveSettings grava a configuração: os limites valem na hora, numa
+// cópia nova da configuração, e o aviso lista as opções que mudaram no
+// arquivo e pedem reinício. O App roda com host e porta de flags, que não
+// contam como mudança.
+func TestSaveSettings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "0.0.0.0", Port: 9000, Storage: "json", WindowMode: "app", Privilege: "auto", DBRowLimit: 500})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	t.Cleanup(func() { fdevssh.SetBackupRetention(10) })
+	file := config.Defaults()
+	file.Port = 7777
+	if err := config.SaveFile(a.Paths().ConfigFile, file); err != nil {
+		t.Fatal(err)
+	}
+	before := a.Config()
+
+	form := url.Values{
+		"host": {"127.0.0.1"}, "port": {"7777"}, "storage": {"json"}, "openBrowser": {"on"},
+		"windowMode": {"browser"}, "privilege": {"sudo"},
+		"containerLogTail": {"100"}, "dbRowLimit": {"50"}, "apiTimeoutSeconds": {"10"}, "backupRetention": {"3"},
+	}
+	req := httptest.NewRequest("POST", "/tools/settings", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	New(a).SaveSettings(w, req)
+	if w.Code != 200 {
+		t.Fatalf("save = %d %s", w.Code, w.Header().Get("HX-Trigger"))
+	}
+
+	toast := w.Header().Get("HX-Trigger")
+	for _, want := range []string{"elevação de privilégios", "modo de janela", "ícone na bandeja"} {
+		if !strings.Contains(toast, want) {
+			t.Errorf("aviso sem %q: %s", want, toast)
+		}
+	}
+	if strings.Contains(toast, "servidor") || strings.Contains(toast, "porta") || strings.Contains(toast, "storage") || strings.Contains(toast, "navegador") {
+		t.Errorf("aviso com opção que não mudou: %s", toast)
+	}
+	if cur := a.Config(); cur == before || cur.DBRowLimit != 50 || before.DBRowLimit != 500 {
+		t.Fatalf("configuração não trocada por cópia: antes %d, agora %d", before.DBRowLimit, cur.DBRowLimit)
+	}
+	if cur := a.Config(); cur.WindowMode != "app" || cur.Privilege != "auto" {
+		t.Fatalf("opções de inicialização aplicadas em execução: %+v", cur)
+	}
+	if fdevssh.BackupRetention() != 3 {
+		t.Fatalf("BackupRetention = %d", fdevssh.BackupRetention())
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"os"
-	"regexp"
 	"strings"
 	"time"
 
@@ -38,7 +37,7 @@ type accountFormData struct {
 
 func (h *Handler) ListAccounts(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -67,7 +66,7 @@ func (h *Handler) ListAccounts(w http.ResponseWriter, r *http.Request) {
 		} else if a.IdentityFile != "" {
 			// Legacy: usa IdentityFile diretamente (sem depender do alias)
 			kt := a.EffectiveKeyType()
-			privPath = expandHome(a.IdentityFile, h.app.Paths.Home)
+			privPath = expandHome(a.IdentityFile, h.app.Paths().Home)
 			pubPath = privPath + ".pub"
 			keyTyp = kt
 		}
@@ -120,7 +119,7 @@ func (h *Handler) ListAccounts(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) NewAccountDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -136,7 +135,7 @@ func (h *Handler) NewAccountDrawer(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) EditAccountDrawer(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -172,39 +171,35 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	a.CreatedAt = time.Now()
 	a.UpdatedAt = time.Now()
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
-	if errs := storage.Validate(a, state.Accounts); len(errs) > 0 {
-		w.WriteHeader(http.StatusUnprocessableEntity)
-		h.renderDrawer(w, "Nova Conta SSH", "ssh/account-drawer.html", accountFormData{
-			Account:   a,
-			Keys:      state.Keys,
-			Errors:    mapValidation(errs),
-			SubmitURL: "/tools/ssh/accounts",
-		})
-		return
-	}
+	err := h.store(r).Update(func(state *storage.State) error {
+		if errs := storage.Validate(a, state.Accounts); len(errs) > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			h.renderDrawer(w, "Nova Conta SSH", "ssh/account-drawer.html", accountFormData{
+				Account:   a,
+				Keys:      state.Keys,
+				Errors:    mapValidation(errs),
+				SubmitURL: "/tools/ssh/accounts",
+			})
+			return errResponded
+		}
 
-	// Resolver chave (selecionar existente ou criar inline)
-	if err := h.resolveAccountKey(r, &a, state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
+		// Resolver chave (selecionar existente ou criar inline)
+		if err := h.resolveAccountKey(r, &a, state); err != nil {
+			return err
+		}
 
-	// Atualizar IdentityFile para compat com ApplySSHConfig
-	if a.KeyID != "" {
-		if idx := findKeyIndex(state.Keys, a.KeyID); idx >= 0 {
-			a.IdentityFile = state.Keys[idx].PrivateKeyPath
+		// Atualizar IdentityFile para compat com ApplySSHConfig
+		if a.KeyID != "" {
+			if idx := findKeyIndex(state.Keys, a.KeyID); idx >= 0 {
+				a.IdentityFile = state.Keys[idx].PrivateKeyPath
+			}
 		}
-	}
 
-	state.Accounts = append(state.Accounts, a)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		state.Accounts = append(state.Accounts, a)
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 
@@ -219,66 +214,62 @@ func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-	idx := findAccountIndex(state.Accounts, id)
-	if idx < 0 {
-		h.operationError(w, "Conta não encontrada", http.StatusNotFound)
-		return
-	}
+	err := h.store(r).Update(func(state *storage.State) error {
+		idx := findAccountIndex(state.Accounts, id)
+		if idx < 0 {
+			return errNotFound
+		}
 
-	old := state.Accounts[idx]
-	a := accountFromRequest(r)
-	a.ID = old.ID
-	a.CreatedAt = old.CreatedAt
-	a.UpdatedAt = time.Now()
-	// Preserva legado se a nova conta não especifica chave
-	if a.KeyID == "" {
-		a.KeyID = old.KeyID
-		a.IdentityFile = old.IdentityFile
-		a.KeyType = old.KeyType
-	}
-	if a.Provider != "" && a.HostName != "" && a.GitUserName != "" && a.GitUserEmail != "" {
-		a.IsSimpleKey = false
-	} else {
-		a.IsSimpleKey = old.IsSimpleKey
-	}
+		old := state.Accounts[idx]
+		a := accountFromRequest(r)
+		a.ID = old.ID
+		a.CreatedAt = old.CreatedAt
+		a.UpdatedAt = time.Now()
+		// Preserva legado se a nova conta não especifica chave
+		if a.KeyID == "" {
+			a.KeyID = old.KeyID
+			a.IdentityFile = old.IdentityFile
+			a.KeyType = old.KeyType
+		}
+		if a.Provider != "" && a.HostName != "" && a.GitUserName != "" && a.GitUserEmail != "" {
+			a.IsSimpleKey = false
+		} else {
+			a.IsSimpleKey = old.IsSimpleKey
+		}
 
-	if errs := storage.Validate(a, state.Accounts); len(errs) > 0 {
-		w.WriteHeader(http.StatusUnprocessableEntity)
-		showAliasWarning := old.HostAlias != a.HostAlias
-		h.renderDrawer(w, "Configurar Conta SSH", "ssh/account-drawer.html", accountFormData{
-			Account:          a,
-			Keys:             state.Keys,
-			Errors:           mapValidation(errs),
-			SubmitURL:        "/tools/ssh/accounts/" + id,
-			IsEdit:           true,
-			ShowAliasWarning: showAliasWarning,
-		})
-		return
-	}
+		if errs := storage.Validate(a, state.Accounts); len(errs) > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			showAliasWarning := old.HostAlias != a.HostAlias
+			h.renderDrawer(w, "Configurar Conta SSH", "ssh/account-drawer.html", accountFormData{
+				Account:          a,
+				Keys:             state.Keys,
+				Errors:           mapValidation(errs),
+				SubmitURL:        "/tools/ssh/accounts/" + id,
+				IsEdit:           true,
+				ShowAliasWarning: showAliasWarning,
+			})
+			return errResponded
+		}
 
-	// Resolver chave (somente se keyMode estiver no form)
-	if r.FormValue("keyMode") != "" {
-		if err := h.resolveAccountKey(r, &a, state); err != nil {
-			h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-			return
+		// Resolver chave (somente se keyMode estiver no form)
+		if r.FormValue("keyMode") != "" {
+			if err := h.resolveAccountKey(r, &a, state); err != nil {
+				return err
+			}
 		}
-	}
 
-	// Atualizar IdentityFile para compat com ApplySSHConfig
-	if a.KeyID != "" {
-		if kidx := findKeyIndex(state.Keys, a.KeyID); kidx >= 0 {
-			a.IdentityFile = state.Keys[kidx].PrivateKeyPath
+		// Atualizar IdentityFile para compat com ApplySSHConfig
+		if a.KeyID != "" {
+			if kidx := findKeyIndex(state.Keys, a.KeyID); kidx >= 0 {
+				a.IdentityFile = state.Keys[kidx].PrivateKeyPath
+			}
 		}
-	}
 
-	state.Accounts[idx] = a
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		state.Accounts[idx] = a
+		return nil
+	})
+	if err != nil {
+		h.updateFailed(w, err, "Conta não encontrada")
 		return
 	}
 
@@ -288,25 +279,20 @@ func (h *Handler) UpdateAccount(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	markHX(w, r)
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	err := h.store(r).Update(func(state *storage.State) error {
+		idx := findAccountIndex(state.Accounts, id)
+		if idx < 0 {
+			return errNotFound
+		}
+		target := state.Accounts[idx]
+		if target.KeyID != "" {
+			h.app.Logger.Info("conta removida, chave permanece no Key Manager", "alias", target.HostAlias, "keyID", target.KeyID)
+		}
+		state.Accounts = append(state.Accounts[:idx], state.Accounts[idx+1:]...)
+		return nil
+	})
 	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
-	idx := findAccountIndex(state.Accounts, id)
-	if idx < 0 {
-		h.operationError(w, "Conta não encontrada", http.StatusNotFound)
-		return
-	}
-	target := state.Accounts[idx]
-	if target.KeyID != "" {
-		h.app.Logger.Info("conta removida, chave permanece no Key Manager", "alias", target.HostAlias, "keyID", target.KeyID)
-	}
-
-	state.Accounts = append(state.Accounts[:idx], state.Accounts[idx+1:]...)
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		h.updateFailed(w, err, "Conta não encontrada")
 		return
 	}
 
@@ -319,14 +305,10 @@ func (h *Handler) ApplySSHConfig(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	// Resolver IdentityFile pelo Key Manager
-	if a.KeyID != "" {
-		if idx := findKeyIndex(state.Keys, a.KeyID); idx >= 0 {
-			a.IdentityFile = state.Keys[idx].PrivateKeyPath
-		}
-	}
+	// Resolver IdentityFile e certificado pelo Key Manager
+	a = state.ResolveKeyFiles(a)
 
-	if err := ssh.ApplyAccount(a, h.app.Paths); err != nil {
+	if err := ssh.ApplyAccount(a, h.app.Paths()); err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
 	}
@@ -340,12 +322,8 @@ func (h *Handler) PreviewApplySSHConfig(w http.ResponseWriter, r *http.Request)
 	if !ok {
 		return
 	}
-	if a.KeyID != "" {
-		if idx := findKeyIndex(state.Keys, a.KeyID); idx >= 0 {
-			a.IdentityFile = state.Keys[idx].PrivateKeyPath
-		}
-	}
-	lines, err := ssh.PreviewApply(a, h.app.Paths)
+	a = state.ResolveKeyFiles(a)
+	lines, err := ssh.PreviewApply(a, h.app.Paths())
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return
@@ -375,7 +353,7 @@ func (h *Handler) TestConnection(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) accountByID(w http.ResponseWriter, r *http.Request) (storage.Account, bool) {
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return storage.Account{}, false
@@ -390,7 +368,7 @@ func (h *Handler) accountByID(w http.ResponseWriter, r *http.Request) (storage.A
 
 func (h *Handler) accountByIDWithState(w http.ResponseWriter, r *http.Request) (storage.Account, *storage.State, bool) {
 	id := chi.URLParam(r, "id")
-	state, err := h.app.Storage.LoadState()
+	state, err := h.app.Storage().LoadState()
 	if err != nil {
 		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
 		return storage.Account{}, nil, false
@@ -425,23 +403,9 @@ func (h *Handler) resolveAccountKey(r *http.Request, account *storage.Account, s
 		}
 		passphrase := []byte(r.FormValue("newKeyPassphrase"))
 
-		alias := account.HostAlias
-		base := alias
-		for i := 2; ; i++ {
-			conflict := false
-			for _, k := range state.Keys {
-				if k.Alias == alias {
-					conflict = true
-					break
-				}
-			}
-			if !conflict {
-				break
-			}
-			alias = fmt.Sprintf("%s-%d", base, i)
-		}
+		alias := state.UniqueKeyAlias(account.HostAlias)
 
-		result, err := ssh.GenerateKeyFull(alias, comment, keyType, bits, passphrase, h.app.Paths)
+		result, err := ssh.GenerateKeyFull(alias, comment, keyType, bits, passphrase, h.app.Paths())
 		if err != nil {
 			return err
 		}
@@ -510,18 +474,6 @@ func buildSSHPreview(a storage.Account, identityPath string) string {
 	}, "\n")
 }
 
-var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
-
-func sanitizeAlias(name string) string {
-	s := strings.ToLower(strings.TrimSpace(name))
-	s = nonAlnum.ReplaceAllString(s, "-")
-	s = strings.Trim(s, "-")
-	if s == "" {
-		s = "key"
-	}
-	return s
-}
-
 func keyTypeLabel(kt string) string {
 	switch kt {
 	case "rsa", "rsa4096":
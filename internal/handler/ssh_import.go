@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -65,50 +66,48 @@ func (h *Handler) ImportSSHAccounts(w http.ResponseWriter, r *http.Request) {
 		selectedSet[s] = true
 	}
 
-	state, err := h.app.Storage.LoadState()
-	if err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
-		return
-	}
-
 	imported := 0
-	for _, block := range blocks {
-		if !selectedSet[block.HostAlias] {
-			continue
-		}
-		// Verificar conflito de alias
-		conflict := false
-		for _, a := range state.Accounts {
-			if a.HostAlias == block.HostAlias {
-				conflict = true
-				break
+	err = h.store(r).Update(func(state *storage.State) error {
+		for _, block := range blocks {
+			if !selectedSet[block.HostAlias] {
+				continue
+			}
+			// Verificar conflito de alias
+			conflict := false
+			for _, a := range state.Accounts {
+				if a.HostAlias == block.HostAlias {
+					conflict = true
+					break
+				}
+			}
+			if conflict {
+				continue
 			}
-		}
-		if conflict {
-			continue
-		}
 
-		a := storage.Account{
-			ID:           newID(),
-			Name:         block.HostAlias,
-			Provider:     "other",
-			HostName:     block.HostName,
-			HostAlias:    block.HostAlias,
-			IdentityFile: block.IdentityFile,
-			CreatedAt:    time.Now(),
-			UpdatedAt:    time.Now(),
+			a := storage.Account{
+				ID:           newID(),
+				Name:         block.HostAlias,
+				Provider:     "other",
+				HostName:     block.HostName,
+				HostAlias:    block.HostAlias,
+				IdentityFile: block.IdentityFile,
+				CreatedAt:    time.Now(),
+				UpdatedAt:    time.Now(),
+			}
+			state.Accounts = append(state.Accounts, a)
+			imported++
 		}
-		state.Accounts = append(state.Accounts, a)
-		imported++
-	}
-
-	if imported == 0 {
+		if imported == 0 {
+			return errNothingImported
+		}
+		return nil
+	})
+	if errors.Is(err, errNothingImported) {
 		h.operationError(w, "Nenhuma conta foi importada (possíveis conflitos de alias)", http.StatusConflict)
 		return
 	}
-
-	if err := h.app.Storage.SaveState(state); err != nil {
-		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+	if err != nil {
+		h.updateFailed(w, err, "")
 		return
 	}
 
@@ -7,9 +7,9 @@ import (
 	"sort"
 	"strconv"
 	"strings"
-	"syscall"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/command"
 )
 
 type processView struct {
@@ -60,8 +60,8 @@ func (h *Handler) KillProcess(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := syscall.Kill(int(pid), syscall.SIGTERM); err != nil {
-		h.errorToast(w, fmt.Sprintf("Erro ao encerrar PID %d: %v", pid, err))
+	if out, err := command.CombinedOutput(r.Context(), command.Cmd{Name: "kill", Args: []string{"-TERM", pidStr}}); err != nil {
+		h.errorToast(w, fmt.Sprintf("Erro ao encerrar PID %d: %v %s", pid, err, strings.TrimSpace(string(out))))
 		w.WriteHeader(http.StatusUnprocessableEntity)
 		return
 	}
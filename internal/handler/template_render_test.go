@@ -2,10 +2,25 @@ package handler
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
 	"html/template"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/seuusuario/factorydev/internal/audit"
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/knownhosts"
+	"github.com/seuusuario/factorydev/internal/privilege"
+	"github.com/seuusuario/factorydev/internal/scheduler"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/terminal"
 	"github.com/seuusuario/factorydev/web"
+	gossh "golang.org/x/crypto/ssh"
 )
 
 func TestDoctorTemplateRendersFullPage(t *testing.T) {
@@ -66,6 +81,7 @@ func TestReposTemplateRendersFullPage(t *testing.T) {
 			"Accounts": []map[string]any{
 				{"ID": "1", "Name": "work", "HostAlias": "github-work", "HasKey": true},
 			},
+			"Repos":       []map[string]any{},
 			"DefaultDest": "/tmp",
 		},
 	}
@@ -86,8 +102,308 @@ func TestReposTemplateRendersPartial(t *testing.T) {
 	var out bytes.Buffer
 	if err := tpl.ExecuteTemplate(&out, "repos/list.html", map[string]any{
 		"Accounts":    []map[string]any{{"ID": "1", "Name": "work", "HostAlias": "github-work", "HasKey": true}},
+		"Repos":       []map[string]any{},
 		"DefaultDest": "/tmp",
 	}); err != nil {
 		t.Fatalf("execute partial: %v", err)
 	}
 }
+
+func TestActivityTemplateRendersFullPage(t *testing.T) {
+	tpl := template.New("root").Funcs(tmplFuncs)
+	_, err := tpl.ParseFS(web.FS,
+		"templates/layout.html",
+		"templates/partials/sidebar.html",
+		"templates/partials/drawer.html",
+		"templates/activity/list.html",
+	)
+	if err != nil {
+		t.Fatalf("parse templates: %v", err)
+	}
+
+	data := PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "activity",
+		ContentTpl: "activity/list.html",
+		Data: map[string]any{
+			"Rows": []activityRow{
+				{Entry: audit.Entry{ID: "1", Entity: "aliases", Op: audit.OpUpdate, Label: "ll"}, BeforeJSON: "{}", AfterJSON: "{}", CanUndo: true},
+				{Entry: audit.Entry{ID: "2", Entity: "aliases", Op: audit.OpDelete, UndoOf: "0"}},
+			},
+			"Entities": []string{"aliases"},
+			"Ops":      []string{audit.OpCreate, audit.OpUpdate, audit.OpDelete},
+			"Filter":   audit.Filter{Entity: "aliases"},
+			"Limit":    activityLimit,
+			"LogPath":  "/tmp/audit.jsonl",
+		},
+	}
+
+	var out bytes.Buffer
+	if err := tpl.ExecuteTemplate(&out, "layout.html", data); err != nil {
+		t.Fatalf("execute layout: %v", err)
+	}
+}
+
+func TestWorkspaceAndSettingsTemplatesRender(t *testing.T) {
+	ws := config.Workspace{Name: "acme", OwnKeys: true}
+	cases := map[string]any{
+		"workspaces/list.html": map[string]any{
+			"Active": "default",
+			"Items":  []map[string]any{{"Workspace": ws, "Active": false, "Dir": "/d", "Keys": "/k", "SSHConfig": "/s"}},
+		},
+		"workspaces/switcher.html":        map[string]any{"Active": "acme", "Items": []config.Workspace{ws}},
+		"settings/page.html":              map[string]any{"Config": config.Defaults(), "Path": "/c", "Overrides": map[string]string{"port": "FDEV_PORT"}, "LoadError": "x"},
+		"workspaces/transfer-drawer.html": map[string]any{"Entities": transferEntities, "Entity": "keys", "Records": []transferRecord{{ID: "1", Label: "k"}}, "Targets": []config.Workspace{ws}, "Source": "default"},
+	}
+	for name, data := range cases {
+		tpl := template.New("root").Funcs(tmplFuncs)
+		if _, err := tpl.ParseFS(web.FS, "templates/"+name); err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tpl.ExecuteTemplate(&out, name, data); err != nil {
+			t.Fatalf("execute %s: %v", name, err)
+		}
+	}
+}
+
+func TestJobsTemplatesRender(t *testing.T) {
+	now := time.Now()
+	running := jobs.Job{ID: "1", Kind: "pull-all", Title: "Pull", Status: jobs.Running, Progress: 50, StartedAt: now,
+		Steps: []jobs.Step{{Name: "app", Done: true, OK: true}, {Name: "api"}}}
+	failed := jobs.Job{ID: "2", Kind: "clone", Title: "Clone", Status: jobs.Failed, Error: "x", Output: "log", StartedAt: now, FinishedAt: now}
+	cases := map[string]any{
+		"jobs/list.html":   map[string]any{"Live": []jobs.Job{running}, "History": []jobs.Job{failed}, "Kinds": []string{"clone"}, "Kind": "clone", "Limit": 100, "HistoryPath": "/j"},
+		"jobs/live.html":   map[string]any{"Live": []jobs.Job{running, failed}},
+		"jobs/detail.html": map[string]any{"Job": running},
+	}
+	for name, data := range cases {
+		tpl := template.New("root").Funcs(tmplFuncs)
+		if _, err := tpl.ParseFS(web.FS, "templates/"+name); err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tpl.ExecuteTemplate(&out, name, data); err != nil {
+			t.Fatalf("execute %s: %v", name, err)
+		}
+	}
+}
+
+func TestSchedulesTemplatesRender(t *testing.T) {
+	now := time.Now()
+	sc := storage.Schedule{ID: "s1", Name: "Pull noturno", Action: "server-test", Target: "srv", Cron: "0 3 * * *", Enabled: true}
+	run := storage.ScheduleRun{ID: "r1", ScheduleID: "s1", JobID: "j1", Status: "failed", Error: "x", CatchUp: true, StartedAt: now}
+	cases := map[string]any{
+		"schedules/list.html": map[string]any{
+			"Schedules": []scheduleView{{Schedule: sc, ActionLabel: "Teste", TargetName: "prod", Next: now, Last: run, HasLast: true}},
+			"Runs":      []scheduleRunView{{ScheduleRun: run, Name: sc.Name}, {ScheduleRun: run}},
+		},
+		"schedules/form.html": map[string]any{
+			"SubmitURL": "/tools/schedules/s1", "IsEdit": true, "Schedule": sc,
+			"Actions":     []scheduler.Action{{Key: "server-test", Label: "Teste", Target: "server"}},
+			"Servers":     []storage.Server{{ID: "srv", Name: "prod"}},
+			"Collections": []storage.APICollection{{ID: "c1", Name: "API"}},
+		},
+	}
+	for name, data := range cases {
+		tpl := template.New("root").Funcs(tmplFuncs)
+		if _, err := tpl.ParseFS(web.FS, "templates/"+name); err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tpl.ExecuteTemplate(&out, name, data); err != nil {
+			t.Fatalf("execute %s: %v", name, err)
+		}
+	}
+}
+
+func TestCommandsTemplatesRender(t *testing.T) {
+	records := []command.Record{
+		{Time: time.Now(), Kind: "exec", Command: "git -C /repo pull", Dir: "/repo", ExitCode: 1, Duration: time.Second, Error: "exit status 1"},
+		{Time: time.Now(), Kind: "write", Command: "/home/u/.ssh/config", Bytes: 120, DryRun: true},
+		{Time: time.Now(), Kind: "exec", Command: "git status", ReadOnly: true},
+	}
+	cases := map[string]any{
+		"commands/list.html": map[string]any{
+			"DryRun": true, "Queries": true, "Records": records,
+			"Privilege": privilege.Status{Method: privilege.MethodSudoPassword, Cached: true, ExpiresAt: time.Now(), Pending: 1},
+		},
+		"partials/dry-run-badge.html": map[string]any{"DryRun": true},
+		"privilege/prompt.html": map[string]any{
+			"Pending":    []privilege.Request{{ID: "p1", Command: "ufw --force enable", Reason: "Ativar o firewall", ExpiresAt: time.Now()}},
+			"Error":      "senha de administrador incorreta",
+			"User":       "dev",
+			"TTLMinutes": 5,
+		},
+	}
+	for name, data := range cases {
+		tpl := template.New("root").Funcs(tmplFuncs)
+		if _, err := tpl.ParseFS(web.FS, "templates/"+name); err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tpl.ExecuteTemplate(&out, name, data); err != nil {
+			t.Fatalf("execute %s: %v", name, err)
+		}
+	}
+}
+
+func TestKeyManagerTemplatesRender(t *testing.T) {
+	id := ssh.AgentIdentity{Type: "ssh-ed25519", Comment: "dev@host", Fingerprint: "SHA256:abc"}
+	key := storage.Key{ID: "k1", Name: "Work", Protected: true, PrivateKeyPath: "/k/id_ed25519"}
+	ca := storage.Key{ID: "ca", Name: "CA", CA: true, Protected: true}
+	now := time.Now()
+	certs := []certView{
+		{Certificate: storage.Certificate{ID: "c2", Serial: 2, Type: "user", Principals: []string{"deploy"}, ValidAfter: now, ValidBefore: now.Add(time.Hour)}, KeyName: "Work", CAName: "CA", Status: "expiring"},
+		{Certificate: storage.Certificate{ID: "c1", Serial: 1, Type: "host", ValidAfter: now}, KeyName: "Work", Status: "valid"},
+	}
+	cases := map[string]any{
+		"keys/agent.html": map[string]any{
+			"Socket":     "/tmp/agent.sock",
+			"Identities": []agentIdentityView{{AgentIdentity: id, ManagedName: "Work"}, {AgentIdentity: id}},
+		},
+		"keys/agent-drawer.html": map[string]any{"Key": key, "Errors": map[string]string{"passphrase": "x"}, "Lifetime": "60", "Confirm": true},
+		"keys/list.html": map[string]any{
+			"Keys": []keyView{
+				{Key: key, HasPrivKey: true, Fingerprint: "SHA256:abc", InAgent: true, Certificates: certs},
+				{Key: ca, HasPrivKey: true, Issued: 2},
+				{Key: storage.Key{ID: "k0", Name: "Old", ArchivedAt: now, ReplacedBy: "k1"}, HasPrivKey: true, ReplacedByName: "Work"},
+			},
+			"Certificates": certs,
+			"Rotations": []rotationView{{
+				KeyRotation: storage.KeyRotation{ID: "r1", JobID: "j1", Status: "partial", StartedAt: now, Steps: []storage.RotationStep{
+					{Kind: "server", Name: "vps", Status: "failed", Message: "login falhou"}, {Kind: "account", Name: "Work", Status: "done"},
+				}},
+				OldName: "Work", NewName: "Work 2",
+			}},
+		},
+		"keys/rotate-drawer.html": rotationFormData{
+			Old: key, New: storage.Key{Name: "Work", Alias: "work-2", Type: "rsa", Bits: 4096},
+			Refs: storage.KeyReferences{
+				Accounts:   []storage.Account{{Name: "Work", HostAlias: "gh-work"}},
+				Servers:    []storage.Server{{ID: "s1", Name: "vps", User: "deploy", Host: "10.0.0.1", Port: 2222}},
+				Identities: []storage.GitIdentity{{Name: "Dev", Email: "dev@example.com"}},
+			},
+			Push: map[string]bool{"s1": true}, Errors: map[string]string{"alias": "x"},
+		},
+		"keys/rotate-progress.html": map[string]any{
+			"ID": "j1", "Title": "Rotação da chave Work", "Done": true, "OK": false, "Error": "falhou",
+			"Steps": []jobs.Step{{Name: "Servidor vps", Done: true, Error: "login falhou"}, {Name: "Contas", Done: true, OK: true}},
+		},
+		"keys/passphrase-drawer.html": map[string]any{
+			"Key": storage.Key{ID: "k1", Name: "Work", Protected: true, Source: "imported"}, "Backup": "/k/id_ed25519.bak",
+			"Errors": map[string]string{"current": "x", "confirm": "y"},
+		},
+		"keys/sign-drawer.html": signFormData{
+			CA: ca, Keys: []storage.Key{key}, KeyID: "k1", Type: "user", Validity: "52w",
+			Extensions: []string{"permit-pty"}, AllExts: ssh.DefaultUserExtensions, Errors: map[string]string{"principals": "x"},
+		},
+	}
+	for name, data := range cases {
+		tpl := template.New("root").Funcs(tmplFuncs)
+		if _, err := tpl.ParseFS(web.FS, "templates/"+name); err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tpl.ExecuteTemplate(&out, name, data); err != nil {
+			t.Fatalf("execute %s: %v", name, err)
+		}
+	}
+}
+
+func TestServerAndKnownHostsTemplatesRender(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(signer.PublicKey())))
+	f, err := knownhosts.Parse(strings.NewReader("github.com " + line + "\n@cert-authority *.corp " + line + "\nquebrada\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Add("db.corp", 2222, signer.PublicKey(), true)
+	state := &storage.State{
+		Servers:  []storage.Server{{Name: "db", Host: "db.corp", Port: 2222}, {Name: "app", Host: "app.corp", Port: 22}},
+		Accounts: []storage.Account{{Name: "Pessoal", HostName: "github.com"}, {Name: "Work", HostName: "GitHub.com"}},
+	}
+	hosts := managedKnownHosts(state, f)
+	if len(hosts) != 3 || len(hosts[2].Names) != 2 || hosts[2].Provider != "GitHub" || len(hosts[0].Entries) != 1 || len(hosts[1].Entries) != 1 {
+		t.Fatalf("hosts gerenciados = %+v", hosts)
+	}
+
+	cases := map[string]any{
+		"servers/exec-drawer.html": execFormData{
+			Servers:  []storage.Server{{ID: "s1", Name: "web", User: "deploy", Host: "10.0.0.1", Port: 22, Tags: []string{"prod"}}},
+			Tags:     []string{"prod"},
+			Selected: map[string]bool{"s1": true},
+			Timeout:  60,
+			Errors:   map[string]string{"command": "x", "server": "y"},
+		},
+		"servers/exec-progress.html": map[string]any{
+			"ID": "j1", "Title": "uptime em 2 servidor(es)", "Done": true, "OK": false, "Error": "falhou em 1 de 2 servidor(es): db",
+			"Duration": time.Second,
+			"Steps":    []jobs.Step{{Name: "web", Done: true, OK: true, Output: "up 3 days"}, {Name: "db", Done: true, Error: "saiu com código 1"}},
+		},
+		"servers/test-progress.html": map[string]any{
+			"ID": "j2", "ServerID": "s1", "Done": true, "OK": false, "Error": "Falha na conexão", "HostKeyChanged": true,
+			"Output": "Chave de host: ssh-ed25519 SHA256:abc (DIVERGE do known_hosts)",
+		},
+		"knownhosts/list.html": map[string]any{
+			"Path": "/home/dev/.ssh/known_hosts", "Hosts": hosts, "Entries": f.Entries, "Invalid": f.Invalid,
+		},
+		"knownhosts/verify-drawer.html": map[string]any{
+			"Host": "github.com", "Port": 22, "Provider": "GitHub", "Current": f.Lookup("github.com", 22),
+			"Keys":   knownhosts.Verify("github.com", []gossh.PublicKey{signer.PublicKey()}),
+			"Errors": map[string]string{"expected": "x"},
+		},
+	}
+	for name, data := range cases {
+		tpl := template.New("root").Funcs(tmplFuncs)
+		if _, err := tpl.ParseFS(web.FS, "templates/"+name); err != nil {
+			t.Fatalf("parse %s: %v", name, err)
+		}
+		var out bytes.Buffer
+		if err := tpl.ExecuteTemplate(&out, name, data); err != nil {
+			t.Fatalf("execute %s: %v", name, err)
+		}
+	}
+}
+
+func TestTerminalTemplateRenders(t *testing.T) {
+	payload := map[string]any{
+		"Sessions": []terminal.Session{
+			{ID: "a1", Kind: terminal.KindLocal, Title: "api", Target: "/home/dev/api", Recording: "20260101-120000-a1.cast"},
+			{ID: "b2", Kind: terminal.KindSSH, Title: "web", Target: "deploy@10.0.0.1", Exited: true, ExitCode: 130},
+		},
+		"Active":     "b2",
+		"Repos":      []storage.Repository{{ID: "r1", Name: "api", LocalPath: "/home/dev/api"}},
+		"Servers":    []storage.Server{{ID: "s1", Name: "web", User: "deploy", Host: "10.0.0.1"}},
+		"Recordings": []terminal.Recording{{Name: "20260101-120000-a1.cast", Title: "api", Started: time.Now(), Size: 4096}},
+		"DryRun":     true,
+	}
+	tpl := template.New("root").Funcs(tmplFuncs)
+	if _, err := tpl.ParseFS(web.FS,
+		"templates/layout.html",
+		"templates/partials/sidebar.html",
+		"templates/partials/drawer.html",
+		"templates/terminal/page.html",
+	); err != nil {
+		t.Fatalf("parse templates: %v", err)
+	}
+	var out bytes.Buffer
+	if err := tpl.ExecuteTemplate(&out, "layout.html", PageData{
+		Title: "FactoryDev", ActiveTool: "terminal", ContentTpl: "terminal/page.html", Data: payload,
+	}); err != nil {
+		t.Fatalf("execute layout: %v", err)
+	}
+	html := out.String()
+	for _, want := range []string{`data-session="a1" hidden`, `class="fdev-tab-btn fdev-terminal-tab active"`, "saiu (130)", "4.0 KB", "xterm.min.js"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("página sem %q", want)
+		}
+	}
+}
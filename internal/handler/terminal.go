@@ -0,0 +1,324 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/command"
+	idocker "github.com/seuusuario/factorydev/internal/docker"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/terminal"
+	"github.com/seuusuario/factorydev/internal/websocket"
+)
+
+// Tamanho inicial do terminal; o navegador manda o real ao conectar.
+const (
+	terminalCols = 120
+	terminalRows = 32
+)
+
+// terminalMessage é uma mensagem de texto do WebSocket do terminal. Do
+// navegador chegam "input" e "resize"; o servidor manda "exit" no fim. A
+// saída do processo vai em mensagens binárias.
+type terminalMessage struct {
+	Type  string `json:"type"`
+	Data  string `json:"data,omitempty"`
+	Cols  int    `json:"cols,omitempty"`
+	Rows  int    `json:"rows,omitempty"`
+	Code  int    `json:"code,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// GET /tools/terminal?session=
+func (h *Handler) TerminalPage(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	sessions := h.app.Terminals.List()
+	active := r.URL.Query().Get("session")
+	if _, ok := h.app.Terminals.Get(active); !ok && len(sessions) > 0 {
+		active = sessions[len(sessions)-1].ID
+	}
+	payload := map[string]any{
+		"Sessions": sessions,
+		"Active":   active,
+		"Repos":    state.Repositories,
+		"Servers":  state.Servers,
+		"DryRun":   command.DryRun(),
+	}
+	if recs, err := h.app.Terminals.Recordings(); err == nil {
+		payload["Recordings"] = recs
+	}
+	if cli, err := newDockerClient(); err == nil && cli.Available() {
+		ctx, cancel := context.WithTimeout(r.Context(), 3*time.Second)
+		defer cancel()
+		if list, err := cli.ListContainers(ctx); err == nil {
+			var running []idocker.ContainerInfo
+			for _, c := range list {
+				if c.State == "running" {
+					running = append(running, c)
+				}
+			}
+			payload["Containers"] = running
+		}
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "terminal/page.html", payload)
+		return
+	}
+	h.render(w, "terminal/page.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "terminal",
+		ContentTpl: "terminal/page.html",
+		Data:       payload,
+	})
+}
+
+// POST /tools/terminal/sessions
+// kind=local&repoId= | kind=ssh&serverId= | kind=docker&container=
+func (h *Handler) CreateTerminalSession(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	record := r.FormValue("record") == "on"
+	switch r.FormValue("kind") {
+	case terminal.KindLocal:
+		h.startRepoTerminal(w, r, r.FormValue("repoId"), record)
+	case terminal.KindSSH:
+		h.startServerTerminal(w, r, r.FormValue("serverId"), record)
+	case terminal.KindDocker:
+		h.startContainerTerminal(w, r, r.FormValue("container"), record)
+	default:
+		h.operationError(w, "Tipo de sessão inválido", http.StatusBadRequest)
+	}
+}
+
+// startRepoTerminal abre um shell local no diretório do repositório; sem
+// repoId, na home.
+func (h *Handler) startRepoTerminal(w http.ResponseWriter, r *http.Request, repoID string, record bool) {
+	dir, title := h.app.Paths().Home, "~"
+	if repoID != "" {
+		state, err := h.app.Storage().LoadState()
+		if err != nil {
+			h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+			return
+		}
+		var repo *storage.Repository
+		for i := range state.Repositories {
+			if state.Repositories[i].ID == repoID {
+				repo = &state.Repositories[i]
+				break
+			}
+		}
+		if repo == nil || repo.LocalPath == "" {
+			h.operationError(w, "Repositório não encontrado", http.StatusNotFound)
+			return
+		}
+		if _, err := os.Stat(repo.LocalPath); err != nil {
+			h.operationError(w, "Diretório do repositório não encontrado: "+repo.LocalPath, http.StatusUnprocessableEntity)
+			return
+		}
+		dir, title = repo.LocalPath, repo.Name
+	}
+	h.startTerminal(w, r, terminal.Options{Kind: terminal.KindLocal, Title: title, Target: dir, Record: record},
+		func(context.Context) (terminal.Process, error) {
+			return terminal.StartLocal(dir, terminalCols, terminalRows)
+		})
+}
+
+func (h *Handler) startServerTerminal(w http.ResponseWriter, r *http.Request, serverID string, record bool) {
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	srv, keyPath := findServerAndKey(state, serverID)
+	if srv == nil {
+		h.operationError(w, "Servidor não encontrado", http.StatusNotFound)
+		return
+	}
+	target := serverTarget(srv, keyPath)
+	h.startTerminal(w, r, terminal.Options{Kind: terminal.KindSSH, Title: srv.Name, Target: target.User + "@" + target.Host, Record: record},
+		func(ctx context.Context) (terminal.Process, error) {
+			return ssh.OpenShell(ctx, target, terminalCols, terminalRows)
+		})
+}
+
+func (h *Handler) startContainerTerminal(w http.ResponseWriter, r *http.Request, id string, record bool) {
+	if id == "" {
+		h.operationError(w, "Selecione um container", http.StatusUnprocessableEntity)
+		return
+	}
+	cli, err := newDockerClient()
+	if err != nil {
+		h.operationError(w, "Docker indisponível: "+err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	title := id
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	if c, err := cli.GetContainer(ctx, id); err == nil {
+		title = c.Name
+		if len(title) > 0 && title[0] == '/' {
+			title = title[1:]
+		}
+	}
+	h.startTerminal(w, r, terminal.Options{Kind: terminal.KindDocker, Title: title, Target: id, Record: record},
+		func(ctx context.Context) (terminal.Process, error) {
+			return cli.OpenShell(ctx, id, terminalCols, terminalRows)
+		})
+}
+
+// startTerminal inicia o processo, registra a sessão e leva o navegador
+// para a aba dela. Em dry-run nenhuma sessão é aberta: um shell interativo
+// não tem como ser simulado.
+func (h *Handler) startTerminal(w http.ResponseWriter, r *http.Request, opts terminal.Options, start func(context.Context) (terminal.Process, error)) {
+	if command.DryRun() {
+		h.operationError(w, "Terminal indisponível em modo dry-run", http.StatusConflict)
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+	proc, err := start(ctx)
+	if err != nil {
+		h.app.Logger.Warn("falha ao abrir terminal", "kind", opts.Kind, "target", opts.Target, "err", err)
+		h.operationError(w, "Não foi possível abrir o terminal: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	opts.Cols, opts.Rows = terminalCols, terminalRows
+	sess, err := h.app.Terminals.Start(opts, proc)
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	h.app.Logger.Info("terminal aberto", "kind", sess.Kind, "target", sess.Target, "session", sess.ID)
+
+	loc, _ := json.Marshal(map[string]string{
+		"path":   "/tools/terminal?session=" + url.QueryEscape(sess.ID),
+		"target": "#main-content",
+	})
+	w.Header().Set("HX-Location", string(loc))
+}
+
+// GET /tools/terminal/sessions/{id}/ws
+// Manda o histórico da sessão e depois a saída ao vivo; recebe a entrada e
+// os redimensionamentos.
+func (h *Handler) TerminalSocket(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	history, output, detach, err := h.app.Terminals.Attach(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	defer detach()
+	conn, err := websocket.Upgrade(w, r)
+	if err != nil {
+		h.app.Logger.Warn("websocket do terminal recusado", "session", id, "err", err)
+		return
+	}
+
+	go func() {
+		// Leitura: termina quando o navegador fecha a conexão.
+		defer detach()
+		for {
+			typ, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			var msg terminalMessage
+			if typ != websocket.TextMessage || json.Unmarshal(data, &msg) != nil {
+				continue
+			}
+			switch msg.Type {
+			case "input":
+				err = h.app.Terminals.Write(id, []byte(msg.Data))
+			case "resize":
+				err = h.app.Terminals.Resize(id, msg.Cols, msg.Rows)
+			}
+			if errors.Is(err, terminal.ErrNotFound) {
+				return
+			}
+		}
+	}()
+
+	if len(history) > 0 {
+		if err := conn.WriteMessage(websocket.BinaryMessage, history); err != nil {
+			conn.Close("")
+			return
+		}
+	}
+	for data := range output {
+		if err := conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+			conn.Close("")
+			return
+		}
+	}
+
+	// Canal fechado: o processo terminou, a sessão foi fechada, o cliente
+	// ficou para trás ou desconectou.
+	sess, ok := h.app.Terminals.Get(id)
+	switch {
+	case ok && sess.Exited:
+		msg, _ := json.Marshal(terminalMessage{Type: "exit", Code: sess.ExitCode, Error: sess.Error})
+		_ = conn.WriteMessage(websocket.TextMessage, msg)
+		conn.Close("processo terminou")
+	case !ok:
+		conn.Close("sessão encerrada")
+	default:
+		conn.Close("reconectar")
+	}
+}
+
+// DELETE /tools/terminal/sessions/{id}
+func (h *Handler) CloseTerminalSession(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	id := chi.URLParam(r, "id")
+	if err := h.app.Terminals.Close(id); err != nil {
+		h.operationError(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	h.app.Logger.Info("terminal encerrado", "session", id)
+	h.successToast(w, "Sessão encerrada")
+}
+
+// GET /tools/terminal/recordings/{name}
+// Devolve a gravação (asciicast v2); com ?download=1, como anexo.
+func (h *Handler) TerminalRecording(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	path, err := h.app.Terminals.RecordingPath(name)
+	if err != nil {
+		http.Error(w, "Gravação não encontrada", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	w.Header().Set("Cache-Control", "no-store")
+	if r.URL.Query().Get("download") != "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	http.ServeFile(w, r, path)
+}
+
+// DELETE /tools/terminal/recordings/{name}
+func (h *Handler) DeleteTerminalRecording(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := h.app.Terminals.RemoveRecording(chi.URLParam(r, "name")); err != nil {
+		h.operationError(w, "Gravação não encontrada", http.StatusNotFound)
+		return
+	}
+	h.successToast(w, "Gravação removida")
+}
@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+// GET /tools/vault
+func (h *Handler) VaultPage(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	payload := map[string]any{
+		"Status": h.app.Vault.Status(),
+	}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "vault/panel.html", payload)
+		return
+	}
+	h.render(w, "vault/panel.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "vault",
+		ContentTpl: "vault/panel.html",
+		Data:       payload,
+	})
+}
+
+// POST /tools/vault/init
+// Define a passphrase mestra e sela os segredos que ainda estão em texto puro
+// em todos os workspaces: o vault é um só para a instalação.
+func (h *Handler) InitVault(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	pass := r.FormValue("passphrase")
+	if pass != r.FormValue("confirm") {
+		h.errorToast(w, "As passphrases não conferem")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+	if err := h.app.Vault.Init(pass); err != nil {
+		h.errorToast(w, err.Error())
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := h.store(r).Update(h.sealStateSecrets); err != nil {
+		h.operationError(w, "Erro ao selar segredos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := h.sealOtherWorkspaces(r.Method + " " + r.URL.Path); err != nil {
+		h.operationError(w, "Erro ao selar segredos: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.app.Logger.Info("vault configurado")
+	h.successToast(w, "Vault configurado e desbloqueado")
+}
+
+// POST /tools/vault/unlock
+func (h *Handler) UnlockVault(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := h.app.Vault.Unlock(r.FormValue("passphrase")); err != nil {
+		if errors.Is(err, vault.ErrWrongPassphrase) {
+			h.errorToast(w, "Passphrase incorreta")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		h.operationError(w, "Erro ao desbloquear: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.successToast(w, "Vault desbloqueado")
+}
+
+// POST /tools/vault/lock
+func (h *Handler) LockVault(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.app.Vault.Lock()
+	closeAllDBConns()
+	h.successToast(w, "Vault bloqueado")
+}
+
+// ── Helpers ──────────────────────────────────────────────────────
+
+// vaultError responde a erros de selar/revelar segredos. Vault bloqueado vira
+// 423 com instrução para desbloquear; o resto é erro interno.
+func (h *Handler) vaultError(w http.ResponseWriter, err error) {
+	if errors.Is(err, vault.ErrLocked) {
+		h.operationError(w, "Vault bloqueado — desbloqueie em Vault para acessar os segredos", http.StatusLocked)
+		return
+	}
+	h.operationError(w, "Erro no vault: "+err.Error(), http.StatusInternalServerError)
+}
+
+// sealOtherWorkspaces sela os segredos dos workspaces que não estão
+// abertos; sem isso eles ficariam em texto puro até o próximo salvamento.
+func (h *Handler) sealOtherWorkspaces(route string) error {
+	reg, err := h.app.Workspaces()
+	if err != nil {
+		return err
+	}
+	active := h.app.Workspace().Name
+	for _, ws := range reg.Items {
+		if ws.Name == active {
+			continue
+		}
+		_, rec, err := h.app.OpenWorkspace(ws)
+		if err != nil {
+			return err
+		}
+		err = rec.WithRoute(route).Update(h.sealStateSecrets)
+		_ = rec.Close()
+		if err != nil {
+			return fmt.Errorf("workspace %s: %w", ws.Name, err)
+		}
+	}
+	return nil
+}
+
+// sealStateSecrets sela todos os campos sensíveis do state. Valores já
+// selados são mantidos.
+func (h *Handler) sealStateSecrets(st *storage.State) error {
+	for i := range st.DBConnections {
+		if err := h.sealDBConnection(&st.DBConnections[i]); err != nil {
+			return err
+		}
+	}
+	for i := range st.APICollections {
+		if err := h.sealCollection(&st.APICollections[i]); err != nil {
+			return err
+		}
+	}
+	for i := range st.EnvFiles {
+		if err := h.sealEnvFile(&st.EnvFiles[i]); err != nil {
+			return err
+		}
+	}
+	for i := range st.MCPServers {
+		if err := h.sealMCPServer(&st.MCPServers[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *Handler) sealDBConnection(c *storage.DBConnection) (err error) {
+	c.Password, err = h.app.Vault.Seal(c.Password)
+	return err
+}
+
+func (h *Handler) revealDBConnection(c *storage.DBConnection) (err error) {
+	c.Password, err = h.app.Vault.Reveal(c.Password)
+	return err
+}
+
+func (h *Handler) sealCollection(c *storage.APICollection) (err error) {
+	c.AuthData, err = h.app.Vault.SealMap(c.AuthData)
+	return err
+}
+
+// revealCollection devolve uma cópia da collection com AuthData decifrado.
+func (h *Handler) revealCollection(c storage.APICollection) (storage.APICollection, error) {
+	auth, err := h.app.Vault.RevealMap(c.AuthData)
+	if err != nil {
+		return c, err
+	}
+	c.AuthData = auth
+	return c, nil
+}
+
+func (h *Handler) sealEnvFile(e *storage.EnvFile) (err error) {
+	e.Variables, err = h.app.Vault.SealMap(e.Variables)
+	return err
+}
+
+func (h *Handler) revealEnvFile(e *storage.EnvFile) (err error) {
+	e.Variables, err = h.app.Vault.RevealMap(e.Variables)
+	return err
+}
+
+func (h *Handler) sealMCPServer(s *storage.MCPServer) (err error) {
+	s.Env, err = h.app.Vault.SealMap(s.Env)
+	return err
+}
+
+func (h *Handler) revealMCPServer(s *storage.MCPServer) (err error) {
+	s.Env, err = h.app.Vault.RevealMap(s.Env)
+	return err
+}
+
+// secretMask substitui um segredo selado quando o vault está bloqueado.
+var secretMask = strings.Repeat("•", 8)
+
+// maskSealed troca valores selados por uma máscara, para listagens com o
+// vault bloqueado.
+func maskSealed(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		if vault.IsSealed(v) {
+			v = secretMask
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+// TestInitVaultSealsEveryWorkspace configura o vault com segredos em texto
+// puro no workspace ativo e em outro: os dois saem selados.
+func TestInitVaultSealsEveryWorkspace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	a, err := app.New(&config.Config{Host: "127.0.0.1", Port: 7777, Storage: "json"})
+	if err != nil {
+		t.Fatalf("app.New: %v", err)
+	}
+	if err := a.CreateWorkspace(config.Workspace{Name: "acme"}); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := a.Workspaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	acme, _ := reg.Get("acme")
+	seed := func(st *storage.State) error {
+		st.EnvFiles = []storage.EnvFile{{ID: "e1", Name: "api", Variables: map[string]string{"TOKEN": "abc"}}}
+		return nil
+	}
+	if err := a.Storage().Update(seed); err != nil {
+		t.Fatal(err)
+	}
+	_, rec, err := a.OpenWorkspace(acme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Update(seed); err != nil {
+		t.Fatal(err)
+	}
+	rec.Close()
+
+	form := url.Values{"passphrase": {"correct horse"}, "confirm": {"correct horse"}}
+	req := httptest.NewRequest("POST", "/tools/vault/init", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	New(a).InitVault(w, req)
+	if w.Code != 200 {
+		t.Fatalf("init = %d %s", w.Code, w.Body)
+	}
+
+	_, rec, err = a.OpenWorkspace(acme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rec.Close()
+	for name, st := range map[string]storage.Storage{"ativo": a.Storage(), "acme": rec} {
+		state, err := st.LoadState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := state.EnvFiles[0].Variables["TOKEN"]; !vault.IsSealed(v) {
+			t.Errorf("workspace %s: TOKEN em texto puro: %q", name, v)
+		}
+	}
+}
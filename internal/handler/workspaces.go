@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/seuusuario/factorydev/internal/app"
+	"github.com/seuusuario/factorydev/internal/audit"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// transferEntities são as entidades que podem ir de um workspace a outro,
+// na ordem exibida no drawer.
+var transferEntities = []struct{ Name, Label string }{
+	{"accounts", "Contas SSH/Git"},
+	{"keys", "Chaves SSH"},
+	{"identities", "Identidades Git"},
+	{"repositories", "Repositórios"},
+	{"servers", "Servidores"},
+	{"env_files", "Arquivos .env"},
+	{"aliases", "Aliases"},
+	{"api_collections", "Collections de API"},
+	{"api_endpoints", "Endpoints de API"},
+	{"db_connections", "Conexões de banco"},
+	{"mcp_servers", "MCP servers"},
+	{"custom_skills", "Skills"},
+	{"schedules", "Agendamentos"},
+}
+
+type transferRecord struct {
+	ID    string
+	Label string
+}
+
+// GET /tools/workspaces
+func (h *Handler) ListWorkspaces(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	reg, err := h.app.Workspaces()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+
+	items := make([]map[string]any, 0, len(reg.Items))
+	for _, ws := range reg.Items {
+		p := h.app.Paths().ForWorkspace(ws)
+		items = append(items, map[string]any{
+			"Workspace": ws,
+			"Active":    ws.Name == h.app.Workspace().Name,
+			"Dir":       p.WorkspaceDir(),
+			"Keys":      p.Keys,
+			"SSHConfig": p.SSHConfig(),
+		})
+	}
+
+	payload := map[string]any{"Items": items, "Active": h.app.Workspace().Name}
+	if r.Header.Get("HX-Request") == "true" {
+		h.render(w, "workspaces/list.html", payload)
+		return
+	}
+	h.render(w, "workspaces/list.html", PageData{
+		Title:      "FactoryDev",
+		ActiveTool: "workspaces",
+		ContentTpl: "workspaces/list.html",
+		Data:       payload,
+	})
+}
+
+// GET /tools/workspaces/switcher
+func (h *Handler) WorkspaceSwitcher(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	reg, err := h.app.Workspaces()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	h.render(w, "workspaces/switcher.html", map[string]any{
+		"Items":  reg.Items,
+		"Active": h.app.Workspace().Name,
+	})
+}
+
+// POST /tools/workspaces/switch
+func (h *Handler) SwitchWorkspace(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	if err := h.app.SwitchWorkspace(name); err != nil {
+		h.workspaceError(w, err)
+		return
+	}
+	// Todas as telas abertas passam a mostrar outro state: recarrega a página.
+	w.Header().Set("HX-Refresh", "true")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /tools/workspaces/new
+func (h *Handler) NewWorkspaceDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	h.renderDrawer(w, "Novo workspace", "workspaces/workspace-drawer.html", map[string]any{})
+}
+
+// POST /tools/workspaces
+func (h *Handler) CreateWorkspace(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	ws := config.Workspace{
+		Name:        strings.ToLower(strings.TrimSpace(r.FormValue("name"))),
+		Description: strings.TrimSpace(r.FormValue("description")),
+		OwnKeys:     r.FormValue("ownKeys") == "on",
+		SSHInclude:  r.FormValue("sshInclude") == "on",
+	}
+	if err := h.app.CreateWorkspace(ws); err != nil {
+		h.workspaceError(w, err)
+		return
+	}
+	h.successToast(w, "Workspace criado: "+ws.Name)
+}
+
+// DELETE /tools/workspaces/{name}
+func (h *Handler) DeleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	name := chi.URLParam(r, "name")
+	if err := h.app.RemoveWorkspace(name); err != nil {
+		h.workspaceError(w, err)
+		return
+	}
+	h.successToast(w, "Workspace removido: "+name)
+}
+
+// GET /tools/workspaces/transfer
+func (h *Handler) TransferDrawer(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	entity := r.URL.Query().Get("entity")
+	if entity == "" {
+		entity = transferEntities[0].Name
+	}
+	reg, err := h.app.Workspaces()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	state, err := h.app.Storage().LoadState()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	set := storage.EntitySetByName(state, entity)
+	if set == nil {
+		h.operationError(w, "Entidade desconhecida", http.StatusBadRequest)
+		return
+	}
+	snap, order, err := set.Snapshot()
+	if err != nil {
+		h.operationError(w, app.FriendlyMessage(err), http.StatusInternalServerError)
+		return
+	}
+	records := make([]transferRecord, 0, len(order))
+	for _, id := range order {
+		label := audit.Label(snap[id])
+		if label == "" {
+			label = id
+		}
+		records = append(records, transferRecord{ID: id, Label: label})
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Label < records[j].Label })
+
+	var targets []config.Workspace
+	for _, ws := range reg.Items {
+		if ws.Name != h.app.Workspace().Name {
+			targets = append(targets, ws)
+		}
+	}
+
+	h.renderDrawer(w, "Mover/copiar registros", "workspaces/transfer-drawer.html", map[string]any{
+		"Entities": transferEntities,
+		"Entity":   entity,
+		"Records":  records,
+		"Targets":  targets,
+		"Source":   h.app.Workspace().Name,
+	})
+}
+
+// POST /tools/workspaces/transfer
+func (h *Handler) TransferEntities(w http.ResponseWriter, r *http.Request) {
+	markHX(w, r)
+	if err := r.ParseForm(); err != nil {
+		h.operationError(w, "Formulário inválido", http.StatusBadRequest)
+		return
+	}
+	opts := app.TransferOptions{
+		Target: r.FormValue("target"),
+		Entity: r.FormValue("entity"),
+		IDs:    r.Form["ids"],
+		Move:   r.FormValue("mode") == "move",
+		Route:  r.Method + " " + r.URL.Path,
+	}
+	if opts.Target == "" || len(opts.IDs) == 0 {
+		h.errorToast(w, "Escolha o workspace de destino e ao menos um registro")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		return
+	}
+
+	res, err := h.app.TransferEntities(opts)
+	if err != nil {
+		h.workspaceError(w, err)
+		return
+	}
+
+	verb := "copiado(s)"
+	if opts.Move {
+		verb = "movido(s)"
+	}
+	msg := fmt.Sprintf("%d registro(s) %s para %s", res.Copied, verb, opts.Target)
+	if res.Keys > 0 {
+		msg += fmt.Sprintf(", %d chave(s) copiada(s) junto", res.Keys)
+	}
+	if len(res.Skipped) > 0 {
+		msg += fmt.Sprintf("; ignorados: %s", strings.Join(res.Skipped, ", "))
+	}
+	h.successToast(w, msg)
+}
+
+// ── Helpers ───────────────────────────────────────────────────
+
+func (h *Handler) workspaceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, config.ErrWorkspaceNotFound):
+		h.operationError(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, config.ErrWorkspaceExists):
+		h.errorToast(w, "Já existe um workspace com esse nome")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	default:
+		// As mensagens de workspace já são voltadas ao usuário.
+		h.app.Logger.Warn("operação de workspace", "err", err)
+		h.errorToast(w, err.Error())
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+}
@@ -77,30 +77,32 @@ func DetectPM() PackageManager {
 	return PMNone
 }
 
-// InstallCmd retorna o comando completo para instalar a ferramenta.
-func InstallCmd(t Tool, pm PackageManager) (string, []string) {
+// InstallCmd retorna o comando para instalar a ferramenta; admin indica que
+// ele precisa rodar como administrador (apt e dnf; o brew recusa root).
+func InstallCmd(t Tool, pm PackageManager) (bin string, args []string, admin bool) {
 	switch pm {
 	case PMBrew:
-		return "brew", []string{"install", t.BrewName}
+		return "brew", []string{"install", t.BrewName}, false
 	case PMApt:
-		return "sudo", []string{"apt-get", "install", "-y", t.AptName}
+		return "apt-get", []string{"install", "-y", t.AptName}, true
 	case PMDnf:
-		return "sudo", []string{"dnf", "install", "-y", t.DnfName}
+		return "dnf", []string{"install", "-y", t.DnfName}, true
 	default:
-		return "", nil
+		return "", nil, false
 	}
 }
 
-// UninstallCmd retorna o comando completo para desinstalar a ferramenta.
-func UninstallCmd(t Tool, pm PackageManager) (string, []string) {
+// UninstallCmd retorna o comando para desinstalar a ferramenta, como
+// InstallCmd.
+func UninstallCmd(t Tool, pm PackageManager) (bin string, args []string, admin bool) {
 	switch pm {
 	case PMBrew:
-		return "brew", []string{"uninstall", t.BrewName}
+		return "brew", []string{"uninstall", t.BrewName}, false
 	case PMApt:
-		return "sudo", []string{"apt-get", "remove", "-y", t.AptName}
+		return "apt-get", []string{"remove", "-y", t.AptName}, true
 	case PMDnf:
-		return "sudo", []string{"dnf", "remove", "-y", t.DnfName}
+		return "dnf", []string{"remove", "-y", t.DnfName}, true
 	default:
-		return "", nil
+		return "", nil, false
 	}
 }
@@ -0,0 +1,73 @@
+// Package instance garante um único servidor FactoryDev por usuário. O
+// primeiro processo segura o lock em ~/.fdev/instance.lock e grava em
+// instance.json onde está escutando; um segundo lançamento lê esse endereço
+// e apenas abre a interface já em execução, em vez de falhar com a porta
+// ocupada.
+package instance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrRunning indica que outra instância já segura o lock.
+var ErrRunning = errors.New("o FactoryDev já está em execução")
+
+// Info descreve a instância em execução.
+type Info struct {
+	PID int `json:"pid"`
+	// URL é a base da interface (sem token), ex: http://127.0.0.1:7331.
+	URL       string    `json:"url"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+const (
+	lockName = "instance.lock"
+	infoName = "instance.json"
+)
+
+// Acquire tenta se tornar a instância ativa em dir. Com outra instância no
+// ar devolve ErrRunning junto com os dados dela. release apaga
+// instance.json e solta o lock; deve ser chamado no shutdown.
+func Acquire(dir string, info Info) (release func(), running Info, err error) {
+	unlock, ok, err := tryLock(dir)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	if !ok {
+		running, _ = Read(dir)
+		return nil, running, ErrRunning
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		unlock()
+		return nil, Info{}, err
+	}
+	path := filepath.Join(dir, infoName)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		unlock()
+		return nil, Info{}, fmt.Errorf("gravar %s: %w", infoName, err)
+	}
+	return func() {
+		_ = os.Remove(path)
+		unlock()
+	}, Info{}, nil
+}
+
+// Read lê os dados gravados pela instância ativa.
+func Read(dir string) (Info, error) {
+	var info Info
+	data, err := os.ReadFile(filepath.Join(dir, infoName))
+	if err != nil {
+		return info, err
+	}
+	if err := json.Unmarshal(data, &info); err != nil {
+		return info, fmt.Errorf("ler %s: %w", infoName, err)
+	}
+	return info, nil
+}
@@ -0,0 +1,36 @@
+package instance
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAcquireSecondInstance(t *testing.T) {
+	dir := t.TempDir()
+	first := Info{PID: 100, URL: "http://127.0.0.1:7331", StartedAt: time.Now().UTC()}
+	release, _, err := Acquire(dir, first)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+
+	_, running, err := Acquire(dir, Info{PID: 200, URL: "http://127.0.0.1:7332"})
+	if !errors.Is(err, ErrRunning) {
+		t.Fatalf("segunda instância: err = %v", err)
+	}
+	if running.PID != 100 || running.URL != first.URL {
+		t.Fatalf("instância em execução = %+v", running)
+	}
+
+	release()
+	if _, err := os.Stat(filepath.Join(dir, infoName)); !os.IsNotExist(err) {
+		t.Fatalf("instance.json não removido: %v", err)
+	}
+	release, _, err = Acquire(dir, Info{PID: 300})
+	if err != nil {
+		t.Fatalf("Acquire após release: %v", err)
+	}
+	release()
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package instance
+
+import (
+	"net/http"
+	"time"
+)
+
+// tryLock no Windows não usa lock de arquivo: considera a vaga ocupada se a
+// instância registrada em instance.json ainda responde em /health.
+func tryLock(dir string) (unlock func(), ok bool, err error) {
+	if info, err := Read(dir); err == nil && info.URL != "" {
+		client := http.Client{Timeout: time.Second}
+		if resp, err := client.Get(info.URL + "/health"); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil, false, nil
+			}
+		}
+	}
+	return func() {}, true, nil
+}
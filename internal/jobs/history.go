@@ -0,0 +1,155 @@
+package jobs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	// historyKeep é quantos jobs sobrevivem à compactação do arquivo.
+	historyKeep = 500
+	// historyOutput limita o log gravado por job.
+	historyOutput = 16 << 10
+)
+
+// History é o arquivo append-only de jobs terminados, um JSON por linha.
+type History struct {
+	path  string
+	mu    sync.Mutex
+	lines int // -1 = ainda não contado
+}
+
+func NewHistory(path string) *History {
+	return &History{path: path, lines: -1}
+}
+
+// Append grava o job e compacta o arquivo quando passa de 2×historyKeep.
+func (h *History) Append(j Job) error {
+	if len(j.Output) > historyOutput {
+		j.Output = "…" + j.Output[len(j.Output)-historyOutput:]
+	}
+	for i := range j.Steps {
+		if len(j.Steps[i].Output) > historyOutput {
+			j.Steps[i].Output = "…" + j.Steps[i].Output[len(j.Steps[i].Output)-historyOutput:]
+		}
+	}
+	line, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	if h.lines < 0 {
+		all, err := h.readLocked()
+		if err != nil {
+			return err
+		}
+		h.lines = len(all)
+	} else {
+		h.lines++
+	}
+	if h.lines > 2*historyKeep {
+		return h.compactLocked()
+	}
+	return nil
+}
+
+// List devolve até limit jobs (0 = todos), mais recentes primeiro.
+func (h *History) List(limit int) ([]Job, error) {
+	h.mu.Lock()
+	all, err := h.readLocked()
+	h.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Job, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		out = append(out, all[i])
+		if limit > 0 && len(out) == limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// Find procura um job pelo ID.
+func (h *History) Find(id string) (Job, bool) {
+	all, err := h.List(0)
+	if err != nil {
+		return Job{}, false
+	}
+	for _, j := range all {
+		if j.ID == id {
+			return j, true
+		}
+	}
+	return Job{}, false
+}
+
+func (h *History) readLocked() ([]Job, error) {
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []Job
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for sc.Scan() {
+		var j Job
+		if json.Unmarshal(sc.Bytes(), &j) == nil {
+			out = append(out, j)
+		}
+	}
+	return out, sc.Err()
+}
+
+// compactLocked reescreve o arquivo mantendo só os historyKeep mais recentes.
+func (h *History) compactLocked() error {
+	all, err := h.readLocked()
+	if err != nil {
+		return err
+	}
+	if len(all) > historyKeep {
+		all = all[len(all)-historyKeep:]
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, j := range all {
+		if err := enc.Encode(j); err != nil {
+			return err
+		}
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return err
+	}
+	h.lines = len(all)
+	return nil
+}
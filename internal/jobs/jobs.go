@@ -0,0 +1,458 @@
+// Package jobs executa operações longas em segundo plano (clone, pull, testes
+// SSH, pull de imagens, instalador...) num registro único: cada job tem tipo,
+// progresso, log incremental, cancelamento via context e, ao terminar, entra
+// no histórico persistido em ~/.fdev/jobs.jsonl.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/events"
+)
+
+// Status do ciclo de vida de um job.
+type Status string
+
+const (
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+	Canceled  Status = "canceled"
+)
+
+var (
+	ErrNotFound = errors.New("job não encontrado")
+	ErrFinished = errors.New("job já terminou")
+)
+
+// maxOutput limita o log guardado em memória; mantém o final.
+const maxOutput = 256 << 10
+
+// Step é uma etapa de um job com várias partes (ex: pull de cada repositório).
+type Step struct {
+	Name   string `json:"name"`
+	Done   bool   `json:"done"`
+	OK     bool   `json:"ok"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Job é o snapshot de um job. Os valores devolvidos pelo Manager são cópias.
+type Job struct {
+	ID         string            `json:"id"`
+	Kind       string            `json:"kind"`
+	Title      string            `json:"title"`
+	Status     Status            `json:"status"`
+	Progress   int               `json:"progress"` // 0–100
+	Output     string            `json:"output,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Steps      []Step            `json:"steps,omitempty"`
+	Meta       map[string]string `json:"meta,omitempty"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt time.Time         `json:"finishedAt,omitzero"`
+}
+
+func (j Job) Done() bool { return j.Status != Running }
+func (j Job) OK() bool   { return j.Status == Succeeded }
+
+// Duration é o tempo de execução até agora (ou até o fim).
+func (j Job) Duration() time.Duration {
+	end := j.FinishedAt
+	if end.IsZero() {
+		end = time.Now()
+	}
+	return end.Sub(j.StartedAt).Round(time.Second)
+}
+
+func (j Job) clone() Job {
+	j.Steps = append([]Step(nil), j.Steps...)
+	if j.Meta != nil {
+		meta := make(map[string]string, len(j.Meta))
+		for k, v := range j.Meta {
+			meta[k] = v
+		}
+		j.Meta = meta
+	}
+	return j
+}
+
+// Options descreve o job a iniciar.
+type Options struct {
+	Kind    string // ex: "clone", "pull-all", "docker-pull"
+	Title   string
+	Timeout time.Duration // 0 = sem limite
+	Steps   []string      // nomes das etapas, se houver
+	Meta    map[string]string
+}
+
+// Func é o corpo do job. O erro devolvido define o status final e sua
+// mensagem é a exibida ao usuário; o cancelamento do context vira Canceled.
+type Func func(run *Run) error
+
+type entry struct {
+	job     Job
+	cancel  context.CancelFunc
+	done    chan struct{}
+	partial string // linha de log ainda sem quebra
+}
+
+// Manager guarda os jobs em memória e o histórico em disco.
+type Manager struct {
+	mu      sync.Mutex
+	jobs    map[string]*entry
+	ttl     time.Duration
+	history *History
+
+	// Events, quando definido, recebe as linhas de log ("log") e as mudanças
+	// de estado ("update", "done") em events.JobTopic(id); atualizações e
+	// términos também vão para events.TopicJobs.
+	Events *events.Bus
+	// Hold, quando definido, é chamado ao iniciar cada job; a função
+	// devolvida roda quando ele termina. O App o usa para manter aberto o
+	// storage do workspace durante o job.
+	Hold func() (release func())
+}
+
+// New cria o manager. Jobs terminados ficam em memória por ttl; depois disso
+// só aparecem no histórico (historyPath, vazio = sem histórico).
+func New(historyPath string, ttl time.Duration) *Manager {
+	m := &Manager{jobs: make(map[string]*entry), ttl: ttl}
+	if historyPath != "" {
+		m.history = NewHistory(historyPath)
+	}
+	return m
+}
+
+// Start registra o job e executa fn numa goroutine.
+func (m *Manager) Start(opts Options, fn Func) Job {
+	ctx, cancel := context.Background(), context.CancelFunc(func() {})
+	if opts.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+	}
+	ctx, cancelRun := context.WithCancelCause(ctx)
+
+	e := &entry{
+		job: Job{
+			ID:        newID(),
+			Kind:      opts.Kind,
+			Title:     opts.Title,
+			Status:    Running,
+			Meta:      opts.Meta,
+			StartedAt: time.Now(),
+		},
+		cancel: func() { cancelRun(errCanceled) },
+		done:   make(chan struct{}),
+	}
+	for _, name := range opts.Steps {
+		e.job.Steps = append(e.job.Steps, Step{Name: name})
+	}
+
+	m.mu.Lock()
+	m.pruneLocked()
+	m.jobs[e.job.ID] = e
+	snap := e.job.clone()
+	m.mu.Unlock()
+	m.publish("update", snap)
+
+	run := &Run{m: m, e: e, ctx: ctx}
+	release := func() {}
+	if m.Hold != nil {
+		release = m.Hold()
+	}
+	go func() {
+		defer release()
+		defer cancel()
+		defer close(e.done)
+		err := runSafely(fn, run)
+		m.finish(e, ctx, err)
+	}()
+	return snap
+}
+
+// errCanceled é a causa registrada por Cancel.
+var errCanceled = errors.New("cancelado pelo usuário")
+
+func runSafely(fn Func, run *Run) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return fn(run)
+}
+
+func (m *Manager) finish(e *entry, ctx context.Context, err error) {
+	m.mu.Lock()
+	j := &e.job
+	rest := e.partial
+	e.partial = ""
+	j.FinishedAt = time.Now()
+	switch {
+	case errors.Is(context.Cause(ctx), errCanceled):
+		j.Status = Canceled
+		j.Error = errCanceled.Error()
+	case err != nil:
+		j.Status = Failed
+		j.Error = err.Error()
+	default:
+		j.Status = Succeeded
+		j.Progress = 100
+	}
+	snap := j.clone()
+	m.mu.Unlock()
+
+	if rest != "" {
+		m.publishLog(snap.ID, []string{rest})
+	}
+	if m.history != nil {
+		_ = m.history.Append(snap)
+	}
+	m.publish("done", snap)
+}
+
+// publish notifica uma mudança de estado do job.
+func (m *Manager) publish(typ string, j Job) {
+	if m.Events == nil {
+		return
+	}
+	m.Events.Publish(events.Event{Topic: events.JobTopic(j.ID), Type: typ, Data: j})
+	m.Events.Publish(events.Event{Topic: events.TopicJobs, Type: typ, Data: j})
+}
+
+// logLine é o payload dos eventos "log".
+type logLine struct {
+	ID   string `json:"id"`
+	Line string `json:"line"`
+}
+
+func (m *Manager) publishLog(id string, lines []string) {
+	if m.Events == nil {
+		return
+	}
+	for _, l := range lines {
+		m.Events.Publish(events.Event{Topic: events.JobTopic(id), Type: "log", Data: logLine{ID: id, Line: l}})
+	}
+}
+
+// Get devolve o job em memória ou, se já expirou, do histórico.
+func (m *Manager) Get(id string) (Job, bool) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	var snap Job
+	if ok {
+		snap = e.job.clone()
+	}
+	m.mu.Unlock()
+	if ok {
+		return snap, true
+	}
+	if m.history != nil {
+		return m.history.Find(id)
+	}
+	return Job{}, false
+}
+
+// List devolve os jobs em memória, mais recentes primeiro.
+func (m *Manager) List() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneLocked()
+	out := make([]Job, 0, len(m.jobs))
+	for _, e := range m.jobs {
+		out = append(out, e.job.clone())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.After(out[j].StartedAt) })
+	return out
+}
+
+// History devolve até limit jobs terminados, mais recentes primeiro.
+func (m *Manager) History(limit int) ([]Job, error) {
+	if m.history == nil {
+		return nil, nil
+	}
+	return m.history.List(limit)
+}
+
+// Cancel interrompe um job em execução.
+func (m *Manager) Cancel(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.jobs[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if e.job.Status != Running {
+		return ErrFinished
+	}
+	e.cancel()
+	return nil
+}
+
+// Wait bloqueia até o job terminar ou ctx expirar.
+func (m *Manager) Wait(ctx context.Context, id string) (Job, error) {
+	m.mu.Lock()
+	e, ok := m.jobs[id]
+	m.mu.Unlock()
+	if !ok {
+		if j, found := m.Get(id); found {
+			return j, nil
+		}
+		return Job{}, ErrNotFound
+	}
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+	j, _ := m.Get(id)
+	return j, nil
+}
+
+// pruneLocked remove da memória os jobs terminados há mais de ttl.
+func (m *Manager) pruneLocked() {
+	if m.ttl <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-m.ttl)
+	for id, e := range m.jobs {
+		if e.job.Status != Running && e.job.FinishedAt.Before(cutoff) {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Run é o handle passado ao corpo do job para reportar andamento.
+type Run struct {
+	m   *Manager
+	e   *entry
+	ctx context.Context
+}
+
+// Context é cancelado por Cancel ou pelo timeout do job.
+func (r *Run) Context() context.Context { return r.ctx }
+
+// ID do job em execução.
+func (r *Run) ID() string { return r.e.job.ID }
+
+// Write acrescenta ao log do job e publica cada linha completa; permite usar
+// o Run como Stdout/Stderr de comandos externos. "\r" (barras de progresso
+// do git e do docker) também encerra a linha.
+func (r *Run) Write(p []byte) (int, error) {
+	r.m.mu.Lock()
+	out := r.e.job.Output + string(p)
+	if len(out) > maxOutput {
+		out = out[len(out)-maxOutput:]
+	}
+	r.e.job.Output = out
+
+	var lines []string
+	buf := r.e.partial + string(p)
+	for {
+		i := strings.IndexAny(buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		if line := buf[:i]; line != "" {
+			lines = append(lines, line)
+		}
+		buf = buf[i+1:]
+	}
+	r.e.partial = buf
+	id := r.e.job.ID
+	r.m.mu.Unlock()
+
+	r.m.publishLog(id, lines)
+	return len(p), nil
+}
+
+// Log acrescenta uma linha ao log.
+func (r *Run) Log(format string, args ...any) {
+	line := fmt.Sprintf(format, args...)
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	_, _ = r.Write([]byte(line))
+}
+
+// SetProgress define o progresso em porcentagem.
+func (r *Run) SetProgress(pct int) {
+	r.m.mu.Lock()
+	r.e.job.Progress = min(max(pct, 0), 100)
+	snap := r.e.job.clone()
+	r.m.mu.Unlock()
+	r.m.publish("update", snap)
+}
+
+// FinishStep marca a etapa i como concluída e recalcula o progresso.
+// output vazio mantém o que foi escrito com StepWriter.
+func (r *Run) FinishStep(i int, output string, err error) {
+	r.m.mu.Lock()
+	steps := r.e.job.Steps
+	if i < 0 || i >= len(steps) {
+		r.m.mu.Unlock()
+		return
+	}
+	steps[i].Done, steps[i].OK = true, err == nil
+	if output != "" {
+		steps[i].Output = output
+	}
+	if err != nil {
+		steps[i].Error = err.Error()
+	}
+	done := 0
+	for _, s := range steps {
+		if s.Done {
+			done++
+		}
+	}
+	r.e.job.Progress = done * 100 / len(steps)
+	snap := r.e.job.clone()
+	r.m.mu.Unlock()
+	r.m.publish("update", snap)
+}
+
+// StepWriter devolve um io.Writer que acrescenta à saída da etapa i e
+// publica a atualização, para etapas que rodam em paralelo e mostram a
+// própria saída enquanto executam (ex: comando em vários servidores).
+func (r *Run) StepWriter(i int) io.Writer {
+	return stepWriter{r: r, i: i}
+}
+
+type stepWriter struct {
+	r *Run
+	i int
+}
+
+func (w stepWriter) Write(p []byte) (int, error) {
+	r := w.r
+	r.m.mu.Lock()
+	steps := r.e.job.Steps
+	if w.i < 0 || w.i >= len(steps) {
+		r.m.mu.Unlock()
+		return len(p), nil
+	}
+	out := steps[w.i].Output + string(p)
+	if limit := maxOutput / len(steps); len(out) > limit {
+		out = out[len(out)-limit:]
+	}
+	steps[w.i].Output = out
+	snap := r.e.job.clone()
+	r.m.mu.Unlock()
+	r.m.publish("update", snap)
+	return len(p), nil
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,133 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/events"
+)
+
+func waitJob(t *testing.T, m *Manager, id string) Job {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	j, err := m.Wait(ctx, id)
+	if err != nil {
+		t.Fatalf("wait %s: %v", id, err)
+	}
+	return j
+}
+
+func TestJobLifecycleAndHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.jsonl")
+	m := New(path, time.Hour)
+
+	ok := m.Start(Options{Kind: "pull-all", Title: "Pull", Steps: []string{"a", "b"}}, func(run *Run) error {
+		run.Log("iniciando")
+		run.FinishStep(0, "ok", nil)
+		run.FinishStep(1, "", errors.New("falhou"))
+		return errors.New("1 de 2 falharam")
+	})
+	j := waitJob(t, m, ok.ID)
+	if j.Status != Failed || j.Error != "1 de 2 falharam" || j.Progress != 100 {
+		t.Fatalf("job inesperado: %+v", j)
+	}
+	if !j.Steps[0].OK || j.Steps[1].OK || j.Steps[1].Error != "falhou" || j.Output != "iniciando\n" {
+		t.Fatalf("etapas/log inesperados: %+v", j)
+	}
+
+	started := make(chan struct{})
+	c := m.Start(Options{Kind: "clone"}, func(run *Run) error {
+		close(started)
+		<-run.Context().Done()
+		return run.Context().Err()
+	})
+	<-started
+	if err := m.Cancel(c.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if j := waitJob(t, m, c.ID); j.Status != Canceled {
+		t.Fatalf("status após cancel = %s", j.Status)
+	}
+	if err := m.Cancel(c.ID); !errors.Is(err, ErrFinished) {
+		t.Fatalf("cancel de job terminado = %v", err)
+	}
+
+	// Um novo manager (reinício) só enxerga o histórico.
+	m2 := New(path, time.Hour)
+	if len(m2.List()) != 0 {
+		t.Fatalf("jobs em memória após reinício")
+	}
+	hist, err := m2.History(0)
+	if err != nil || len(hist) != 2 || hist[0].ID != c.ID {
+		t.Fatalf("histórico = %+v (%v)", hist, err)
+	}
+	if got, found := m2.Get(ok.ID); !found || got.Kind != "pull-all" {
+		t.Fatalf("Get pelo histórico = %+v, %v", got, found)
+	}
+}
+
+func TestPruneAfterTTL(t *testing.T) {
+	m := New("", time.Millisecond)
+	j := m.Start(Options{Kind: "x"}, func(*Run) error { return nil })
+	waitJob(t, m, j.ID)
+	time.Sleep(5 * time.Millisecond)
+	if n := len(m.List()); n != 0 {
+		t.Fatalf("jobs após TTL = %d", n)
+	}
+}
+
+func TestRunPublishesLogLines(t *testing.T) {
+	bus := events.New()
+	m := New("", time.Hour)
+	m.Events = bus
+
+	release := make(chan struct{})
+	j := m.Start(Options{Kind: "clone"}, func(run *Run) error {
+		<-release
+		_, _ = run.Write([]byte("Cloning into 'app'...\nReceiving objects:  50%\rReceiving objects: 100%\n"))
+		_, _ = run.Write([]byte("sem quebra"))
+		return nil
+	})
+	ch, cancel := bus.Subscribe(events.JobTopic(j.ID))
+	defer cancel()
+	close(release)
+
+	var lines []string
+	for e := range ch {
+		if e.Type == "log" {
+			lines = append(lines, e.Data.(logLine).Line)
+		}
+		if e.Type == "done" {
+			break
+		}
+	}
+	want := []string{"Cloning into 'app'...", "Receiving objects:  50%", "Receiving objects: 100%", "sem quebra"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Fatalf("linhas = %q", lines)
+	}
+}
+
+func TestStepWriter(t *testing.T) {
+	m := New("", time.Hour)
+	j := m.Start(Options{Kind: "server-exec", Steps: []string{"a", "b"}}, func(run *Run) error {
+		fmt.Fprint(run.StepWriter(0), "saída ")
+		fmt.Fprint(run.StepWriter(0), "de a\n")
+		run.FinishStep(0, "", nil)
+		fmt.Fprint(run.StepWriter(1), "parcial\n")
+		run.FinishStep(1, "final", errors.New("falhou"))
+		return nil
+	})
+	done := waitJob(t, m, j.ID)
+	if s := done.Steps[0]; !s.OK || s.Output != "saída de a\n" {
+		t.Fatalf("etapa a = %+v", s)
+	}
+	if s := done.Steps[1]; s.OK || s.Output != "final" || s.Error != "falhou" {
+		t.Fatalf("etapa b = %+v", s)
+	}
+}
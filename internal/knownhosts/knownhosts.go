@@ -0,0 +1,260 @@
+// Package knownhosts lê e edita o ~/.ssh/known_hosts preservando as linhas
+// que não altera: entende padrões com curinga e negação, hosts com porta
+// ([host]:2222), entradas hasheadas (|1|salt|hash, HashKnownHosts) e os
+// marcadores @cert-authority e @revoked. Também busca as chaves de host de
+// um servidor (como o ssh-keyscan) e confere fingerprints publicados pelos
+// provedores Git antes da primeira conexão.
+package knownhosts
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/sshconfig"
+	gossh "golang.org/x/crypto/ssh"
+	xknownhosts "golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Marcadores do known_hosts.
+const (
+	MarkerCertAuthority = "@cert-authority"
+	MarkerRevoked       = "@revoked"
+)
+
+// Entry é uma linha de chave do known_hosts.
+type Entry struct {
+	Line   int    // posição no arquivo, a partir de 1
+	Marker string // "", MarkerCertAuthority ou MarkerRevoked
+	// Hosts são os padrões como escritos; numa entrada hasheada, um único
+	// "|1|salt|hash".
+	Hosts   []string
+	Hashed  bool
+	Key     gossh.PublicKey
+	Comment string
+}
+
+// Type é o tipo da chave (ex: ssh-ed25519).
+func (e Entry) Type() string { return e.Key.Type() }
+
+// Fingerprint é o fingerprint SHA256 da chave.
+func (e Entry) Fingerprint() string { return gossh.FingerprintSHA256(e.Key) }
+
+// Match informa se a entrada vale para host na porta dada (0 ou 22 é a
+// padrão), com a mesma regra do ssh: algum padrão casa e nenhum negado.
+func (e Entry) Match(host string, port int) bool {
+	name := Address(host, port)
+	if e.Hashed {
+		return matchHashed(e.Hosts[0], name)
+	}
+	name = strings.ToLower(name)
+	ok := false
+	for _, p := range e.Hosts {
+		neg := strings.HasPrefix(p, "!")
+		if sshconfig.Wildcard(strings.ToLower(strings.TrimPrefix(p, "!")), name) {
+			if neg {
+				return false
+			}
+			ok = true
+		}
+	}
+	return ok
+}
+
+// File é um known_hosts em memória.
+type File struct {
+	lines   []string
+	Entries []Entry
+	// Invalid são as linhas de chave que não puderam ser lidas; são
+	// mantidas como estão ao gravar.
+	Invalid []int
+}
+
+// Parse lê um known_hosts.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for sc.Scan() {
+		f.lines = append(f.lines, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	f.index()
+	return f, nil
+}
+
+// Load lê o arquivo em path; um arquivo inexistente é um known_hosts vazio.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &File{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Parse(bytes.NewReader(data))
+}
+
+// index recalcula Entries e Invalid a partir das linhas.
+func (f *File) index() {
+	f.Entries, f.Invalid = nil, nil
+	for i, raw := range f.lines {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := parseEntry(line)
+		if err != nil {
+			f.Invalid = append(f.Invalid, i+1)
+			continue
+		}
+		e.Line = i + 1
+		f.Entries = append(f.Entries, e)
+	}
+}
+
+func parseEntry(line string) (Entry, error) {
+	var e Entry
+	// Os campos podem vir separados por espaços ou tabs, em qualquer número.
+	fields := strings.Fields(line)
+	if len(fields) > 0 && strings.HasPrefix(fields[0], "@") {
+		if fields[0] != MarkerCertAuthority && fields[0] != MarkerRevoked {
+			return e, fmt.Errorf("marcador desconhecido %q", fields[0])
+		}
+		e.Marker, fields = fields[0], fields[1:]
+	}
+	if len(fields) < 2 {
+		return e, errors.New("linha sem chave")
+	}
+	hosts := fields[0]
+	key, comment, _, _, err := gossh.ParseAuthorizedKey([]byte(strings.Join(fields[1:], " ")))
+	if err != nil {
+		return e, err
+	}
+	e.Key, e.Comment = key, comment
+	e.Hashed = strings.HasPrefix(hosts, "|1|")
+	if e.Hashed {
+		e.Hosts = []string{hosts}
+	} else {
+		e.Hosts = strings.Split(hosts, ",")
+	}
+	return e, nil
+}
+
+// Lookup devolve as entradas que valem para host:port.
+func (f *File) Lookup(host string, port int) []Entry {
+	var out []Entry
+	for _, e := range f.Entries {
+		if e.Match(host, port) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Remove apaga as linhas informadas (números de Entry.Line) e devolve
+// quantas foram removidas.
+func (f *File) Remove(lines ...int) int {
+	drop := make(map[int]bool, len(lines))
+	for _, n := range lines {
+		if n >= 1 && n <= len(f.lines) {
+			drop[n] = true
+		}
+	}
+	if len(drop) == 0 {
+		return 0
+	}
+	kept := f.lines[:0:0]
+	for i, l := range f.lines {
+		if !drop[i+1] {
+			kept = append(kept, l)
+		}
+	}
+	f.lines = kept
+	f.index()
+	return len(drop)
+}
+
+// RemoveHost apaga as chaves de host:port, como o `ssh-keygen -R`. Linhas
+// com marcador (CAs e chaves revogadas) são mantidas.
+func (f *File) RemoveHost(host string, port int) int {
+	var lines []int
+	for _, e := range f.Lookup(host, port) {
+		if e.Marker == "" {
+			lines = append(lines, e.Line)
+		}
+	}
+	return f.Remove(lines...)
+}
+
+// Add acrescenta a chave de host:port; com hash, o host é gravado como
+// HashKnownHosts faria.
+func (f *File) Add(host string, port int, key gossh.PublicKey, hash bool) {
+	name := Address(host, port)
+	if hash {
+		name = xknownhosts.HashHostname(name)
+	}
+	f.lines = append(f.lines, name+" "+strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key))))
+	f.index()
+}
+
+// Bytes devolve o conteúdo do arquivo.
+func (f *File) Bytes() []byte {
+	var b bytes.Buffer
+	for _, l := range f.lines {
+		b.WriteString(l)
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// Save grava o arquivo em path; a versão anterior fica em path.old, como no
+// `ssh-keygen -R`.
+func (f *File) Save(path string) error {
+	if old, err := os.ReadFile(path); err == nil {
+		if err := command.WriteFile(path+".old", old, 0o600); err != nil {
+			return fmt.Errorf("backup do known_hosts: %w", err)
+		}
+	}
+	return command.WriteFile(path, f.Bytes(), 0o600)
+}
+
+// Address é o host como o ssh o grava no known_hosts: o nome puro na porta
+// 22 e [host]:porta nas demais.
+func Address(host string, port int) string {
+	if port == 0 {
+		port = 22
+	}
+	return xknownhosts.Normalize(net.JoinHostPort(host, strconv.Itoa(port)))
+}
+
+// matchHashed confere name com uma entrada |1|salt|hash (HMAC-SHA1).
+func matchHashed(entry, name string) bool {
+	parts := strings.Split(entry, "|")
+	if len(parts) != 4 || parts[1] != "1" {
+		return false
+	}
+	salt, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := base64.StdEncoding.DecodeString(parts[3])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha1.New, salt)
+	mac.Write([]byte(name))
+	return hmac.Equal(mac.Sum(nil), want)
+}
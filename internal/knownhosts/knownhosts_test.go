@@ -0,0 +1,225 @@
+package knownhosts
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	gossh "golang.org/x/crypto/ssh"
+	xknownhosts "golang.org/x/crypto/ssh/knownhosts"
+)
+
+func newKey(t *testing.T) (gossh.PublicKey, gossh.Signer) {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.PublicKey(), signer
+}
+
+func authorized(k gossh.PublicKey) string {
+	return strings.TrimSpace(string(gossh.MarshalAuthorizedKey(k)))
+}
+
+func TestParseAndMatch(t *testing.T) {
+	a, _ := newKey(t)
+	b, _ := newKey(t)
+	c, _ := newKey(t)
+	src := strings.Join([]string{
+		"# comentário",
+		"github.com," + "140.82.112.3 " + authorized(a),
+		xknownhosts.HashHostname("[db.corp]:2222") + " " + authorized(b) + " db",
+		"*.corp,!bastion.corp " + authorized(c),
+		"@cert-authority *.corp " + authorized(a),
+		"@revoked * " + authorized(b),
+		"lixo sem chave",
+		"",
+	}, "\n")
+	f, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Entries) != 5 || len(f.Invalid) != 1 || f.Invalid[0] != 7 {
+		t.Fatalf("entries=%d invalid=%v", len(f.Entries), f.Invalid)
+	}
+	if got := string(f.Bytes()); got != src {
+		t.Fatalf("round-trip alterou o arquivo:\n%s", got)
+	}
+
+	hashed := f.Entries[1]
+	if !hashed.Hashed || hashed.Comment != "db" || hashed.Line != 3 {
+		t.Fatalf("entrada hasheada = %+v", hashed)
+	}
+	if !hashed.Match("db.corp", 2222) || hashed.Match("db.corp", 22) {
+		t.Fatal("match de entrada hasheada com porta")
+	}
+	if e := f.Entries[4]; e.Marker != MarkerRevoked || e.Fingerprint() != gossh.FingerprintSHA256(b) {
+		t.Fatalf("marcador = %+v", e)
+	}
+
+	lines := func(es []Entry) []int {
+		var out []int
+		for _, e := range es {
+			out = append(out, e.Line)
+		}
+		return out
+	}
+	cases := map[string]struct {
+		host string
+		port int
+		want []int
+	}{
+		"nome":      {"GitHub.com", 22, []int{2, 6}},
+		"ip":        {"140.82.112.3", 0, []int{2, 6}},
+		"curinga":   {"app.corp", 22, []int{4, 5, 6}},
+		"negado":    {"bastion.corp", 22, []int{5, 6}},
+		"com porta": {"db.corp", 2222, []int{3, 6}},
+		"outra":     {"app.corp", 2200, []int{6}},
+	}
+	for name, c := range cases {
+		got := lines(f.Lookup(c.host, c.port))
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: linhas = %v, quero %v", name, got, c.want)
+		}
+	}
+}
+
+// TestParseSeparators aceita campos separados por tabs ou vários espaços,
+// como o OpenSSH.
+func TestParseSeparators(t *testing.T) {
+	k, _ := newKey(t)
+	typ, blob, _ := strings.Cut(authorized(k), " ")
+	cases := map[string]struct {
+		line    string
+		marker  string
+		comment string
+	}{
+		"tab":              {"github.com\t" + typ + "\t" + blob, "", ""},
+		"vários espaços":   {"github.com   " + typ + "  " + blob + "   ci", "", "ci"},
+		"marcador com tab": {"@cert-authority\tgithub.com\t" + typ + " " + blob, MarkerCertAuthority, ""},
+		"misto":            {"@revoked \t github.com \t" + typ + "\t " + blob + "\tvelha", MarkerRevoked, "velha"},
+	}
+	for name, c := range cases {
+		f, err := Parse(strings.NewReader(c.line + "\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(f.Entries) != 1 || len(f.Invalid) != 0 {
+			t.Errorf("%s: entries=%d invalid=%v", name, len(f.Entries), f.Invalid)
+			continue
+		}
+		e := f.Entries[0]
+		if e.Marker != c.marker || e.Comment != c.comment || !e.Match("github.com", 22) || e.Fingerprint() != gossh.FingerprintSHA256(k) {
+			t.Errorf("%s: entrada = %+v", name, e)
+		}
+	}
+}
+
+func TestRemoveAddSave(t *testing.T) {
+	a, _ := newKey(t)
+	b, _ := newKey(t)
+	src := "app.corp " + authorized(a) + "\n@cert-authority app.corp " + authorized(a) + "\nother " + authorized(b) + "\n"
+	f, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := f.RemoveHost("app.corp", 22); n != 1 {
+		t.Fatalf("removidas = %d", n)
+	}
+	if len(f.Lookup("app.corp", 22)) != 1 || f.Entries[0].Marker != MarkerCertAuthority {
+		t.Fatal("a linha de CA deveria ser mantida")
+	}
+	f.Add("app.corp", 22, b, true)
+	f.Add("app.corp", 2222, b, false)
+	if got := f.Lookup("app.corp", 22); len(got) != 2 || !got[1].Hashed {
+		t.Fatalf("após Add = %+v", got)
+	}
+	if got := f.Entries[len(f.Entries)-1]; got.Hosts[0] != "[app.corp]:2222" {
+		t.Fatalf("host com porta = %v", got.Hosts)
+	}
+	if n := f.Remove(1, 99); n != 1 || len(f.Entries) != 3 {
+		t.Fatalf("Remove = %d, entries = %d", n, len(f.Entries))
+	}
+
+	path := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(path, []byte(src), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if old, _ := os.ReadFile(path + ".old"); string(old) != src {
+		t.Fatal("backup não guarda a versão anterior")
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Entries) != 3 {
+		t.Fatalf("recarregado = %d entradas", len(loaded.Entries))
+	}
+	if missing, err := Load(filepath.Join(t.TempDir(), "nada")); err != nil || len(missing.Entries) != 0 {
+		t.Fatalf("arquivo inexistente: %v", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	a, _ := newKey(t)
+	got := Verify("GitHub.com", []gossh.PublicKey{a})
+	if got[0].Status != StatusMismatch || got[0].Expected != Providers[0].Fingerprints[gossh.KeyAlgoED25519] {
+		t.Fatalf("provedor com chave diferente = %+v", got[0])
+	}
+	if got := Verify("app.corp", []gossh.PublicKey{a}); got[0].Status != StatusUnverified {
+		t.Fatalf("sem fingerprint esperado = %+v", got[0])
+	}
+	fp := strings.TrimPrefix(gossh.FingerprintSHA256(a), "SHA256:")
+	if got := Verify("app.corp", []gossh.PublicKey{a}, " "+fp+"= "); got[0].Status != StatusVerified {
+		t.Fatalf("fingerprint informado = %+v", got[0])
+	}
+}
+
+func TestScan(t *testing.T) {
+	pub, signer := newKey(t)
+	cfg := &gossh.ServerConfig{NoClientAuth: true}
+	cfg.AddHostKey(signer)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				gossh.NewServerConn(conn, cfg)
+			}()
+		}
+	}()
+
+	port := l.Addr().(*net.TCPAddr).Port
+	keys, err := Scan(context.Background(), "127.0.0.1", port)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || gossh.FingerprintSHA256(keys[0]) != gossh.FingerprintSHA256(pub) {
+		t.Fatalf("chaves = %v", keys)
+	}
+	if _, err := Scan(context.Background(), "127.0.0.1", port, gossh.KeyAlgoRSASHA512); err == nil {
+		t.Fatal("esperava erro sem algoritmo em comum")
+	}
+}
@@ -0,0 +1,116 @@
+package knownhosts
+
+import (
+	"strings"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Provider é um provedor Git com fingerprints de host publicados.
+type Provider struct {
+	Name string
+	Host string
+	// Fingerprints SHA256 por tipo de chave, como publicados na
+	// documentação do provedor.
+	Fingerprints map[string]string
+}
+
+// Providers são os fingerprints oficiais de GitHub, GitLab e Bitbucket.
+var Providers = []Provider{
+	{
+		Name: "GitHub",
+		Host: "github.com",
+		Fingerprints: map[string]string{
+			gossh.KeyAlgoED25519:  "SHA256:+DiY3wvvV6TuJJhbpZisF/zLDA0zPMSvHdkr4UvCOqU",
+			gossh.KeyAlgoECDSA256: "SHA256:p2QAMXNIC1TJYWeIOttrVc98/R1BUFWu3/LiyKgUfQM",
+			gossh.KeyAlgoRSA:      "SHA256:uNiVztksCsDhcc0u9e8BujQXVUpKZIDTMczCvj3tD2s",
+		},
+	},
+	{
+		Name: "GitLab",
+		Host: "gitlab.com",
+		Fingerprints: map[string]string{
+			gossh.KeyAlgoED25519:  "SHA256:eUXGGm1YGsMAS7vkcx6JOJdOGHPem5gQp4taiCfCLB8",
+			gossh.KeyAlgoECDSA256: "SHA256:HbW3g8zUjNSksFbqTiUWPWg2Bq1x8xdGUrliXFzSnUw",
+			gossh.KeyAlgoRSA:      "SHA256:ROQFvPThGrW4RuWLoL9tq9I9zJ42fK4XywyRtbOz/EQ",
+		},
+	},
+	{
+		Name: "Bitbucket",
+		Host: "bitbucket.org",
+		Fingerprints: map[string]string{
+			gossh.KeyAlgoED25519:  "SHA256:ybgmFkzwOSotHTHLJgHO0QN8L0xErw6vd0VhFA9m3SM",
+			gossh.KeyAlgoECDSA256: "SHA256:FC73VB6C4OQLSCrjEayhMp9UMxS97caD/Yyi2bhW/J0",
+			gossh.KeyAlgoRSA:      "SHA256:46OSHA1Rmj8E8ERTC6xkNcmGOw9oFxYr0WF6zWW8l1E",
+		},
+	},
+}
+
+// ProviderFor devolve o provedor de host, se houver.
+func ProviderFor(host string) (Provider, bool) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, p := range Providers {
+		if p.Host == host {
+			return p, true
+		}
+	}
+	return Provider{}, false
+}
+
+// Status da verificação de uma chave de host.
+const (
+	StatusVerified   = "verified"   // confere com o fingerprint esperado
+	StatusMismatch   = "mismatch"   // difere do fingerprint esperado
+	StatusUnverified = "unverified" // nada a comparar
+)
+
+// Verified é uma chave de host com o resultado da verificação.
+type Verified struct {
+	Key         gossh.PublicKey
+	Fingerprint string
+	Expected    string
+	Status      string
+}
+
+// Type é o tipo da chave.
+func (v Verified) Type() string { return v.Key.Type() }
+
+// Verify compara as chaves obtidas com os fingerprints do provedor de host
+// e com expected (fingerprints informados pelo usuário, com ou sem o
+// prefixo "SHA256:"). Uma chave só é verificada se algum fingerprint
+// esperado conferir; se houver um esperado para o tipo e nenhum conferir,
+// é divergente.
+func Verify(host string, keys []gossh.PublicKey, expected ...string) []Verified {
+	provider, known := ProviderFor(host)
+	want := make(map[string]bool, len(expected))
+	for _, fp := range expected {
+		if fp = normalizeFingerprint(fp); fp != "" {
+			want[fp] = true
+		}
+	}
+	out := make([]Verified, 0, len(keys))
+	for _, k := range keys {
+		v := Verified{Key: k, Fingerprint: gossh.FingerprintSHA256(k), Status: StatusUnverified}
+		if known {
+			v.Expected = provider.Fingerprints[k.Type()]
+		}
+		switch {
+		case want[v.Fingerprint] || (v.Expected != "" && v.Expected == v.Fingerprint):
+			v.Status = StatusVerified
+			v.Expected = v.Fingerprint
+		case v.Expected != "":
+			v.Status = StatusMismatch
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func normalizeFingerprint(fp string) string {
+	fp = strings.TrimSpace(fp)
+	if fp == "" {
+		return ""
+	}
+	fp = strings.TrimRight(strings.TrimPrefix(fp, "SHA256:"), "=")
+	return "SHA256:" + fp
+}
@@ -0,0 +1,85 @@
+package knownhosts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// DefaultAlgorithms são os tipos de chave de host buscados por Scan.
+var DefaultAlgorithms = []string{
+	gossh.KeyAlgoED25519,
+	gossh.KeyAlgoECDSA256,
+	gossh.KeyAlgoRSASHA512,
+}
+
+// errCaptured interrompe o handshake assim que a chave de host chega.
+var errCaptured = errors.New("chave capturada")
+
+// Scan busca as chaves de host de host:port, uma por algoritmo, como o
+// ssh-keyscan: o handshake é abortado logo após o servidor apresentar a
+// chave, sem autenticar. Algoritmos que o servidor não oferece são
+// ignorados; só há erro se nenhuma chave for obtida.
+func Scan(ctx context.Context, host string, port int, algorithms ...string) ([]gossh.PublicKey, error) {
+	if port == 0 {
+		port = 22
+	}
+	if len(algorithms) == 0 {
+		algorithms = DefaultAlgorithms
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(port))
+	var keys []gossh.PublicKey
+	var lastErr error
+	for _, algo := range algorithms {
+		key, err := scanOne(ctx, addr, algo)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		if lastErr == nil {
+			lastErr = errors.New("nenhum algoritmo informado")
+		}
+		return nil, fmt.Errorf("buscar chaves de %s: %w", addr, lastErr)
+	}
+	return keys, nil
+}
+
+func scanOne(ctx context.Context, addr, algo string) (gossh.PublicKey, error) {
+	d := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(10 * time.Second))
+	}
+
+	var key gossh.PublicKey
+	cfg := &gossh.ClientConfig{
+		User:              "factorydev",
+		HostKeyAlgorithms: []string{algo},
+		HostKeyCallback: func(_ string, _ net.Addr, k gossh.PublicKey) error {
+			key = k
+			return errCaptured
+		},
+	}
+	_, _, _, err = gossh.NewClientConn(conn, addr, cfg)
+	if key != nil {
+		return key, nil
+	}
+	if err == nil {
+		err = errors.New("servidor não apresentou chave")
+	}
+	return nil, err
+}
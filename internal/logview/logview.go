@@ -0,0 +1,302 @@
+// Package logview lê os logs do próprio FactoryDev (~/.fdev/logs) para a
+// página Logs: interpreta registros do slog nos formatos JSON (app.log) e
+// texto (saída do servidor em modo debug ou do serviço), filtra e pagina.
+// Linhas que não são do slog (log.Printf, saída do chi) viram registros só
+// com a mensagem.
+package logview
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLine é o tamanho máximo de uma linha (registros de panic trazem o
+// stack inteiro numa linha só).
+const maxLine = 4 << 20
+
+// Levels são os níveis do slog, do menos ao mais grave.
+var Levels = []string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+// Attr é um atributo do registro, já convertido para texto.
+type Attr struct {
+	Key   string
+	Value string
+}
+
+// Record é uma linha do log.
+type Record struct {
+	Line    int // número da linha no arquivo, a partir de 1
+	Time    time.Time
+	Level   string
+	Message string
+	Attrs   []Attr
+	Raw     string
+}
+
+// Attr devolve o valor do atributo key, ou "".
+func (r Record) Attr(key string) string {
+	for _, a := range r.Attrs {
+		if a.Key == key {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Subsystem é o atributo "subsystem" ou, na falta dele, o prefixo da
+// mensagem no padrão "scheduler: ler state".
+func (r Record) Subsystem() string {
+	if s := r.Attr("subsystem"); s != "" {
+		return s
+	}
+	prefix, _, ok := strings.Cut(r.Message, ": ")
+	if !ok || prefix == "" || strings.ContainsAny(prefix, " \t") {
+		return ""
+	}
+	return prefix
+}
+
+// Panic indica um panic capturado pelo recoverer do servidor.
+func (r Record) Panic() bool {
+	return r.Attr("panic") != ""
+}
+
+// Parse interpreta uma linha de log.
+func Parse(line string) Record {
+	rec := Record{Raw: line}
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "{") && parseJSON(trimmed, &rec) {
+		return rec
+	}
+	if strings.HasPrefix(trimmed, "time=") && parseText(trimmed, &rec) {
+		return rec
+	}
+	rec.Message = trimmed
+	// log.Printf: "2026/03/14 10:07:30 mensagem"
+	if len(trimmed) > 20 {
+		if t, err := time.ParseInLocation("2006/01/02 15:04:05", trimmed[:19], time.Local); err == nil {
+			rec.Time, rec.Message = t, strings.TrimSpace(trimmed[19:])
+		}
+	}
+	return rec
+}
+
+func parseJSON(line string, rec *Record) bool {
+	var m map[string]any
+	if err := json.Unmarshal([]byte(line), &m); err != nil {
+		return false
+	}
+	if s, ok := m["time"].(string); ok {
+		rec.Time, _ = time.Parse(time.RFC3339Nano, s)
+	}
+	rec.Level, _ = m["level"].(string)
+	rec.Message, _ = m["msg"].(string)
+	delete(m, "time")
+	delete(m, "level")
+	delete(m, "msg")
+	flatten("", m, &rec.Attrs)
+	sort.Slice(rec.Attrs, func(i, j int) bool { return rec.Attrs[i].Key < rec.Attrs[j].Key })
+	return true
+}
+
+// flatten achata grupos do slog ({"req":{"id":1}} vira req.id).
+func flatten(prefix string, m map[string]any, out *[]Attr) {
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			flatten(key, v, out)
+		case string:
+			*out = append(*out, Attr{key, v})
+		case nil:
+			*out = append(*out, Attr{key, ""})
+		default:
+			b, _ := json.Marshal(v)
+			*out = append(*out, Attr{key, string(b)})
+		}
+	}
+}
+
+// parseText lê o formato do slog.TextHandler: pares key=value separados por
+// espaço, com valores entre aspas (escapados como em Go) quando preciso.
+func parseText(line string, rec *Record) bool {
+	rest := line
+	for rest != "" {
+		key, after, ok := strings.Cut(rest, "=")
+		if !ok || key == "" || strings.ContainsAny(key, " \"") {
+			return rec.Message != "" || !rec.Time.IsZero()
+		}
+		var value string
+		if strings.HasPrefix(after, `"`) {
+			q, err := strconv.QuotedPrefix(after)
+			if err != nil {
+				return false
+			}
+			value, _ = strconv.Unquote(q)
+			after = after[len(q):]
+		} else {
+			value, after, _ = strings.Cut(after, " ")
+			after = " " + after
+		}
+		rest = strings.TrimLeft(after, " ")
+
+		switch key {
+		case "time":
+			rec.Time, _ = time.Parse(time.RFC3339Nano, value)
+		case "level":
+			rec.Level = value
+		case "msg":
+			rec.Message = value
+		default:
+			rec.Attrs = append(rec.Attrs, Attr{key, value})
+		}
+	}
+	return true
+}
+
+// File é um arquivo de log disponível.
+type File struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Files lista os arquivos de log de dir (app.log, app.log.1, service.log),
+// do mais recente para o mais antigo.
+func Files(dir string) ([]File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var files []File
+	for _, e := range entries {
+		if e.IsDir() || !strings.Contains(e.Name(), ".log") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, File{Name: e.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime.After(files[j].ModTime) })
+	return files, nil
+}
+
+// Read lê e interpreta todas as linhas não vazias de dir/name. name não
+// pode sair de dir.
+func Read(dir, name string) ([]Record, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("arquivo de log inválido: %q", name)
+	}
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var recs []Record
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), maxLine)
+	n := 0
+	for sc.Scan() {
+		n++
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+		rec := Parse(sc.Text())
+		rec.Line = n
+		recs = append(recs, rec)
+	}
+	return recs, sc.Err()
+}
+
+// Filter seleciona registros. Campos vazios não filtram.
+type Filter struct {
+	// Level é o nível mínimo (registros sem nível só aparecem sem filtro).
+	Level     string
+	Query     string
+	Subsystem string
+	// Request mostra só os registros de uma requisição (request_id).
+	Request string
+}
+
+// Match informa se rec passa pelo filtro.
+func (f Filter) Match(rec Record) bool {
+	if f.Level != "" && levelRank(rec.Level) < levelRank(f.Level) {
+		return false
+	}
+	if f.Subsystem != "" && rec.Subsystem() != f.Subsystem {
+		return false
+	}
+	if f.Request != "" && rec.Attr("request_id") != f.Request {
+		return false
+	}
+	if f.Query != "" && !strings.Contains(strings.ToLower(rec.Raw), strings.ToLower(f.Query)) {
+		return false
+	}
+	return true
+}
+
+// levelRank ordena os níveis; níveis customizados (WARN+2, ERROR+4) valem
+// como o nível base. Desconhecido vale -1.
+func levelRank(level string) int {
+	base, _, _ := strings.Cut(strings.ToUpper(level), "+")
+	base, _, _ = strings.Cut(base, "-")
+	for i, l := range Levels {
+		if base == l {
+			return i
+		}
+	}
+	return -1
+}
+
+// Page é uma página de registros filtrados, do mais recente ao mais antigo.
+type Page struct {
+	Records []Record
+	// Total é a quantidade de registros que passaram pelo filtro.
+	Total       int
+	Page, Pages int
+	Subsystems  []string
+}
+
+// Paginate filtra recs (em ordem de arquivo) e devolve a página page
+// (a partir de 1) com size registros, mais recentes primeiro. Subsystems
+// lista os subsistemas de todos os registros, para o filtro.
+func Paginate(recs []Record, f Filter, page, size int) Page {
+	seen := map[string]bool{}
+	var matched []Record
+	for i := len(recs) - 1; i >= 0; i-- {
+		if s := recs[i].Subsystem(); s != "" {
+			seen[s] = true
+		}
+		if f.Match(recs[i]) {
+			matched = append(matched, recs[i])
+		}
+	}
+	p := Page{Total: len(matched), Pages: (len(matched) + size - 1) / size}
+	for s := range seen {
+		p.Subsystems = append(p.Subsystems, s)
+	}
+	sort.Strings(p.Subsystems)
+	if p.Pages == 0 {
+		p.Pages = 1
+	}
+	p.Page = min(max(page, 1), p.Pages)
+	start := (p.Page - 1) * size
+	p.Records = matched[start:min(start+size, len(matched))]
+	return p
+}
@@ -0,0 +1,63 @@
+package logview
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	j := Parse(`{"time":"2026-03-14T10:07:30.5-03:00","level":"ERROR","msg":"panic capturado","panic":"boom","request_id":"h/abc-000001","req":{"method":"GET"}}`)
+	if j.Level != "ERROR" || j.Message != "panic capturado" || !j.Panic() || j.Attr("req.method") != "GET" || j.Time.IsZero() {
+		t.Fatalf("JSON: %+v", j)
+	}
+
+	txt := Parse(`time=2026-03-14T10:07:30.000-03:00 level=WARN msg="scheduler: falha ao iniciar" schedule="Pull noturno" err="exit status 1"`)
+	if txt.Level != "WARN" || txt.Message != "scheduler: falha ao iniciar" || txt.Attr("schedule") != "Pull noturno" || txt.Subsystem() != "scheduler" {
+		t.Fatalf("texto: %+v", txt)
+	}
+
+	plain := Parse("2026/03/14 10:07:30 FactoryDev iniciando em 127.0.0.1:7331")
+	if plain.Level != "" || plain.Message != "FactoryDev iniciando em 127.0.0.1:7331" || plain.Time.IsZero() {
+		t.Fatalf("log.Printf: %+v", plain)
+	}
+}
+
+func TestReadAndPaginate(t *testing.T) {
+	dir := t.TempDir()
+	lines := []string{
+		`{"time":"2026-03-14T10:00:00Z","level":"INFO","msg":"servidor iniciado","addr":"127.0.0.1:7331"}`,
+		`{"time":"2026-03-14T10:01:00Z","level":"DEBUG","msg":"tentando abrir navegador"}`,
+		`{"time":"2026-03-14T10:02:00Z","level":"ERROR","msg":"requisição","subsystem":"http","status":500,"request_id":"r1"}`,
+		`{"time":"2026-03-14T10:02:00Z","level":"ERROR","msg":"panic capturado","subsystem":"http","panic":"x","request_id":"r1"}`,
+		`{"time":"2026-03-14T10:03:00Z","level":"WARN","msg":"scheduler: ler state","err":"disco cheio"}`,
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app.log"), []byte(strings.Join(lines, "\n")+"\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	recs, err := Read(dir, "app.log")
+	if err != nil || len(recs) != 5 {
+		t.Fatalf("Read: %d registros, %v", len(recs), err)
+	}
+	if _, err := Read(dir, "../app.log"); err == nil {
+		t.Fatal("Read aceitou caminho fora do diretório")
+	}
+
+	p := Paginate(recs, Filter{Level: "WARN"}, 1, 2)
+	if p.Total != 3 || p.Pages != 2 || p.Records[0].Message != "scheduler: ler state" {
+		t.Fatalf("página 1: %+v", p)
+	}
+	if got := strings.Join(p.Subsystems, ","); got != "http,scheduler" {
+		t.Fatalf("subsistemas = %s", got)
+	}
+	if p = Paginate(recs, Filter{Level: "WARN"}, 9, 2); p.Page != 2 || len(p.Records) != 1 {
+		t.Fatalf("última página: %+v", p)
+	}
+	if p = Paginate(recs, Filter{Request: "r1"}, 1, 10); p.Total != 2 {
+		t.Fatalf("filtro por requisição: %d", p.Total)
+	}
+	if p = Paginate(recs, Filter{Subsystem: "http", Query: "PANIC"}, 1, 10); p.Total != 1 || !p.Records[0].Panic() {
+		t.Fatalf("filtro por texto e subsistema: %+v", p)
+	}
+}
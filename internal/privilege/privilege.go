@@ -0,0 +1,502 @@
+// Package privilege executa comandos como administrador. Em vez de chamar
+// sudo sem terminal (que simplesmente falha quando há senha), o Runner
+// detecta a forma de elevação disponível — root, sudo sem senha, sudo com
+// senha ou pkexec — e, quando precisa de senha, publica um pedido de
+// elevação que a interface mostra num modal. A senha fica só em memória,
+// por PasswordTTL, e é entregue ao sudo pelo stdin ou por SUDO_ASKPASS.
+package privilege
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/events"
+)
+
+// Method é a forma de elevação.
+type Method string
+
+const (
+	// MethodRoot: o FactoryDev já roda como root, nada a prefixar.
+	MethodRoot Method = "root"
+	// MethodSudo: sudo sem senha (NOPASSWD ou credencial ainda em cache).
+	MethodSudo Method = "sudo"
+	// MethodSudoPassword: sudo pede senha; a interface a fornece.
+	MethodSudoPassword Method = "sudo-password"
+	// MethodPkexec: o polkit mostra o próprio diálogo de autenticação.
+	MethodPkexec Method = "pkexec"
+	// MethodNone: nem sudo nem pkexec no PATH.
+	MethodNone Method = "none"
+)
+
+const (
+	// PasswordTTL é por quanto tempo a senha fica em memória.
+	PasswordTTL = 5 * time.Minute
+	// PromptTimeout é quanto um comando espera pela senha.
+	PromptTimeout = 2 * time.Minute
+
+	// askpassEnv leva a senha ao helper de SUDO_ASKPASS; command.Cmd.String
+	// não mostra o valor.
+	askpassEnv = "FDEV_SUDO_PASSWORD"
+)
+
+var (
+	ErrPasswordRequired = errors.New("senha de administrador necessária")
+	ErrBadPassword      = errors.New("senha de administrador incorreta")
+	ErrDenied           = errors.New("elevação negada")
+	ErrPromptTimeout    = errors.New("tempo esgotado aguardando a senha de administrador")
+	ErrUnavailable      = errors.New("nem sudo nem pkexec disponíveis para executar como administrador")
+	ErrNotFound         = errors.New("pedido de elevação não encontrado")
+)
+
+// Request é um comando aguardando a senha de administrador.
+type Request struct {
+	ID string
+	// Command é a linha de comando sem o prefixo sudo.
+	Command string
+	// Reason descreve a operação para o usuário (ex: "Ativar o firewall").
+	Reason    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Status resume o estado da elevação para a interface.
+type Status struct {
+	Method Method
+	// Prefer é a preferência configurada: auto, sudo ou pkexec.
+	Prefer string
+	// Cached indica senha em memória, válida até ExpiresAt.
+	Cached    bool
+	ExpiresAt time.Time
+	Pending   int
+}
+
+type pending struct {
+	req  Request
+	done chan error
+}
+
+// Runner executa comandos como administrador.
+type Runner struct {
+	prefer  string
+	ttl     time.Duration
+	timeout time.Duration
+	bus     *events.Bus
+	// dir guarda o helper de SUDO_ASKPASS (sem segredo dentro).
+	dir string
+
+	lookPath func(string) (string, error)
+	euid     func() int
+	now      func() time.Time
+
+	mu       sync.Mutex
+	password []byte
+	expires  time.Time
+	timer    *time.Timer
+	pending  map[string]*pending
+}
+
+// New cria o Runner. prefer é "auto", "sudo" ou "pkexec"; bus recebe os
+// pedidos de elevação (tópico events.TopicPrivilege). Sem bus não há a quem
+// pedir a senha e os comandos que precisam dela falham com
+// ErrPasswordRequired.
+func New(bus *events.Bus, prefer, dir string) *Runner {
+	if prefer == "" {
+		prefer = "auto"
+	}
+	return &Runner{
+		prefer:   prefer,
+		ttl:      PasswordTTL,
+		timeout:  PromptTimeout,
+		bus:      bus,
+		dir:      dir,
+		lookPath: exec.LookPath,
+		euid:     os.Geteuid,
+		now:      time.Now,
+		pending:  make(map[string]*pending),
+	}
+}
+
+// Detect descobre a forma de elevação que Run usaria agora.
+func (r *Runner) Detect(ctx context.Context) Method {
+	if r.euid() == 0 {
+		return MethodRoot
+	}
+	_, sudoErr := r.lookPath("sudo")
+	_, pkexecErr := r.lookPath("pkexec")
+	if pkexecErr == nil && (r.prefer == "pkexec" || sudoErr != nil) {
+		return MethodPkexec
+	}
+	if sudoErr != nil {
+		return MethodNone
+	}
+	if r.cached() != nil {
+		return MethodSudoPassword
+	}
+	err := command.Run(ctx, command.Cmd{Name: "sudo", Args: []string{"-n", "true"}, ReadOnly: true})
+	if err == nil {
+		return MethodSudo
+	}
+	return MethodSudoPassword
+}
+
+// Status devolve o estado atual.
+func (r *Runner) Status(ctx context.Context) Status {
+	st := Status{Method: r.Detect(ctx), Prefer: r.prefer}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.password != nil && r.now().Before(r.expires) {
+		st.Cached, st.ExpiresAt = true, r.expires
+	}
+	st.Pending = len(r.pending)
+	return st
+}
+
+// Run executa c como administrador. c.Name é o programa, sem sudo/pkexec;
+// reason aparece no pedido de senha. Com sudo pedindo senha e nenhuma em
+// memória, Run publica um Request e espera a resposta da interface (Provide
+// ou Deny) por até PromptTimeout. Em dry-run não há pedido de senha: o
+// comando só é registrado, já com o prefixo.
+func (r *Runner) Run(ctx context.Context, reason string, c command.Cmd) error {
+	switch method := r.Detect(ctx); method {
+	case MethodRoot:
+		return command.Run(ctx, c)
+	case MethodPkexec:
+		return command.Run(ctx, wrap(c, "pkexec"))
+	case MethodSudo:
+		return command.Run(ctx, wrap(c, "sudo", "-n"))
+	case MethodSudoPassword:
+		if command.DryRun() && !c.ReadOnly {
+			return command.Run(ctx, wrap(c, "sudo"))
+		}
+		pw, err := r.ask(ctx, reason, c)
+		if err != nil {
+			return err
+		}
+		err = r.runSudo(ctx, c, pw)
+		if errors.Is(err, ErrBadPassword) {
+			r.Forget()
+		}
+		return err
+	}
+	return ErrUnavailable
+}
+
+// Query executa uma consulta como administrador só se não for preciso pedir
+// nada ao usuário (root, sudo sem senha ou senha em memória); caso contrário
+// devolve ErrPasswordRequired. Serve para telas de status, que não devem
+// abrir um modal a cada atualização.
+func (r *Runner) Query(ctx context.Context, c command.Cmd) error {
+	c.ReadOnly = true
+	switch r.Detect(ctx) {
+	case MethodRoot:
+		return command.Run(ctx, c)
+	case MethodSudo:
+		return command.Run(ctx, wrap(c, "sudo", "-n"))
+	case MethodSudoPassword:
+		if pw := r.cached(); pw != nil {
+			return r.runSudo(ctx, c, pw)
+		}
+		return ErrPasswordRequired
+	case MethodPkexec:
+		return ErrPasswordRequired
+	}
+	return ErrUnavailable
+}
+
+// runSudo roda c com sudo, entregando pw pelo stdin (-S) ou, se o comando
+// já usa o stdin, pelo helper de SUDO_ASKPASS (-A). Com -S vai junto -k:
+// sem ele, um sudo com credencial em cache não leria a senha e ela
+// chegaria ao stdin do comando.
+func (r *Runner) runSudo(ctx context.Context, c command.Cmd, pw []byte) error {
+	var flags []string
+	if c.Stdin == nil {
+		flags = []string{"-k", "-S", "-p", ""}
+		c.Stdin = bytes.NewReader(append(append([]byte(nil), pw...), '\n'))
+	} else {
+		helper, err := r.askpass()
+		if err != nil {
+			return err
+		}
+		flags = []string{"-A"}
+		c.Env = append(c.Env, "SUDO_ASKPASS="+helper, askpassEnv+"="+string(pw))
+	}
+	var out limitedBuffer
+	c.Stdout = teeWriter(c.Stdout, &out)
+	c.Stderr = teeWriter(c.Stderr, &out)
+	err := command.Run(ctx, wrap(c, "sudo", flags...))
+	if err != nil && wrongPassword(out.String()) {
+		return ErrBadPassword
+	}
+	return err
+}
+
+// askpass grava (uma vez) o helper de SUDO_ASKPASS, que só repete a
+// variável de ambiente com a senha.
+func (r *Runner) askpass() (string, error) {
+	if r.dir == "" {
+		return "", errors.New("diretório do helper SUDO_ASKPASS não configurado")
+	}
+	path := filepath.Join(r.dir, "askpass.sh")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$" + askpassEnv + "\"\n"
+	if cur, err := os.ReadFile(path); err == nil && string(cur) == script {
+		return path, nil
+	}
+	if err := os.WriteFile(path, []byte(script), 0o700); err != nil {
+		return "", fmt.Errorf("gravar helper SUDO_ASKPASS: %w", err)
+	}
+	return path, os.Chmod(path, 0o700)
+}
+
+// ask devolve a senha em memória ou publica um pedido e espera a resposta.
+func (r *Runner) ask(ctx context.Context, reason string, c command.Cmd) ([]byte, error) {
+	if pw := r.cached(); pw != nil {
+		return pw, nil
+	}
+	if r.bus == nil {
+		return nil, ErrPasswordRequired
+	}
+	now := r.now()
+	p := &pending{
+		req:  Request{ID: newID(), Command: c.String(), Reason: reason, CreatedAt: now, ExpiresAt: now.Add(r.timeout)},
+		done: make(chan error, 1),
+	}
+	r.mu.Lock()
+	r.pending[p.req.ID] = p
+	r.mu.Unlock()
+	r.notify()
+	defer func() {
+		r.mu.Lock()
+		delete(r.pending, p.req.ID)
+		r.mu.Unlock()
+		r.notify()
+	}()
+
+	timer := time.NewTimer(r.timeout)
+	defer timer.Stop()
+	select {
+	case err := <-p.done:
+		if err != nil {
+			return nil, err
+		}
+		if pw := r.cached(); pw != nil {
+			return pw, nil
+		}
+		return nil, ErrPasswordRequired
+	case <-timer.C:
+		return nil, ErrPromptTimeout
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Pending lista os pedidos de elevação em aberto, do mais antigo ao mais
+// recente.
+func (r *Runner) Pending() []Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]Request, 0, len(r.pending))
+	for _, p := range r.pending {
+		out = append(out, p.req)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+// Provide confere a senha com sudo -v e, se ela for válida, guarda-a por
+// PasswordTTL e libera todos os pedidos em aberto.
+func (r *Runner) Provide(ctx context.Context, password string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+	var out bytes.Buffer
+	err := command.Run(ctx, command.Cmd{
+		Name:     "sudo",
+		Args:     []string{"-k", "-S", "-p", "", "-v"},
+		Stdin:    strings.NewReader(password + "\n"),
+		Stdout:   &out,
+		Stderr:   &out,
+		ReadOnly: true,
+	})
+	if err != nil {
+		if wrongPassword(out.String()) {
+			return ErrBadPassword
+		}
+		if msg := strings.TrimSpace(out.String()); msg != "" {
+			return fmt.Errorf("validar senha: %w: %s", err, msg)
+		}
+		return fmt.Errorf("validar senha: %w", err)
+	}
+
+	r.mu.Lock()
+	r.clearLocked()
+	r.password = []byte(password)
+	r.expires = r.now().Add(r.ttl)
+	r.timer = time.AfterFunc(r.ttl, r.Forget)
+	for _, p := range r.pending {
+		select {
+		case p.done <- nil:
+		default:
+		}
+	}
+	r.mu.Unlock()
+	return nil
+}
+
+// Deny recusa o pedido id; o comando correspondente falha com ErrDenied.
+func (r *Runner) Deny(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	p, ok := r.pending[id]
+	if !ok {
+		return ErrNotFound
+	}
+	select {
+	case p.done <- ErrDenied:
+	default:
+	}
+	return nil
+}
+
+// Forget apaga a senha da memória.
+func (r *Runner) Forget() {
+	r.mu.Lock()
+	had := r.password != nil
+	r.clearLocked()
+	r.mu.Unlock()
+	if had {
+		r.notify()
+	}
+}
+
+func (r *Runner) clearLocked() {
+	for i := range r.password {
+		r.password[i] = 0
+	}
+	r.password, r.expires = nil, time.Time{}
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+}
+
+// cached devolve uma cópia da senha em memória, se ainda válida.
+func (r *Runner) cached() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.password == nil {
+		return nil
+	}
+	if !r.now().Before(r.expires) {
+		r.clearLocked()
+		return nil
+	}
+	return append([]byte(nil), r.password...)
+}
+
+func (r *Runner) notify() {
+	if r.bus != nil {
+		r.bus.Publish(events.Event{Topic: events.TopicPrivilege, Type: "update"})
+	}
+}
+
+// wrap prefixa c com o programa de elevação.
+func wrap(c command.Cmd, prog string, flags ...string) command.Cmd {
+	args := make([]string, 0, len(flags)+len(c.Args)+1)
+	args = append(args, flags...)
+	args = append(args, c.Name)
+	c.Args = append(args, c.Args...)
+	c.Name = prog
+	return c
+}
+
+// wrongPassword reconhece as mensagens do sudo para senha errada.
+func wrongPassword(out string) bool {
+	return strings.Contains(out, "incorrect password") ||
+		strings.Contains(out, "Sorry, try again") ||
+		strings.Contains(out, "no password was provided")
+}
+
+// limitedBuffer guarda só o começo da saída, o bastante para reconhecer as
+// mensagens do sudo.
+type limitedBuffer struct{ bytes.Buffer }
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := 4096 - b.Len(); room > 0 {
+		if len(p) > room {
+			b.Buffer.Write(p[:room])
+		} else {
+			b.Buffer.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func teeWriter(w io.Writer, extra io.Writer) io.Writer {
+	if w == nil {
+		return extra
+	}
+	return io.MultiWriter(w, extra)
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+var (
+	defMu sync.RWMutex
+	def   = New(nil, "auto", "")
+)
+
+// Default devolve o Runner usado pelos pacotes.
+func Default() *Runner {
+	defMu.RLock()
+	defer defMu.RUnlock()
+	return def
+}
+
+// SetDefault troca o Runner padrão e devolve uma função que restaura o
+// anterior.
+func SetDefault(r *Runner) (restore func()) {
+	defMu.Lock()
+	prev := def
+	def = r
+	defMu.Unlock()
+	return func() { SetDefault(prev) }
+}
+
+// Run executa c como administrador pelo Runner padrão.
+func Run(ctx context.Context, reason string, c command.Cmd) error {
+	return Default().Run(ctx, reason, c)
+}
+
+// CombinedOutput é Run devolvendo stdout e stderr juntos.
+func CombinedOutput(ctx context.Context, reason string, c command.Cmd) ([]byte, error) {
+	var out bytes.Buffer
+	c.Stdout, c.Stderr = &out, &out
+	err := Default().Run(ctx, reason, c)
+	return out.Bytes(), err
+}
+
+// QueryOutput é Query pelo Runner padrão, devolvendo stdout e stderr juntos.
+func QueryOutput(ctx context.Context, c command.Cmd) ([]byte, error) {
+	var out bytes.Buffer
+	c.Stdout, c.Stderr = &out, &out
+	err := Default().Query(ctx, c)
+	return out.Bytes(), err
+}
@@ -0,0 +1,148 @@
+package privilege
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/events"
+)
+
+func newTestRunner(t *testing.T) (*Runner, *command.Fake, <-chan events.Event) {
+	t.Helper()
+	fake := command.NewFake()
+	fake.On("sudo -n true", "sudo: a password is required", errors.New("exit status 1"))
+	t.Cleanup(command.SetDefault(fake))
+
+	bus := events.New()
+	ch, cancel := bus.Subscribe(events.TopicPrivilege)
+	t.Cleanup(cancel)
+
+	r := New(bus, "auto", t.TempDir())
+	r.lookPath = func(name string) (string, error) {
+		if name == "sudo" {
+			return "/usr/bin/sudo", nil
+		}
+		return "", errors.New("not found")
+	}
+	r.euid = func() int { return 1000 }
+	return r, fake, ch
+}
+
+// waitPending espera o Runner publicar um pedido de senha.
+func waitPending(t *testing.T, r *Runner, ch <-chan events.Event) Request {
+	t.Helper()
+	deadline := time.After(5 * time.Second)
+	for {
+		if p := r.Pending(); len(p) > 0 {
+			return p[0]
+		}
+		select {
+		case <-ch:
+		case <-deadline:
+			t.Fatal("nenhum pedido de senha publicado")
+		}
+	}
+}
+
+func TestRunAsksForPassword(t *testing.T) {
+	r, fake, ch := newTestRunner(t)
+	ctx := context.Background()
+
+	if m := r.Detect(ctx); m != MethodSudoPassword {
+		t.Fatalf("Detect = %s", m)
+	}
+	if err := r.Query(ctx, command.Cmd{Name: "ufw", Args: []string{"status"}}); !errors.Is(err, ErrPasswordRequired) {
+		t.Fatalf("Query sem senha = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(ctx, "Ativar o firewall", command.Cmd{Name: "ufw", Args: []string{"--force", "enable"}})
+	}()
+	req := waitPending(t, r, ch)
+	if req.Reason != "Ativar o firewall" || req.Command != "ufw --force enable" {
+		t.Fatalf("pedido = %+v", req)
+	}
+
+	fake.On("sudo -k -S -p '' -v", "Sorry, try again.\nsudo: 1 incorrect password attempt\n", errors.New("exit status 1"))
+	if err := r.Provide(ctx, "errada"); !errors.Is(err, ErrBadPassword) {
+		t.Fatalf("senha errada = %v", err)
+	}
+	fake.On("sudo -k -S -p '' -v", "", nil)
+	if err := r.Provide(ctx, "segredo"); err != nil {
+		t.Fatalf("Provide: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	last := fake.Cmds[len(fake.Cmds)-1]
+	if got := last.String(); got != "sudo -k -S -p '' ufw --force enable" {
+		t.Fatalf("comando = %s", got)
+	}
+	stdin, _ := io.ReadAll(last.Stdin)
+	if string(stdin) != "segredo\n" {
+		t.Fatalf("stdin = %q", stdin)
+	}
+	for _, line := range fake.Commands() {
+		if strings.Contains(line, "segredo") {
+			t.Fatalf("senha no histórico: %s", line)
+		}
+	}
+	if st := r.Status(ctx); !st.Cached || st.Pending != 0 {
+		t.Fatalf("status = %+v", st)
+	}
+
+	// Com a senha em memória, consultas e comandos que usam o stdin (via
+	// SUDO_ASKPASS) não pedem nada.
+	if err := r.Query(ctx, command.Cmd{Name: "ufw", Args: []string{"status"}}); err != nil {
+		t.Fatalf("Query com senha: %v", err)
+	}
+	err := r.Run(ctx, "Gravar", command.Cmd{Name: "tee", Args: []string{"/etc/x"}, Stdin: strings.NewReader("conteúdo")})
+	if err != nil {
+		t.Fatalf("Run com stdin: %v", err)
+	}
+	line := fake.Commands()[len(fake.Cmds)-1]
+	if !strings.Contains(line, "SUDO_ASKPASS=") || !strings.Contains(line, "FDEV_SUDO_PASSWORD=***") || !strings.Contains(line, "sudo -A tee") {
+		t.Fatalf("comando com askpass = %s", line)
+	}
+
+	r.Forget()
+	if st := r.Status(ctx); st.Cached {
+		t.Fatal("senha continua em memória")
+	}
+}
+
+func TestRunDenyAndDryRun(t *testing.T) {
+	r, fake, ch := newTestRunner(t)
+	ctx := context.Background()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- r.Run(ctx, "Bloquear", command.Cmd{Name: "ip", Args: []string{"route", "add", "blackhole", "10.0.0.1"}})
+	}()
+	req := waitPending(t, r, ch)
+	if err := r.Deny(req.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; !errors.Is(err, ErrDenied) {
+		t.Fatalf("Run negado = %v", err)
+	}
+
+	// Em dry-run o comando é só registrado, sem pedir senha.
+	fake.SetDryRun(true)
+	if err := r.Run(ctx, "Ativar", command.Cmd{Name: "ufw", Args: []string{"enable"}}); err != nil {
+		t.Fatalf("Run em dry-run: %v", err)
+	}
+	if got := fake.Commands()[len(fake.Cmds)-1]; got != "sudo ufw enable" {
+		t.Fatalf("comando simulado = %s", got)
+	}
+	if len(r.Pending()) != 0 {
+		t.Fatal("dry-run pediu senha")
+	}
+}
@@ -0,0 +1,395 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/gitconfig"
+	"github.com/seuusuario/factorydev/internal/ssh"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// ImportOptions controla como o pacote é mesclado ao state local.
+type ImportOptions struct {
+	Sections  []string // vazio: todas as seções presentes no pacote
+	Overwrite bool     // conflito substitui o registro local em vez de ignorar
+	Secrets   Secrets  // sela segredos com o vault local; nil grava como veio
+}
+
+// Count resume o resultado do merge de uma seção.
+type Count struct {
+	Added   int
+	Updated int
+	Skipped int
+}
+
+// Result é o resumo do import.
+type Result struct {
+	Counts map[string]*Count
+	// Accounts são as contas incluídas ou atualizadas, já com IDs locais,
+	// para reaplicar o SSH config.
+	Accounts []string
+}
+
+// Summary formata o resultado numa linha por seção.
+func (r *Result) Summary() []string {
+	var out []string
+	for _, s := range Sections {
+		c, ok := r.Counts[s.ID]
+		if !ok {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s: %d novo(s), %d atualizado(s), %d ignorado(s)", s.Label, c.Added, c.Updated, c.Skipped))
+	}
+	return out
+}
+
+// Merge mescla o pacote em state. Conflitos são detectados por ID ou pelo
+// nome natural de cada entidade (alias da chave, host alias da conta, nome
+// do servidor...). Com Overwrite o registro local mantém o ID e recebe os
+// dados importados; sem Overwrite o importado é ignorado. Referências a
+// chaves (KeyID) são religadas aos IDs locais e caminhos sob o home de
+// origem são reescritos para o home atual. Os arquivos das chaves são
+// gravados em ~/.fdev/keys/<alias>/.
+func Merge(state *storage.State, b *Bundle, paths *config.Paths, opts ImportOptions) (*Result, error) {
+	sections, err := ParseSections(opts.Sections)
+	if err != nil {
+		return nil, err
+	}
+	want := map[string]bool{}
+	for _, s := range sections {
+		if b.Manifest.Has(s) {
+			want[s] = true
+		}
+	}
+
+	res := &Result{Counts: map[string]*Count{}}
+	oldHome, newHome := b.Manifest.Home, paths.Home
+	keyIDs := map[string]string{}
+
+	if want[SectionKeys] {
+		c := &Count{}
+		for _, k := range b.Manifest.Keys {
+			origID := k.ID
+			if !paths.ValidateAlias(k.Alias) {
+				c.Skipped++
+				continue
+			}
+			idx := indexOf(state.Keys, func(l storage.Key) bool { return l.ID == k.ID || l.Alias == k.Alias })
+			if idx >= 0 && !opts.Overwrite {
+				keyIDs[k.ID] = state.Keys[idx].ID
+				c.Skipped++
+				continue
+			}
+			if err := writeKeyFiles(&k, b.Files, paths); err != nil {
+				return nil, err
+			}
+			k.OriginalPath = rewriteHome(k.OriginalPath, oldHome, newHome)
+			if idx >= 0 {
+				k.ID = state.Keys[idx].ID
+				state.Keys[idx] = k
+				c.Updated++
+			} else {
+				state.Keys = append(state.Keys, k)
+				c.Added++
+			}
+			keyIDs[origID] = k.ID
+		}
+		res.Counts[SectionKeys] = c
+	}
+	remapKey := func(id string) string {
+		if local, ok := keyIDs[id]; ok {
+			return local
+		}
+		return id
+	}
+
+	if want[SectionAccounts] {
+		c := &Count{}
+		for _, a := range b.Manifest.Accounts {
+			a.KeyID = remapKey(a.KeyID)
+			a.IdentityFile = rewriteHome(a.IdentityFile, oldHome, newHome)
+			id, ok := mergeOne(&state.Accounts, a, func(l storage.Account) bool {
+				return l.ID == a.ID || l.HostAlias == a.HostAlias
+			}, func(v *storage.Account) *string { return &v.ID }, opts.Overwrite, c)
+			if ok {
+				res.Accounts = append(res.Accounts, id)
+			}
+		}
+		res.Counts[SectionAccounts] = c
+	}
+
+	if want[SectionIdentities] {
+		c := &Count{}
+		for _, g := range b.Manifest.Identities {
+			g.KeyID = remapKey(g.KeyID)
+			mergeOne(&state.Identities, g, func(l storage.GitIdentity) bool {
+				return l.ID == g.ID || l.Name == g.Name
+			}, func(v *storage.GitIdentity) *string { return &v.ID }, opts.Overwrite, c)
+		}
+		res.Counts[SectionIdentities] = c
+	}
+
+	if want[SectionServers] {
+		c := &Count{}
+		for _, s := range b.Manifest.Servers {
+			s.KeyID = remapKey(s.KeyID)
+			mergeOne(&state.Servers, s, func(l storage.Server) bool {
+				return l.ID == s.ID || l.Name == s.Name
+			}, func(v *storage.Server) *string { return &v.ID }, opts.Overwrite, c)
+		}
+		res.Counts[SectionServers] = c
+	}
+
+	if want[SectionAliases] {
+		c := &Count{}
+		for _, a := range b.Manifest.Aliases {
+			mergeOne(&state.Aliases, a, func(l storage.ShellAlias) bool {
+				return l.ID == a.ID || l.Name == a.Name
+			}, func(v *storage.ShellAlias) *string { return &v.ID }, opts.Overwrite, c)
+		}
+		res.Counts[SectionAliases] = c
+	}
+
+	if want[SectionEnvs] {
+		c := &Count{}
+		for _, e := range b.Manifest.EnvFiles {
+			e.ProjectPath = rewriteHome(e.ProjectPath, oldHome, newHome)
+			if e.Variables, err = seal(opts.Secrets, e.Variables); err != nil {
+				return nil, err
+			}
+			mergeOne(&state.EnvFiles, e, func(l storage.EnvFile) bool {
+				return l.ID == e.ID || l.Name == e.Name
+			}, func(v *storage.EnvFile) *string { return &v.ID }, opts.Overwrite, c)
+		}
+		res.Counts[SectionEnvs] = c
+	}
+
+	if want[SectionAPI] {
+		c := &Count{}
+		colIDs := map[string]string{}
+		for _, col := range b.Manifest.APICollections {
+			if col.AuthData, err = seal(opts.Secrets, col.AuthData); err != nil {
+				return nil, err
+			}
+			// Mesmo quando a collection local é mantida, os endpoints
+			// importados passam a apontar para ela.
+			id, _ := mergeOne(&state.APICollections, col, func(l storage.APICollection) bool {
+				return l.ID == col.ID || l.Name == col.Name
+			}, func(v *storage.APICollection) *string { return &v.ID }, opts.Overwrite, c)
+			colIDs[col.ID] = id
+		}
+		for _, ep := range b.Manifest.APIEndpoints {
+			if local, ok := colIDs[ep.CollectionID]; ok {
+				ep.CollectionID = local
+			}
+			mergeOne(&state.APIEndpoints, ep, func(l storage.APIEndpoint) bool {
+				return l.ID == ep.ID || (l.CollectionID == ep.CollectionID && l.Name == ep.Name)
+			}, func(v *storage.APIEndpoint) *string { return &v.ID }, opts.Overwrite, c)
+		}
+		res.Counts[SectionAPI] = c
+	}
+
+	return res, nil
+}
+
+// ApplySSHConfig reaplica os blocos FDEV do ~/.ssh/config das contas
+// importadas. Deve ser chamado após o state ter sido gravado.
+func ApplySSHConfig(state *storage.State, accountIDs []string, paths *config.Paths) error {
+	for _, id := range accountIDs {
+		idx := indexOf(state.Accounts, func(a storage.Account) bool { return a.ID == id })
+		if idx < 0 {
+			continue
+		}
+		a := state.ResolveKeyFiles(state.Accounts[idx])
+		if err := ssh.ApplyAccount(a, paths); err != nil {
+			return fmt.Errorf("aplicar ssh config de %s: %w", a.HostAlias, err)
+		}
+	}
+	return nil
+}
+
+// ApplyIncludeIf recria as regras [includeIf] do pacote no gitconfig global,
+// gravando o arquivo incluído quando ele ainda não existe (ou sempre, com
+// overwrite). Regras com o mesmo padrão já presentes são mantidas. Os
+// caminhos dos arquivos incluídos são conferidos antes de qualquer gravação.
+func ApplyIncludeIf(b *Bundle, gitConfigPath string, paths *config.Paths, overwrite bool) (*Count, error) {
+	c := &Count{}
+	existing, err := gitconfig.ListIncludeIf(gitConfigPath)
+	if err != nil {
+		return c, fmt.Errorf("ler includeIf: %w", err)
+	}
+	have := map[string]bool{}
+	for _, r := range existing {
+		have[r.Pattern] = true
+	}
+
+	targets := make([]string, len(b.Manifest.IncludeIf))
+	for i, inc := range b.Manifest.IncludeIf {
+		if inc.Content == "" {
+			continue
+		}
+		if targets[i], err = includeTarget(inc.IncludePath, b.Manifest.Home, paths.Home); err != nil {
+			return c, err
+		}
+	}
+
+	for i, inc := range b.Manifest.IncludeIf {
+		pattern := rewritePattern(inc.Pattern, b.Manifest.Home, paths.Home)
+		includePath := rewriteHome(inc.IncludePath, b.Manifest.Home, paths.Home)
+
+		if target := targets[i]; target != "" {
+			includePath = target
+			if _, err := os.Stat(target); os.IsNotExist(err) || overwrite {
+				if !command.DryRun() {
+					if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+						return c, err
+					}
+				}
+				content := rewriteContent(inc.Content, b.Manifest.Home, paths.Home)
+				if err := command.WriteFile(target, []byte(content), 0o644); err != nil {
+					return c, fmt.Errorf("gravar %s: %w", target, err)
+				}
+			}
+		}
+		if have[pattern] {
+			c.Skipped++
+			continue
+		}
+		if err := gitconfig.AddIncludeIf(gitConfigPath, gitconfig.IncludeIfRule{Pattern: pattern, IncludePath: includePath}); err != nil {
+			return c, err
+		}
+		have[pattern] = true
+		c.Added++
+	}
+	return c, nil
+}
+
+// includeTarget resolve o arquivo incluído de uma regra importada. O caminho
+// vem do pacote e não é confiável: só são gravados ~/.gitconfig-<nome> e
+// arquivos em ~/.gitconfig.d/, relativos à home de origem.
+func includeTarget(p, oldHome, home string) (string, error) {
+	var rel string
+	switch {
+	case strings.HasPrefix(p, "~/"):
+		rel = p[2:]
+	case oldHome != "" && strings.HasPrefix(p, oldHome+"/"):
+		rel = p[len(oldHome)+1:]
+	default:
+		return "", fmt.Errorf("arquivo incluído fora da home: %s", p)
+	}
+	rel = filepath.Clean(rel)
+	dir, base := filepath.Split(rel)
+	allowed := (dir == "" && strings.HasPrefix(base, ".gitconfig-")) || strings.HasPrefix(rel, ".gitconfig.d/")
+	if !allowed || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("arquivo incluído não permitido: %s (use ~/.gitconfig-<nome> ou ~/.gitconfig.d/)", p)
+	}
+	return filepath.Join(home, rel), nil
+}
+
+// ── Helpers ───────────────────────────────────────────────────────
+
+func indexOf[T any](items []T, match func(T) bool) int {
+	for i := range items {
+		if match(items[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeOne insere ou substitui item em *dst. Retorna o ID local do registro
+// e false quando o item foi ignorado por conflito.
+func mergeOne[T any](dst *[]T, item T, match func(T) bool, id func(*T) *string, overwrite bool, c *Count) (string, bool) {
+	idx := indexOf(*dst, match)
+	switch {
+	case idx < 0:
+		*dst = append(*dst, item)
+		c.Added++
+		return *id(&item), true
+	case overwrite:
+		*id(&item) = *id(&(*dst)[idx])
+		(*dst)[idx] = item
+		c.Updated++
+		return *id(&item), true
+	default:
+		c.Skipped++
+		return *id(&(*dst)[idx]), false
+	}
+}
+
+func seal(s Secrets, m map[string]string) (map[string]string, error) {
+	if s == nil || len(m) == 0 {
+		return m, nil
+	}
+	return s.SealMap(m)
+}
+
+// writeKeyFiles grava os arquivos de keys/<alias>/ do pacote no diretório
+// local da chave e reescreve PrivateKeyPath/PublicKeyPath.
+func writeKeyFiles(k *storage.Key, files map[string][]byte, paths *config.Paths) error {
+	dir := paths.KeyDir(k.Alias)
+	if dir == "" {
+		return fmt.Errorf("alias de chave inválido: %q", k.Alias)
+	}
+	prefix := path.Join("keys", k.Alias) + "/"
+	var names []string
+	for name := range files {
+		if strings.HasPrefix(name, prefix) && !strings.Contains(name[len(prefix):], "/") {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("pacote sem os arquivos da chave %s", k.Alias)
+	}
+	sort.Strings(names)
+
+	if !command.DryRun() {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return fmt.Errorf("criar %s: %w", dir, err)
+		}
+		if err := os.Chmod(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		base := name[len(prefix):]
+		mode := os.FileMode(0o600)
+		if strings.HasSuffix(base, ".pub") {
+			mode = 0o644
+		}
+		if err := command.WriteFile(filepath.Join(dir, base), files[name], mode); err != nil {
+			return fmt.Errorf("gravar chave %s: %w", k.Alias, err)
+		}
+	}
+	if k.PrivateKeyPath != "" {
+		k.PrivateKeyPath = filepath.Join(dir, filepath.Base(k.PrivateKeyPath))
+	}
+	if k.PublicKeyPath != "" {
+		k.PublicKeyPath = filepath.Join(dir, filepath.Base(k.PublicKeyPath))
+	}
+	return nil
+}
+
+// rewritePattern reescreve o home em padrões "gitdir:/home/x/work/".
+func rewritePattern(pattern, oldHome, newHome string) string {
+	kind, p, ok := strings.Cut(pattern, ":")
+	if !ok {
+		return pattern
+	}
+	return kind + ":" + rewriteHome(p, oldHome, newHome)
+}
+
+// rewriteContent reescreve o home de origem dentro de um arquivo de config.
+func rewriteContent(content, oldHome, newHome string) string {
+	if oldHome == "" || oldHome == newHome {
+		return content
+	}
+	return strings.ReplaceAll(content, oldHome+"/", newHome+"/")
+}
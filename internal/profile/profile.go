@@ -0,0 +1,396 @@
+// Package profile exporta e importa um perfil portátil do FactoryDev: seções
+// selecionadas do state mais o material das chaves em ~/.fdev/keys/<alias>/,
+// tudo num único arquivo cifrado com uma passphrase própria.
+//
+// O arquivo é um tar.gz (profile.json + keys/<alias>/<arquivo>) selado com
+// vault.SealBytes. Segredos guardados no vault local são revelados no export
+// e selados de novo no import, com o vault da máquina de destino.
+package profile
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/gitconfig"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+// FormatVersion é a versão do profile.json dentro do pacote.
+const FormatVersion = 1
+
+// FileExt é a extensão sugerida para o arquivo exportado.
+const FileExt = ".fdevprofile"
+
+const manifestName = "profile.json"
+
+// Seções exportáveis.
+const (
+	SectionKeys       = "keys"
+	SectionAccounts   = "accounts"
+	SectionIdentities = "identities"
+	SectionServers    = "servers"
+	SectionAliases    = "aliases"
+	SectionEnvs       = "envs"
+	SectionAPI        = "api"
+	SectionIncludeIf  = "includeif"
+)
+
+// Section descreve uma seção para a UI e para o --help do CLI.
+type Section struct {
+	ID    string
+	Label string
+}
+
+// Sections lista as seções na ordem em que são importadas: chaves primeiro,
+// para que contas, identidades e servidores possam ser religados a elas.
+var Sections = []Section{
+	{SectionKeys, "Chaves SSH (inclui arquivos)"},
+	{SectionAccounts, "Contas SSH / Git"},
+	{SectionIdentities, "Identidades Git"},
+	{SectionServers, "Servidores"},
+	{SectionAliases, "Aliases"},
+	{SectionEnvs, "Env files"},
+	{SectionAPI, "Collections de API"},
+	{SectionIncludeIf, "Regras includeIf do gitconfig"},
+}
+
+// AllSections retorna os IDs de todas as seções.
+func AllSections() []string {
+	ids := make([]string, len(Sections))
+	for i, s := range Sections {
+		ids[i] = s.ID
+	}
+	return ids
+}
+
+// ParseSections valida uma lista de IDs de seção. Lista vazia vale todas.
+func ParseSections(ids []string) ([]string, error) {
+	if len(ids) == 0 {
+		return AllSections(), nil
+	}
+	var out []string
+	for _, id := range ids {
+		id = strings.ToLower(strings.TrimSpace(id))
+		if id == "" {
+			continue
+		}
+		valid := false
+		for _, s := range Sections {
+			if s.ID == id {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return nil, fmt.Errorf("seção desconhecida: %s", id)
+		}
+		out = append(out, id)
+	}
+	return out, nil
+}
+
+// IncludeIf é uma regra [includeIf] com o conteúdo do arquivo incluído.
+type IncludeIf struct {
+	Pattern     string `json:"pattern"`
+	IncludePath string `json:"includePath"`
+	Content     string `json:"content,omitempty"`
+}
+
+// Manifest é o profile.json dentro do pacote.
+type Manifest struct {
+	Version        int                     `json:"version"`
+	CreatedAt      time.Time               `json:"createdAt"`
+	Hostname       string                  `json:"hostname,omitempty"`
+	Home           string                  `json:"home"` // home de origem, para reescrever caminhos
+	Sections       []string                `json:"sections"`
+	Keys           []storage.Key           `json:"keys,omitempty"`
+	Accounts       []storage.Account       `json:"accounts,omitempty"`
+	Identities     []storage.GitIdentity   `json:"identities,omitempty"`
+	Servers        []storage.Server        `json:"servers,omitempty"`
+	Aliases        []storage.ShellAlias    `json:"aliases,omitempty"`
+	EnvFiles       []storage.EnvFile       `json:"envFiles,omitempty"`
+	APICollections []storage.APICollection `json:"apiCollections,omitempty"`
+	APIEndpoints   []storage.APIEndpoint   `json:"apiEndpoints,omitempty"`
+	IncludeIf      []IncludeIf             `json:"includeIf,omitempty"`
+}
+
+// Has indica se a seção foi exportada no pacote.
+func (m *Manifest) Has(section string) bool {
+	for _, s := range m.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// Bundle é um pacote aberto: manifesto e arquivos de chave indexados por
+// "keys/<alias>/<arquivo>".
+type Bundle struct {
+	Manifest Manifest
+	Files    map[string][]byte
+}
+
+// Secrets revela e sela mapas de segredos. *vault.Vault satisfaz a interface.
+type Secrets interface {
+	RevealMap(map[string]string) (map[string]string, error)
+	SealMap(map[string]string) (map[string]string, error)
+}
+
+// ExportOptions controla o que entra no pacote.
+type ExportOptions struct {
+	Sections      []string
+	Passphrase    string
+	Secrets       Secrets // nil: valores são exportados como estão
+	GitConfigPath string  // ~/.gitconfig, para a seção includeif
+}
+
+// Export monta o pacote cifrado a partir do state atual.
+func Export(state *storage.State, paths *config.Paths, opts ExportOptions) ([]byte, error) {
+	sections, err := ParseSections(opts.Sections)
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	m := Manifest{
+		Version:   FormatVersion,
+		CreatedAt: time.Now(),
+		Hostname:  host,
+		Home:      paths.Home,
+		Sections:  sections,
+	}
+	files := map[string][]byte{}
+
+	for _, sec := range sections {
+		switch sec {
+		case SectionKeys:
+			m.Keys = state.Keys
+			for _, k := range state.Keys {
+				if err := collectKeyFiles(k, paths, files); err != nil {
+					return nil, err
+				}
+			}
+		case SectionAccounts:
+			m.Accounts = state.Accounts
+		case SectionIdentities:
+			m.Identities = state.Identities
+		case SectionServers:
+			m.Servers = state.Servers
+		case SectionAliases:
+			m.Aliases = state.Aliases
+		case SectionEnvs:
+			for _, e := range state.EnvFiles {
+				if e.Variables, err = reveal(opts.Secrets, e.Variables); err != nil {
+					return nil, err
+				}
+				m.EnvFiles = append(m.EnvFiles, e)
+			}
+		case SectionAPI:
+			for _, c := range state.APICollections {
+				if c.AuthData, err = reveal(opts.Secrets, c.AuthData); err != nil {
+					return nil, err
+				}
+				m.APICollections = append(m.APICollections, c)
+			}
+			m.APIEndpoints = state.APIEndpoints
+		case SectionIncludeIf:
+			if m.IncludeIf, err = collectIncludeIf(opts.GitConfigPath, paths.Home); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	archive, err := writeArchive(&m, files)
+	if err != nil {
+		return nil, err
+	}
+	return vault.SealBytes(opts.Passphrase, archive)
+}
+
+// Open decifra e lê um pacote exportado.
+func Open(data []byte, passphrase string) (*Bundle, error) {
+	archive, err := vault.OpenBytes(passphrase, data)
+	if err != nil {
+		return nil, err
+	}
+	b, err := readArchive(archive)
+	if err != nil {
+		return nil, err
+	}
+	if b.Manifest.Version > FormatVersion {
+		return nil, fmt.Errorf("perfil gerado por uma versão mais nova do FactoryDev (formato %d)", b.Manifest.Version)
+	}
+	return b, nil
+}
+
+// ── Helpers ───────────────────────────────────────────────────────
+
+func reveal(s Secrets, m map[string]string) (map[string]string, error) {
+	if s == nil || len(m) == 0 {
+		return m, nil
+	}
+	return s.RevealMap(m)
+}
+
+// collectKeyFiles lê todos os arquivos de ~/.fdev/keys/<alias>/. Chaves
+// cujos arquivos estão fora do diretório gerenciado entram pelos paths
+// registrados no state.
+func collectKeyFiles(k storage.Key, paths *config.Paths, files map[string][]byte) error {
+	if !paths.ValidateAlias(k.Alias) {
+		return fmt.Errorf("alias de chave inválido: %q", k.Alias)
+	}
+	prefix := path.Join("keys", k.Alias)
+	dir := paths.KeyDir(k.Alias)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ler %s: %w", dir, err)
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return fmt.Errorf("ler chave %s: %w", k.Alias, err)
+		}
+		files[path.Join(prefix, e.Name())] = data
+	}
+	for _, p := range []string{k.PrivateKeyPath, k.PublicKeyPath} {
+		name := path.Join(prefix, filepath.Base(p))
+		if p == "" || files[name] != nil {
+			continue
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return fmt.Errorf("ler chave %s: %w", k.Alias, err)
+		}
+		files[name] = data
+	}
+	return nil
+}
+
+func collectIncludeIf(gitConfigPath, home string) ([]IncludeIf, error) {
+	if gitConfigPath == "" {
+		return nil, nil
+	}
+	rules, err := gitconfig.ListIncludeIf(gitConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("ler includeIf: %w", err)
+	}
+	out := make([]IncludeIf, 0, len(rules))
+	for _, r := range rules {
+		inc := IncludeIf{Pattern: r.Pattern, IncludePath: r.IncludePath}
+		if data, err := os.ReadFile(expandHome(r.IncludePath, home)); err == nil {
+			inc.Content = string(data)
+		}
+		out = append(out, inc)
+	}
+	return out, nil
+}
+
+func writeArchive(m *Manifest, files map[string][]byte) ([]byte, error) {
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("serializar perfil: %w", err)
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte, mode int64) error {
+		hdr := &tar.Header{Name: name, Mode: mode, Size: int64(len(data)), ModTime: m.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+	if err := add(manifestName, manifest, 0o600); err != nil {
+		return nil, fmt.Errorf("gravar pacote: %w", err)
+	}
+	for name, data := range files {
+		mode := int64(0o600)
+		if strings.HasSuffix(name, ".pub") {
+			mode = 0o644
+		}
+		if err := add(name, data, mode); err != nil {
+			return nil, fmt.Errorf("gravar pacote: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("gravar pacote: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("gravar pacote: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func readArchive(archive []byte) (*Bundle, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("pacote corrompido: %w", err)
+	}
+	tr := tar.NewReader(gz)
+	b := &Bundle{Files: map[string][]byte{}}
+	found := false
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("pacote corrompido: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("pacote corrompido: %w", err)
+		}
+		name := path.Clean(hdr.Name)
+		switch {
+		case name == manifestName:
+			if err := json.Unmarshal(data, &b.Manifest); err != nil {
+				return nil, fmt.Errorf("profile.json inválido: %w", err)
+			}
+			found = true
+		case strings.HasPrefix(name, "keys/"):
+			b.Files[name] = data
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("pacote sem profile.json")
+	}
+	return b, nil
+}
+
+func expandHome(p, home string) string {
+	if strings.HasPrefix(p, "~/") {
+		return filepath.Join(home, p[2:])
+	}
+	return p
+}
+
+// rewriteHome troca o prefixo oldHome por newHome em p.
+func rewriteHome(p, oldHome, newHome string) string {
+	if p == "" || oldHome == "" || oldHome == newHome {
+		return p
+	}
+	if p == oldHome {
+		return newHome
+	}
+	if strings.HasPrefix(p, oldHome+"/") {
+		return newHome + p[len(oldHome):]
+	}
+	return p
+}
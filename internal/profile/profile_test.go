@@ -0,0 +1,142 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+	"github.com/seuusuario/factorydev/internal/vault"
+)
+
+func testPaths(t *testing.T) *config.Paths {
+	home := t.TempDir()
+	base := filepath.Join(home, ".fdev")
+	return &config.Paths{Base: base, Keys: filepath.Join(base, "keys"), Home: home}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	src := testPaths(t)
+	keyDir := src.KeyDir("work")
+	if err := os.MkdirAll(keyDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(keyDir, "id_ed25519"), []byte("PRIVATE"), 0o600)
+	os.WriteFile(filepath.Join(keyDir, "id_ed25519.pub"), []byte("PUBLIC"), 0o644)
+
+	state := &storage.State{
+		Keys: []storage.Key{{ID: "k1", Alias: "work", Type: "ed25519",
+			PrivateKeyPath: filepath.Join(keyDir, "id_ed25519"),
+			PublicKeyPath:  filepath.Join(keyDir, "id_ed25519.pub")}},
+		Accounts: []storage.Account{{ID: "a1", HostAlias: "github-work", KeyID: "k1"}},
+		Servers:  []storage.Server{{ID: "s1", Name: "vps", KeyID: "k1"}},
+		EnvFiles: []storage.EnvFile{{ID: "e1", Name: "api", ProjectPath: filepath.Join(src.Home, "proj"),
+			Variables: map[string]string{"TOKEN": "abc"}}},
+	}
+
+	data, err := Export(state, src, ExportOptions{Passphrase: "correct horse"})
+	if err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	if _, err := Open(data, "wrong passphrase"); err != vault.ErrWrongPassphrase {
+		t.Fatalf("want ErrWrongPassphrase got %v", err)
+	}
+	b, err := Open(data, "correct horse")
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	// Destino já tem uma chave com o mesmo alias e outro ID.
+	dst := testPaths(t)
+	local := &storage.State{Keys: []storage.Key{{ID: "local", Alias: "work"}}}
+	res, err := Merge(local, b, dst, ImportOptions{})
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	if c := res.Counts[SectionKeys]; c.Skipped != 1 || c.Added != 0 {
+		t.Fatalf("chave em conflito deveria ser ignorada: %+v", c)
+	}
+	if local.Accounts[0].KeyID != "local" || local.Servers[0].KeyID != "local" {
+		t.Fatalf("KeyID não religado à chave local: %+v %+v", local.Accounts, local.Servers)
+	}
+	if got := local.EnvFiles[0].ProjectPath; got != filepath.Join(dst.Home, "proj") {
+		t.Fatalf("projectPath não reescrito: %s", got)
+	}
+	if local.EnvFiles[0].Variables["TOKEN"] != "abc" {
+		t.Fatalf("variáveis perdidas: %+v", local.EnvFiles[0].Variables)
+	}
+
+	// Com overwrite a chave é gravada no home de destino.
+	fresh := &storage.State{}
+	if _, err := Merge(fresh, b, dst, ImportOptions{Overwrite: true}); err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+	want := filepath.Join(dst.KeyDir("work"), "id_ed25519")
+	if fresh.Keys[0].PrivateKeyPath != want {
+		t.Fatalf("privateKeyPath: want %s got %s", want, fresh.Keys[0].PrivateKeyPath)
+	}
+	if got, _ := os.ReadFile(want); string(got) != "PRIVATE" {
+		t.Fatalf("conteúdo da chave: %q", got)
+	}
+	if info, _ := os.Stat(want); info.Mode().Perm() != 0o600 {
+		t.Fatalf("permissão da chave privada: %v", info.Mode().Perm())
+	}
+}
+
+// TestApplyIncludeIfRejectsUnsafePaths importa regras cujo arquivo incluído
+// aponta para fora de ~/.gitconfig-* e ~/.gitconfig.d/: nada é gravado.
+func TestApplyIncludeIfRejectsUnsafePaths(t *testing.T) {
+	paths := testPaths(t)
+	gitConfig := filepath.Join(paths.Home, ".gitconfig")
+	bashrc := filepath.Join(paths.Home, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("export A=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{
+		"/home/outro/.bashrc",
+		"/home/outro/.ssh/authorized_keys",
+		"/home/outro/.gitconfig.d/../.bashrc",
+		"~/.gitconfig-x/../../etc/passwd",
+		"/etc/gitconfig-work",
+		"/home/outro/.gitconfig",
+		".gitconfig-work",
+	} {
+		b := &Bundle{Manifest: Manifest{
+			Home: "/home/outro",
+			IncludeIf: []IncludeIf{
+				{Pattern: "gitdir:/home/outro/ok/", IncludePath: "/home/outro/.gitconfig-ok", Content: "[user]\n"},
+				{Pattern: "gitdir:/home/outro/work/", IncludePath: p, Content: "echo pwned\n"},
+			},
+		}}
+		if _, err := ApplyIncludeIf(b, gitConfig, paths, true); err == nil {
+			t.Errorf("%s: caminho aceito", p)
+		}
+	}
+	if got, _ := os.ReadFile(bashrc); string(got) != "export A=1\n" {
+		t.Fatalf(".bashrc alterado: %q", got)
+	}
+	for _, name := range []string{".gitconfig", ".gitconfig-ok", ".ssh"} {
+		if _, err := os.Stat(filepath.Join(paths.Home, name)); !os.IsNotExist(err) {
+			t.Errorf("%s gravado com regra recusada", name)
+		}
+	}
+
+	b := &Bundle{Manifest: Manifest{
+		Home: "/home/outro",
+		IncludeIf: []IncludeIf{
+			{Pattern: "gitdir:/home/outro/work/", IncludePath: "/home/outro/.gitconfig-work", Content: "[user]\n\temail = dev@work\n"},
+			{Pattern: "gitdir:/home/outro/oss/", IncludePath: "~/.gitconfig.d/oss", Content: "[user]\n\temail = dev@oss\n"},
+		},
+	}}
+	c, err := ApplyIncludeIf(b, gitConfig, paths, false)
+	if err != nil || c.Added != 2 {
+		t.Fatalf("regras válidas: %+v, %v", c, err)
+	}
+	for _, name := range []string{".gitconfig-work", ".gitconfig.d/oss"} {
+		if _, err := os.Stat(filepath.Join(paths.Home, name)); err != nil {
+			t.Errorf("%s não gravado: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+// Package pty abre pseudo-terminais para o terminal embutido: o shell
+// roda com o lado escravo como terminal de controle e o servidor lê e
+// escreve no lado mestre.
+package pty
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+// ErrUnsupported indica um sistema sem suporte a PTY neste pacote.
+var ErrUnsupported = errors.New("pty: não suportado neste sistema")
+
+// Start abre um PTY, liga stdin/stdout/stderr do comando ao lado escravo e
+// inicia o processo numa sessão nova. Devolve o lado mestre; fechá-lo
+// encerra o terminal.
+func Start(cmd *exec.Cmd, cols, rows int) (*os.File, error) {
+	return start(cmd, cols, rows)
+}
+
+// Supported informa se este sistema abre PTYs; sem suporte, o terminal
+// local fica com o emulador do desktop.
+func Supported() bool { return supported }
+
+// Resize informa ao PTY o novo tamanho da janela (o processo recebe
+// SIGWINCH).
+func Resize(master *os.File, cols, rows int) error {
+	return resize(master, cols, rows)
+}
@@ -0,0 +1,44 @@
+package pty
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// open abre /dev/ptmx, libera e destrava o escravo e descobre o nome dele.
+func open() (*os.File, string, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|unix.O_NOCTTY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, "", fmt.Errorf("pty: abrir /dev/ptmx: %w", err)
+	}
+	fd := master.Fd()
+	if err := ioctl(fd, unix.TIOCPTYGRANT, 0); err != nil {
+		master.Close()
+		return nil, "", fmt.Errorf("pty: grantpt: %w", err)
+	}
+	if err := ioctl(fd, unix.TIOCPTYUNLK, 0); err != nil {
+		master.Close()
+		return nil, "", fmt.Errorf("pty: unlockpt: %w", err)
+	}
+	name := make([]byte, 128)
+	if err := ioctl(fd, unix.TIOCPTYGNAME, uintptr(unsafe.Pointer(&name[0]))); err != nil {
+		master.Close()
+		return nil, "", fmt.Errorf("pty: ptsname: %w", err)
+	}
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+	return master, string(name), nil
+}
+
+func ioctl(fd uintptr, req uint, arg uintptr) error {
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(req), arg); e != 0 {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package pty
+
+import (
+	"os"
+	"os/exec"
+)
+
+const supported = false
+
+func start(*exec.Cmd, int, int) (*os.File, error) { return nil, ErrUnsupported }
+
+func resize(*os.File, int, int) error { return ErrUnsupported }
@@ -0,0 +1,34 @@
+//go:build linux || darwin
+
+package pty
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	cmd := exec.Command("/bin/sh", "-c", "stty size; test -t 0 && echo tty")
+	master, err := Start(cmd, 100, 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer master.Close()
+	var out bytes.Buffer
+	done := make(chan struct{})
+	go func() {
+		io.Copy(&out, master) // termina com EIO quando o escravo fecha
+		close(done)
+	}()
+	if err := cmd.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	got := strings.ReplaceAll(out.String(), "\r", "")
+	if got != "30 100\ntty\n" {
+		t.Fatalf("saída = %q", got)
+	}
+}
@@ -0,0 +1,55 @@
+//go:build linux || darwin
+
+package pty
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+const supported = true
+
+func start(cmd *exec.Cmd, cols, rows int) (*os.File, error) {
+	master, slaveName, err := open()
+	if err != nil {
+		return nil, err
+	}
+	slave, err := os.OpenFile(slaveName, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, fmt.Errorf("pty: abrir %s: %w", slaveName, err)
+	}
+	defer slave.Close()
+	if err := resize(master, cols, rows); err != nil {
+		master.Close()
+		return nil, err
+	}
+
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setsid = true
+	cmd.SysProcAttr.Setctty = true
+	cmd.SysProcAttr.Ctty = 0 // stdin do filho
+	if err := cmd.Start(); err != nil {
+		master.Close()
+		return nil, err
+	}
+	return master, nil
+}
+
+func resize(master *os.File, cols, rows int) error {
+	if cols <= 0 || rows <= 0 {
+		return nil
+	}
+	ws := &unix.Winsize{Col: uint16(cols), Row: uint16(rows)}
+	if err := unix.IoctlSetWinsize(int(master.Fd()), unix.TIOCSWINSZ, ws); err != nil {
+		return fmt.Errorf("pty: redimensionar: %w", err)
+	}
+	return nil
+}
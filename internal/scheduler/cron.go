@@ -0,0 +1,170 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spec é uma expressão cron de cinco campos: minuto, hora, dia do mês, mês e
+// dia da semana. Aceita "*", listas (1,15), intervalos (1-5), passos (*/10,
+// 8-18/2), nomes de meses e dias (jan, mon) e os atalhos @hourly, @daily,
+// @midnight, @weekly, @monthly e @yearly.
+type Spec struct {
+	minute, hour, dom, month, dow uint64
+	// Como no cron tradicional, se dia do mês e dia da semana forem ambos
+	// restritos, basta um deles bater.
+	domAny, dowAny bool
+}
+
+var descriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+var (
+	monthNames = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	dayNames   = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+type field struct {
+	name     string
+	min, max int
+	names    []string // names[i] vale min+i
+}
+
+var fields = [5]field{
+	{"minuto", 0, 59, nil},
+	{"hora", 0, 23, nil},
+	{"dia do mês", 1, 31, nil},
+	{"mês", 1, 12, monthNames},
+	{"dia da semana", 0, 7, dayNames}, // 7 também é domingo
+}
+
+// Parse interpreta a expressão cron.
+func Parse(expr string) (Spec, error) {
+	expr = strings.TrimSpace(strings.ToLower(expr))
+	if d, ok := descriptors[expr]; ok {
+		expr = d
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return Spec{}, fmt.Errorf("expressão cron precisa de 5 campos (minuto hora dia mês dia-da-semana), recebeu %d", len(parts))
+	}
+	var bits [5]uint64
+	for i, p := range parts {
+		b, err := parseField(p, fields[i])
+		if err != nil {
+			return Spec{}, err
+		}
+		bits[i] = b
+	}
+	// domingo = 0 ou 7
+	if bits[4]&(1<<7) != 0 {
+		bits[4] |= 1
+	}
+	return Spec{
+		minute: bits[0], hour: bits[1], dom: bits[2], month: bits[3], dow: bits[4],
+		domAny: parts[2] == "*" || strings.HasPrefix(parts[2], "*/"),
+		dowAny: parts[4] == "*" || strings.HasPrefix(parts[4], "*/"),
+	}, nil
+}
+
+func parseField(s string, f field) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(s, ",") {
+		rng, stepStr, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("passo inválido no campo %s: %q", f.name, item)
+			}
+			step = n
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case rng == "*":
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("intervalo invertido no campo %s: %q", f.name, item)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if !hasStep {
+				hi = v
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << v
+		}
+	}
+	return bits, nil
+}
+
+func (f field) value(s string) (int, error) {
+	for i, n := range f.names {
+		if s == n {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("valor inválido no campo %s: %q (%d–%d)", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// Next devolve o primeiro instante depois de t que casa com a expressão, ou
+// o zero se não houver nenhum nos próximos cinco anos (ex: 31 de fevereiro).
+func (s Spec) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s Spec) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
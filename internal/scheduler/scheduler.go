@@ -0,0 +1,303 @@
+// Package scheduler dispara, conforme expressões cron guardadas no state
+// (State.Schedules), as ações de manutenção registradas pela aplicação: pull
+// de todos os repositórios, backup do ssh_config, testes de servidores,
+// limpeza de imagens Docker, checagem de endpoints. Cada execução roda como
+// job e fica registrada em State.ScheduleRuns.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+const (
+	// TickInterval é de quanto em quanto tempo os agendamentos são avaliados.
+	TickInterval = 30 * time.Second
+	// MaxRuns é quantas execuções (de todos os agendamentos) ficam no state,
+	// além da mais recente de cada agendamento (ver trimRuns).
+	MaxRuns = 200
+)
+
+var (
+	ErrNotFound = errors.New("agendamento não encontrado")
+	// ErrRunning indica que o agendamento ainda tem uma execução em andamento.
+	ErrRunning = errors.New("agendamento já em execução")
+)
+
+// Action é uma operação que pode ser agendada.
+type Action struct {
+	Key   string
+	Label string
+	// Target é o tipo de registro que a ação recebe em Schedule.Target
+	// ("server", "collection"); vazio dispensa o alvo. Ações com alvo
+	// opcional tratam "" como "todos".
+	Target string
+	// Start dispara a ação como job.
+	Start func(target string) (jobs.Job, error)
+}
+
+// Scheduler avalia os agendamentos do workspace ativo.
+type Scheduler struct {
+	jobs   *jobs.Manager
+	store  func() (storage.Storage, func())
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu      sync.Mutex
+	actions map[string]Action
+	active  map[string]bool // agendamentos com job em andamento
+}
+
+// New cria o scheduler. store devolve o storage do workspace ativo a cada
+// avaliação, acompanhando trocas de workspace, e a função que libera o uso
+// dele. Cada execução fica registrada no storage em que começou.
+func New(jm *jobs.Manager, store func() (storage.Storage, func()), logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		jobs:    jm,
+		store:   store,
+		logger:  logger,
+		now:     time.Now,
+		actions: make(map[string]Action),
+		active:  make(map[string]bool),
+	}
+}
+
+// Register adiciona (ou substitui) uma ação agendável.
+func (s *Scheduler) Register(a Action) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.actions[a.Key] = a
+}
+
+// Action devolve a ação registrada com a chave dada.
+func (s *Scheduler) Action(key string) (Action, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.actions[key]
+	return a, ok
+}
+
+// Actions lista as ações registradas, por nome.
+func (s *Scheduler) Actions() []Action {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Action, 0, len(s.actions))
+	for _, a := range s.actions {
+		out = append(out, a)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Label < out[j].Label })
+	return out
+}
+
+// Validate confere nome, expressão cron e ação de sc.
+func (s *Scheduler) Validate(sc storage.Schedule) []storage.ValidationError {
+	var errs []storage.ValidationError
+	if strings.TrimSpace(sc.Name) == "" {
+		errs = append(errs, storage.ValidationError{Field: "name", Message: "obrigatório"})
+	}
+	if _, err := Parse(sc.Cron); err != nil {
+		errs = append(errs, storage.ValidationError{Field: "cron", Message: err.Error()})
+	}
+	if _, ok := s.Action(sc.Action); !ok {
+		errs = append(errs, storage.ValidationError{Field: "action", Message: "ação desconhecida"})
+	}
+	return errs
+}
+
+// LastRun devolve a execução mais recente do agendamento id.
+func LastRun(st *storage.State, id string) (storage.ScheduleRun, bool) {
+	for i := len(st.ScheduleRuns) - 1; i >= 0; i-- {
+		if st.ScheduleRuns[i].ScheduleID == id {
+			return st.ScheduleRuns[i], true
+		}
+	}
+	return storage.ScheduleRun{}, false
+}
+
+// NextRun calcula a próxima execução de sc a partir da última (ou da
+// criação/edição, se mais recente). Zero se desabilitado ou inválido.
+func NextRun(st *storage.State, sc storage.Schedule) time.Time {
+	if !sc.Enabled {
+		return time.Time{}
+	}
+	spec, err := Parse(sc.Cron)
+	if err != nil {
+		return time.Time{}
+	}
+	ref := sc.UpdatedAt
+	if sc.CreatedAt.After(ref) {
+		ref = sc.CreatedAt
+	}
+	if last, ok := LastRun(st, sc.ID); ok && last.StartedAt.After(ref) {
+		ref = last.StartedAt
+	}
+	return spec.Next(ref)
+}
+
+// Run avalia os agendamentos a cada TickInterval até ctx ser cancelado. A
+// primeira avaliação é imediata: o que deveria ter rodado enquanto o
+// FactoryDev estava fechado roda uma vez (catch-up), não uma vez por
+// horário perdido.
+func (s *Scheduler) Run(ctx context.Context) {
+	t := time.NewTicker(TickInterval)
+	defer t.Stop()
+	for {
+		s.tick(s.now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Scheduler) tick(now time.Time) {
+	store, release := s.store()
+	defer release()
+	st, err := store.LoadState()
+	if err != nil {
+		s.logger.Warn("scheduler: ler state", "err", err)
+		return
+	}
+	for _, sc := range st.Schedules {
+		next := NextRun(st, sc)
+		if next.IsZero() || next.After(now) {
+			continue
+		}
+		catchUp := now.Sub(next) > 2*TickInterval
+		if catchUp {
+			s.logger.Info("scheduler: execução perdida, rodando agora", "schedule", sc.Name, "previsto", next)
+		}
+		if _, err := s.start(sc, catchUp); err != nil && !errors.Is(err, ErrRunning) {
+			s.logger.Warn("scheduler: falha ao iniciar", "schedule", sc.Name, "err", err)
+		}
+	}
+}
+
+// RunNow executa o agendamento id imediatamente, fora do horário.
+func (s *Scheduler) RunNow(id string) (storage.ScheduleRun, error) {
+	store, release := s.store()
+	defer release()
+	st, err := store.LoadState()
+	if err != nil {
+		return storage.ScheduleRun{}, err
+	}
+	for _, sc := range st.Schedules {
+		if sc.ID == id {
+			return s.start(sc, false)
+		}
+	}
+	return storage.ScheduleRun{}, ErrNotFound
+}
+
+func (s *Scheduler) start(sc storage.Schedule, catchUp bool) (storage.ScheduleRun, error) {
+	s.mu.Lock()
+	if s.active[sc.ID] {
+		s.mu.Unlock()
+		return storage.ScheduleRun{}, ErrRunning
+	}
+	action, ok := s.actions[sc.Action]
+	s.active[sc.ID] = true
+	s.mu.Unlock()
+
+	// O início e o resultado vão para o mesmo storage, ainda que o
+	// workspace seja trocado durante o job.
+	store, release := s.store()
+	run := storage.ScheduleRun{ID: newID(), ScheduleID: sc.ID, CatchUp: catchUp, StartedAt: s.now()}
+	var job jobs.Job
+	err := fmt.Errorf("ação desconhecida: %s", sc.Action)
+	if ok {
+		job, err = action.Start(sc.Target)
+	}
+	if err != nil {
+		run.Status, run.Error, run.FinishedAt = string(jobs.Failed), err.Error(), s.now()
+		s.record(store, run)
+		release()
+		s.release(sc.ID)
+		return run, err
+	}
+
+	run.JobID, run.Status = job.ID, string(job.Status)
+	s.record(store, run)
+	go s.await(store, release, run)
+	return run, nil
+}
+
+// await registra o resultado quando o job termina e libera o storage.
+func (s *Scheduler) await(store storage.Storage, release func(), run storage.ScheduleRun) {
+	defer s.release(run.ScheduleID)
+	defer release()
+	j, err := s.jobs.Wait(context.Background(), run.JobID)
+	if err != nil {
+		return
+	}
+	run.Status, run.Error, run.FinishedAt = string(j.Status), j.Error, j.FinishedAt
+	s.record(store, run)
+}
+
+func (s *Scheduler) release(id string) {
+	s.mu.Lock()
+	delete(s.active, id)
+	s.mu.Unlock()
+}
+
+// record insere ou atualiza a execução, mantendo as MaxRuns mais recentes.
+func (s *Scheduler) record(store storage.Storage, run storage.ScheduleRun) {
+	err := store.Update(func(st *storage.State) error {
+		for i := range st.ScheduleRuns {
+			if st.ScheduleRuns[i].ID == run.ID {
+				st.ScheduleRuns[i] = run
+				return nil
+			}
+		}
+		st.ScheduleRuns = trimRuns(append(st.ScheduleRuns, run), MaxRuns)
+		return nil
+	})
+	if err != nil {
+		s.logger.Warn("scheduler: registrar execução", "err", err)
+	}
+}
+
+// trimRuns mantém as limit execuções mais recentes e, mesmo fora delas, a
+// última de cada agendamento: NextRun parte dela, e sem ela um agendamento
+// pouco frequente pareceria atrasado e rodaria um catch-up indevido.
+func trimRuns(runs []storage.ScheduleRun, limit int) []storage.ScheduleRun {
+	if len(runs) <= limit {
+		return runs
+	}
+	keep := make([]bool, len(runs))
+	seen := map[string]bool{}
+	kept := 0
+	for i := len(runs) - 1; i >= 0; i-- {
+		id := runs[i].ScheduleID
+		if kept < limit || !seen[id] {
+			keep[i] = true
+			kept++
+		}
+		seen[id] = true
+	}
+	out := make([]storage.ScheduleRun, 0, kept)
+	for i, r := range runs {
+		if keep[i] {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,147 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/jobs"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+func TestNext(t *testing.T) {
+	base := time.Date(2026, 3, 14, 10, 7, 30, 0, time.UTC) // sábado
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2026, 3, 14, 10, 15, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, 3, 15, 3, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 14, 11, 0, 0, 0, time.UTC)},
+		{"30 9 * * mon-fri", time.Date(2026, 3, 16, 9, 30, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		// dia do mês e da semana restritos: basta um bater (dia 20 ou segunda).
+		{"0 0 20 * 1", time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		spec, err := Parse(c.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", c.expr, err)
+		}
+		if got := spec.Next(base); !got.Equal(c.want) {
+			t.Errorf("Next(%q) = %v, want %v", c.expr, got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "0 0 * foo *"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) deveria falhar", bad)
+		}
+	}
+}
+
+func TestTickCatchUpAndHistory(t *testing.T) {
+	store := storage.NewJSONStorage(filepath.Join(t.TempDir(), "state.json"))
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	err := store.Update(func(st *storage.State) error {
+		st.Schedules = []storage.Schedule{
+			{ID: "nightly", Name: "Pull noturno", Action: "pull-all", Cron: "0 3 * * *", Enabled: true, CreatedAt: created},
+			{ID: "off", Name: "Desligado", Action: "pull-all", Cron: "* * * * *", CreatedAt: created},
+			{ID: "broken", Name: "Quebrado", Action: "backup", Cron: "0 * * * *", Enabled: true, CreatedAt: created},
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jm := jobs.New("", time.Hour)
+	s := New(jm, func() (storage.Storage, func()) { return store, func() {} }, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now := time.Date(2026, 3, 5, 9, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	started := 0
+	s.Register(Action{Key: "pull-all", Label: "Pull", Start: func(string) (jobs.Job, error) {
+		started++
+		return jm.Start(jobs.Options{Kind: "pull-all"}, func(*jobs.Run) error { return nil }), nil
+	}})
+	s.Register(Action{Key: "backup", Label: "Backup", Start: func(string) (jobs.Job, error) {
+		return jobs.Job{}, errors.New("sem ssh_config")
+	}})
+
+	// Quatro execuções noturnas perdidas viram uma só.
+	s.tick(now)
+	if started != 1 {
+		t.Fatalf("execuções iniciadas = %d", started)
+	}
+	st, _ := store.LoadState()
+	run, ok := LastRun(st, "nightly")
+	if !ok || !run.CatchUp || run.JobID == "" {
+		t.Fatalf("execução = %+v, %v", run, ok)
+	}
+	if failed, _ := LastRun(st, "broken"); failed.Status != string(jobs.Failed) || failed.Error != "sem ssh_config" {
+		t.Fatalf("execução com erro = %+v", failed)
+	}
+	if _, ok := LastRun(st, "off"); ok {
+		t.Fatal("agendamento desabilitado rodou")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := jm.Wait(ctx, run.JobID); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		st, _ = store.LoadState()
+		if run, _ = LastRun(st, "nightly"); run.Status == string(jobs.Succeeded) || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if run.Status != string(jobs.Succeeded) || run.FinishedAt.IsZero() {
+		t.Fatalf("resultado não registrado: %+v", run)
+	}
+
+	// Já rodou hoje: só volta às 3h do dia seguinte.
+	if next := NextRun(st, st.Schedules[0]); !next.Equal(time.Date(2026, 3, 6, 3, 0, 0, 0, time.UTC)) {
+		t.Fatalf("próxima execução = %v", next)
+	}
+	s.tick(now.Add(time.Minute))
+	if started != 1 {
+		t.Fatalf("rodou de novo antes do horário")
+	}
+}
+
+func TestTrimRunsKeepsLastOfEachSchedule(t *testing.T) {
+	var runs []storage.ScheduleRun
+	runs = append(runs, storage.ScheduleRun{ID: "w1", ScheduleID: "weekly"})
+	for i := range 5 {
+		runs = append(runs, storage.ScheduleRun{ID: fmt.Sprint("m", i), ScheduleID: "minutely"})
+	}
+	got := trimRuns(runs, 3)
+	var ids []string
+	for _, r := range got {
+		ids = append(ids, r.ID)
+	}
+	if strings.Join(ids, ",") != "w1,m2,m3,m4" {
+		t.Fatalf("execuções mantidas = %v", ids)
+	}
+
+	// Sem a última execução no histórico, o semanal rodaria de novo (catch-up).
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	weekly := storage.Schedule{ID: "weekly", Cron: "0 3 * * 1", Enabled: true, CreatedAt: created}
+	last := time.Date(2026, 3, 9, 3, 0, 0, 0, time.UTC)
+	st := &storage.State{ScheduleRuns: trimRuns(append([]storage.ScheduleRun{{ID: "w1", ScheduleID: "weekly", StartedAt: last}}, runs[1:]...), 3)}
+	if next := NextRun(st, weekly); !next.Equal(time.Date(2026, 3, 16, 3, 0, 0, 0, time.UTC)) {
+		t.Fatalf("próxima execução = %v", next)
+	}
+}
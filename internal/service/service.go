@@ -0,0 +1,300 @@
+// Package service instala o FactoryDev como serviço em segundo plano do
+// usuário: unit do systemd (--user) no Linux e LaunchAgent do launchd no
+// macOS. O serviço aponta para o binário atual com --open-browser=false; a
+// interface continua acessível pelo navegador ou abrindo o app de novo (ver
+// internal/instance).
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+)
+
+const (
+	// Name é o nome da unit do systemd.
+	Name = "factorydev"
+	// Label identifica o LaunchAgent no launchd.
+	Label = "com.factorydev.agent"
+)
+
+// ErrUnsupported indica um sistema sem gerenciador de serviços suportado.
+var ErrUnsupported = errors.New("serviço de usuário disponível apenas no Linux (systemd) e no macOS (launchd)")
+
+// Service descreve o serviço a instalar.
+type Service struct {
+	// OS é o sistema alvo (runtime.GOOS por padrão).
+	OS string
+	// Exe é o caminho absoluto do binário.
+	Exe string
+	// Args são as flags passadas ao servidor.
+	Args []string
+	// Home é o diretório do usuário; ConfigHome respeita XDG_CONFIG_HOME.
+	Home       string
+	ConfigHome string
+	// LogDir recebe stdout/stderr do LaunchAgent (~/.fdev/logs).
+	LogDir string
+}
+
+// New monta o serviço para o binário em execução. extraArgs são flags
+// adicionais do servidor (ex: --port 8080).
+func New(logDir string, extraArgs ...string) (*Service, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("localizar binário: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("obter home dir: %w", err)
+	}
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	return &Service{
+		OS:         runtime.GOOS,
+		Exe:        exe,
+		Args:       append([]string{"--open-browser=false"}, extraArgs...),
+		Home:       home,
+		ConfigHome: configHome,
+		LogDir:     logDir,
+	}, nil
+}
+
+// Path é onde fica a unit (Linux) ou o plist (macOS).
+func (s *Service) Path() (string, error) {
+	switch s.OS {
+	case "linux":
+		return filepath.Join(s.ConfigHome, "systemd", "user", Name+".service"), nil
+	case "darwin":
+		return filepath.Join(s.Home, "Library", "LaunchAgents", Label+".plist"), nil
+	}
+	return "", ErrUnsupported
+}
+
+// LogPath é o arquivo de stdout/stderr do LaunchAgent. No Linux a saída vai
+// para o journal.
+func (s *Service) LogPath() string {
+	return filepath.Join(s.LogDir, "service.log")
+}
+
+// Render gera o conteúdo da unit ou do plist.
+func (s *Service) Render() (string, error) {
+	switch s.OS {
+	case "linux":
+		return s.systemdUnit(), nil
+	case "darwin":
+		return s.launchdPlist(), nil
+	}
+	return "", ErrUnsupported
+}
+
+func (s *Service) systemdUnit() string {
+	cmd := make([]string, 0, len(s.Args)+1)
+	for _, a := range append([]string{s.Exe}, s.Args...) {
+		cmd = append(cmd, quoteSystemd(a))
+	}
+	return `[Unit]
+Description=FactoryDev
+After=network-online.target
+
+[Service]
+Type=simple
+ExecStart=` + strings.Join(cmd, " ") + `
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`
+}
+
+// quoteSystemd protege um argumento de ExecStart: aspas quando há espaço ou
+// caracteres especiais, e % / $ duplicados para não virarem especificadores.
+func quoteSystemd(s string) string {
+	s = strings.NewReplacer("%", "%%", "$", "$$").Replace(s)
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func (s *Service) launchdPlist() string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + Label + `</string>
+	<key>ProgramArguments</key>
+	<array>
+`)
+	for _, a := range append([]string{s.Exe}, s.Args...) {
+		b.WriteString("\t\t<string>" + html.EscapeString(a) + "</string>\n")
+	}
+	b.WriteString(`	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>StandardOutPath</key>
+	<string>` + html.EscapeString(s.LogPath()) + `</string>
+	<key>StandardErrorPath</key>
+	<string>` + html.EscapeString(s.LogPath()) + `</string>
+</dict>
+</plist>
+`)
+	return b.String()
+}
+
+// Installed informa se a unit/plist existe.
+func (s *Service) Installed() bool {
+	path, err := s.Path()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// Install grava a unit/plist e inicia o serviço, que passa a subir junto
+// com a sessão do usuário. Reinstalar sobrescreve a definição anterior.
+func (s *Service) Install() error {
+	path, err := s.Path()
+	if err != nil {
+		return err
+	}
+	content, _ := s.Render()
+	if s.OS == "darwin" {
+		if err := os.MkdirAll(s.LogDir, 0o700); err != nil {
+			return fmt.Errorf("criar %s: %w", s.LogDir, err)
+		}
+		if s.Installed() {
+			_, _ = s.run("launchctl", "unload", path)
+		}
+	}
+	if err := command.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("gravar %s: %w", path, err)
+	}
+
+	if s.OS == "darwin" {
+		return s.exec("launchctl", "load", "-w", path)
+	}
+	if err := s.exec("systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	if err := s.exec("systemctl", "--user", "enable", Name+".service"); err != nil {
+		return err
+	}
+	return s.exec("systemctl", "--user", "restart", Name+".service")
+}
+
+// Uninstall para o serviço e remove a unit/plist.
+func (s *Service) Uninstall() error {
+	path, err := s.Path()
+	if err != nil {
+		return err
+	}
+	if !s.Installed() {
+		return fmt.Errorf("serviço não instalado (%s não existe)", path)
+	}
+	if s.OS == "darwin" {
+		_, _ = s.run("launchctl", "unload", "-w", path)
+	} else {
+		_, _ = s.run("systemctl", "--user", "disable", "--now", Name+".service")
+	}
+	if command.DryRun() {
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("remover %s: %w", path, err)
+	}
+	if s.OS == "linux" {
+		return s.exec("systemctl", "--user", "daemon-reload")
+	}
+	return nil
+}
+
+// Status devolve a saída do gerenciador de serviços sobre o FactoryDev.
+// systemctl status sai com código != 0 quando o serviço está parado; nesse
+// caso a saída ainda é o que interessa.
+func (s *Service) Status() (string, error) {
+	path, err := s.Path()
+	if err != nil {
+		return "", err
+	}
+	if !s.Installed() {
+		return "não instalado (" + path + ")\n", nil
+	}
+	var out []byte
+	if s.OS == "darwin" {
+		out, err = s.query("launchctl", "list", Label)
+		if err != nil {
+			return "instalado, mas não carregado (" + path + ")\n", nil
+		}
+	} else {
+		out, err = s.query("systemctl", "--user", "status", "--no-pager", Name+".service")
+	}
+	if err != nil && len(out) == 0 {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// LogsCommand monta o comando que mostra os logs do serviço: journalctl no
+// Linux, tail do service.log no macOS.
+func (s *Service) LogsCommand(lines int, follow bool) (*exec.Cmd, error) {
+	switch s.OS {
+	case "linux":
+		args := []string{"--user", "-u", Name + ".service", "--no-pager", "-n", strconv.Itoa(lines)}
+		if follow {
+			args = append(args, "-f")
+		}
+		return exec.Command("journalctl", args...), nil
+	case "darwin":
+		args := []string{"-n", strconv.Itoa(lines)}
+		if follow {
+			args = append(args, "-f")
+		}
+		return exec.Command("tail", append(args, s.LogPath())...), nil
+	}
+	return nil, ErrUnsupported
+}
+
+func (s *Service) run(name string, args ...string) ([]byte, error) {
+	return command.CombinedOutput(context.Background(), command.Cmd{Name: name, Args: args})
+}
+
+// query roda uma consulta, que vale também em dry-run.
+func (s *Service) query(name string, args ...string) ([]byte, error) {
+	return command.CombinedOutput(context.Background(), command.Cmd{Name: name, Args: args, ReadOnly: true})
+}
+
+// exec roda o comando e inclui a saída no erro.
+func (s *Service) exec(name string, args ...string) error {
+	out, err := s.run(name, args...)
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			return fmt.Errorf("%s %s: %w", name, strings.Join(args, " "), err)
+		}
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, msg)
+	}
+	return nil
+}
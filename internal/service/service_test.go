@@ -0,0 +1,69 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/command"
+)
+
+func TestInstallSystemdUnit(t *testing.T) {
+	home := t.TempDir()
+	fake := command.NewFake()
+	defer command.SetDefault(fake)()
+	s := &Service{
+		OS:         "linux",
+		Exe:        "/opt/Factory Dev/factorydev",
+		Args:       []string{"--open-browser=false", "--port", "8080"},
+		Home:       home,
+		ConfigHome: home + "/.config",
+	}
+	if err := s.Install(); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	path := home + "/.config/systemd/user/factorydev.service"
+	data := fake.Files[path]
+	if !strings.Contains(string(data), `ExecStart="/opt/Factory Dev/factorydev" --open-browser=false --port 8080`) {
+		t.Fatalf("unit:\n%s", data)
+	}
+	if got := strings.Join(fake.Commands(), "; "); got != "systemctl --user daemon-reload; systemctl --user enable factorydev.service; systemctl --user restart factorydev.service" {
+		t.Fatalf("comandos = %s", got)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Uninstall(); err != nil {
+		t.Fatalf("Uninstall: %v", err)
+	}
+	if s.Installed() {
+		t.Fatal("unit não removida")
+	}
+}
+
+func TestLaunchdPlist(t *testing.T) {
+	s := &Service{OS: "darwin", Exe: "/Applications/FactoryDev.app/Contents/MacOS/factorydev", Args: []string{"--open-browser=false", "--workspace", "a&b"}, Home: "/Users/dev", LogDir: "/Users/dev/.fdev/logs"}
+	out, err := s.Render()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<string>com.factorydev.agent</string>",
+		"<string>/Applications/FactoryDev.app/Contents/MacOS/factorydev</string>",
+		"<string>--open-browser=false</string>",
+		"<string>a&amp;b</string>",
+		"<string>/Users/dev/.fdev/logs/service.log</string>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("plist sem %q:\n%s", want, out)
+		}
+	}
+	if p, _ := s.Path(); p != "/Users/dev/Library/LaunchAgents/com.factorydev.agent.plist" {
+		t.Fatalf("path = %s", p)
+	}
+}
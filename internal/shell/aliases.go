@@ -0,0 +1,65 @@
+// Package shell gera os arquivos que o FactoryDev entrega ao shell do
+// usuário: aliases.sh (com source no .zshrc/.bashrc) e arquivos .env.
+package shell
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
+)
+
+// AliasFile é o caminho de aliases.sh.
+func AliasFile(paths *config.Paths) string {
+	return filepath.Join(paths.Base, "aliases.sh")
+}
+
+// SyncAliases regenera ~/.fdev/aliases.sh e garante a linha de source no
+// .zshrc e no .bashrc. Retorna o caminho gravado.
+func SyncAliases(aliases []storage.ShellAlias, paths *config.Paths) (string, error) {
+	aliasPath := AliasFile(paths)
+
+	var sb strings.Builder
+	sb.WriteString("# Gerado automaticamente pelo FactoryDev — não edite manualmente\n")
+	for _, a := range aliases {
+		// Escapa aspas simples no comando
+		cmd := strings.ReplaceAll(a.Command, "'", "'\\''")
+		sb.WriteString(fmt.Sprintf("alias %s='%s'\n", a.Name, cmd))
+	}
+
+	if err := command.WriteFile(aliasPath, []byte(sb.String()), 0o644); err != nil {
+		return "", fmt.Errorf("gravar aliases.sh: %w", err)
+	}
+
+	sourceLine := fmt.Sprintf("source %q", aliasPath)
+	for _, rc := range []string{
+		filepath.Join(paths.Home, ".zshrc"),
+		filepath.Join(paths.Home, ".bashrc"),
+	} {
+		ensureSourceLine(rc, sourceLine)
+	}
+	return aliasPath, nil
+}
+
+// ensureSourceLine adiciona a linha de source se não existir no arquivo rc,
+// preservando a permissão do arquivo.
+func ensureSourceLine(rcPath, sourceLine string) {
+	data, err := os.ReadFile(rcPath)
+	if err != nil {
+		// Arquivo não existe — cria com a linha
+		_ = command.WriteFile(rcPath, []byte("# Added by FactoryDev\n"+sourceLine+"\n"), 0o644)
+		return
+	}
+	if strings.Contains(string(data), sourceLine) {
+		return // já existe
+	}
+	mode := os.FileMode(0o644)
+	if fi, err := os.Stat(rcPath); err == nil {
+		mode = fi.Mode().Perm()
+	}
+	_ = command.WriteFile(rcPath, append(data, []byte("\n# Added by FactoryDev\n"+sourceLine+"\n")...), mode)
+}
@@ -0,0 +1,29 @@
+package shell
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+)
+
+// RenderEnv gera o conteúdo KEY=VALUE, ordenado por chave.
+func RenderEnv(vars map[string]string) string {
+	lines := make([]string, 0, len(vars))
+	for k, v := range vars {
+		lines = append(lines, fmt.Sprintf("%s=%s", k, v))
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// WriteEnv grava vars em dir/<id>.env com permissão 0600 e retorna o caminho.
+func WriteEnv(dir, id string, vars map[string]string) (string, error) {
+	outPath := filepath.Join(dir, id+".env")
+	if err := command.WriteFile(outPath, []byte(RenderEnv(vars)), 0o600); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
@@ -0,0 +1,152 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// ErrNoAgent indica que não há ssh-agent acessível em $SSH_AUTH_SOCK.
+var ErrNoAgent = errors.New("ssh-agent não encontrado (SSH_AUTH_SOCK vazio ou inacessível)")
+
+// ErrPassphraseRequired indica que a chave é protegida e a passphrase não
+// foi informada.
+var ErrPassphraseRequired = errors.New("a chave é protegida: informe a passphrase")
+
+// AgentIdentity é uma chave carregada no ssh-agent.
+type AgentIdentity struct {
+	Type        string
+	Comment     string
+	Fingerprint string // SHA256:...
+	PublicKey   string // formato authorized_keys
+}
+
+// AgentOptions são as restrições aplicadas ao adicionar uma chave.
+type AgentOptions struct {
+	// Lifetime remove a chave do agent depois desse tempo; zero mantém
+	// até ser removida ou o agent encerrar.
+	Lifetime time.Duration
+	// Confirm faz o agent pedir confirmação (ssh-askpass) a cada uso.
+	Confirm bool
+}
+
+// AgentSocket devolve o caminho do socket do agent, vazio se não houver.
+func AgentSocket() string {
+	return os.Getenv("SSH_AUTH_SOCK")
+}
+
+// withAgent conecta no agent e chama fn com o cliente.
+func withAgent(fn func(agent.ExtendedAgent) error) error {
+	sock := AgentSocket()
+	if sock == "" {
+		return ErrNoAgent
+	}
+	conn, err := net.DialTimeout("unix", sock, 3*time.Second)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrNoAgent, err)
+	}
+	defer conn.Close()
+	return fn(agent.NewClient(conn))
+}
+
+// ListAgent lista as chaves carregadas no agent.
+func ListAgent() ([]AgentIdentity, error) {
+	var out []AgentIdentity
+	err := withAgent(func(a agent.ExtendedAgent) error {
+		keys, err := a.List()
+		if err != nil {
+			return fmt.Errorf("listar chaves do agent: %w", err)
+		}
+		for _, k := range keys {
+			out = append(out, AgentIdentity{
+				Type:        k.Type(),
+				Comment:     k.Comment,
+				Fingerprint: gossh.FingerprintSHA256(k),
+				PublicKey:   string(gossh.MarshalAuthorizedKey(k)),
+			})
+		}
+		return nil
+	})
+	return out, err
+}
+
+// AddToAgent decifra a chave privada e a adiciona ao agent com as
+// restrições de opts. comment aparece no `ssh-add -l`.
+func AddToAgent(privKeyPath string, passphrase []byte, comment string, opts AgentOptions) error {
+	privBytes, err := os.ReadFile(privKeyPath)
+	if err != nil {
+		return fmt.Errorf("ler chave privada: %w", err)
+	}
+	var raw any
+	if len(passphrase) > 0 {
+		raw, err = gossh.ParseRawPrivateKeyWithPassphrase(privBytes, passphrase)
+	} else {
+		raw, err = gossh.ParseRawPrivateKey(privBytes)
+	}
+	var missing *gossh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return ErrPassphraseRequired
+	}
+	if err != nil {
+		return fmt.Errorf("parsear chave privada: %w", err)
+	}
+
+	key := agent.AddedKey{
+		PrivateKey:       raw,
+		Comment:          comment,
+		ConfirmBeforeUse: opts.Confirm,
+	}
+	if opts.Lifetime > 0 {
+		key.LifetimeSecs = uint32(opts.Lifetime / time.Second)
+	}
+	return withAgent(func(a agent.ExtendedAgent) error {
+		if err := a.Add(key); err != nil {
+			return fmt.Errorf("adicionar chave ao agent: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveFromAgent remove do agent a chave com o fingerprint SHA256 dado.
+func RemoveFromAgent(fingerprint string) error {
+	return withAgent(func(a agent.ExtendedAgent) error {
+		keys, err := a.List()
+		if err != nil {
+			return fmt.Errorf("listar chaves do agent: %w", err)
+		}
+		for _, k := range keys {
+			if gossh.FingerprintSHA256(k) == fingerprint {
+				if err := a.Remove(k); err != nil {
+					return fmt.Errorf("remover chave do agent: %w", err)
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("chave %s não está no agent", fingerprint)
+	})
+}
+
+// RemoveAllFromAgent esvazia o agent, como `ssh-add -D`.
+func RemoveAllFromAgent() error {
+	return withAgent(func(a agent.ExtendedAgent) error {
+		return a.RemoveAll()
+	})
+}
+
+// PublicKeyFingerprint lê um arquivo .pub e devolve o fingerprint SHA256.
+func PublicKeyFingerprint(pubKeyPath string) (string, error) {
+	data, err := os.ReadFile(pubKeyPath)
+	if err != nil {
+		return "", err
+	}
+	pub, _, _, _, err := gossh.ParseAuthorizedKey(data)
+	if err != nil {
+		return "", fmt.Errorf("parsear chave pública: %w", err)
+	}
+	return gossh.FingerprintSHA256(pub), nil
+}
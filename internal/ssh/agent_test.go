@@ -0,0 +1,85 @@
+package ssh
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// serveKeyring atende um agent em memória num socket temporário e aponta
+// SSH_AUTH_SOCK para ele.
+func serveKeyring(t *testing.T) agent.Agent {
+	t.Helper()
+	sock := filepath.Join(t.TempDir(), "agent.sock")
+	l, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	keyring := agent.NewKeyring()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_ = agent.ServeAgent(keyring, conn)
+			}()
+		}
+	}()
+	t.Setenv("SSH_AUTH_SOCK", sock)
+	return keyring
+}
+
+func TestAgentAddListRemove(t *testing.T) {
+	serveKeyring(t)
+	home := t.TempDir()
+	paths := &config.Paths{Home: home, Keys: filepath.Join(home, "keys")}
+	key, err := GenerateKeyFull("work", "dev@host", "ed25519", 0, []byte("segredo"), paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddToAgent(key.PrivateKeyPath, nil, "work", AgentOptions{}); !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("sem passphrase: %v", err)
+	}
+	if err := AddToAgent(key.PrivateKeyPath, []byte("segredo"), "work", AgentOptions{Lifetime: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+
+	fp, err := PublicKeyFingerprint(key.PublicKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids, err := ListAgent()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 1 || ids[0].Fingerprint != fp || ids[0].Comment != "work" || ids[0].Type != "ssh-ed25519" {
+		t.Fatalf("identidades = %+v", ids)
+	}
+
+	if err := RemoveFromAgent(fp); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := ListAgent(); len(ids) != 0 {
+		t.Fatalf("chave não removida: %+v", ids)
+	}
+	if err := RemoveFromAgent(fp); err == nil {
+		t.Fatal("remover chave ausente deveria falhar")
+	}
+}
+
+func TestAgentMissingSocket(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if _, err := ListAgent(); !errors.Is(err, ErrNoAgent) {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -8,23 +8,40 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/seuusuario/factorydev/internal/config"
 )
 
+// backupRetention é quantos backups de cada ssh_config são mantidos. Vem
+// da configuração (backupRetention) e pode mudar com o servidor no ar.
+var backupRetention atomic.Int64
+
+func init() { backupRetention.Store(10) }
+
+// SetBackupRetention ajusta quantos backups de cada ssh_config são mantidos.
+func SetBackupRetention(n int) { backupRetention.Store(int64(n)) }
+
+// BackupRetention devolve quantos backups de cada ssh_config são mantidos.
+func BackupRetention() int { return int(backupRetention.Load()) }
+
 func BackupSSHConfig(paths *config.Paths) error {
 	src := paths.SSHConfig()
 	if _, err := os.Stat(src); os.IsNotExist(err) {
 		return nil
 	}
+	prefix := "ssh_config_"
+	if paths.SSHInclude != "" {
+		prefix = "ssh_include_" + paths.Workspace + "_"
+	}
 	ts := time.Now().Format("20060102_150405")
-	dst := filepath.Join(paths.Backups, "ssh_config_"+ts)
+	dst := filepath.Join(paths.Backups, prefix+ts)
 	if err := copyFile(src, dst, 0o600); err != nil {
 		return fmt.Errorf("backup ssh_config: %w", err)
 	}
 	slog.Info("backup criado", "path", dst)
-	return pruneOldBackups(paths.Backups, "ssh_config_", 10)
+	return pruneOldBackups(paths.Backups, prefix, BackupRetention())
 }
 
 func copyFile(src, dst string, perm os.FileMode) error {
@@ -0,0 +1,165 @@
+package ssh
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Tipos de certificado, como no `ssh-keygen -s` (com ou sem -h).
+const (
+	CertTypeUser = "user"
+	CertTypeHost = "host"
+)
+
+// DefaultUserExtensions são as extensões que o ssh-keygen aplica por padrão
+// em certificados de usuário.
+var DefaultUserExtensions = []string{
+	"permit-X11-forwarding",
+	"permit-agent-forwarding",
+	"permit-port-forwarding",
+	"permit-pty",
+	"permit-user-rc",
+}
+
+// SignRequest descreve um certificado a emitir.
+type SignRequest struct {
+	CAKeyPath    string
+	CAPassphrase []byte
+	// PublicKeyPath é o .pub a assinar; o certificado vai para o mesmo
+	// caminho com o sufixo -cert.pub.
+	PublicKeyPath   string
+	Type            string // CertTypeUser | CertTypeHost
+	Identity        string
+	Serial          uint64
+	Principals      []string
+	ValidAfter      time.Time
+	ValidBefore     time.Time // zero: sem expiração
+	CriticalOptions map[string]string
+	Extensions      map[string]string
+}
+
+// CertPath devolve o caminho do certificado de uma chave pública
+// (id_ed25519.pub → id_ed25519-cert.pub), onde o ssh o procura.
+func CertPath(pubKeyPath string) string {
+	return strings.TrimSuffix(pubKeyPath, ".pub") + "-cert.pub"
+}
+
+// SignCertificate assina a chave pública com a CA e grava o certificado.
+// Devolve o caminho gravado.
+func SignCertificate(req SignRequest) (string, error) {
+	caBytes, err := os.ReadFile(req.CAKeyPath)
+	if err != nil {
+		return "", fmt.Errorf("ler chave da CA: %w", err)
+	}
+	var signer gossh.Signer
+	if len(req.CAPassphrase) > 0 {
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(caBytes, req.CAPassphrase)
+	} else {
+		signer, err = gossh.ParsePrivateKey(caBytes)
+	}
+	var missing *gossh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return "", ErrPassphraseRequired
+	}
+	if err != nil {
+		return "", fmt.Errorf("parsear chave da CA: %w", err)
+	}
+
+	pubBytes, err := os.ReadFile(req.PublicKeyPath)
+	if err != nil {
+		return "", fmt.Errorf("ler chave pública: %w", err)
+	}
+	pub, comment, _, _, err := gossh.ParseAuthorizedKey(pubBytes)
+	if err != nil {
+		return "", fmt.Errorf("parsear chave pública: %w", err)
+	}
+	if _, ok := pub.(*gossh.Certificate); ok {
+		return "", errors.New("a chave informada já é um certificado")
+	}
+
+	cert := &gossh.Certificate{
+		Key:             pub,
+		Serial:          req.Serial,
+		CertType:        gossh.UserCert,
+		KeyId:           req.Identity,
+		ValidPrincipals: req.Principals,
+		ValidAfter:      uint64(req.ValidAfter.Unix()),
+		ValidBefore:     gossh.CertTimeInfinity,
+		Permissions: gossh.Permissions{
+			CriticalOptions: req.CriticalOptions,
+			Extensions:      req.Extensions,
+		},
+	}
+	if req.Type == CertTypeHost {
+		cert.CertType = gossh.HostCert
+	}
+	if !req.ValidBefore.IsZero() {
+		cert.ValidBefore = uint64(req.ValidBefore.Unix())
+	}
+	if err := cert.SignCert(rand.Reader, signer); err != nil {
+		return "", fmt.Errorf("assinar certificado: %w", err)
+	}
+
+	line := strings.TrimSuffix(string(gossh.MarshalAuthorizedKey(cert)), "\n")
+	if comment != "" {
+		line += " " + comment
+	}
+	path := CertPath(req.PublicKeyPath)
+	if err := command.WriteFile(path, []byte(line+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("gravar certificado: %w", err)
+	}
+	return path, nil
+}
+
+// ParseCertificate lê um arquivo -cert.pub.
+func ParseCertificate(path string) (*gossh.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pub, _, _, _, err := gossh.ParseAuthorizedKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsear certificado: %w", err)
+	}
+	cert, ok := pub.(*gossh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("%s não é um certificado", path)
+	}
+	return cert, nil
+}
+
+// ParseValidity interpreta a validade de um certificado: uma duração do Go
+// ("12h") ou em dias/semanas ("30d", "52w"); "forever" ou vazio não expira.
+func ParseValidity(s string) (time.Duration, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" || s == "forever" {
+		return 0, nil
+	}
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("validade inválida: %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("validade inválida: %q", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,116 @@
+package ssh
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/config"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestSignCertificate(t *testing.T) {
+	home := t.TempDir()
+	paths := &config.Paths{Home: home, Keys: filepath.Join(home, "keys")}
+	ca, err := GenerateKeyFull("ca", "ca@fdev", "ed25519", 0, []byte("ca-pass"), paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user, err := GenerateKeyFull("work", "dev@host", "ecdsa", 256, nil, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	req := SignRequest{
+		CAKeyPath:       ca.PrivateKeyPath,
+		PublicKeyPath:   user.PublicKeyPath,
+		Type:            CertTypeUser,
+		Identity:        "dev",
+		Serial:          7,
+		Principals:      []string{"deploy", "ubuntu"},
+		ValidAfter:      now,
+		ValidBefore:     now.Add(time.Hour),
+		CriticalOptions: map[string]string{"source-address": "10.0.0.0/8"},
+		Extensions:      map[string]string{"permit-pty": ""},
+	}
+	if _, err := SignCertificate(req); !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("CA protegida sem passphrase: %v", err)
+	}
+	req.CAPassphrase = []byte("ca-pass")
+	path, err := SignCertificate(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := strings.TrimSuffix(user.PublicKeyPath, ".pub") + "-cert.pub"; path != want {
+		t.Fatalf("path = %q, want %q", path, want)
+	}
+
+	cert, err := ParseCertificate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.CertType != gossh.UserCert || cert.Serial != 7 || cert.KeyId != "dev" || len(cert.ValidPrincipals) != 2 {
+		t.Fatalf("certificado = %+v", cert)
+	}
+	if cert.ValidBefore != uint64(now.Add(time.Hour).Unix()) || cert.CriticalOptions["source-address"] != "10.0.0.0/8" {
+		t.Fatalf("validade/opções = %d %v", cert.ValidBefore, cert.CriticalOptions)
+	}
+	caPub, err := PublicKeyFingerprint(ca.PublicKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gossh.FingerprintSHA256(cert.SignatureKey) != caPub {
+		t.Fatal("certificado não foi assinado pela CA")
+	}
+	checker := gossh.CertChecker{}
+	if err := checker.CheckCert("deploy", cert); err != nil {
+		t.Fatalf("CheckCert: %v", err)
+	}
+
+	// Certificado de host sem expiração.
+	req.Type, req.ValidBefore, req.Extensions = CertTypeHost, time.Time{}, nil
+	path, err = SignCertificate(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert, _ = ParseCertificate(path); cert.CertType != gossh.HostCert || cert.ValidBefore != gossh.CertTimeInfinity {
+		t.Fatalf("certificado de host = %+v", cert)
+	}
+
+	// Em dry-run a assinatura não regrava o -cert.pub.
+	fake := command.NewFake()
+	fake.SetDryRun(true)
+	defer command.SetDefault(fake)()
+	req.Serial = 8
+	if _, err := SignCertificate(req); err != nil {
+		t.Fatal(err)
+	}
+	if cert, _ = ParseCertificate(path); cert.Serial != 7 {
+		t.Fatalf("dry-run regravou o certificado: serial %d", cert.Serial)
+	}
+}
+
+func TestParseValidity(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":        0,
+		"forever": 0,
+		"12h":     12 * time.Hour,
+		"30d":     30 * 24 * time.Hour,
+		"2w":      14 * 24 * time.Hour,
+	}
+	for in, want := range cases {
+		got, err := ParseValidity(in)
+		if err != nil || got != want {
+			t.Errorf("ParseValidity(%q) = %v, %v", in, got, err)
+		}
+	}
+	for _, in := range []string{"3xd", "-1h", "abc", "0d"} {
+		if _, err := ParseValidity(in); err == nil {
+			t.Errorf("ParseValidity(%q) deveria falhar", in)
+		}
+	}
+}
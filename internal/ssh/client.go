@@ -0,0 +1,424 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/knownhosts"
+	gossh "golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	xknownhosts "golang.org/x/crypto/ssh/knownhosts"
+)
+
+// Situação da chave de host após a conexão.
+const (
+	HostKeyKnown   = "known"   // já estava no known_hosts
+	HostKeyAdded   = "added"   // desconhecida, gravada (accept-new)
+	HostKeyChanged = "changed" // diverge do known_hosts: conexão recusada
+	HostKeyRevoked = "revoked" // marcada como @revoked
+)
+
+// dialTimeout é o limite da conexão TCP, como o ConnectTimeout=5 do ssh.
+const dialTimeout = 5 * time.Second
+
+// Diagnostics descreve o que aconteceu numa conexão: endereço, latência,
+// versão e banner do servidor, chave de host e as chaves oferecidas na
+// autenticação. É preenchido até o ponto em que a conexão falhou.
+type Diagnostics struct {
+	Addr          string
+	ConnectTime   time.Duration // conexão TCP
+	HandshakeTime time.Duration // conexão TCP até a autenticação concluída
+	ServerVersion string
+	Banner        string
+
+	HostKeyType        string
+	HostKeyFingerprint string
+	HostKeyStatus      string
+
+	Auth []AuthAttempt
+}
+
+// AuthAttempt é uma identidade oferecida (ou descartada) na autenticação.
+type AuthAttempt struct {
+	Method   string // publickey
+	Identity string // arquivo ou "agent", com o fingerprint
+	Accepted bool
+	Note     string // motivo de a identidade não ter sido oferecida
+}
+
+// Report escreve o diagnóstico em linhas legíveis.
+func (d Diagnostics) Report(w io.Writer) {
+	if d.Addr != "" {
+		fmt.Fprintf(w, "Endereço: %s", d.Addr)
+		if d.ConnectTime > 0 {
+			fmt.Fprintf(w, " (TCP em %s)", d.ConnectTime.Round(time.Millisecond))
+		}
+		fmt.Fprintln(w)
+	}
+	if d.ServerVersion != "" {
+		fmt.Fprintf(w, "Servidor: %s\n", d.ServerVersion)
+	}
+	if d.HostKeyFingerprint != "" {
+		fmt.Fprintf(w, "Chave de host: %s %s (%s)\n", d.HostKeyType, d.HostKeyFingerprint, hostKeyStatusLabel(d.HostKeyStatus))
+	}
+	if b := strings.TrimSpace(d.Banner); b != "" {
+		fmt.Fprintf(w, "Banner:\n%s\n", b)
+	}
+	for _, a := range d.Auth {
+		status := "não aceita"
+		switch {
+		case a.Accepted:
+			status = "aceita"
+		case a.Note != "":
+			status = a.Note
+		}
+		fmt.Fprintf(w, "Autenticação %s: %s — %s\n", a.Method, a.Identity, status)
+	}
+	if d.HandshakeTime > 0 {
+		fmt.Fprintf(w, "Conectado em %s\n", d.HandshakeTime.Round(time.Millisecond))
+	}
+}
+
+func hostKeyStatusLabel(s string) string {
+	switch s {
+	case HostKeyKnown:
+		return "conhecida"
+	case HostKeyAdded:
+		return "nova, adicionada ao known_hosts"
+	case HostKeyChanged:
+		return "DIVERGE do known_hosts"
+	case HostKeyRevoked:
+		return "revogada"
+	}
+	return "não verificada"
+}
+
+// Dial conecta e autentica no servidor com o cliente SSH do Go, sem o
+// binário ssh. Com KeyPath, só essa chave é oferecida (IdentitiesOnly):
+// lida do disco ou, se protegida, do ssh-agent; sem KeyPath, as chaves do
+// agent. A chave de host segue o StrictHostKeyChecking=accept-new: hosts
+// novos são gravados no known_hosts e chaves divergentes recusam a conexão.
+func Dial(ctx context.Context, t Target) (*gossh.Client, Diagnostics, error) {
+	addr := net.JoinHostPort(t.Host, t.port())
+	d := Diagnostics{Addr: addr}
+
+	start := time.Now()
+	conn, err := (&net.Dialer{Timeout: dialTimeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, d, fmt.Errorf("conectar em %s: %w", addr, err)
+	}
+	d.ConnectTime = time.Since(start)
+
+	var agentClient agent.ExtendedAgent
+	if sock := AgentSocket(); sock != "" {
+		if ac, err := net.DialTimeout("unix", sock, 3*time.Second); err == nil {
+			defer ac.Close()
+			agentClient = agent.NewClient(ac)
+		}
+	}
+	khPath := t.knownHostsPath()
+	cfg := &gossh.ClientConfig{
+		User:              t.User,
+		Auth:              []gossh.AuthMethod{gossh.PublicKeysCallback(t.signers(agentClient, &d))},
+		HostKeyCallback:   hostKeyCallback(khPath, &d),
+		HostKeyAlgorithms: knownHostKeyAlgorithms(khPath, t.Host, t.Port),
+		BannerCallback: func(msg string) error {
+			d.Banner += msg
+			return nil
+		},
+	}
+
+	// O handshake não recebe context: o prazo vai na conexão e o
+	// cancelamento a fecha.
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, chans, reqs, err := gossh.NewClientConn(conn, addr, cfg)
+	if err != nil {
+		conn.Close()
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, d, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	d.HandshakeTime = time.Since(start)
+	d.ServerVersion = string(c.ServerVersion())
+	return gossh.NewClient(c, chans, reqs), d, nil
+}
+
+// Diagnose conecta, abre uma sessão que apenas sai e devolve o diagnóstico.
+func Diagnose(ctx context.Context, t Target) (Diagnostics, error) {
+	client, d, err := Dial(ctx, t)
+	if err != nil {
+		return d, err
+	}
+	defer client.Close()
+	sess, err := client.NewSession()
+	if err != nil {
+		return d, fmt.Errorf("abrir sessão: %w", err)
+	}
+	defer sess.Close()
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+	if err := sess.Run("exit"); err != nil {
+		return d, fmt.Errorf("executar comando de teste: %w", err)
+	}
+	return d, nil
+}
+
+// ExecResult é o resultado de um comando remoto.
+type ExecResult struct {
+	ExitCode int
+	Duration time.Duration
+}
+
+// Exec executa cmd no servidor, enviando stdout e stderr conforme chegam.
+// Código de saída diferente de zero também é devolvido como erro. Em
+// dry-run, o comando só é registrado em stdout.
+func Exec(ctx context.Context, t Target, cmd string, stdout, stderr io.Writer) (ExecResult, error) {
+	if command.DryRun() {
+		fmt.Fprintf(stdout, "[dry-run] %s@%s: %s\n", t.User, t.Host, cmd)
+		return ExecResult{}, nil
+	}
+	start := time.Now()
+	client, _, err := Dial(ctx, t)
+	if err != nil {
+		return ExecResult{ExitCode: -1}, err
+	}
+	defer client.Close()
+	sess, err := client.NewSession()
+	if err != nil {
+		return ExecResult{ExitCode: -1}, fmt.Errorf("abrir sessão: %w", err)
+	}
+	defer sess.Close()
+	sess.Stdout, sess.Stderr = stdout, stderr
+
+	stop := context.AfterFunc(ctx, func() {
+		_ = sess.Signal(gossh.SIGKILL)
+		client.Close()
+	})
+	defer stop()
+
+	err = sess.Run(cmd)
+	res := ExecResult{Duration: time.Since(start)}
+	var exitErr *gossh.ExitError
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		res.ExitCode = exitErr.ExitStatus()
+		err = fmt.Errorf("saiu com código %d", res.ExitCode)
+	case ctx.Err() != nil:
+		res.ExitCode = -1
+		err = ctx.Err()
+	default:
+		res.ExitCode = -1
+	}
+	return res, err
+}
+
+func (t Target) knownHostsPath() string {
+	if t.KnownHosts != "" {
+		return t.KnownHosts
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".ssh", "known_hosts")
+}
+
+// signers devolve as chaves oferecidas na autenticação, registrando cada
+// uma em d. A chave é marcada como aceita quando o servidor pede a
+// assinatura, o que só acontece depois de ele aceitá-la.
+func (t Target) signers(ag agent.ExtendedAgent, d *Diagnostics) func() ([]gossh.Signer, error) {
+	return func() ([]gossh.Signer, error) {
+		var out []gossh.Signer
+		offer := func(s gossh.Signer, identity string) {
+			d.Auth = append(d.Auth, AuthAttempt{Method: "publickey", Identity: identity + " " + gossh.FingerprintSHA256(s.PublicKey())})
+			out = append(out, &trackedSigner{Signer: s, attempt: len(d.Auth) - 1, d: d})
+		}
+		agentSigners := func() []gossh.Signer {
+			if ag == nil {
+				return nil
+			}
+			ss, err := ag.Signers()
+			if err != nil {
+				return nil
+			}
+			return ss
+		}
+
+		if t.KeyPath == "" {
+			for _, s := range agentSigners() {
+				offer(s, "agent")
+			}
+			if len(out) == 0 {
+				d.Auth = append(d.Auth, AuthAttempt{Method: "publickey", Identity: "agent", Note: "nenhuma chave disponível"})
+			}
+			return out, nil
+		}
+
+		name := filepath.Base(t.KeyPath)
+		data, err := os.ReadFile(t.KeyPath)
+		if err != nil {
+			d.Auth = append(d.Auth, AuthAttempt{Method: "publickey", Identity: name, Note: "não foi possível ler a chave"})
+			return nil, nil
+		}
+		signer, err := gossh.ParsePrivateKey(data)
+		if err == nil {
+			offer(signer, name)
+			return out, nil
+		}
+		// Chave protegida: usa a do agent com a mesma chave pública.
+		var missing *gossh.PassphraseMissingError
+		if !errors.As(err, &missing) {
+			d.Auth = append(d.Auth, AuthAttempt{Method: "publickey", Identity: name, Note: "chave inválida: " + err.Error()})
+			return nil, nil
+		}
+		pub := missing.PublicKey
+		if pub == nil {
+			if pubData, err := os.ReadFile(t.KeyPath + ".pub"); err == nil {
+				pub, _, _, _, _ = gossh.ParseAuthorizedKey(pubData)
+			}
+		}
+		if pub != nil {
+			for _, s := range agentSigners() {
+				if string(s.PublicKey().Marshal()) == string(pub.Marshal()) {
+					offer(s, name+" via agent")
+					return out, nil
+				}
+			}
+		}
+		d.Auth = append(d.Auth, AuthAttempt{Method: "publickey", Identity: name, Note: "protegida por passphrase e fora do ssh-agent"})
+		return nil, nil
+	}
+}
+
+// trackedSigner marca em d a tentativa aceita pelo servidor.
+type trackedSigner struct {
+	gossh.Signer
+	attempt int
+	d       *Diagnostics
+}
+
+func (s *trackedSigner) Sign(rand io.Reader, data []byte) (*gossh.Signature, error) {
+	s.d.Auth[s.attempt].Accepted = true
+	return s.Signer.Sign(rand, data)
+}
+
+// SignWithAlgorithm mantém as assinaturas rsa-sha2-* das chaves RSA.
+func (s *trackedSigner) SignWithAlgorithm(rand io.Reader, data []byte, algorithm string) (*gossh.Signature, error) {
+	s.d.Auth[s.attempt].Accepted = true
+	if as, ok := s.Signer.(gossh.AlgorithmSigner); ok {
+		return as.SignWithAlgorithm(rand, data, algorithm)
+	}
+	return s.Signer.Sign(rand, data)
+}
+
+// hostKeyCallback confere a chave de host no known_hosts (com hash,
+// portas e marcadores) e grava as desconhecidas.
+func hostKeyCallback(path string, d *Diagnostics) gossh.HostKeyCallback {
+	return func(hostname string, remote net.Addr, key gossh.PublicKey) error {
+		d.HostKeyType, d.HostKeyFingerprint = key.Type(), gossh.FingerprintSHA256(key)
+		if path == "" {
+			return errors.New("known_hosts indisponível: HOME não definido")
+		}
+		err := checkKnownHost(path, hostname, remote, key)
+		var keyErr *xknownhosts.KeyError
+		var revoked *xknownhosts.RevokedError
+		switch {
+		case err == nil:
+			d.HostKeyStatus = HostKeyKnown
+			return nil
+		case errors.As(err, &revoked):
+			d.HostKeyStatus = HostKeyRevoked
+			return fmt.Errorf("a chave de host %s de %s está revogada no known_hosts", d.HostKeyFingerprint, hostname)
+		case errors.As(err, &keyErr) && len(keyErr.Want) == 0:
+			if err := appendKnownHost(path, hostname, key); err != nil {
+				return fmt.Errorf("gravar chave de host: %w", err)
+			}
+			d.HostKeyStatus = HostKeyAdded
+			return nil
+		case errors.As(err, &keyErr):
+			d.HostKeyStatus = HostKeyChanged
+			return fmt.Errorf("REMOTE HOST IDENTIFICATION HAS CHANGED: a chave %s de %s não confere com %s:%d",
+				d.HostKeyFingerprint, hostname, keyErr.Want[0].Filename, keyErr.Want[0].Line)
+		default:
+			return err
+		}
+	}
+}
+
+func checkKnownHost(path, hostname string, remote net.Addr, key gossh.PublicKey) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return &xknownhosts.KeyError{}
+	}
+	cb, err := xknownhosts.New(path)
+	if err != nil {
+		return fmt.Errorf("ler known_hosts: %w", err)
+	}
+	return cb(hostname, remote, key)
+}
+
+// appendKnownHost acrescenta a chave ao known_hosts, como o ssh faz com
+// accept-new. A gravação passa pelo executor: em dry-run o arquivo fica
+// como está.
+func appendKnownHost(path, hostname string, key gossh.PublicKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	mode := os.FileMode(0o600)
+	if fi, err := os.Stat(path); err == nil {
+		mode = fi.Mode().Perm()
+	} else if !command.DryRun() {
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return err
+		}
+	}
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	line := xknownhosts.Line([]string{xknownhosts.Normalize(hostname)}, key)
+	return command.WriteFile(path, append(data, line+"\n"...), mode)
+}
+
+// knownHostKeyAlgorithms prefere os tipos de chave já conhecidos do host,
+// para que o servidor não apresente outro tipo e a conexão pareça uma troca
+// de chave. Sem entradas (ou com @cert-authority), fica o padrão.
+func knownHostKeyAlgorithms(path, host string, port int) []string {
+	f, err := knownhosts.Load(path)
+	if err != nil {
+		return nil
+	}
+	var algos []string
+	seen := map[string]bool{}
+	for _, e := range f.Lookup(host, port) {
+		if e.Marker == knownhosts.MarkerCertAuthority {
+			return nil
+		}
+		if e.Marker != "" || seen[e.Type()] {
+			continue
+		}
+		seen[e.Type()] = true
+		if e.Type() == gossh.KeyAlgoRSA {
+			algos = append(algos, gossh.KeyAlgoRSASHA512, gossh.KeyAlgoRSASHA256)
+		}
+		algos = append(algos, e.Type())
+	}
+	return algos
+}
@@ -0,0 +1,226 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	"github.com/seuusuario/factorydev/internal/config"
+	gossh "golang.org/x/crypto/ssh"
+	xknownhosts "golang.org/x/crypto/ssh/knownhosts"
+)
+
+// fakeServer é um sshd mínimo: aceita a chave autorizada, responde a
+// "exec" com "echo <texto>" ou "exit <código>" e tem um "shell" que ecoa
+// cada linha até receber "exit".
+func fakeServer(t *testing.T, hostKey gossh.Signer, authorized gossh.PublicKey) int {
+	t.Helper()
+	cfg := &gossh.ServerConfig{
+		PublicKeyCallback: func(_ gossh.ConnMetadata, key gossh.PublicKey) (*gossh.Permissions, error) {
+			if bytes.Equal(key.Marshal(), authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, os.ErrPermission
+		},
+		BannerCallback: func(gossh.ConnMetadata) string { return "bem-vindo\n" },
+	}
+	cfg.AddHostKey(hostKey)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveConn(conn, cfg)
+		}
+	}()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func serveConn(conn net.Conn, cfg *gossh.ServerConfig) {
+	defer conn.Close()
+	_, chans, reqs, err := gossh.NewServerConn(conn, cfg)
+	if err != nil {
+		return
+	}
+	go gossh.DiscardRequests(reqs)
+	for nc := range chans {
+		ch, chReqs, err := nc.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer ch.Close()
+			for req := range chReqs {
+				switch req.Type {
+				case "pty-req", "window-change":
+					req.Reply(req.WantReply, nil)
+					continue
+				case "shell":
+					req.Reply(true, nil)
+					go serveShell(ch)
+					continue
+				case "exec":
+				default:
+					req.Reply(false, nil)
+					continue
+				}
+				req.Reply(true, nil)
+				cmd := string(req.Payload[4:])
+				var code uint32
+				switch {
+				case strings.HasPrefix(cmd, "echo "):
+					ch.Write([]byte(strings.TrimPrefix(cmd, "echo ") + "\n"))
+				case strings.HasPrefix(cmd, "exit "):
+					var n int
+					for _, c := range strings.TrimPrefix(cmd, "exit ") {
+						n = n*10 + int(c-'0')
+					}
+					code = uint32(n)
+					ch.Stderr().Write([]byte("falhou\n"))
+				}
+				status := make([]byte, 4)
+				binary.BigEndian.PutUint32(status, code)
+				ch.SendRequest("exit-status", false, status)
+				return
+			}
+		}()
+	}
+}
+
+func serveShell(ch gossh.Channel) {
+	sc := bufio.NewScanner(ch)
+	for sc.Scan() {
+		if sc.Text() == "exit" {
+			break
+		}
+		ch.Write([]byte("> " + sc.Text() + "\r\n"))
+	}
+	ch.SendRequest("exit-status", false, []byte{0, 0, 0, 4})
+	ch.Close()
+}
+
+func newSigner(t *testing.T) gossh.Signer {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := gossh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestNativeClient(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	dir := t.TempDir()
+	paths := &config.Paths{Home: dir, Keys: filepath.Join(dir, "keys")}
+	key, err := GenerateKeyFull("srv", "dev@host", "ed25519", 0, nil, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubData, _ := os.ReadFile(key.PublicKeyPath)
+	pub, _, _, _, err := gossh.ParseAuthorizedKey(pubData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hostKey := newSigner(t)
+	port := fakeServer(t, hostKey, pub)
+	target := Target{User: "deploy", Host: "127.0.0.1", Port: port, KeyPath: key.PrivateKeyPath, KnownHosts: filepath.Join(dir, "known_hosts")}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Em dry-run a chave de host é aceita sem gravar o known_hosts.
+	fake := command.NewFake()
+	fake.SetDryRun(true)
+	restore := command.SetDefault(fake)
+	dry := target
+	dry.KnownHosts = filepath.Join(dir, "dry", "known_hosts")
+	if d, err := Diagnose(ctx, dry); err != nil || d.HostKeyStatus != HostKeyAdded {
+		t.Fatalf("dry-run: %v %s", err, d.HostKeyStatus)
+	}
+	restore()
+	if _, err := os.Stat(filepath.Dir(dry.KnownHosts)); !os.IsNotExist(err) {
+		t.Fatalf("known_hosts criado em dry-run: %v", err)
+	}
+
+	d, err := Diagnose(ctx, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.HostKeyStatus != HostKeyAdded || d.HostKeyFingerprint != gossh.FingerprintSHA256(hostKey.PublicKey()) {
+		t.Fatalf("chave de host = %s %s", d.HostKeyStatus, d.HostKeyFingerprint)
+	}
+	if len(d.Auth) != 1 || !d.Auth[0].Accepted || d.Banner != "bem-vindo\n" || !strings.HasPrefix(d.ServerVersion, "SSH-2.0-") {
+		t.Fatalf("diagnóstico = %+v", d)
+	}
+	var report bytes.Buffer
+	d.Report(&report)
+	if !strings.Contains(report.String(), "aceita") || !strings.Contains(report.String(), "adicionada ao known_hosts") {
+		t.Fatalf("relatório:\n%s", report.String())
+	}
+	if d, err := Diagnose(ctx, target); err != nil || d.HostKeyStatus != HostKeyKnown {
+		t.Fatalf("segunda conexão: %v %s", err, d.HostKeyStatus)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if res, err := Exec(ctx, target, "echo olá", &stdout, &stderr); err != nil || res.ExitCode != 0 || stdout.String() != "olá\n" {
+		t.Fatalf("exec: %v %+v %q", err, res, stdout.String())
+	}
+	if res, err := Exec(ctx, target, "exit 3", &stdout, &stderr); err == nil || res.ExitCode != 3 || stderr.String() != "falhou\n" {
+		t.Fatalf("exec com erro: %v %+v %q", err, res, stderr.String())
+	}
+
+	sh, err := OpenShell(ctx, target, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sh.Resize(100, 30); err != nil {
+		t.Fatal(err)
+	}
+	sh.Write([]byte("ls\nexit\n"))
+	out, _ := io.ReadAll(sh)
+	if code, err := sh.Wait(); err != nil || code != 4 || string(out) != "> ls\r\n" {
+		t.Fatalf("shell: %v %d %q", err, code, out)
+	}
+
+	// Chave não autorizada: a autenticação falha e a tentativa fica registrada.
+	other, err := GenerateKeyFull("other", "x", "ed25519", 0, nil, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := target
+	bad.KeyPath = other.PrivateKeyPath
+	if d, err := Diagnose(ctx, bad); err == nil || len(d.Auth) != 1 || d.Auth[0].Accepted {
+		t.Fatalf("chave não autorizada: %v %+v", err, d.Auth)
+	}
+
+	// Outra chave de host no known_hosts: conexão recusada.
+	addr := xknownhosts.Normalize(net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	line := xknownhosts.Line([]string{addr}, newSigner(t).PublicKey())
+	if err := os.WriteFile(target.KnownHosts, []byte(line+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := Diagnose(ctx, target); err == nil || d.HostKeyStatus != HostKeyChanged || !strings.Contains(err.Error(), "REMOTE HOST IDENTIFICATION HAS CHANGED") {
+		t.Fatalf("chave de host divergente: %v %s", err, d.HostKeyStatus)
+	}
+}
@@ -0,0 +1,41 @@
+package ssh
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/config"
+)
+
+// includeMarker identifica a diretiva gerenciada pelo FactoryDev.
+const includeMarker = "# FDEV workspace include"
+
+// EnsureInclude garante que o ~/.ssh/config inclua o arquivo de blocos do
+// workspace. O Include precisa vir antes de qualquer Host para valer para
+// todos os hosts, então é inserido no topo do arquivo.
+func EnsureInclude(paths *config.Paths) error {
+	if paths.SSHInclude == "" {
+		return nil
+	}
+	main := paths.UserSSHConfig()
+	current, err := os.ReadFile(main)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("ler ~/.ssh/config: %w", err)
+	}
+	directive := "Include " + paths.SSHInclude
+	for _, line := range strings.Split(string(current), "\n") {
+		if strings.TrimSpace(line) == directive {
+			return nil
+		}
+	}
+
+	// O backup usa paths.SSHConfig(); aqui o alvo é o arquivo principal.
+	mainPaths := *paths
+	mainPaths.SSHInclude = ""
+	if err := BackupSSHConfig(&mainPaths); err != nil {
+		return err
+	}
+	content := includeMarker + "\n" + directive + "\n\n" + string(current)
+	return writeFileAtomic(&mainPaths, main, content)
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/seuusuario/factorydev/internal/command"
 	"github.com/seuusuario/factorydev/internal/config"
 	gossh "golang.org/x/crypto/ssh"
 )
@@ -34,18 +35,17 @@ func GenerateKey(alias, comment, keyType string, paths *config.Paths) error {
 
 // ForceGenerateKey remove e recria a chave, mesmo que já exista.
 func ForceGenerateKey(alias, comment, keyType string, paths *config.Paths) error {
-	_ = os.Remove(paths.PrivateKeyForType(alias, keyType))
-	_ = os.Remove(paths.PublicKeyForType(alias, keyType))
+	if !command.DryRun() {
+		_ = os.Remove(paths.PrivateKeyForType(alias, keyType))
+		_ = os.Remove(paths.PublicKeyForType(alias, keyType))
+	}
 	return generateKey(alias, comment, keyType, paths)
 }
 
 // GenerateKeyFull gera uma chave com opções completas: tipo, bits e passphrase.
 // Retorna os paths dos arquivos gerados.
 func GenerateKeyFull(alias, comment, keyType string, bits int, passphrase []byte, paths *config.Paths) (*KeyGenResult, error) {
-	if err := os.MkdirAll(paths.KeyDir(alias), 0o700); err != nil {
-		return nil, err
-	}
-	if err := os.Chmod(paths.KeyDir(alias), 0o700); err != nil {
+	if err := ensureKeyDir(paths.KeyDir(alias)); err != nil {
 		return nil, err
 	}
 	privPath := paths.PrivateKeyForType(alias, keyType)
@@ -72,14 +72,11 @@ func RegenPublicKey(privKeyPath, pubKeyPath string, passphrase []byte) error {
 		return fmt.Errorf("parsear chave privada: %w", err)
 	}
 	pubBytes := gossh.MarshalAuthorizedKey(signer.PublicKey())
-	return os.WriteFile(pubKeyPath, pubBytes, 0o644)
+	return command.WriteFile(pubKeyPath, pubBytes, 0o644)
 }
 
 func generateKey(alias, comment, keyType string, paths *config.Paths) error {
-	if err := os.MkdirAll(paths.KeyDir(alias), 0o700); err != nil {
-		return err
-	}
-	if err := os.Chmod(paths.KeyDir(alias), 0o700); err != nil {
+	if err := ensureKeyDir(paths.KeyDir(alias)); err != nil {
 		return err
 	}
 	privPath := paths.PrivateKeyForType(alias, keyType)
@@ -87,6 +84,18 @@ func generateKey(alias, comment, keyType string, paths *config.Paths) error {
 	return writeKeyPair(privPath, pubPath, comment, keyType, 0, nil)
 }
 
+// ensureKeyDir cria o diretório da chave com permissão 0700. Em dry-run não
+// toca no disco, assim como as gravações das chaves.
+func ensureKeyDir(dir string) error {
+	if command.DryRun() {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	return os.Chmod(dir, 0o700)
+}
+
 func writeKeyPair(privPath, pubPath, comment, keyType string, bits int, passphrase []byte) error {
 	switch keyType {
 	case "rsa", "rsa4096":
@@ -122,10 +131,10 @@ func writeEd25519Key(privPath, pubPath, comment string, passphrase []byte) error
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(privPath, pem.EncodeToMemory(privPEM), 0o600); err != nil {
+	if err := command.WriteFile(privPath, pem.EncodeToMemory(privPEM), 0o600); err != nil {
 		return err
 	}
-	return os.WriteFile(pubPath, gossh.MarshalAuthorizedKey(sshPub), 0o644)
+	return command.WriteFile(pubPath, gossh.MarshalAuthorizedKey(sshPub), 0o644)
 }
 
 func writeRSAKey(privPath, pubPath, comment string, bits int, passphrase []byte) error {
@@ -146,10 +155,10 @@ func writeRSAKey(privPath, pubPath, comment string, bits int, passphrase []byte)
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(privPath, pem.EncodeToMemory(privPEM), 0o600); err != nil {
+	if err := command.WriteFile(privPath, pem.EncodeToMemory(privPEM), 0o600); err != nil {
 		return err
 	}
-	return os.WriteFile(pubPath, gossh.MarshalAuthorizedKey(sshPub), 0o644)
+	return command.WriteFile(pubPath, gossh.MarshalAuthorizedKey(sshPub), 0o644)
 }
 
 func writeECDSAKey(privPath, pubPath, comment string, bits int, passphrase []byte) error {
@@ -179,8 +188,8 @@ func writeECDSAKey(privPath, pubPath, comment string, bits int, passphrase []byt
 	if err != nil {
 		return err
 	}
-	if err := os.WriteFile(privPath, pem.EncodeToMemory(privPEM), 0o600); err != nil {
+	if err := command.WriteFile(privPath, pem.EncodeToMemory(privPEM), 0o600); err != nil {
 		return err
 	}
-	return os.WriteFile(pubPath, gossh.MarshalAuthorizedKey(sshPub), 0o644)
+	return command.WriteFile(pubPath, gossh.MarshalAuthorizedKey(sshPub), 0o644)
 }
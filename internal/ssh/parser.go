@@ -1,15 +1,17 @@
 package ssh
 
 import (
-	"bufio"
-	"os"
-	"strings"
+	"github.com/seuusuario/factorydev/internal/sshconfig"
 )
 
+// SSHConfigBlock é a visão resumida de um bloco Host ou Match. Em blocos
+// gerenciados, Lines não inclui os marcadores BEGIN/END FDEV.
 type SSHConfigBlock struct {
-	Alias  string
-	IsFDev bool
-	Lines  []string
+	// Alias é o primeiro padrão do Host; vazio em blocos Match.
+	Alias    string
+	Patterns []string
+	IsFDev   bool
+	Lines    []string
 }
 
 type ParsedSSHConfig struct {
@@ -17,62 +19,35 @@ type ParsedSSHConfig struct {
 	HeaderLines []string
 }
 
+// ParseSSHConfig lê path com o parser do sshconfig, sem resolver Include.
 func ParseSSHConfig(path string) (*ParsedSSHConfig, error) {
-	f, err := os.Open(path)
-	if os.IsNotExist(err) {
-		return &ParsedSSHConfig{}, nil
-	}
+	cfg, err := sshconfig.ParseFile(path)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
 	var result ParsedSSHConfig
-	var currentBlock *SSHConfigBlock
-	var pendingFDevAlias string
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		if strings.HasPrefix(trimmed, "# BEGIN FDEV ") {
-			pendingFDevAlias = strings.TrimSpace(strings.TrimPrefix(trimmed, "# BEGIN FDEV "))
-			continue
-		}
-		if strings.HasPrefix(trimmed, "# END FDEV ") {
-			continue
+	for _, l := range cfg.Global().Lines {
+		result.HeaderLines = append(result.HeaderLines, l.String())
+	}
+	for _, b := range cfg.Blocks[1:] {
+		block := SSHConfigBlock{Patterns: b.Patterns(), IsFDev: b.Managed != ""}
+		if len(block.Patterns) > 0 {
+			block.Alias = block.Patterns[0]
 		}
-
-		if strings.HasPrefix(strings.ToLower(trimmed), "host ") {
-			if currentBlock != nil {
-				result.Blocks = append(result.Blocks, *currentBlock)
+		started := false
+		for _, l := range b.Lines {
+			// Linhas antes do cabeçalho são o marcador BEGIN; o END é o
+			// comentário que fecha o bloco gerenciado.
+			if l == b.Header {
+				started = true
 			}
-			fields := strings.Fields(trimmed)
-			alias := ""
-			if len(fields) > 1 {
-				alias = fields[1]
+			if !started || block.IsFDev && !l.IsDirective() && l.Comment == sshconfig.MarkerEnd+b.Managed {
+				continue
 			}
-			currentBlock = &SSHConfigBlock{
-				Alias:  alias,
-				IsFDev: pendingFDevAlias == alias,
-				Lines:  []string{line},
-			}
-			pendingFDevAlias = ""
-			continue
-		}
-
-		if currentBlock != nil {
-			currentBlock.Lines = append(currentBlock.Lines, line)
-		} else {
-			result.HeaderLines = append(result.HeaderLines, line)
+			block.Lines = append(block.Lines, l.String())
 		}
-	}
-	if currentBlock != nil {
-		result.Blocks = append(result.Blocks, *currentBlock)
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, err
+		result.Blocks = append(result.Blocks, block)
 	}
 	return &result, nil
 }
@@ -3,7 +3,12 @@ package ssh
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/storage"
 )
 
 func TestParseSSHConfig_MixedBlocks(t *testing.T) {
@@ -38,3 +43,119 @@ Host github-work
 		t.Fatalf("unexpected second block: %+v", parsed.Blocks[1])
 	}
 }
+
+func TestGenerateAppliedConfig_PreservesExternalBlocks(t *testing.T) {
+	home := t.TempDir()
+	paths := &config.Paths{Home: home, Keys: filepath.Join(home, ".fdev", "keys")}
+	if err := os.MkdirAll(paths.SSHDir(), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	content := `Include config.d/*
+Host   a b *.corp   # sem normalizar
+  Port=2222
+
+# BEGIN FDEV github-work
+Host github-work
+  HostName github.com
+# END FDEV github-work
+
+Host *
+  ServerAliveInterval 30
+`
+	if err := os.WriteFile(paths.SSHConfig(), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	account := storage.Account{HostAlias: "github-work", HostName: "gitlab.com", IdentityFile: "/keys/work"}
+	out, err := GenerateAppliedConfig(account, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(content, "  HostName github.com\n", "  HostName gitlab.com\n  User git\n  IdentityFile /keys/work\n  IdentitiesOnly yes\n", 1)
+	if out != want {
+		t.Fatalf("config gerado:\n%s", out)
+	}
+
+	account.HostAlias = "novo"
+	out, err = GenerateAppliedConfig(account, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(out, content) || !strings.HasSuffix(out, "\n\n# BEGIN FDEV novo\nHost novo\n  HostName gitlab.com\n  User git\n  IdentityFile /keys/work\n  IdentitiesOnly yes\n# END FDEV novo\n") {
+		t.Fatalf("bloco novo:\n%s", out)
+	}
+}
+
+func TestParseImportableBlocks_IncludeAndPatterns(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(filepath.Join(sshDir, "config.d"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(sshDir, "config")
+	files := map[string]string{
+		main: `Include config.d/*
+
+Host db web.corp *.corp
+  ProxyJump bastion
+  User deploy
+
+# BEGIN FDEV github-work
+Host github-work
+  HostName github.com
+# END FDEV github-work
+
+Host *
+  IdentityFile ~/.ssh/id_default
+`,
+		filepath.Join(sshDir, "config.d", "work"): "Host db\n  HostName = 10.0.0.5\n  Port 2222\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blocks, err := ParseImportableBlocks(main)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 aliases, got %+v", blocks)
+	}
+	db, web := blocks[0], blocks[1]
+	if db.HostAlias != "db" || db.HostName != "10.0.0.5" || db.Port != "2222" || db.User != "deploy" || db.ProxyJump != "bastion" {
+		t.Fatalf("unexpected db: %+v", db)
+	}
+	if !strings.HasSuffix(db.Source, ".ssh/config:3") {
+		t.Fatalf("unexpected db source: %q", db.Source)
+	}
+	if web.HostAlias != "web.corp" || web.HostName != "web.corp" || web.Port != "" || web.IdentityFile != filepath.Join(sshDir, "id_default") {
+		t.Fatalf("unexpected web: %+v", web)
+	}
+}
+
+func TestGenerateAppliedConfig_CertificateFile(t *testing.T) {
+	home := t.TempDir()
+	paths := &config.Paths{Home: home, Keys: filepath.Join(home, ".fdev", "keys")}
+	now := time.Now()
+	state := &storage.State{
+		Keys:     []storage.Key{{ID: "k1", PrivateKeyPath: "/keys/work/id_ed25519"}},
+		Accounts: []storage.Account{{ID: "a1", HostAlias: "work", HostName: "git.corp", KeyID: "k1"}},
+		Certificates: []storage.Certificate{
+			{KeyID: "k1", Type: "user", Path: "/old-cert.pub", ValidAfter: now.Add(-48 * time.Hour), ValidBefore: now.Add(-time.Hour)},
+			{KeyID: "k1", Type: "host", Path: "/host-cert.pub", ValidAfter: now.Add(-time.Hour)},
+			{KeyID: "k1", Type: "user", Path: "/keys/work/id_ed25519-cert.pub", ValidAfter: now.Add(-time.Hour), ValidBefore: now.Add(time.Hour)},
+		},
+	}
+
+	out, err := GenerateAppliedConfig(state.ResolveKeyFiles(state.Accounts[0]), paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "  IdentityFile /keys/work/id_ed25519\n  CertificateFile /keys/work/id_ed25519-cert.pub\n  IdentitiesOnly yes\n"
+	if !strings.Contains(out, want) {
+		t.Fatalf("bloco sem CertificateFile:\n%s", out)
+	}
+}
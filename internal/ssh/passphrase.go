@@ -0,0 +1,141 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// ErrWrongPassphrase indica que a passphrase informada não decifra a chave.
+var ErrWrongPassphrase = errors.New("passphrase atual incorreta")
+
+// BackupPath é onde ChangePassphrase guarda a versão anterior da chave.
+func BackupPath(privKeyPath string) string {
+	return privKeyPath + ".bak"
+}
+
+// ChangePassphrase decifra a chave privada com oldPass (ignorada se a chave
+// não for protegida) e a regrava no formato OpenSSH cifrada com newPass;
+// newPass vazio remove a proteção. comment é gravado na chave (vazio usa o
+// do .pub), já que o x/crypto não expõe o comentário da chave privada.
+// Chaves PEM legadas (PKCS#1, SEC1, PKCS#8 e PEM cifrado com Proc-Type)
+// são convertidas.
+//
+// A chave regravada é relida e conferida. A versão anterior só fica em
+// BackupPath quando já era cifrada: um backup sem proteção anularia a
+// passphrase nova. restore regrava a versão anterior, para desfazer a troca
+// quando o registro da chave não puder ser salvo.
+func ChangePassphrase(privKeyPath, pubKeyPath, comment string, oldPass, newPass []byte) (restore func() error, err error) {
+	data, err := os.ReadFile(privKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("ler chave privada: %w", err)
+	}
+	raw, err := gossh.ParseRawPrivateKey(data)
+	var missing *gossh.PassphraseMissingError
+	encrypted := errors.As(err, &missing)
+	if encrypted {
+		if len(oldPass) == 0 {
+			return nil, ErrPassphraseRequired
+		}
+		raw, err = gossh.ParseRawPrivateKeyWithPassphrase(data, oldPass)
+		if errors.Is(err, x509.IncorrectPasswordError) {
+			return nil, ErrWrongPassphrase
+		}
+	}
+	if err != nil {
+		if block, _ := pem.Decode(data); block != nil && block.Type == "ENCRYPTED PRIVATE KEY" {
+			return nil, errors.New("PKCS#8 cifrado não é suportado: converta antes com ssh-keygen -p")
+		}
+		return nil, fmt.Errorf("parsear chave privada: %w", err)
+	}
+
+	signer, err := gossh.NewSignerFromKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("tipo de chave não suportado: %w", err)
+	}
+	if pubBytes, err := os.ReadFile(pubKeyPath); err == nil {
+		pub, c, _, _, err := gossh.ParseAuthorizedKey(pubBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parsear chave pública: %w", err)
+		}
+		if !bytes.Equal(pub.Marshal(), signer.PublicKey().Marshal()) {
+			return nil, errors.New("a chave pública não corresponde à chave privada")
+		}
+		if comment == "" {
+			comment = c
+		}
+	}
+
+	var block *pem.Block
+	if len(newPass) > 0 {
+		block, err = gossh.MarshalPrivateKeyWithPassphrase(raw, comment, newPass)
+	} else {
+		block, err = gossh.MarshalPrivateKey(raw, comment)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("serializar chave privada: %w", err)
+	}
+
+	// O backup protege contra uma gravação interrompida; sai depois da
+	// conferência se a chave anterior não era cifrada.
+	backup := BackupPath(privKeyPath)
+	if err := command.WriteFile(backup, data, 0o600); err != nil {
+		return nil, fmt.Errorf("backup da chave privada: %w", err)
+	}
+	removeBackup := func() {
+		if !command.DryRun() {
+			_ = os.Remove(backup)
+		}
+	}
+	restore = func() error {
+		if err := command.WriteFile(privKeyPath, data, 0o600); err != nil {
+			return fmt.Errorf("restaurar chave privada de %s: %w", backup, err)
+		}
+		removeBackup()
+		return nil
+	}
+	if err := command.WriteFile(privKeyPath, pem.EncodeToMemory(block), 0o600); err != nil {
+		return nil, fmt.Errorf("gravar chave privada: %w", err)
+	}
+	if command.DryRun() {
+		return restore, nil
+	}
+	if err := verifyPrivateKey(privKeyPath, newPass, signer.PublicKey()); err != nil {
+		if rerr := restore(); rerr != nil {
+			return nil, fmt.Errorf("%w (%v)", err, rerr)
+		}
+		return nil, err
+	}
+	if !encrypted {
+		removeBackup()
+	}
+	return restore, nil
+}
+
+// verifyPrivateKey relê a chave gravada e confere que ela abre com pass e
+// corresponde a want.
+func verifyPrivateKey(path string, pass []byte, want gossh.PublicKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("conferir chave gravada: %w", err)
+	}
+	var signer gossh.Signer
+	if len(pass) > 0 {
+		signer, err = gossh.ParsePrivateKeyWithPassphrase(data, pass)
+	} else {
+		signer, err = gossh.ParsePrivateKey(data)
+	}
+	if err != nil {
+		return fmt.Errorf("conferir chave gravada: %w", err)
+	}
+	if !bytes.Equal(signer.PublicKey().Marshal(), want.Marshal()) {
+		return errors.New("conferir chave gravada: a chave regravada não corresponde à original")
+	}
+	return nil
+}
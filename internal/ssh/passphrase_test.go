@@ -0,0 +1,139 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/config"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestChangePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	paths := &config.Paths{Home: dir, Keys: filepath.Join(dir, "keys")}
+	key, err := GenerateKeyFull("work", "dev@host", "ed25519", 0, nil, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Adicionar: a versão anterior, sem proteção, não fica em backup.
+	if _, err := ChangePassphrase(key.PrivateKeyPath, key.PublicKeyPath, "dev@host", nil, []byte("s3cret")); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(key.PrivateKeyPath)
+	var missing *gossh.PassphraseMissingError
+	if _, err := gossh.ParsePrivateKey(data); !errors.As(err, &missing) {
+		t.Fatalf("chave deveria estar protegida: %v", err)
+	}
+	if _, err := os.Stat(BackupPath(key.PrivateKeyPath)); !os.IsNotExist(err) {
+		t.Fatalf("backup sem proteção mantido: %v", err)
+	}
+	protected := data
+
+	// Trocar
+	if _, err := ChangePassphrase(key.PrivateKeyPath, key.PublicKeyPath, "dev@host", nil, []byte("x")); !errors.Is(err, ErrPassphraseRequired) {
+		t.Fatalf("sem passphrase atual: %v", err)
+	}
+	if _, err := ChangePassphrase(key.PrivateKeyPath, key.PublicKeyPath, "dev@host", []byte("wrong"), []byte("x")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("passphrase errada: %v", err)
+	}
+	restore, err := ChangePassphrase(key.PrivateKeyPath, key.PublicKeyPath, "dev@host", []byte("s3cret"), []byte("n3w"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(key.PrivateKeyPath)
+	if _, err := gossh.ParsePrivateKeyWithPassphrase(data, []byte("n3w")); err != nil {
+		t.Fatalf("nova passphrase: %v", err)
+	}
+	if backup, _ := os.ReadFile(BackupPath(key.PrivateKeyPath)); !bytes.Equal(backup, protected) {
+		t.Fatal("backup não guarda a versão anterior protegida")
+	}
+
+	// Desfazer volta a versão anterior e descarta o backup.
+	if err := restore(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ = os.ReadFile(key.PrivateKeyPath); !bytes.Equal(data, protected) {
+		t.Fatal("restore não regravou a versão anterior")
+	}
+	if _, err := os.Stat(BackupPath(key.PrivateKeyPath)); !os.IsNotExist(err) {
+		t.Fatalf("backup mantido após restore: %v", err)
+	}
+	if _, err := ChangePassphrase(key.PrivateKeyPath, key.PublicKeyPath, "dev@host", []byte("s3cret"), []byte("n3w")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Remover: o comentário fica visível no bloco OpenSSH sem cifra.
+	if _, err := ChangePassphrase(key.PrivateKeyPath, key.PublicKeyPath, "dev@host", []byte("n3w"), nil); err != nil {
+		t.Fatal(err)
+	}
+	data, _ = os.ReadFile(key.PrivateKeyPath)
+	if _, err := gossh.ParsePrivateKey(data); err != nil {
+		t.Fatalf("chave deveria estar sem proteção: %v", err)
+	}
+	if block, _ := pem.Decode(data); block == nil || !bytes.Contains(block.Bytes, []byte("dev@host")) {
+		t.Fatal("comentário não preservado")
+	}
+}
+
+func TestChangePassphraseLegacyPEM(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := gossh.NewPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//nolint:staticcheck // PEM cifrado legado, como o gerado por ssh-keygen antigos
+	encrypted, err := x509.EncryptPEMBlock(rand.Reader, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), []byte("old"), x509.PEMCipherAES128)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(rsaKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		block *pem.Block
+		old   []byte
+	}{
+		"pkcs1":     {&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}, nil},
+		"pkcs8":     {&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}, nil},
+		"encrypted": {encrypted, []byte("old")},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			priv, pubPath := filepath.Join(dir, "id_rsa"), filepath.Join(dir, "id_rsa.pub")
+			if err := os.WriteFile(priv, pem.EncodeToMemory(c.block), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(pubPath, gossh.MarshalAuthorizedKey(pub), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := ChangePassphrase(priv, pubPath, "", c.old, []byte("new")); err != nil {
+				t.Fatal(err)
+			}
+			data, _ := os.ReadFile(priv)
+			if block, _ := pem.Decode(data); block == nil || block.Type != "OPENSSH PRIVATE KEY" {
+				t.Fatalf("não convertida para o formato OpenSSH:\n%s", data)
+			}
+			signer, err := gossh.ParsePrivateKeyWithPassphrase(data, []byte("new"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(signer.PublicKey().Marshal(), pub.Marshal()) {
+				t.Fatal("chave diferente após conversão")
+			}
+		})
+	}
+}
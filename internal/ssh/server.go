@@ -0,0 +1,150 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/seuusuario/factorydev/internal/command"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Target identifica um servidor cadastrado: usuário, host, porta e a chave
+// privada (opcional) usada na autenticação.
+type Target struct {
+	User    string
+	Host    string
+	Port    int
+	KeyPath string
+	// KnownHosts é o known_hosts usado pelo cliente nativo (Dial); vazio
+	// usa ~/.ssh/known_hosts.
+	KnownHosts string
+}
+
+func (t Target) port() string {
+	if t.Port == 0 {
+		return "22"
+	}
+	return strconv.Itoa(t.Port)
+}
+
+// Args monta os argumentos do ssh interativo para o servidor.
+func (t Target) Args() []string {
+	args := []string{"-p", t.port(), "-o", "StrictHostKeyChecking=accept-new"}
+	if t.KeyPath != "" {
+		args = append(args, "-i", t.KeyPath)
+	}
+	return append(args, t.User+"@"+t.Host)
+}
+
+// TestServer abre uma sessão não interativa e sai; erro indica falha de
+// conexão ou autenticação. A saída é o diagnóstico da conexão.
+func TestServer(ctx context.Context, t Target) (string, error) {
+	var out bytes.Buffer
+	err := TestServerTo(ctx, t, &out)
+	return strings.TrimSpace(out.String()), err
+}
+
+// TestServerTo é o TestServer com o diagnóstico (ver Diagnose) escrito em
+// out.
+func TestServerTo(ctx context.Context, t Target, out io.Writer) error {
+	d, err := Diagnose(ctx, t)
+	d.Report(out)
+	return err
+}
+
+// AuthorizeKeyTo acrescenta a chave pública de pubKeyPath ao
+// ~/.ssh/authorized_keys do servidor, se ainda não estiver lá. A conexão usa
+// a chave de t, que precisa já estar autorizada.
+func AuthorizeKeyTo(ctx context.Context, t Target, pubKeyPath string, out io.Writer) error {
+	key, line, err := authorizedKeyLine(pubKeyPath)
+	if err != nil {
+		return err
+	}
+	return runTo(ctx, command.Cmd{Name: "ssh", Args: t.batchArgs(authorizeScript(key, line))}, out)
+}
+
+// RevokeKeyTo remove do ~/.ssh/authorized_keys do servidor as linhas com a
+// chave pública de pubKeyPath, mantendo opções e comentários das demais.
+func RevokeKeyTo(ctx context.Context, t Target, pubKeyPath string, out io.Writer) error {
+	key, _, err := authorizedKeyLine(pubKeyPath)
+	if err != nil {
+		return err
+	}
+	return runTo(ctx, command.Cmd{Name: "ssh", Args: t.batchArgs(revokeScript(key))}, out)
+}
+
+// authorizeScript é o comando remoto do AuthorizeKeyTo.
+func authorizeScript(key, line string) string {
+	return "umask 077; mkdir -p ~/.ssh && touch ~/.ssh/authorized_keys && " +
+		"{ grep -qF " + command.Quote(key) + " ~/.ssh/authorized_keys || " +
+		"printf '%s\\n' " + command.Quote(line) + " >> ~/.ssh/authorized_keys; }"
+}
+
+// revokeScript é o comando remoto do RevokeKeyTo. grep -v sai com 1 quando
+// não sobra nenhuma linha; só 2 é erro.
+func revokeScript(key string) string {
+	return "f=~/.ssh/authorized_keys; [ -f \"$f\" ] || exit 0; umask 077; " +
+		"grep -vF " + command.Quote(key) + " \"$f\" > \"$f.fdev\"; " +
+		"[ $? -le 1 ] && mv \"$f.fdev\" \"$f\""
+}
+
+// batchArgs monta os argumentos do ssh não interativo que executa remote.
+func (t Target) batchArgs(remote string) []string {
+	args := []string{
+		"-o", "ConnectTimeout=5",
+		"-o", "BatchMode=yes",
+		"-o", "StrictHostKeyChecking=accept-new",
+		"-p", t.port(),
+	}
+	if t.KeyPath != "" {
+		args = append(args, "-i", t.KeyPath, "-o", "IdentitiesOnly=yes")
+	}
+	return append(args, t.User+"@"+t.Host, remote)
+}
+
+// authorizedKeyLine lê um .pub e devolve "tipo base64", que identifica a
+// chave no authorized_keys, e a linha completa com o comentário.
+func authorizedKeyLine(pubKeyPath string) (key, line string, err error) {
+	data, err := os.ReadFile(pubKeyPath)
+	if err != nil {
+		return "", "", fmt.Errorf("ler chave pública: %w", err)
+	}
+	pub, comment, _, _, err := gossh.ParseAuthorizedKey(data)
+	if err != nil {
+		return "", "", fmt.Errorf("parsear chave pública: %w", err)
+	}
+	key = strings.TrimSpace(string(gossh.MarshalAuthorizedKey(pub)))
+	line = key
+	if comment != "" {
+		line += " " + comment
+	}
+	return key, line, nil
+}
+
+// SendFile copia localPath para remoteDest no servidor via scp.
+func SendFile(ctx context.Context, t Target, localPath, remoteDest string) (string, error) {
+	var out bytes.Buffer
+	err := SendFileTo(ctx, t, localPath, remoteDest, &out)
+	return strings.TrimSpace(out.String()), err
+}
+
+// SendFileTo é o SendFile com a saída do scp escrita em out.
+func SendFileTo(ctx context.Context, t Target, localPath, remoteDest string, out io.Writer) error {
+	args := []string{"-P", t.port(), "-o", "StrictHostKeyChecking=accept-new"}
+	if t.KeyPath != "" {
+		args = append(args, "-i", t.KeyPath)
+	}
+	args = append(args, localPath, t.User+"@"+t.Host+":"+remoteDest)
+	return runTo(ctx, command.Cmd{Name: "scp", Args: args}, out)
+}
+
+// runTo executa c enviando stdout e stderr para out.
+func runTo(ctx context.Context, c command.Cmd, out io.Writer) error {
+	c.Stdout, c.Stderr = out, out
+	return command.Run(ctx, c)
+}
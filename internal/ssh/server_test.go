@@ -0,0 +1,74 @@
+package ssh
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seuusuario/factorydev/internal/config"
+)
+
+// TestAuthorizedKeysScripts roda os comandos remotos da rotação num HOME
+// temporário, no lugar do servidor.
+func TestAuthorizedKeysScripts(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh indisponível")
+	}
+	dir := t.TempDir()
+	paths := &config.Paths{Home: dir, Keys: filepath.Join(dir, "keys")}
+	oldKey, err := GenerateKeyFull("old", "dev@host", "ed25519", 0, nil, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newKey, err := GenerateKeyFull("new", "dev's new key", "ed25519", 0, nil, paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldField, oldLine, err := authorizedKeyLine(oldKey.PublicKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newField, newLine, err := authorizedKeyLine(newKey.PublicKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remoteHome := filepath.Join(dir, "remote")
+	run := func(script string) {
+		t.Helper()
+		cmd := exec.Command("sh", "-c", script)
+		cmd.Env = append(os.Environ(), "HOME="+remoteHome)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%s: %v\n%s", script, err, out)
+		}
+	}
+	read := func() string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(remoteHome, ".ssh", "authorized_keys"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	if err := os.MkdirAll(remoteHome, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	run(authorizeScript(oldField, oldLine))
+	run(authorizeScript(newField, newLine))
+	run(authorizeScript(newField, newLine)) // idempotente
+	if got := read(); strings.Count(got, newField) != 1 || !strings.Contains(got, newLine+"\n") || !strings.Contains(got, oldField) {
+		t.Fatalf("authorized_keys após autorizar:\n%s", got)
+	}
+
+	run(revokeScript(oldField))
+	if got := read(); strings.Contains(got, oldField) || !strings.Contains(got, newLine) {
+		t.Fatalf("authorized_keys após revogar:\n%s", got)
+	}
+	run(revokeScript(newField)) // arquivo fica vazio, sem erro
+	if got := read(); got != "" {
+		t.Fatalf("authorized_keys deveria ficar vazio:\n%s", got)
+	}
+}
@@ -0,0 +1,91 @@
+package ssh
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// Shell é um shell interativo remoto, com PTY, para o terminal embutido.
+type Shell struct {
+	client *gossh.Client
+	sess   *gossh.Session
+	stdin  io.WriteCloser
+	out    io.Reader
+	once   sync.Once
+	done   chan struct{}
+	err    error // resultado de sess.Wait, válido depois de done
+}
+
+// OpenShell conecta com Dial (ctx vale só para a conexão) e abre um shell
+// de login com PTY xterm-256color do tamanho informado. stdout e stderr
+// chegam juntos, como num terminal.
+func OpenShell(ctx context.Context, t Target, cols, rows int) (*Shell, error) {
+	client, _, err := Dial(ctx, t)
+	if err != nil {
+		return nil, err
+	}
+	sess, err := client.NewSession()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("abrir sessão: %w", err)
+	}
+	stdin, err := sess.StdinPipe()
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	pr, pw := io.Pipe()
+	sess.Stdout, sess.Stderr = pw, pw
+	modes := gossh.TerminalModes{gossh.ECHO: 1, gossh.TTY_OP_ISPEED: 38400, gossh.TTY_OP_OSPEED: 38400}
+	if err := sess.RequestPty("xterm-256color", rows, cols, modes); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("pedir PTY: %w", err)
+	}
+	if err := sess.Shell(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("iniciar shell: %w", err)
+	}
+	s := &Shell{client: client, sess: sess, stdin: stdin, out: pr, done: make(chan struct{})}
+	go func() {
+		// Wait só volta depois de copiar toda a saída.
+		s.err = sess.Wait()
+		pw.Close()
+		close(s.done)
+	}()
+	return s, nil
+}
+
+func (s *Shell) Read(b []byte) (int, error)  { return s.out.Read(b) }
+func (s *Shell) Write(b []byte) (int, error) { return s.stdin.Write(b) }
+
+func (s *Shell) Resize(cols, rows int) error { return s.sess.WindowChange(rows, cols) }
+
+// Wait espera o shell terminar e devolve o código de saída.
+func (s *Shell) Wait() (int, error) {
+	<-s.done
+	s.client.Close()
+	err := s.err
+	var exitErr *gossh.ExitError
+	var missing *gossh.ExitMissingError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		return exitErr.ExitStatus(), nil
+	case errors.As(err, &missing):
+		return -1, nil
+	default:
+		return -1, err
+	}
+}
+
+// Close derruba a conexão, o que encerra o shell remoto.
+func (s *Shell) Close() error {
+	s.once.Do(func() { s.client.Close() })
+	return nil
+}
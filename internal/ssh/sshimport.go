@@ -1,44 +1,80 @@
 package ssh
 
-import "strings"
+import (
+	"fmt"
 
-// ImportableAccount representa um bloco Host do ~/.ssh/config que pode ser importado.
+	"github.com/seuusuario/factorydev/internal/sshconfig"
+)
+
+// ImportableAccount representa um host do ~/.ssh/config que pode ser
+// importado, com os valores efetivos (como no `ssh -G`).
 type ImportableAccount struct {
 	HostAlias    string
 	HostName     string
 	User         string
 	IdentityFile string
+	Port         string
+	ProxyJump    string
+	// Source é o arquivo e a linha do bloco Host de onde o alias veio.
+	Source string
 }
 
-// ParseImportableBlocks parseia um arquivo SSH config e retorna blocos Host não-FDEV.
+// ParseImportableBlocks lê o arquivo SSH config, resolvendo os Include, e
+// retorna um item por alias literal dos blocos Host não-FDEV. Padrões com
+// curinga ou negação ficam de fora, mas suas diretivas entram nos valores
+// efetivos dos aliases que casam com eles.
 func ParseImportableBlocks(configPath string) ([]ImportableAccount, error) {
-	parsed, err := ParseSSHConfig(configPath)
+	cfg, err := sshconfig.Load(configPath)
 	if err != nil {
 		return nil, err
 	}
-	var result []ImportableAccount
-	for _, block := range parsed.Blocks {
-		if block.IsFDev {
-			continue
+
+	managed := make(map[string]bool)
+	cfg.Walk(func(c *sshconfig.Config) {
+		for _, b := range c.Blocks {
+			if b.Managed != "" {
+				managed[b.Managed] = true
+			}
 		}
-		acc := ImportableAccount{HostAlias: block.Alias}
-		for _, line := range block.Lines[1:] { // pula a linha "Host ..."
-			parts := strings.Fields(line)
-			if len(parts) < 2 {
+	})
+
+	var result []ImportableAccount
+	seen := make(map[string]bool)
+	cfg.Walk(func(c *sshconfig.Config) {
+		for _, b := range c.Blocks {
+			if b.Managed != "" {
 				continue
 			}
-			key := strings.ToLower(parts[0])
-			val := parts[1]
-			switch key {
-			case "hostname":
-				acc.HostName = val
-			case "user":
-				acc.User = val
-			case "identityfile":
-				acc.IdentityFile = val
+			for _, alias := range b.Aliases() {
+				if seen[alias] || managed[alias] {
+					continue
+				}
+				seen[alias] = true
+				result = append(result, importable(cfg, alias, b.Header, c.Path))
 			}
 		}
-		result = append(result, acc)
-	}
+	})
 	return result, nil
 }
+
+// importable avalia a configuração efetiva do alias. User e Port só são
+// preenchidos quando vêm do arquivo, não dos padrões do ssh.
+func importable(cfg *sshconfig.Config, alias string, header *sshconfig.Line, path string) ImportableAccount {
+	opts := cfg.Evaluate(alias)
+	acc := ImportableAccount{
+		HostAlias: alias,
+		HostName:  opts.Get("hostname"),
+		ProxyJump: opts.Get("proxyjump"),
+		Source:    fmt.Sprintf("%s:%d", path, header.Num),
+	}
+	if opts.Source("user") != "" {
+		acc.User = opts.Get("user")
+	}
+	if opts.Source("port") != "" {
+		acc.Port = opts.Get("port")
+	}
+	if ids := opts.All("identityfile"); len(ids) > 0 {
+		acc.IdentityFile = ids[0]
+	}
+	return acc
+}
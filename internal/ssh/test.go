@@ -3,22 +3,25 @@ package ssh
 import (
 	"context"
 	"errors"
-	"os/exec"
 	"time"
+
+	"github.com/seuusuario/factorydev/internal/command"
 )
 
 func TestConnection(alias string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "ssh",
-		"-T",
-		"-o", "StrictHostKeyChecking=accept-new",
-		"-o", "BatchMode=yes",
-		"git@"+alias,
-	)
-
-	out, _ := cmd.CombinedOutput()
+	out, _ := command.CombinedOutput(ctx, command.Cmd{
+		Name: "ssh",
+		Args: []string{
+			"-T",
+			"-o", "StrictHostKeyChecking=accept-new",
+			"-o", "BatchMode=yes",
+			"git@" + alias,
+		},
+		ReadOnly: true,
+	})
 	if ctx.Err() != nil {
 		return "", errors.New("timeout: conexão demorou mais de 10s")
 	}
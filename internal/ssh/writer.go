@@ -3,9 +3,11 @@ package ssh
 import (
 	"fmt"
 	"os"
-	"strings"
+	"path/filepath"
 
+	"github.com/seuusuario/factorydev/internal/command"
 	"github.com/seuusuario/factorydev/internal/config"
+	"github.com/seuusuario/factorydev/internal/sshconfig"
 	"github.com/seuusuario/factorydev/internal/storage"
 )
 
@@ -17,63 +19,51 @@ func ApplyAccount(account storage.Account, paths *config.Paths) error {
 	if err != nil {
 		return err
 	}
-	return writeSSHConfigAtomic(paths, content)
+	if err := writeSSHConfigAtomic(paths, content); err != nil {
+		return err
+	}
+	return EnsureInclude(paths)
 }
 
+// GenerateAppliedConfig devolve o ssh_config com o bloco FDEV da conta
+// criado ou substituído. O restante do arquivo é preservado byte a byte.
 func GenerateAppliedConfig(account storage.Account, paths *config.Paths) (string, error) {
-	parsed, err := ParseSSHConfig(paths.SSHConfig())
+	cfg, err := sshconfig.ParseFile(paths.SSHConfig())
 	if err != nil {
 		return "", err
 	}
 
-	newBlock := buildFDevBlock(account, paths)
-	updated := false
-	for i := range parsed.Blocks {
-		if parsed.Blocks[i].Alias == account.HostAlias && parsed.Blocks[i].IsFDev {
-			parsed.Blocks[i] = SSHConfigBlock{Alias: account.HostAlias, IsFDev: true, Lines: newBlock}
-			updated = true
-			break
-		}
-	}
-	if !updated {
-		parsed.Blocks = append(parsed.Blocks, SSHConfigBlock{Alias: account.HostAlias, IsFDev: true, Lines: newBlock})
-	}
-
-	var out []string
-	out = append(out, parsed.HeaderLines...)
-	if len(out) > 0 && out[len(out)-1] != "" {
-		out = append(out, "")
+	block := buildFDevBlock(account, paths)
+	if old := cfg.Managed(account.HostAlias); old != nil {
+		cfg.Replace(old, block)
+	} else {
+		cfg.Append(block)
 	}
-	for idx, b := range parsed.Blocks {
-		if b.IsFDev {
-			out = append(out, "# BEGIN FDEV "+b.Alias)
-			out = append(out, b.Lines...)
-			out = append(out, "# END FDEV "+b.Alias)
-		} else {
-			out = append(out, b.Lines...)
-		}
-		if idx < len(parsed.Blocks)-1 {
-			out = append(out, "")
-		}
-	}
-	return strings.TrimSpace(strings.Join(out, "\n")) + "\n", nil
+	return cfg.String(), nil
 }
 
-func buildFDevBlock(account storage.Account, paths *config.Paths) []string {
+func buildFDevBlock(account storage.Account, paths *config.Paths) *sshconfig.Block {
 	identityFile := account.IdentityFile
 	if identityFile == "" {
 		identityFile = paths.PrivateKey(account.HostAlias)
 	}
-	return []string{
-		"Host " + account.HostAlias,
-		"  HostName " + account.HostName,
-		"  User git",
-		"  IdentityFile " + identityFile,
-		"  IdentitiesOnly yes",
+	directives := []*sshconfig.Line{
+		sshconfig.Directive("  ", "HostName", account.HostName),
+		sshconfig.Directive("  ", "User", "git"),
+		sshconfig.Directive("  ", "IdentityFile", identityFile),
+	}
+	if account.CertificateFile != "" {
+		directives = append(directives, sshconfig.Directive("  ", "CertificateFile", account.CertificateFile))
 	}
+	directives = append(directives, sshconfig.Directive("  ", "IdentitiesOnly", "yes"))
+	return sshconfig.NewManaged(account.HostAlias, directives...)
 }
 
 func writeSSHConfigAtomic(paths *config.Paths, content string) error {
+	return writeFileAtomic(paths, paths.SSHConfig(), content)
+}
+
+func writeFileAtomic(paths *config.Paths, path, content string) error {
 	if err := os.MkdirAll(paths.SSHDir(), 0o700); err != nil {
 		return fmt.Errorf("criar ~/.ssh: %w", err)
 	}
@@ -81,24 +71,8 @@ func writeSSHConfigAtomic(paths *config.Paths, content string) error {
 		return fmt.Errorf("chmod ~/.ssh: %w", err)
 	}
 
-	tmp := paths.SSHConfig() + ".tmp"
-	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
-	if err != nil {
-		return err
-	}
-	if _, err := f.WriteString(content); err != nil {
-		_ = f.Close()
-		return err
-	}
-	if err := f.Sync(); err != nil {
-		_ = f.Close()
-		return err
-	}
-	if err := f.Close(); err != nil {
-		return err
-	}
-	if err := os.Rename(tmp, paths.SSHConfig()); err != nil {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
 		return err
 	}
-	return os.Chmod(paths.SSHConfig(), 0o600)
+	return command.WriteFile(path, []byte(content), 0o600)
 }
@@ -0,0 +1,362 @@
+package sshconfig
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// Setting é um valor efetivo e a linha de onde ele veio.
+type Setting struct {
+	// Key é a palavra-chave em minúsculas, como no `ssh -G`.
+	Key    string   `json:"key"`
+	Values []string `json:"values"`
+	// Source é "arquivo:linha"; vazio para os padrões (hostname, user, port).
+	Source string `json:"source,omitempty"`
+}
+
+// Options é a configuração efetiva de um host.
+type Options struct {
+	settings []Setting
+	seen     map[string]bool
+}
+
+// Get devolve o primeiro valor de key.
+func (o *Options) Get(key string) string {
+	key = strings.ToLower(key)
+	for _, s := range o.settings {
+		if s.Key == key && len(s.Values) > 0 {
+			return s.Values[0]
+		}
+	}
+	return ""
+}
+
+// All devolve o primeiro valor de cada ocorrência de key (IdentityFile,
+// LocalForward e outras diretivas que acumulam).
+func (o *Options) All(key string) []string {
+	key = strings.ToLower(key)
+	var out []string
+	for _, s := range o.settings {
+		if s.Key == key && len(s.Values) > 0 {
+			out = append(out, s.Values[0])
+		}
+	}
+	return out
+}
+
+// Source devolve a origem da primeira ocorrência de key.
+func (o *Options) Source(key string) string {
+	key = strings.ToLower(key)
+	for _, s := range o.settings {
+		if s.Key == key {
+			return s.Source
+		}
+	}
+	return ""
+}
+
+// Settings devolve os valores na ordem em que foram definidos, com
+// hostname, user e port primeiro.
+func (o *Options) Settings() []Setting {
+	return o.settings
+}
+
+// String formata como a saída do `ssh -G`: "chave valor" por linha.
+func (o *Options) String() string {
+	var b strings.Builder
+	for _, s := range o.settings {
+		vals := make([]string, len(s.Values))
+		for i, v := range s.Values {
+			vals[i] = Quote(v)
+		}
+		fmt.Fprintf(&b, "%s %s\n", s.Key, strings.Join(vals, " "))
+	}
+	return b.String()
+}
+
+// Target descreve a conexão avaliada.
+type Target struct {
+	// Host é o nome passado ao ssh (alias ou hostname).
+	Host string
+	// LocalUser e Home são do usuário local; vazios usam o usuário atual.
+	LocalUser string
+	Home      string
+}
+
+// Evaluate calcula a configuração efetiva para host com o usuário atual.
+func (c *Config) Evaluate(host string) *Options {
+	return c.EvaluateFor(Target{Host: host})
+}
+
+// EvaluateFor calcula a configuração efetiva como o `ssh -G`: percorre o
+// arquivo e os Include na ordem, aplica as diretivas dos blocos Host/Match
+// que casam e, para cada palavra-chave, fica com o primeiro valor (ou com
+// todos, nas que acumulam). Limitações: Match exec e localnetwork nunca
+// casam (o comando não é executado), e canonical/final casam sempre, como
+// na passada final do ssh.
+func (c *Config) EvaluateFor(t Target) *Options {
+	if t.LocalUser == "" {
+		if u, err := user.Current(); err == nil {
+			t.LocalUser = u.Username
+		}
+	}
+	if t.Home == "" {
+		t.Home, _ = os.UserHomeDir()
+	}
+	e := &evaluator{t: t, o: &Options{seen: map[string]bool{}}, host: t.Host}
+	e.config(c, true)
+	e.finish()
+	return e.o
+}
+
+type evaluator struct {
+	t Target
+	o *Options
+	// host é o valor de %h: o nome passado ao ssh até o HostName efetivo
+	// ser resolvido em finish.
+	host string
+}
+
+func (e *evaluator) config(c *Config, active bool) {
+	for _, b := range c.Blocks {
+		act := active
+		if b.Header != nil {
+			act = e.matches(b)
+		}
+		if !act {
+			continue
+		}
+		for _, l := range b.Lines {
+			if !l.IsDirective() || l == b.Header {
+				continue
+			}
+			if l.Keyword() == "include" {
+				for _, inc := range c.includes[l] {
+					e.config(inc, true)
+				}
+				continue
+			}
+			e.set(l, c.Path)
+		}
+	}
+}
+
+func (e *evaluator) set(l *Line, path string) {
+	key := l.Keyword()
+	if key == "host" || key == "match" {
+		return
+	}
+	if !multiValue[key] {
+		if e.o.seen[key] {
+			return
+		}
+		e.o.seen[key] = true
+	}
+	e.o.settings = append(e.o.settings, Setting{
+		Key:    key,
+		Values: append([]string(nil), l.Values...),
+		Source: fmt.Sprintf("%s:%d", displayPath(path), l.Num),
+	})
+}
+
+func (e *evaluator) hostname() string {
+	if h := e.o.Get("hostname"); h != "" {
+		return e.expand(h)
+	}
+	return e.t.Host
+}
+
+func (e *evaluator) user() string {
+	if u := e.o.Get("user"); u != "" {
+		return u
+	}
+	return e.t.LocalUser
+}
+
+func (e *evaluator) matches(b *Block) bool {
+	if b.IsHost() {
+		return matchHost(e.t.Host, b.Header.Values)
+	}
+	args := b.Header.Values
+	ok := len(args) > 0
+	for i := 0; i < len(args); i++ {
+		crit := strings.ToLower(args[i])
+		neg := strings.HasPrefix(crit, "!")
+		crit = strings.TrimPrefix(crit, "!")
+		var m bool
+		switch crit {
+		case "all", "canonical", "final":
+			m = true
+		default:
+			if i+1 >= len(args) {
+				return false
+			}
+			i++
+			switch arg := args[i]; crit {
+			case "host":
+				m = matchList(e.hostname(), arg)
+			case "originalhost":
+				m = matchList(e.t.Host, arg)
+			case "user":
+				m = matchList(e.user(), arg)
+			case "localuser":
+				m = matchList(e.t.LocalUser, arg)
+			case "tagged":
+				m = matchList(e.o.Get("tag"), arg)
+			}
+		}
+		if neg {
+			m = !m
+		}
+		if !m {
+			ok = false
+		}
+	}
+	return ok
+}
+
+// finish aplica os padrões e expande %h, %r, %u, %d e ~ como o ssh. No
+// próprio HostName, %h é o nome passado ao ssh; nas demais opções, o
+// HostName efetivo.
+func (e *evaluator) finish() {
+	hostname := e.hostname()
+	e.host = hostname
+	for i, s := range e.o.settings {
+		switch s.Key {
+		case "identityfile", "certificatefile", "userknownhostsfile", "controlpath", "identityagent":
+			vals := make([]string, len(s.Values))
+			for j, v := range s.Values {
+				vals[j] = e.expandPath(v)
+			}
+			e.o.settings[i].Values = vals
+		}
+	}
+	var head []Setting
+	add := func(key, value string) {
+		for i, s := range e.o.settings {
+			if s.Key == key {
+				if key == "hostname" {
+					s.Values = []string{hostname}
+				}
+				head = append(head, s)
+				e.o.settings = append(e.o.settings[:i], e.o.settings[i+1:]...)
+				return
+			}
+		}
+		head = append(head, Setting{Key: key, Values: []string{value}})
+	}
+	add("hostname", e.t.Host)
+	add("user", e.t.LocalUser)
+	add("port", "22")
+	e.o.settings = append(head, e.o.settings...)
+}
+
+func (e *evaluator) expand(s string) string {
+	if !strings.Contains(s, "%") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' || i+1 >= len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'h':
+			b.WriteString(e.host)
+		case 'n':
+			b.WriteString(e.t.Host)
+		case 'r':
+			b.WriteString(e.user())
+		case 'u':
+			b.WriteString(e.t.LocalUser)
+		case 'd':
+			b.WriteString(e.t.Home)
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+func (e *evaluator) expandPath(p string) string {
+	if p == "none" {
+		return p
+	}
+	p = e.expand(p)
+	if p == "~" {
+		return e.t.Home
+	}
+	if strings.HasPrefix(p, "~/") {
+		return filepath.Join(e.t.Home, p[2:])
+	}
+	return p
+}
+
+// matchHost aplica os padrões de uma linha Host: basta um casar, mas um
+// padrão negado que case descarta o bloco.
+func matchHost(host string, patterns []string) bool {
+	host = strings.ToLower(host)
+	ok := false
+	for _, p := range patterns {
+		neg := strings.HasPrefix(p, "!")
+		if Wildcard(strings.ToLower(strings.TrimPrefix(p, "!")), host) {
+			if neg {
+				return false
+			}
+			ok = true
+		}
+	}
+	return ok
+}
+
+// matchList aplica uma lista separada por vírgulas dos critérios de Match.
+func matchList(s, list string) bool {
+	s = strings.ToLower(s)
+	ok := false
+	for _, p := range strings.Split(list, ",") {
+		p = strings.ToLower(strings.TrimSpace(p))
+		neg := strings.HasPrefix(p, "!")
+		if Wildcard(strings.TrimPrefix(p, "!"), s) {
+			if neg {
+				return false
+			}
+			ok = true
+		}
+	}
+	return ok
+}
+
+// Wildcard compara s com um padrão do ssh_config: * casa qualquer sequência
+// (inclusive pontos) e ? um caractere.
+func Wildcard(pattern, s string) bool {
+	p, i := 0, 0
+	star, mark := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, i
+			p++
+		case star >= 0:
+			p = star + 1
+			mark++
+			i = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
@@ -0,0 +1,68 @@
+package sshconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxIncludeDepth é o limite de Include aninhados do OpenSSH.
+const maxIncludeDepth = 16
+
+// Load lê path e resolve os Include, recursivamente. Caminhos relativos são
+// relativos a ~/.ssh, como no ssh; globs são expandidos em ordem
+// alfabética e padrões sem arquivo são ignorados. Arquivo inexistente vira
+// uma configuração vazia.
+func Load(path string) (*Config, error) {
+	home, _ := os.UserHomeDir()
+	return load(path, home, 0)
+}
+
+func load(path, home string, depth int) (*Config, error) {
+	c, err := ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c.includes = make(map[*Line][]*Config)
+	for _, b := range c.Blocks {
+		for _, l := range b.Lines {
+			if l.Keyword() != "include" {
+				continue
+			}
+			if depth+1 > maxIncludeDepth {
+				return nil, fmt.Errorf("%s:%d: Include aninhado demais (limite %d)", path, l.Num, maxIncludeDepth)
+			}
+			for _, pattern := range l.Values {
+				matches, err := filepath.Glob(includePath(pattern, home))
+				if err != nil {
+					return nil, fmt.Errorf("%s:%d: padrão de Include inválido %q: %w", path, l.Num, pattern, err)
+				}
+				for _, m := range matches {
+					if fi, err := os.Stat(m); err != nil || fi.IsDir() {
+						continue
+					}
+					inc, err := load(m, home, depth+1)
+					if err != nil {
+						return nil, err
+					}
+					c.includes[l] = append(c.includes[l], inc)
+				}
+			}
+		}
+	}
+	return c, nil
+}
+
+// includePath expande ~ e torna relativo a ~/.ssh.
+func includePath(p, home string) string {
+	switch {
+	case p == "~":
+		return home
+	case strings.HasPrefix(p, "~/"):
+		return filepath.Join(home, p[2:])
+	case filepath.IsAbs(p):
+		return p
+	}
+	return filepath.Join(home, ".ssh", p)
+}
@@ -0,0 +1,67 @@
+package sshconfig
+
+import "strings"
+
+// keywords são as diretivas do cliente OpenSSH na grafia da man page.
+var keywords = []string{
+	"AddKeysToAgent", "AddressFamily", "BatchMode", "BindAddress", "BindInterface",
+	"CanonicalDomains", "CanonicalizeFallbackLocal", "CanonicalizeHostname",
+	"CanonicalizeMaxDots", "CanonicalizePermittedCNAMEs", "CASignatureAlgorithms",
+	"CertificateFile", "ChannelTimeout", "CheckHostIP", "Ciphers", "ClearAllForwardings",
+	"Compression", "ConnectionAttempts", "ConnectTimeout", "ControlMaster", "ControlPath",
+	"ControlPersist", "DynamicForward", "EnableEscapeCommandline", "EnableSSHKeysign",
+	"EscapeChar", "ExitOnForwardFailure", "FingerprintHash", "ForkAfterAuthentication",
+	"ForwardAgent", "ForwardX11", "ForwardX11Timeout", "ForwardX11Trusted",
+	"GatewayPorts", "GlobalKnownHostsFile", "GSSAPIAuthentication",
+	"GSSAPIDelegateCredentials", "HashKnownHosts", "Host", "HostbasedAcceptedAlgorithms",
+	"HostbasedAuthentication", "HostKeyAlgorithms", "HostKeyAlias", "HostName",
+	"IdentitiesOnly", "IdentityAgent", "IdentityFile", "IgnoreUnknown", "Include",
+	"IPQoS", "KbdInteractiveAuthentication", "KbdInteractiveDevices", "KexAlgorithms",
+	"KnownHostsCommand", "LocalCommand", "LocalForward", "LogLevel", "LogVerbose",
+	"MACs", "Match", "NoHostAuthenticationForLocalhost", "NumberOfPasswordPrompts",
+	"ObscureKeystrokeTiming", "PasswordAuthentication", "PermitLocalCommand",
+	"PermitRemoteOpen", "PKCS11Provider", "Port", "PreferredAuthentications",
+	"ProxyCommand", "ProxyJump", "ProxyUseFdpass", "PubkeyAcceptedAlgorithms",
+	"PubkeyAuthentication", "RekeyLimit", "RemoteCommand", "RemoteForward",
+	"RequestTTY", "RequiredRSASize", "RevokedHostKeys", "SecurityKeyProvider",
+	"SendEnv", "ServerAliveCountMax", "ServerAliveInterval", "SessionType", "SetEnv",
+	"StdinNull", "StreamLocalBindMask", "StreamLocalBindUnlink", "StrictHostKeyChecking",
+	"SyslogFacility", "Tag", "TCPKeepAlive", "Tunnel", "TunnelDevice",
+	"UpdateHostKeys", "UseKeychain", "User", "UserKnownHostsFile", "VerifyHostKeyDNS",
+	"VisualHostKey", "XAuthLocation",
+}
+
+var canonical = func() map[string]string {
+	m := make(map[string]string, len(keywords))
+	for _, k := range keywords {
+		m[strings.ToLower(k)] = k
+	}
+	return m
+}()
+
+// multiValue são as diretivas que acumulam valores em vez de ficar com o
+// primeiro encontrado.
+var multiValue = map[string]bool{
+	"identityfile":    true,
+	"certificatefile": true,
+	"localforward":    true,
+	"remoteforward":   true,
+	"dynamicforward":  true,
+	"sendenv":         true,
+	"setenv":          true,
+}
+
+// Canonical devolve a grafia da man page para key; palavras desconhecidas
+// voltam como vieram.
+func Canonical(key string) string {
+	if k, ok := canonical[strings.ToLower(key)]; ok {
+		return k
+	}
+	return key
+}
+
+// Known informa se key é uma diretiva do cliente OpenSSH.
+func Known(key string) bool {
+	_, ok := canonical[strings.ToLower(key)]
+	return ok
+}
@@ -0,0 +1,443 @@
+// Package sshconfig lê e escreve arquivos ssh_config(5) preservando o texto
+// original: cada linha guarda o conteúdo lido e só é reformatada quando
+// alterada, então gravar um arquivo sem mudanças devolve os mesmos bytes.
+// Entende a sintaxe completa do OpenSSH (palavra-chave e valores separados
+// por espaço ou "=", aspas, comentários no fim da linha, Host com vários
+// padrões e negação, Match e Include com globs) e calcula a configuração
+// efetiva de um host como o `ssh -G`.
+//
+// Os blocos gerenciados pelo FactoryDev ficam entre "# BEGIN FDEV <alias>" e
+// "# END FDEV <alias>" e são identificados em Block.Managed.
+package sshconfig
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Marcadores dos blocos gerenciados pelo FactoryDev.
+const (
+	MarkerBegin = "# BEGIN FDEV "
+	MarkerEnd   = "# END FDEV "
+)
+
+// Line é uma linha do arquivo: diretiva, comentário ou linha em branco.
+type Line struct {
+	// Raw é o texto lido; vale enquanto a linha não for alterada.
+	Raw string
+	// Num é a posição no arquivo (a partir de 1); zero em linhas novas.
+	Num    int
+	Indent string
+	// Key é a palavra-chave como escrita (ex: "HostName"); vazia em
+	// comentários e linhas em branco.
+	Key string
+	// Sep separa a palavra-chave dos valores: " ", "=", " = "...
+	Sep    string
+	Values []string
+	// Comment é o comentário do fim da linha (com o #) ou, numa linha só de
+	// comentário, a linha inteira sem a indentação.
+	Comment string
+
+	dirty bool
+}
+
+// Directive cria uma linha nova com a palavra-chave na grafia canônica.
+func Directive(indent, key string, values ...string) *Line {
+	return &Line{Indent: indent, Key: Canonical(key), Sep: " ", Values: values, dirty: true}
+}
+
+// CommentLine cria uma linha de comentário; text deve começar com #.
+func CommentLine(text string) *Line {
+	return &Line{Comment: text, dirty: true}
+}
+
+// IsDirective informa se a linha tem palavra-chave.
+func (l *Line) IsDirective() bool { return l.Key != "" }
+
+// Keyword é a palavra-chave em minúsculas, para comparação.
+func (l *Line) Keyword() string { return strings.ToLower(l.Key) }
+
+// Value devolve o primeiro valor.
+func (l *Line) Value() string {
+	if len(l.Values) == 0 {
+		return ""
+	}
+	return l.Values[0]
+}
+
+// Set troca os valores; a linha passa a ser reformatada ao gravar.
+func (l *Line) Set(values ...string) {
+	l.Values = values
+	l.dirty = true
+}
+
+// String devolve a linha como será gravada.
+func (l *Line) String() string {
+	if !l.dirty {
+		return l.Raw
+	}
+	if l.Key == "" {
+		return l.Indent + l.Comment
+	}
+	var b strings.Builder
+	b.WriteString(l.Indent)
+	b.WriteString(l.Key)
+	if len(l.Values) > 0 {
+		sep := l.Sep
+		if sep == "" {
+			sep = " "
+		}
+		b.WriteString(sep)
+		for i, v := range l.Values {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(Quote(v))
+		}
+	}
+	if l.Comment != "" {
+		b.WriteString(" " + l.Comment)
+	}
+	return b.String()
+}
+
+// Block é um trecho do arquivo: o global (antes do primeiro Host/Match) ou
+// um bloco Host/Match com as linhas até o próximo. Lines inclui o cabeçalho
+// e, em blocos gerenciados, os marcadores BEGIN/END.
+type Block struct {
+	// Header é a linha Host ou Match; nil no bloco global.
+	Header *Line
+	// Managed é o alias do bloco FDEV; vazio nos demais.
+	Managed string
+	Lines   []*Line
+}
+
+// IsHost informa se o bloco começa com Host.
+func (b *Block) IsHost() bool { return b.Header != nil && b.Header.Keyword() == "host" }
+
+// IsMatch informa se o bloco começa com Match.
+func (b *Block) IsMatch() bool { return b.Header != nil && b.Header.Keyword() == "match" }
+
+// Patterns devolve os padrões de um bloco Host.
+func (b *Block) Patterns() []string {
+	if !b.IsHost() {
+		return nil
+	}
+	return b.Header.Values
+}
+
+// Aliases devolve os padrões de Host que são nomes literais (sem curingas
+// nem negação), os que podem ser usados diretamente no ssh/git.
+func (b *Block) Aliases() []string {
+	var out []string
+	for _, p := range b.Patterns() {
+		if !strings.ContainsAny(p, "*?!") {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Directives devolve as diretivas do corpo, sem o cabeçalho.
+func (b *Block) Directives() []*Line {
+	var out []*Line
+	for _, l := range b.Lines {
+		if l.IsDirective() && l != b.Header {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// Get devolve o primeiro valor da diretiva key no corpo do bloco.
+func (b *Block) Get(key string) string {
+	if l := b.find(key); l != nil {
+		return l.Value()
+	}
+	return ""
+}
+
+// GetAll devolve o primeiro valor de cada ocorrência de key (para
+// diretivas repetíveis como IdentityFile).
+func (b *Block) GetAll(key string) []string {
+	key = strings.ToLower(key)
+	var out []string
+	for _, l := range b.Directives() {
+		if l.Keyword() == key {
+			out = append(out, l.Value())
+		}
+	}
+	return out
+}
+
+func (b *Block) find(key string) *Line {
+	key = strings.ToLower(key)
+	for _, l := range b.Directives() {
+		if l.Keyword() == key {
+			return l
+		}
+	}
+	return nil
+}
+
+// Set altera a primeira ocorrência de key ou acrescenta a diretiva depois da
+// última do bloco, com a indentação das demais.
+func (b *Block) Set(key string, values ...string) {
+	if l := b.find(key); l != nil {
+		l.Set(values...)
+		return
+	}
+	indent := ""
+	if b.Header != nil {
+		indent = "  "
+	}
+	at := len(b.Lines)
+	for i := len(b.Lines) - 1; i >= 0; i-- {
+		l := b.Lines[i]
+		if l.IsDirective() {
+			if l != b.Header {
+				indent = l.Indent
+			}
+			at = i + 1
+			break
+		}
+	}
+	line := Directive(indent, key, values...)
+	b.Lines = append(b.Lines[:at], append([]*Line{line}, b.Lines[at:]...)...)
+}
+
+// Remove apaga todas as ocorrências de key do corpo do bloco.
+func (b *Block) Remove(key string) {
+	key = strings.ToLower(key)
+	out := b.Lines[:0]
+	for _, l := range b.Lines {
+		if l != b.Header && l.Keyword() == key {
+			continue
+		}
+		out = append(out, l)
+	}
+	b.Lines = out
+}
+
+// NewHost cria um bloco Host com os padrões dados.
+func NewHost(patterns ...string) *Block {
+	h := Directive("", "Host", patterns...)
+	return &Block{Header: h, Lines: []*Line{h}}
+}
+
+// NewManaged cria um bloco Host gerenciado, já com os marcadores FDEV; as
+// diretivas entram antes do END.
+func NewManaged(alias string, directives ...*Line) *Block {
+	h := Directive("", "Host", alias)
+	lines := []*Line{CommentLine(MarkerBegin + alias), h}
+	lines = append(lines, directives...)
+	lines = append(lines, CommentLine(MarkerEnd+alias))
+	return &Block{Header: h, Managed: alias, Lines: lines}
+}
+
+// Config é um arquivo ssh_config.
+type Config struct {
+	// Path é o arquivo lido (vazio quando veio de Parse).
+	Path string
+	// Blocks[0] é sempre o bloco global, mesmo vazio.
+	Blocks []*Block
+	// includes guarda os arquivos de cada linha Include, resolvidos por Load.
+	includes map[*Line][]*Config
+	// noFinalNewline registra arquivo sem \n no fim, para o round-trip.
+	noFinalNewline bool
+}
+
+// Global é o bloco antes do primeiro Host/Match.
+func (c *Config) Global() *Block { return c.Blocks[0] }
+
+// Host devolve o primeiro bloco Host que tem alias como padrão literal.
+func (c *Config) Host(alias string) *Block {
+	for _, b := range c.Blocks {
+		for _, p := range b.Patterns() {
+			if p == alias {
+				return b
+			}
+		}
+	}
+	return nil
+}
+
+// Managed devolve o bloco FDEV do alias.
+func (c *Config) Managed(alias string) *Block {
+	for _, b := range c.Blocks {
+		if b.Managed == alias {
+			return b
+		}
+	}
+	return nil
+}
+
+// Append acrescenta b no fim do arquivo, separado por uma linha em branco.
+func (c *Config) Append(b *Block) {
+	if last := c.lastLine(); last != nil && strings.TrimSpace(last.String()) != "" {
+		b.Lines = append([]*Line{{dirty: true}}, b.Lines...)
+	}
+	c.Blocks = append(c.Blocks, b)
+}
+
+// Replace troca old por b na mesma posição. Em blocos gerenciados, as
+// linhas depois do END (em geral a linha em branco antes do próximo bloco)
+// são mantidas.
+func (c *Config) Replace(old, b *Block) bool {
+	for i, cur := range c.Blocks {
+		if cur != old {
+			continue
+		}
+		if end := old.endMarker(); end >= 0 {
+			b.Lines = append(b.Lines, old.Lines[end+1:]...)
+		}
+		c.Blocks[i] = b
+		return true
+	}
+	return false
+}
+
+// Remove tira o bloco b do arquivo. O bloco global não pode ser removido.
+func (c *Config) Remove(b *Block) bool {
+	for i := 1; i < len(c.Blocks); i++ {
+		if c.Blocks[i] == b {
+			c.Blocks = append(c.Blocks[:i], c.Blocks[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Included devolve os arquivos carregados para a linha Include l.
+func (c *Config) Included(l *Line) []*Config { return c.includes[l] }
+
+// Walk visita c e todos os arquivos incluídos, em profundidade e na ordem em
+// que aparecem.
+func (c *Config) Walk(fn func(*Config)) {
+	fn(c)
+	for _, b := range c.Blocks {
+		for _, l := range b.Lines {
+			for _, inc := range c.includes[l] {
+				inc.Walk(fn)
+			}
+		}
+	}
+}
+
+func (c *Config) lastLine() *Line {
+	for i := len(c.Blocks) - 1; i >= 0; i-- {
+		if n := len(c.Blocks[i].Lines); n > 0 {
+			return c.Blocks[i].Lines[n-1]
+		}
+	}
+	return nil
+}
+
+func (b *Block) endMarker() int {
+	if b.Managed == "" {
+		return -1
+	}
+	for i, l := range b.Lines {
+		if !l.IsDirective() && strings.TrimSpace(l.Comment) == strings.TrimSpace(MarkerEnd+b.Managed) {
+			return i
+		}
+	}
+	return -1
+}
+
+// String devolve o arquivo como será gravado.
+func (c *Config) String() string {
+	var b strings.Builder
+	n := 0
+	for _, blk := range c.Blocks {
+		for _, l := range blk.Lines {
+			if n > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(l.String())
+			n++
+		}
+	}
+	if n > 0 && !c.noFinalNewline {
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// Parse lê um ssh_config. Include não é resolvido (ver Load).
+func Parse(r io.Reader) (*Config, error) {
+	return parse(r, "")
+}
+
+// ParseFile lê path sem resolver os Include; arquivo inexistente vira uma
+// configuração vazia.
+func ParseFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Config{Path: path, Blocks: []*Block{{}}}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parse(bytes.NewReader(data), path)
+}
+
+func parse(r io.Reader, path string) (*Config, error) {
+	c := &Config{Path: path}
+	cur := &Block{}
+	c.Blocks = append(c.Blocks, cur)
+
+	br := bufio.NewReader(r)
+	num := 0
+	for {
+		raw, err := br.ReadString('\n')
+		if raw == "" && err != nil {
+			if err != io.EOF {
+				return nil, err
+			}
+			break
+		}
+		num++
+		if strings.HasSuffix(raw, "\n") {
+			raw = strings.TrimSuffix(raw, "\n")
+		} else {
+			c.noFinalNewline = true
+		}
+		l, perr := parseLine(raw, num)
+		if perr != nil {
+			return nil, fmt.Errorf("%s:%d: %w", displayPath(path), num, perr)
+		}
+
+		switch {
+		case !l.IsDirective() && strings.HasPrefix(l.Comment, MarkerBegin):
+			cur = &Block{Managed: strings.TrimSpace(strings.TrimPrefix(l.Comment, MarkerBegin))}
+			c.Blocks = append(c.Blocks, cur)
+		case l.Keyword() == "host" || l.Keyword() == "match":
+			// O Host logo depois do BEGIN é o cabeçalho do bloco gerenciado.
+			if cur.Managed == "" || cur.Header != nil {
+				cur = &Block{}
+				c.Blocks = append(c.Blocks, cur)
+			}
+			cur.Header = l
+			if cur.Managed != "" && (l.Keyword() != "host" || l.Value() != cur.Managed) {
+				cur.Managed = ""
+			}
+		}
+		cur.Lines = append(cur.Lines, l)
+		if err != nil {
+			break
+		}
+	}
+	return c, nil
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "ssh_config"
+	}
+	return path
+}
@@ -0,0 +1,219 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sample = `# config pessoal
+Include config.d/*
+ServerAliveInterval=30
+
+Host github.com gh
+    HostName github.com   # comentário no fim
+    User git
+    IdentityFile ~/.ssh/id_pessoal
+
+Host *.corp !bastion.corp
+  ProxyJump = bastion.corp
+  User "joão silva"
+  IdentityFile ~/.ssh/id_corp
+  IdentityFile %d/.ssh/id_%r
+
+Match originalhost db.corp user "joão silva"
+  Port 2222
+
+# BEGIN FDEV github-work
+Host github-work
+  HostName github.com
+  IdentityFile /keys/work
+  IdentitiesOnly yes
+# END FDEV github-work
+
+Host *
+  User fallback
+  Port 22
+`
+
+func TestParseRoundTripAndEdit(t *testing.T) {
+	c, err := Parse(strings.NewReader(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.String(); got != sample {
+		t.Fatalf("round-trip alterou o arquivo:\n%s", got)
+	}
+	if len(c.Blocks) != 6 {
+		t.Fatalf("blocos = %d", len(c.Blocks))
+	}
+
+	gh := c.Host("gh")
+	if gh == nil || gh.Get("hostname") != "github.com" || !reflect.DeepEqual(gh.Patterns(), []string{"github.com", "gh"}) {
+		t.Fatalf("bloco gh = %+v", gh)
+	}
+	if l := gh.Lines[1]; l.Comment != "# comentário no fim" || l.Key != "HostName" {
+		t.Fatalf("linha com comentário = %+v", l)
+	}
+	corp := c.Blocks[2]
+	if corp.Get("proxyjump") != "bastion.corp" || corp.Get("user") != "joão silva" || len(corp.Aliases()) != 0 {
+		t.Fatalf("bloco corp = %+v", corp)
+	}
+	if w := c.Managed("github-work"); w == nil || w.Get("IdentityFile") != "/keys/work" {
+		t.Fatalf("bloco gerenciado não encontrado")
+	}
+
+	gh.Set("Port", "443")
+	gh.Set("user", "outro")
+	corp.Remove("IdentityFile")
+	out := c.String()
+	for _, want := range []string{"    User outro\n    IdentityFile ~/.ssh/id_pessoal\n    Port 443\n", "Host *.corp !bastion.corp\n  ProxyJump = bastion.corp\n  User \"joão silva\"\n\nMatch"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("edição sem %q:\n%s", want, out)
+		}
+	}
+
+	repl := NewManaged("github-work", Directive("  ", "hostname", "gitlab.com"))
+	c.Replace(c.Managed("github-work"), repl)
+	c.Append(NewManaged("novo", Directive("  ", "HostName", "example.com")))
+	out = c.String()
+	if !strings.Contains(out, "# BEGIN FDEV github-work\nHost github-work\n  HostName gitlab.com\n# END FDEV github-work\n\nHost *") {
+		t.Fatalf("substituição do bloco gerenciado:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "  Port 22\n\n# BEGIN FDEV novo\nHost novo\n  HostName example.com\n# END FDEV novo\n") {
+		t.Fatalf("bloco acrescentado:\n%s", out)
+	}
+
+	if _, err := Parse(strings.NewReader("Host a\n  User \"sem fim\n")); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Fatalf("aspas abertas: %v", err)
+	}
+}
+
+func TestLoadIncludeAndEvaluate(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(filepath.Join(sshDir, "config.d"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(sshDir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("config", sample)
+	write("config.d/10-work", "Host db.corp\n  HostName 10.0.0.5\n  IdentityFile ~/.ssh/id_db\n")
+	write("config.d/20-all", "ServerAliveInterval 60\nCompression yes\n")
+
+	c, err := Load(filepath.Join(sshDir, "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := 0
+	c.Walk(func(*Config) { files++ })
+	if files != 3 {
+		t.Fatalf("arquivos carregados = %d", files)
+	}
+
+	o := c.EvaluateFor(Target{Host: "db.corp", LocalUser: "dev", Home: home})
+	checks := map[string]string{
+		"hostname":            "10.0.0.5",
+		"user":                "joão silva",
+		"port":                "2222",
+		"proxyjump":           "bastion.corp",
+		"serveraliveinterval": "60", // o Include vem antes do valor do arquivo principal
+		"compression":         "yes",
+	}
+	for k, want := range checks {
+		if got := o.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+	wantIDs := []string{
+		filepath.Join(home, ".ssh/id_db"),
+		filepath.Join(home, ".ssh/id_corp"),
+		home + "/.ssh/id_joão silva",
+	}
+	if got := o.All("identityfile"); !reflect.DeepEqual(got, wantIDs) {
+		t.Fatalf("identityfile = %v", got)
+	}
+	if src := o.Source("hostname"); !strings.HasSuffix(src, "config.d/10-work:2") {
+		t.Fatalf("origem do hostname = %q", src)
+	}
+	if !strings.HasPrefix(o.String(), "hostname 10.0.0.5\nuser \"joão silva\"\nport 2222\n") {
+		t.Fatalf("saída estilo ssh -G:\n%s", o)
+	}
+
+	// Padrão negado e Host * como fallback.
+	b := c.EvaluateFor(Target{Host: "bastion.corp", LocalUser: "dev", Home: home})
+	if b.Get("user") != "fallback" || b.Get("proxyjump") != "" || b.Get("hostname") != "bastion.corp" {
+		t.Fatalf("bastion = %s", b)
+	}
+	if gh := c.EvaluateFor(Target{Host: "gh", LocalUser: "dev", Home: home}); gh.Get("hostname") != "github.com" || gh.Get("user") != "git" {
+		t.Fatalf("gh = %s", gh)
+	}
+}
+
+// TestEvaluateExpandsHostName segue o `ssh -G`: no HostName, %h é o nome
+// passado ao ssh; nas demais opções, o HostName já resolvido. %n é sempre o
+// nome original.
+func TestEvaluateExpandsHostName(t *testing.T) {
+	c, err := Parse(strings.NewReader(strings.Join([]string{
+		"Host web",
+		"  HostName %h.example.com",
+		"  IdentityFile ~/.ssh/id_%h_%n",
+		"  UserKnownHostsFile ~/.ssh/kh_%h",
+		"",
+		"Host plain",
+		"  UserKnownHostsFile ~/.ssh/kh_%h",
+		"",
+	}, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Valores de `ssh -G -F config web` e `ssh -G -F config plain`.
+	cases := []struct {
+		host string
+		want map[string]string
+	}{
+		{"web", map[string]string{
+			"hostname":           "web.example.com",
+			"identityfile":       "/home/dev/.ssh/id_web.example.com_web",
+			"userknownhostsfile": "/home/dev/.ssh/kh_web.example.com",
+		}},
+		{"plain", map[string]string{
+			"hostname":           "plain",
+			"userknownhostsfile": "/home/dev/.ssh/kh_plain",
+		}},
+	}
+	for _, tc := range cases {
+		o := c.EvaluateFor(Target{Host: tc.host, LocalUser: "dev", Home: "/home/dev"})
+		for k, want := range tc.want {
+			if got := o.Get(k); got != want {
+				t.Errorf("%s: %s = %q, want %q", tc.host, k, got, want)
+			}
+		}
+	}
+}
+
+func TestWildcard(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*", "a.b.c", true},
+		{"*.corp", "db.corp", true},
+		{"*.corp", "corp", false},
+		{"db?", "db1", true},
+		{"db?", "db12", false},
+		{"a*b*c", "axxbyyc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+	for _, c := range cases {
+		if got := Wildcard(c.pattern, c.s); got != c.want {
+			t.Errorf("Wildcard(%q, %q) = %v", c.pattern, c.s, got)
+		}
+	}
+}
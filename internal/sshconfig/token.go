@@ -0,0 +1,101 @@
+package sshconfig
+
+import (
+	"errors"
+	"strings"
+)
+
+var errUnterminatedQuote = errors.New("aspas sem fechamento")
+
+// parseLine separa indentação, palavra-chave, separador, valores e
+// comentário, como o readconf.c do OpenSSH: a palavra-chave termina em
+// espaço ou "=", os valores aceitam aspas simples ou duplas e escapes com
+// barra invertida, e um # no começo de um valor inicia um comentário.
+func parseLine(raw string, num int) (*Line, error) {
+	l := &Line{Raw: raw, Num: num}
+	text := strings.TrimRight(raw, " \t\r")
+	trimmed := strings.TrimLeft(text, " \t")
+	l.Indent = text[:len(text)-len(trimmed)]
+	if trimmed == "" {
+		return l, nil
+	}
+	if trimmed[0] == '#' {
+		l.Comment = trimmed
+		return l, nil
+	}
+
+	end := strings.IndexAny(trimmed, " \t=")
+	if end < 0 {
+		l.Key = trimmed
+		return l, nil
+	}
+	l.Key = trimmed[:end]
+	i := skipSpace(trimmed, end)
+	if i < len(trimmed) && trimmed[i] == '=' {
+		i = skipSpace(trimmed, i+1)
+	}
+	l.Sep = trimmed[end:i]
+
+	values, comment, err := splitArgs(trimmed[i:])
+	if err != nil {
+		return nil, err
+	}
+	l.Values, l.Comment = values, comment
+	return l, nil
+}
+
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
+
+// splitArgs divide os valores; devolve também o comentário do fim da linha.
+func splitArgs(s string) (args []string, comment string, err error) {
+	i := 0
+	for {
+		i = skipSpace(s, i)
+		if i >= len(s) {
+			return args, "", nil
+		}
+		if s[i] == '#' {
+			return args, s[i:], nil
+		}
+		var tok strings.Builder
+		var quote byte
+		for ; i < len(s); i++ {
+			ch := s[i]
+			switch {
+			case ch == '\\' && i+1 < len(s) && (strings.IndexByte(`\"'`, s[i+1]) >= 0 || quote == 0 && s[i+1] == ' '):
+				i++
+				tok.WriteByte(s[i])
+			case quote != 0 && ch == quote:
+				quote = 0
+			case quote != 0:
+				tok.WriteByte(ch)
+			case ch == '"' || ch == '\'':
+				quote = ch
+			case ch == ' ' || ch == '\t':
+				goto done
+			default:
+				tok.WriteByte(ch)
+			}
+		}
+	done:
+		if quote != 0 {
+			return nil, "", errUnterminatedQuote
+		}
+		args = append(args, tok.String())
+	}
+}
+
+// Quote protege um valor com aspas duplas quando ele tem espaço, aspas, #
+// no início ou está vazio.
+func Quote(v string) string {
+	if v != "" && !strings.ContainsAny(v, " \t\"'\\") && v[0] != '#' {
+		return v
+	}
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + r.Replace(v) + `"`
+}
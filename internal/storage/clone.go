@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"maps"
+	"slices"
+)
+
+// Clone devolve uma cópia profunda do State. O cache do JSONStorage entrega
+// clones para que handlers possam mutar o resultado de LoadState livremente.
+func (s *State) Clone() *State {
+	c := *s
+	c.Keys = cloneSlice(s.Keys)
+	c.Accounts = cloneSlice(s.Accounts)
+	c.Repositories = cloneSlice(s.Repositories)
+	c.Identities = cloneSlice(s.Identities)
+	c.Aliases = cloneSlice(s.Aliases)
+	c.APIHistory = cloneSlice(s.APIHistory)
+	c.DBConnections = cloneSlice(s.DBConnections)
+	c.Schedules = cloneSlice(s.Schedules)
+	c.ScheduleRuns = cloneSlice(s.ScheduleRuns)
+
+	c.Servers = cloneSlice(s.Servers)
+	for i := range c.Servers {
+		c.Servers[i].Tags = slices.Clone(c.Servers[i].Tags)
+	}
+	c.EnvFiles = cloneSlice(s.EnvFiles)
+	for i := range c.EnvFiles {
+		c.EnvFiles[i].Variables = maps.Clone(c.EnvFiles[i].Variables)
+	}
+	c.APICollections = cloneSlice(s.APICollections)
+	for i := range c.APICollections {
+		c.APICollections[i].AuthData = maps.Clone(c.APICollections[i].AuthData)
+		c.APICollections[i].EnvVars = maps.Clone(c.APICollections[i].EnvVars)
+	}
+	c.APIEndpoints = cloneSlice(s.APIEndpoints)
+	for i := range c.APIEndpoints {
+		c.APIEndpoints[i].Headers = maps.Clone(c.APIEndpoints[i].Headers)
+	}
+	c.MCPServers = cloneSlice(s.MCPServers)
+	for i := range c.MCPServers {
+		c.MCPServers[i].Args = slices.Clone(c.MCPServers[i].Args)
+		c.MCPServers[i].Env = maps.Clone(c.MCPServers[i].Env)
+	}
+	c.CustomSkills = cloneSlice(s.CustomSkills)
+	for i := range c.CustomSkills {
+		c.CustomSkills[i].Tags = slices.Clone(c.CustomSkills[i].Tags)
+	}
+	c.Certificates = cloneSlice(s.Certificates)
+	for i := range c.Certificates {
+		c.Certificates[i].Principals = slices.Clone(c.Certificates[i].Principals)
+		c.Certificates[i].CriticalOptions = maps.Clone(c.Certificates[i].CriticalOptions)
+		c.Certificates[i].Extensions = maps.Clone(c.Certificates[i].Extensions)
+	}
+	c.KeyRotations = cloneSlice(s.KeyRotations)
+	for i := range c.KeyRotations {
+		c.KeyRotations[i].Steps = slices.Clone(c.KeyRotations[i].Steps)
+	}
+	return &c
+}
+
+// cloneSlice copia s preservando a distinção entre nil e slice vazio.
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
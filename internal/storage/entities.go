@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EntitySet dá acesso genérico, por ID, a um dos slices do State. Os
+// registros circulam como JSON, o que permite ao audit log comparar e
+// restaurar snapshots sem conhecer cada tipo.
+type EntitySet interface {
+	// Name é o nome da entidade (o mesmo da tabela no SQLite).
+	Name() string
+	// Snapshot retorna os registros serializados, indexados por ID, e os
+	// IDs na ordem do slice.
+	Snapshot() (map[string]json.RawMessage, []string, error)
+	// Put insere o registro ou substitui o que tem o mesmo ID.
+	Put(data json.RawMessage) error
+	// Remove apaga o registro com o ID dado; false se não existia.
+	Remove(id string) bool
+}
+
+// EntitySets lista os conjuntos de entidades de s.
+func EntitySets(s *State) []EntitySet {
+	tables := stateTables(s)
+	out := make([]EntitySet, len(tables))
+	for i, t := range tables {
+		out[i] = t
+	}
+	return out
+}
+
+// EntitySetByName retorna o conjunto com o nome dado, ou nil.
+func EntitySetByName(s *State, name string) EntitySet {
+	for _, t := range stateTables(s) {
+		if t.Name() == name {
+			return t
+		}
+	}
+	return nil
+}
+
+func (t entityTable[T]) Name() string { return t.table }
+
+func (t entityTable[T]) Snapshot() (map[string]json.RawMessage, []string, error) {
+	byID := make(map[string]json.RawMessage, len(*t.items))
+	order := make([]string, 0, len(*t.items))
+	for _, item := range *t.items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return nil, nil, fmt.Errorf("serializar %s: %w", t.table, err)
+		}
+		id := t.id(item)
+		byID[id] = data
+		order = append(order, id)
+	}
+	return byID, order, nil
+}
+
+func (t entityTable[T]) Put(data json.RawMessage) error {
+	var item T
+	if err := json.Unmarshal(data, &item); err != nil {
+		return fmt.Errorf("parse %s: %w", t.table, err)
+	}
+	id := t.id(item)
+	for i := range *t.items {
+		if t.id((*t.items)[i]) == id {
+			(*t.items)[i] = item
+			return nil
+		}
+	}
+	*t.items = append(*t.items, item)
+	return nil
+}
+
+func (t entityTable[T]) Remove(id string) bool {
+	for i := range *t.items {
+		if t.id((*t.items)[i]) == id {
+			*t.items = append((*t.items)[:i], (*t.items)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package storage
+
+// lockFile no Windows não usa lock entre processos: a serialização fica a
+// cargo do mutex do JSONStorage.
+func lockFile(path string) (unlock func(), err error) {
+	return func() {}, nil
+}
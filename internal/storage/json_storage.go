@@ -6,11 +6,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 )
 
+// JSONStorage persiste o State em um único arquivo JSON. Escritas são
+// serializadas por um mutex no processo e por flock em <path>.lock entre
+// processos; leituras usam um cache invalidado pelo mtime/tamanho do arquivo.
 type JSONStorage struct {
 	path string
+	mu   sync.Mutex // serializa writers (Update/SaveState)
+
+	cacheMu   sync.Mutex
+	cache     *State
+	cacheMod  time.Time
+	cacheSize int64
 }
 
 func NewJSONStorage(path string) *JSONStorage {
@@ -18,7 +28,7 @@ func NewJSONStorage(path string) *JSONStorage {
 }
 
 func (s *JSONStorage) LoadState() (*State, error) {
-	data, err := os.ReadFile(s.path)
+	info, err := os.Stat(s.path)
 	if errors.Is(err, os.ErrNotExist) {
 		return &State{SchemaVersion: CurrentSchema, Keys: make([]Key, 0), Accounts: make([]Account, 0), Repositories: make([]Repository, 0), Servers: make([]Server, 0), Identities: make([]GitIdentity, 0)}, nil
 	}
@@ -26,6 +36,57 @@ func (s *JSONStorage) LoadState() (*State, error) {
 		return nil, fmt.Errorf("ler state: %w", err)
 	}
 
+	s.cacheMu.Lock()
+	if s.cache != nil && info.ModTime().Equal(s.cacheMod) && info.Size() == s.cacheSize {
+		st := s.cache.Clone()
+		s.cacheMu.Unlock()
+		return st, nil
+	}
+	s.cacheMu.Unlock()
+
+	state, err := s.readFile()
+	if err != nil {
+		return nil, err
+	}
+	s.setCache(state, info)
+	return state, nil
+}
+
+// Update carrega o state, aplica fn e grava o resultado, tudo sob o lock de
+// escrita. Se fn retornar erro nada é gravado e o erro é devolvido.
+func (s *JSONStorage) Update(fn func(*State) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	state, err := s.LoadState()
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	return s.write(state)
+}
+
+func (s *JSONStorage) setCache(state *State, info os.FileInfo) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.cache = state.Clone()
+	s.cacheMod = info.ModTime()
+	s.cacheSize = info.Size()
+}
+
+func (s *JSONStorage) readFile() (*State, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("ler state: %w", err)
+	}
+
 	var state State
 	if err := json.Unmarshal(data, &state); err != nil {
 		return nil, fmt.Errorf("parse state: %w", err)
@@ -55,7 +116,21 @@ func (s *JSONStorage) LoadState() (*State, error) {
 	return &state, nil
 }
 
+// SaveState grava o state inteiro. Prefira Update, que evita sobrescrever
+// alterações concorrentes feitas entre o LoadState e o SaveState.
 func (s *JSONStorage) SaveState(state *State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := lockFile(s.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return s.write(state)
+}
+
+// write grava o state de forma atômica. Exige s.mu e o flock.
+func (s *JSONStorage) write(state *State) error {
 	state.UpdatedAt = time.Now()
 	if state.SchemaVersion == 0 {
 		state.SchemaVersion = CurrentSchema
@@ -97,5 +172,8 @@ func (s *JSONStorage) SaveState(state *State) error {
 	if err = os.Chmod(s.path, 0o600); err != nil {
 		return fmt.Errorf("chmod state final: %w", err)
 	}
+	if info, err := os.Stat(s.path); err == nil {
+		s.setCache(state, info)
+	}
 	return nil
 }
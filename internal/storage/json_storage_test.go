@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestJSONStorageConcurrentUpdate(t *testing.T) {
+	st := NewJSONStorage(filepath.Join(t.TempDir(), "state.json"))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := st.Update(func(s *State) error {
+				s.Aliases = append(s.Aliases, ShellAlias{ID: fmt.Sprint(i), Name: fmt.Sprint("a", i)})
+				return nil
+			})
+			if err != nil {
+				t.Errorf("update: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	out, err := st.LoadState()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(out.Aliases) != 20 {
+		t.Fatalf("want 20 aliases got %d", len(out.Aliases))
+	}
+}
+
+func TestJSONStorageUpdateAbortsOnError(t *testing.T) {
+	st := NewJSONStorage(filepath.Join(t.TempDir(), "state.json"))
+	if err := st.SaveState(&State{Servers: []Server{{ID: "s1"}}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	boom := fmt.Errorf("boom")
+	err := st.Update(func(s *State) error {
+		s.Servers = nil
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("want boom got %v", err)
+	}
+	out, _ := st.LoadState()
+	if len(out.Servers) != 1 {
+		t.Fatalf("state não deveria ter sido gravado: %+v", out.Servers)
+	}
+}
+
+func TestJSONStorageCacheInvalidation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	st := NewJSONStorage(path)
+	if err := st.SaveState(&State{Keys: []Key{{ID: "k1"}}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	// Mutar o retorno não pode afetar o cache.
+	first, _ := st.LoadState()
+	first.Keys[0].ID = "mutado"
+	second, _ := st.LoadState()
+	if second.Keys[0].ID != "k1" {
+		t.Fatalf("cache compartilhado com o chamador: %+v", second.Keys)
+	}
+
+	// Alteração externa (outra instância) precisa ser vista.
+	ext := `{"schemaVersion":` + fmt.Sprint(CurrentSchema) + `,"keys":[{"id":"k1"},{"id":"k2"}]}`
+	if err := os.WriteFile(path, []byte(ext), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	third, err := st.LoadState()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if len(third.Keys) != 2 {
+		t.Fatalf("alteração externa ignorada: %+v", third.Keys)
+	}
+}
@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var nonAlnum = regexp.MustCompile(`[^a-z0-9]+`)
+
+// SanitizeAlias converte um nome livre em alias (minúsculas e hífens),
+// usado no diretório ~/.fdev/keys/<alias>/.
+func SanitizeAlias(name string) string {
+	s := strings.ToLower(strings.TrimSpace(name))
+	s = nonAlnum.ReplaceAllString(s, "-")
+	s = strings.Trim(s, "-")
+	if s == "" {
+		s = "key"
+	}
+	return s
+}
+
+// FindKey retorna a chave com o ID dado, ou nil.
+func (s *State) FindKey(id string) *Key {
+	for i := range s.Keys {
+		if s.Keys[i].ID == id {
+			return &s.Keys[i]
+		}
+	}
+	return nil
+}
+
+// UniqueKeyAlias devolve base, ou base-2, base-3... se o alias já existir.
+func (s *State) UniqueKeyAlias(base string) string {
+	alias := base
+	for i := 2; ; i++ {
+		conflict := false
+		for _, k := range s.Keys {
+			if k.Alias == alias {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			return alias
+		}
+		alias = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// KeyReferences são os registros que apontam para uma chave.
+type KeyReferences struct {
+	Accounts   []Account
+	Servers    []Server
+	Identities []GitIdentity
+}
+
+// Empty informa se nada referencia a chave.
+func (r KeyReferences) Empty() bool {
+	return len(r.Accounts) == 0 && len(r.Servers) == 0 && len(r.Identities) == 0
+}
+
+// KeyReferences lista as contas, servidores e identidades que usam keyID.
+func (s *State) KeyReferences(keyID string) KeyReferences {
+	var refs KeyReferences
+	if keyID == "" {
+		return refs
+	}
+	for _, a := range s.Accounts {
+		if a.KeyID == keyID {
+			refs.Accounts = append(refs.Accounts, a)
+		}
+	}
+	for _, srv := range s.Servers {
+		if srv.KeyID == keyID {
+			refs.Servers = append(refs.Servers, srv)
+		}
+	}
+	for _, id := range s.Identities {
+		if id.KeyID == keyID {
+			refs.Identities = append(refs.Identities, id)
+		}
+	}
+	return refs
+}
+
+// AccountKeyPath retorna o caminho da chave privada de uma conta,
+// respeitando a precedência: KeyID (Key Manager) > IdentityFile (legado).
+func (s *State) AccountKeyPath(a Account, home string) string {
+	if a.KeyID != "" {
+		if k := s.FindKey(a.KeyID); k != nil {
+			return k.PrivateKeyPath
+		}
+	}
+	if strings.HasPrefix(a.IdentityFile, "~/") {
+		return filepath.Join(home, a.IdentityFile[2:])
+	}
+	return a.IdentityFile
+}
+
+// ResolveKeyFiles devolve a conta com IdentityFile apontando para a chave do
+// Key Manager e CertificateFile para o certificado de usuário válido dessa
+// chave, prontos para gerar o bloco FDEV do ssh config.
+func (s *State) ResolveKeyFiles(a Account) Account {
+	if k := s.FindKey(a.KeyID); k != nil {
+		a.IdentityFile = k.PrivateKeyPath
+	}
+	if c := s.ActiveCertificate(a.KeyID, "user", time.Now()); c != nil {
+		a.CertificateFile = c.Path
+	}
+	return a
+}
+
+// ActiveCertificate devolve o certificado do tipo dado, emitido para a chave
+// keyID e válido em now, que expira por último; nil se não houver.
+func (s *State) ActiveCertificate(keyID, certType string, now time.Time) *Certificate {
+	if keyID == "" {
+		return nil
+	}
+	var best *Certificate
+	for i := range s.Certificates {
+		c := &s.Certificates[i]
+		if c.KeyID != keyID || c.Type != certType || !c.ValidAt(now) {
+			continue
+		}
+		if best == nil || c.expiresAfter(*best) {
+			best = c
+		}
+	}
+	return best
+}
+
+// NextCertificateSerial devolve o próximo número de série livre.
+func (s *State) NextCertificateSerial() uint64 {
+	var max uint64
+	for _, c := range s.Certificates {
+		if c.Serial > max {
+			max = c.Serial
+		}
+	}
+	return max + 1
+}
+
+// expiresAfter informa se c expira depois de o; sem expiração vem por último.
+func (c Certificate) expiresAfter(o Certificate) bool {
+	if o.ValidBefore.IsZero() {
+		return false
+	}
+	return c.ValidBefore.IsZero() || c.ValidBefore.After(o.ValidBefore)
+}
+
+// ValidAt informa se o certificado vale em t.
+func (c Certificate) ValidAt(t time.Time) bool {
+	return !t.Before(c.ValidAfter) && (c.ValidBefore.IsZero() || t.Before(c.ValidBefore))
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteStorage persiste o State em um banco SQLite, com uma tabela por
+// entidade. Cada linha guarda o ID, a posição na lista e o registro em JSON,
+// o que mantém a compatibilidade com os campos novos sem exigir migração de
+// colunas a cada mudança nos structs.
+type SQLiteStorage struct {
+	path string
+	db   *sql.DB
+}
+
+// sqliteTable liga uma tabela do banco a um slice do State.
+type sqliteTable interface {
+	EntitySet
+	load(q *sql.Tx) error
+	save(tx *sql.Tx) error
+}
+
+type entityTable[T any] struct {
+	table string
+	items *[]T
+	id    func(T) string
+}
+
+func (t entityTable[T]) load(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT data FROM ` + t.table + ` ORDER BY pos`)
+	if err != nil {
+		return fmt.Errorf("ler %s: %w", t.table, err)
+	}
+	defer rows.Close()
+
+	out := make([]T, 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return fmt.Errorf("ler %s: %w", t.table, err)
+		}
+		var item T
+		if err := json.Unmarshal(data, &item); err != nil {
+			return fmt.Errorf("parse %s: %w", t.table, err)
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("ler %s: %w", t.table, err)
+	}
+	*t.items = out
+	return nil
+}
+
+func (t entityTable[T]) save(tx *sql.Tx) error {
+	if _, err := tx.Exec(`DELETE FROM ` + t.table); err != nil {
+		return fmt.Errorf("limpar %s: %w", t.table, err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO ` + t.table + ` (pos, id, data) VALUES (?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("preparar %s: %w", t.table, err)
+	}
+	defer stmt.Close()
+
+	for i, item := range *t.items {
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("serializar %s: %w", t.table, err)
+		}
+		if _, err := stmt.Exec(i, t.id(item), data); err != nil {
+			return fmt.Errorf("gravar %s: %w", t.table, err)
+		}
+	}
+	return nil
+}
+
+// stateTables lista as tabelas do banco e os slices correspondentes do State.
+func stateTables(s *State) []sqliteTable {
+	return []sqliteTable{
+		entityTable[Key]{"keys", &s.Keys, func(v Key) string { return v.ID }},
+		entityTable[Account]{"accounts", &s.Accounts, func(v Account) string { return v.ID }},
+		entityTable[Repository]{"repositories", &s.Repositories, func(v Repository) string { return v.ID }},
+		entityTable[Server]{"servers", &s.Servers, func(v Server) string { return v.ID }},
+		entityTable[GitIdentity]{"identities", &s.Identities, func(v GitIdentity) string { return v.ID }},
+		entityTable[EnvFile]{"env_files", &s.EnvFiles, func(v EnvFile) string { return v.ID }},
+		entityTable[ShellAlias]{"aliases", &s.Aliases, func(v ShellAlias) string { return v.ID }},
+		entityTable[APICollection]{"api_collections", &s.APICollections, func(v APICollection) string { return v.ID }},
+		entityTable[APIEndpoint]{"api_endpoints", &s.APIEndpoints, func(v APIEndpoint) string { return v.ID }},
+		entityTable[APIRequestHistory]{"api_history", &s.APIHistory, func(v APIRequestHistory) string { return v.ID }},
+		entityTable[DBConnection]{"db_connections", &s.DBConnections, func(v DBConnection) string { return v.ID }},
+		entityTable[MCPServer]{"mcp_servers", &s.MCPServers, func(v MCPServer) string { return v.ID }},
+		entityTable[CustomSkill]{"custom_skills", &s.CustomSkills, func(v CustomSkill) string { return v.ID }},
+		entityTable[Schedule]{"schedules", &s.Schedules, func(v Schedule) string { return v.ID }},
+		entityTable[ScheduleRun]{"schedule_runs", &s.ScheduleRuns, func(v ScheduleRun) string { return v.ID }},
+		entityTable[Certificate]{"certificates", &s.Certificates, func(v Certificate) string { return v.ID }},
+		entityTable[KeyRotation]{"key_rotations", &s.KeyRotations, func(v KeyRotation) string { return v.ID }},
+	}
+}
+
+// NewSQLiteStorage abre (ou cria) o banco em path e garante o schema.
+func NewSQLiteStorage(path string) (*SQLiteStorage, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, fmt.Errorf("criar pasta state: %w", err)
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("abrir sqlite: %w", err)
+	}
+	// Um único writer evita SQLITE_BUSY entre goroutines do mesmo processo.
+	db.SetMaxOpenConns(1)
+
+	s := &SQLiteStorage{path: path, db: db}
+	if err := s.createSchema(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := os.Chmod(path, 0o600); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("chmod sqlite: %w", err)
+	}
+	return s, nil
+}
+
+func (s *SQLiteStorage) createSchema() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS meta (key TEXT PRIMARY KEY, value TEXT NOT NULL)`,
+	}
+	for _, t := range stateTables(&State{}) {
+		stmts = append(stmts,
+			`CREATE TABLE IF NOT EXISTS `+t.Name()+` (pos INTEGER PRIMARY KEY, id TEXT NOT NULL, data TEXT NOT NULL)`,
+			`CREATE INDEX IF NOT EXISTS `+t.Name()+`_id ON `+t.Name()+` (id)`,
+		)
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("criar schema sqlite: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close fecha o banco.
+func (s *SQLiteStorage) Close() error {
+	return s.db.Close()
+}
+
+// Empty indica se o banco ainda não recebeu nenhum SaveState.
+func (s *SQLiteStorage) Empty() (bool, error) {
+	var n int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM meta WHERE key = 'schemaVersion'`).Scan(&n); err != nil {
+		return false, fmt.Errorf("ler meta: %w", err)
+	}
+	return n == 0, nil
+}
+
+func (s *SQLiteStorage) LoadState() (*State, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("iniciar leitura: %w", err)
+	}
+	defer tx.Rollback()
+	return loadTx(tx)
+}
+
+func (s *SQLiteStorage) SaveState(state *State) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := saveTx(tx, state); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit state: %w", err)
+	}
+	return nil
+}
+
+// Update roda leitura, fn e escrita na mesma transação. Com _txlock=immediate
+// o SQLite reserva o lock de escrita no BEGIN, serializando também outros
+// processos que usem o mesmo banco.
+func (s *SQLiteStorage) Update(fn func(*State) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	state, err := loadTx(tx)
+	if err != nil {
+		return err
+	}
+	if err := fn(state); err != nil {
+		return err
+	}
+	if err := saveTx(tx, state); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit state: %w", err)
+	}
+	return nil
+}
+
+func loadTx(tx *sql.Tx) (*State, error) {
+	state := &State{SchemaVersion: CurrentSchema}
+	meta, err := readMeta(tx)
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := meta["schemaVersion"]; ok {
+		state.SchemaVersion, _ = strconv.Atoi(v)
+	}
+	if v, ok := meta["updatedAt"]; ok {
+		state.UpdatedAt, _ = time.Parse(time.RFC3339Nano, v)
+	}
+
+	for _, t := range stateTables(state) {
+		if err := t.load(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := migrate(state); err != nil {
+		return nil, fmt.Errorf("migrar state: %w", err)
+	}
+	return state, nil
+}
+
+func saveTx(tx *sql.Tx, state *State) error {
+	state.UpdatedAt = time.Now()
+	if state.SchemaVersion == 0 {
+		state.SchemaVersion = CurrentSchema
+	}
+
+	for _, t := range stateTables(state) {
+		if err := t.save(tx); err != nil {
+			return err
+		}
+	}
+	meta := map[string]string{
+		"schemaVersion": strconv.Itoa(state.SchemaVersion),
+		"updatedAt":     state.UpdatedAt.Format(time.RFC3339Nano),
+	}
+	for k, v := range meta {
+		if _, err := tx.Exec(`INSERT INTO meta (key, value) VALUES (?, ?)
+			ON CONFLICT(key) DO UPDATE SET value = excluded.value`, k, v); err != nil {
+			return fmt.Errorf("gravar meta: %w", err)
+		}
+	}
+	return nil
+}
+
+func readMeta(tx *sql.Tx) (map[string]string, error) {
+	rows, err := tx.Query(`SELECT key, value FROM meta`)
+	if err != nil {
+		return nil, fmt.Errorf("ler meta: %w", err)
+	}
+	defer rows.Close()
+
+	meta := make(map[string]string)
+	for rows.Next() {
+		var k, v string
+		if err := rows.Scan(&k, &v); err != nil {
+			return nil, fmt.Errorf("ler meta: %w", err)
+		}
+		meta[k] = v
+	}
+	return meta, rows.Err()
+}
+
+// ImportJSON migra um state.json existente para o banco SQLite. O arquivo
+// passa pela cadeia de migrate() via JSONStorage e, depois de gravado no
+// banco, é renomeado para <path>.bak — o JSON fica como backup e não é
+// importado de novo. Retorna false quando não há state.json para importar.
+func (s *SQLiteStorage) ImportJSON(jsonPath string) (bool, error) {
+	if _, err := os.Stat(jsonPath); errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+
+	state, err := NewJSONStorage(jsonPath).LoadState()
+	if err != nil {
+		return false, fmt.Errorf("carregar state.json: %w", err)
+	}
+	updatedAt := state.UpdatedAt
+	if err := s.SaveState(state); err != nil {
+		return false, fmt.Errorf("importar state.json: %w", err)
+	}
+	// Preserva o updatedAt original do JSON.
+	if !updatedAt.IsZero() {
+		if _, err := s.db.Exec(`UPDATE meta SET value = ? WHERE key = 'updatedAt'`,
+			updatedAt.Format(time.RFC3339Nano)); err != nil {
+			return false, fmt.Errorf("gravar meta: %w", err)
+		}
+	}
+
+	backup := jsonPath + ".bak"
+	if err := os.Rename(jsonPath, backup); err != nil {
+		return false, fmt.Errorf("backup state.json: %w", err)
+	}
+	return true, nil
+}
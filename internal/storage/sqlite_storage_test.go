@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSQLiteStorageRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	st, err := NewSQLiteStorage(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer st.Close()
+
+	in := &State{
+		Keys:     []Key{{ID: "k1", Alias: "work", Type: "ed25519"}, {ID: "k2", Alias: "home", Type: "rsa", Bits: 4096}},
+		Servers:  []Server{{ID: "s1", Name: "vps", Host: "10.0.0.1", Port: 22, Tags: []string{"prod"}}},
+		EnvFiles: []EnvFile{{ID: "e1", Name: "api", Variables: map[string]string{"A": "1"}}},
+	}
+	if err := st.SaveState(in); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	out, err := st.LoadState()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if out.SchemaVersion != CurrentSchema {
+		t.Fatalf("schema: want %d got %d", CurrentSchema, out.SchemaVersion)
+	}
+	if len(out.Keys) != 2 || out.Keys[0].ID != "k1" || out.Keys[1].Bits != 4096 {
+		t.Fatalf("keys não preservadas: %+v", out.Keys)
+	}
+	if len(out.Servers) != 1 || out.Servers[0].Tags[0] != "prod" {
+		t.Fatalf("servers não preservados: %+v", out.Servers)
+	}
+	if out.EnvFiles[0].Variables["A"] != "1" {
+		t.Fatalf("env vars não preservadas: %+v", out.EnvFiles)
+	}
+	if out.Accounts == nil || out.Repositories == nil {
+		t.Fatalf("slices vazios devem ser não-nil")
+	}
+
+	// Remoção também precisa refletir no banco.
+	out.Keys = out.Keys[:1]
+	if err := st.SaveState(out); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	again, _ := st.LoadState()
+	if len(again.Keys) != 1 {
+		t.Fatalf("want 1 key got %d", len(again.Keys))
+	}
+}
+
+func TestSQLiteImportJSONRunsMigrations(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "state.json")
+	legacy := `{"schemaVersion":1,"accounts":[{"id":"a1","name":"work","hostAlias":"github-work","identityFile":"/tmp/id_ed25519"}]}`
+	if err := os.WriteFile(jsonPath, []byte(legacy), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err := NewSQLiteStorage(filepath.Join(dir, "state.db"))
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer st.Close()
+
+	empty, err := st.Empty()
+	if err != nil || !empty {
+		t.Fatalf("banco novo deveria estar vazio (empty=%v err=%v)", empty, err)
+	}
+	imported, err := st.ImportJSON(jsonPath)
+	if err != nil || !imported {
+		t.Fatalf("import: imported=%v err=%v", imported, err)
+	}
+
+	got, err := st.LoadState()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.SchemaVersion != CurrentSchema {
+		t.Fatalf("schema: want %d got %d", CurrentSchema, got.SchemaVersion)
+	}
+	if len(got.Keys) != 1 || got.Accounts[0].KeyID != got.Keys[0].ID {
+		t.Fatalf("migração v1→v2 não aplicada: keys=%+v accounts=%+v", got.Keys, got.Accounts)
+	}
+	if _, err := os.Stat(jsonPath); !os.IsNotExist(err) {
+		t.Fatalf("state.json deveria ter sido movido para backup")
+	}
+	if _, err := os.Stat(jsonPath + ".bak"); err != nil {
+		t.Fatalf("backup ausente: %v", err)
+	}
+
+	imported, err = st.ImportJSON(jsonPath)
+	if err != nil || imported {
+		t.Fatalf("segunda importação deveria ser no-op (imported=%v err=%v)", imported, err)
+	}
+}
@@ -7,6 +7,9 @@ const CurrentSchema = 3
 type Storage interface {
 	LoadState() (*State, error)
 	SaveState(*State) error
+	// Update aplica fn sobre o state atual de forma transacional: writers são
+	// serializados e, se fn retornar erro, nada é gravado.
+	Update(fn func(*State) error) error
 }
 
 type Key struct {
@@ -21,7 +24,13 @@ type Key struct {
 	Protected      bool      `json:"protected"`      // tem passphrase
 	Source         string    `json:"source"`         // "generated" | "imported"
 	OriginalPath   string    `json:"originalPath,omitempty"`
+	CA             bool      `json:"ca,omitempty"` // autoridade certificadora: assina outras chaves
 	CreatedAt      time.Time `json:"createdAt"`
+
+	// ArchivedAt marca a chave aposentada por uma rotação; ReplacedBy é a
+	// chave que a substituiu (ver KeyRotation).
+	ArchivedAt time.Time `json:"archivedAt,omitzero"`
+	ReplacedBy string    `json:"replacedBy,omitempty"`
 }
 
 type EnvFile struct {
@@ -103,6 +112,73 @@ type CustomSkill struct {
 	CreatedAt   time.Time `json:"createdAt"`
 }
 
+// Schedule é uma tarefa recorrente do scheduler interno: uma ação registrada
+// (ex: "pull-all", "server-test") disparada conforme a expressão cron.
+type Schedule struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Action    string    `json:"action"`
+	Target    string    `json:"target,omitempty"` // ID do registro usado pela ação (servidor, collection)
+	Cron      string    `json:"cron"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ScheduleRun é uma execução de um Schedule; a saída fica no job (JobID).
+type ScheduleRun struct {
+	ID         string    `json:"id"`
+	ScheduleID string    `json:"scheduleId"`
+	JobID      string    `json:"jobId,omitempty"`
+	Status     string    `json:"status"` // o status do job, ou "failed" se nem começou
+	Error      string    `json:"error,omitempty"`
+	CatchUp    bool      `json:"catchUp,omitempty"` // execução perdida, feita na inicialização
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt,omitzero"`
+}
+
+// Certificate é um certificado OpenSSH emitido por uma chave CA do Key
+// Manager para outra chave gerenciada, gravado em <chave>-cert.pub.
+type Certificate struct {
+	ID              string            `json:"id"`
+	CAKeyID         string            `json:"caKeyId"`
+	KeyID           string            `json:"keyId"`
+	Type            string            `json:"type"` // "user" | "host"
+	Identity        string            `json:"identity"`
+	Serial          uint64            `json:"serial"`
+	Principals      []string          `json:"principals,omitempty"`
+	ValidAfter      time.Time         `json:"validAfter"`
+	ValidBefore     time.Time         `json:"validBefore,omitzero"` // zero: sem expiração
+	CriticalOptions map[string]string `json:"criticalOptions,omitempty"`
+	Extensions      map[string]string `json:"extensions,omitempty"`
+	Path            string            `json:"path"`
+	CreatedAt       time.Time         `json:"createdAt"`
+}
+
+// KeyRotation registra a troca de uma chave por outra feita pelo assistente
+// de rotação do Key Manager: o que usava a chave antiga e o resultado em
+// cada registro.
+type KeyRotation struct {
+	ID         string         `json:"id"`
+	OldKeyID   string         `json:"oldKeyId"`
+	NewKeyID   string         `json:"newKeyId"`
+	JobID      string         `json:"jobId,omitempty"`
+	Status     string         `json:"status"` // "running" | "completed" | "partial" | "failed"
+	Steps      []RotationStep `json:"steps,omitempty"`
+	StartedAt  time.Time      `json:"startedAt"`
+	FinishedAt time.Time      `json:"finishedAt,omitzero"`
+}
+
+// RotationStep é o resultado da rotação em uma conta, servidor ou
+// identidade que referenciava a chave antiga.
+type RotationStep struct {
+	Kind    string `json:"kind"` // "account" | "server" | "identity"
+	RefID   string `json:"refId"`
+	Name    string `json:"name"`
+	Status  string `json:"status"` // "done" | "failed" | "skipped"
+	Message string `json:"message,omitempty"`
+}
+
 type State struct {
 	SchemaVersion  int                 `json:"schemaVersion"`
 	Keys           []Key               `json:"keys"`
@@ -118,6 +194,10 @@ type State struct {
 	DBConnections  []DBConnection      `json:"dbConnections,omitempty"`
 	MCPServers     []MCPServer         `json:"mcpServers,omitempty"`
 	CustomSkills   []CustomSkill       `json:"customSkills,omitempty"`
+	Schedules      []Schedule          `json:"schedules,omitempty"`
+	ScheduleRuns   []ScheduleRun       `json:"scheduleRuns,omitempty"`
+	Certificates   []Certificate       `json:"certificates,omitempty"`
+	KeyRotations   []KeyRotation       `json:"keyRotations,omitempty"`
 	UpdatedAt      time.Time           `json:"updatedAt"`
 }
 
@@ -145,6 +225,10 @@ type Account struct {
 	IsSimpleKey  bool      `json:"isSimpleKey,omitempty"`
 	CreatedAt    time.Time `json:"createdAt"`
 	UpdatedAt    time.Time `json:"updatedAt"`
+
+	// CertificateFile não é persistido: é preenchido ao aplicar o ssh
+	// config com o certificado válido da chave (ver State.ResolveKeyFiles).
+	CertificateFile string `json:"-"`
 }
 
 type GitIdentity struct {
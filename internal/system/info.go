@@ -3,11 +3,11 @@ package system
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"runtime"
 	"strings"
 	"time"
 
+	"github.com/seuusuario/factorydev/internal/command"
 	"github.com/shirou/gopsutil/v3/cpu"
 	"github.com/shirou/gopsutil/v3/disk"
 	"github.com/shirou/gopsutil/v3/host"
@@ -148,8 +148,7 @@ func SetHostname(name string) error {
 	if name == "" {
 		return fmt.Errorf("hostname não pode ser vazio")
 	}
-	cmd := exec.Command("hostnamectl", "set-hostname", name)
-	out, err := cmd.CombinedOutput()
+	out, err := command.CombinedOutput(context.Background(), command.Cmd{Name: "hostnamectl", Args: []string{"set-hostname", name}})
 	if err != nil {
 		return fmt.Errorf("hostnamectl: %s", strings.TrimSpace(string(out)))
 	}
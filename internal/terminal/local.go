@@ -0,0 +1,76 @@
+package terminal
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+
+	"github.com/seuusuario/factorydev/internal/pty"
+)
+
+// localProcess é um shell local num PTY.
+type localProcess struct {
+	cmd    *exec.Cmd
+	master *os.File
+	once   sync.Once
+}
+
+// Shell devolve o shell do usuário ($SHELL) ou /bin/sh.
+func Shell() string {
+	if sh := os.Getenv("SHELL"); sh != "" {
+		return sh
+	}
+	return "/bin/sh"
+}
+
+// StartLocal inicia o shell do usuário, como shell de login, no
+// diretório dir.
+func StartLocal(dir string, cols, rows int) (Process, error) {
+	cmd := exec.Command(Shell(), "-l")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TERM=xterm-256color", "COLORTERM=truecolor")
+	master, err := pty.Start(cmd, cols, rows)
+	if err != nil {
+		return nil, err
+	}
+	return &localProcess{cmd: cmd, master: master}, nil
+}
+
+// Read trata o EIO do Linux (o escravo fechou) como fim da saída.
+func (p *localProcess) Read(b []byte) (int, error) {
+	n, err := p.master.Read(b)
+	if errors.Is(err, syscall.EIO) {
+		return n, os.ErrClosed
+	}
+	return n, err
+}
+
+func (p *localProcess) Write(b []byte) (int, error) { return p.master.Write(b) }
+
+func (p *localProcess) Resize(cols, rows int) error { return pty.Resize(p.master, cols, rows) }
+
+func (p *localProcess) Wait() (int, error) {
+	err := p.cmd.Wait()
+	p.master.Close()
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return exit.ExitCode(), nil
+	}
+	if err != nil {
+		return -1, err
+	}
+	return 0, nil
+}
+
+// Close manda SIGHUP ao shell e fecha o PTY, como um terminal que fecha.
+func (p *localProcess) Close() error {
+	p.once.Do(func() {
+		if p.cmd.Process != nil {
+			_ = p.cmd.Process.Signal(syscall.SIGHUP)
+		}
+		p.master.Close()
+	})
+	return nil
+}
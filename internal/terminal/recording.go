@@ -0,0 +1,190 @@
+package terminal
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ErrBadRecording indica um nome de gravação inválido (fora do diretório
+// de gravações ou sem a extensão .cast).
+var ErrBadRecording = errors.New("gravação inválida")
+
+// header é a primeira linha de um arquivo asciicast v2.
+type header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// recorder grava a saída da sessão em asciicast v2: o cabeçalho e depois
+// uma linha [tempo, "o", dados] por pedaço de saída e [tempo, "r",
+// "COLSxROWS"] por redimensionamento. A entrada digitada não é gravada.
+type recorder struct {
+	f       *os.File
+	w       *bufio.Writer
+	start   time.Time
+	pending []byte // fim de um caractere UTF-8 partido entre leituras
+}
+
+func newRecorder(dir string, s Session, cols, rows int) (*recorder, string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, "", fmt.Errorf("criar diretório de gravações: %w", err)
+	}
+	name := s.Started.Format("20060102-150405") + "-" + s.ID + ".cast"
+	f, err := os.OpenFile(filepath.Join(dir, name), os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0o600)
+	if err != nil {
+		return nil, "", fmt.Errorf("criar gravação: %w", err)
+	}
+	if cols <= 0 || rows <= 0 {
+		cols, rows = 80, 24
+	}
+	r := &recorder{f: f, w: bufio.NewWriter(f), start: s.Started}
+	h, _ := json.Marshal(header{
+		Version:   2,
+		Width:     cols,
+		Height:    rows,
+		Timestamp: s.Started.Unix(),
+		Title:     s.Title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	r.w.Write(append(h, '\n'))
+	return r, name, nil
+}
+
+func (r *recorder) output(data []byte) {
+	data = append(r.pending, data...)
+	r.pending = nil
+	if cut := incompleteSuffix(data); cut > 0 {
+		r.pending = append([]byte(nil), data[len(data)-cut:]...)
+		data = data[:len(data)-cut]
+	}
+	if len(data) > 0 {
+		r.event("o", string(data))
+	}
+}
+
+func (r *recorder) resize(cols, rows int) {
+	r.event("r", fmt.Sprintf("%dx%d", cols, rows))
+}
+
+func (r *recorder) event(code, data string) {
+	line, _ := json.Marshal([]any{
+		float64(time.Since(r.start).Microseconds()) / 1e6, code, data,
+	})
+	r.w.Write(append(line, '\n'))
+	r.w.Flush()
+}
+
+func (r *recorder) close() {
+	if len(r.pending) > 0 {
+		r.event("o", string(r.pending))
+	}
+	r.w.Flush()
+	r.f.Close()
+}
+
+// incompleteSuffix devolve quantos bytes do fim formam o começo de um
+// caractere UTF-8 que ainda não chegou inteiro.
+func incompleteSuffix(b []byte) int {
+	for i := 1; i <= 3 && i <= len(b); i++ {
+		c := b[len(b)-i]
+		if c < 0x80 {
+			return 0
+		}
+		if utf8.RuneStart(c) {
+			if utf8.FullRune(b[len(b)-i:]) {
+				return 0
+			}
+			return i
+		}
+	}
+	return 0
+}
+
+// Recording descreve um arquivo de gravação.
+type Recording struct {
+	Name    string
+	Title   string
+	Started time.Time
+	Size    int64
+	Cols    int
+	Rows    int
+}
+
+// Recordings lista as gravações, das mais novas para as mais antigas.
+func (m *Manager) Recordings() ([]Recording, error) {
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Recording
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".cast") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		rec := Recording{Name: e.Name(), Started: info.ModTime(), Size: info.Size()}
+		if h, err := readHeader(filepath.Join(m.dir, e.Name())); err == nil {
+			rec.Title, rec.Cols, rec.Rows = h.Title, h.Width, h.Height
+			rec.Started = time.Unix(h.Timestamp, 0)
+		}
+		list = append(list, rec)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Started.After(list[j].Started) })
+	return list, nil
+}
+
+func readHeader(path string) (header, error) {
+	var h header
+	f, err := os.Open(path)
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return h, err
+	}
+	err = json.Unmarshal(line, &h)
+	return h, err
+}
+
+// RecordingPath devolve o caminho da gravação, recusando nomes que saiam
+// do diretório de gravações.
+func (m *Manager) RecordingPath(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || !strings.HasSuffix(name, ".cast") {
+		return "", ErrBadRecording
+	}
+	path := filepath.Join(m.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// RemoveRecording apaga a gravação. A de uma sessão ainda aberta também
+// pode ser apagada; a sessão continua, mas o resto da gravação se perde.
+func (m *Manager) RemoveRecording(name string) error {
+	path, err := m.RecordingPath(name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,334 @@
+// Package terminal mantém as sessões do terminal embutido: cada sessão é
+// um processo interativo (shell local num PTY, SSH ou docker exec) com
+// histórico de saída para reconectar, vários navegadores assistindo e
+// gravação opcional em asciicast v2.
+package terminal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Tipos de sessão.
+const (
+	KindLocal  = "local"
+	KindSSH    = "ssh"
+	KindDocker = "docker"
+)
+
+// scrollback é quanto da saída recente fica guardado para quem conecta
+// depois (ou reconecta) a uma sessão.
+const scrollback = 256 << 10
+
+// subscriberBuffer é quantos pedaços de saída um cliente pode acumular
+// antes de ser desconectado por lentidão.
+const subscriberBuffer = 256
+
+var (
+	ErrNotFound = errors.New("sessão de terminal não encontrada")
+	ErrExited   = errors.New("a sessão de terminal já terminou")
+)
+
+// Process é o lado do servidor de um shell interativo.
+type Process interface {
+	io.ReadWriter
+	// Resize ajusta o tamanho da janela do terminal remoto.
+	Resize(cols, rows int) error
+	// Wait espera o fim do processo e devolve o código de saída.
+	Wait() (int, error)
+	// Close encerra o processo.
+	Close() error
+}
+
+// Session é o snapshot de uma sessão. Os valores devolvidos pelo Manager
+// são cópias.
+type Session struct {
+	ID       string
+	Kind     string
+	Title    string
+	Target   string // diretório, user@host ou container
+	Started  time.Time
+	Exited   bool
+	ExitCode int
+	Error    string
+	// Recording é o nome do arquivo da gravação, se houver.
+	Recording string
+}
+
+// Options descreve a sessão a registrar.
+type Options struct {
+	Kind       string
+	Title      string
+	Target     string
+	Cols, Rows int
+	Record     bool
+}
+
+type session struct {
+	mu     sync.Mutex
+	info   Session
+	proc   Process
+	buf    []byte
+	subs   map[chan []byte]struct{}
+	rec    *recorder
+	done   chan struct{}
+	closed bool
+}
+
+// Manager guarda as sessões abertas.
+type Manager struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+	dir      string // gravações
+}
+
+// New cria o gerenciador; as gravações vão para dir.
+func New(dir string) *Manager {
+	return &Manager{sessions: map[string]*session{}, dir: dir}
+}
+
+// Start registra o processo como uma sessão e começa a ler a saída dele.
+// Se a gravação não puder ser criada o processo é encerrado.
+func (m *Manager) Start(opts Options, proc Process) (Session, error) {
+	s := &session{
+		info: Session{
+			ID:      newID(),
+			Kind:    opts.Kind,
+			Title:   opts.Title,
+			Target:  opts.Target,
+			Started: time.Now(),
+		},
+		proc: proc,
+		subs: map[chan []byte]struct{}{},
+		done: make(chan struct{}),
+	}
+	if opts.Record {
+		rec, name, err := newRecorder(m.dir, s.info, opts.Cols, opts.Rows)
+		if err != nil {
+			proc.Close()
+			return Session{}, err
+		}
+		s.rec, s.info.Recording = rec, name
+	}
+	m.mu.Lock()
+	m.sessions[s.info.ID] = s
+	m.mu.Unlock()
+	go s.pump()
+	return s.info, nil
+}
+
+// pump copia a saída do processo para o histórico, a gravação e os
+// clientes conectados até o processo terminar.
+func (s *session) pump() {
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := s.proc.Read(buf)
+		if n > 0 {
+			s.output(append([]byte(nil), buf[:n]...))
+		}
+		if err != nil {
+			break
+		}
+	}
+	code, err := s.proc.Wait()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.info.Exited, s.info.ExitCode = true, code
+	if err != nil {
+		s.info.Error = err.Error()
+	}
+	if s.rec != nil {
+		s.rec.close()
+	}
+	for ch := range s.subs {
+		close(ch)
+		delete(s.subs, ch)
+	}
+	close(s.done)
+}
+
+func (s *session) output(data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf = append(s.buf, data...)
+	if over := len(s.buf) - scrollback; over > 0 {
+		s.buf = append(s.buf[:0:0], s.buf[over:]...)
+	}
+	if s.rec != nil {
+		s.rec.output(data)
+	}
+	for ch := range s.subs {
+		select {
+		case ch <- data:
+		default:
+			// Cliente lento: desconecta; ao reconectar recebe o histórico.
+			close(ch)
+			delete(s.subs, ch)
+		}
+	}
+}
+
+func (m *Manager) get(id string) (*session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	return s, ok
+}
+
+// Get devolve o snapshot da sessão.
+func (m *Manager) Get(id string) (Session, bool) {
+	s, ok := m.get(id)
+	if !ok {
+		return Session{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info, true
+}
+
+// List devolve as sessões, das mais antigas para as mais novas.
+func (m *Manager) List() []Session {
+	m.mu.Lock()
+	list := make([]*session, 0, len(m.sessions))
+	for _, s := range m.sessions {
+		list = append(list, s)
+	}
+	m.mu.Unlock()
+	out := make([]Session, len(list))
+	for i, s := range list {
+		s.mu.Lock()
+		out[i] = s.info
+		s.mu.Unlock()
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Started.Before(out[j].Started) })
+	return out
+}
+
+// Attach conecta um cliente à sessão: devolve o histórico de saída e um
+// canal com a saída nova. O canal é fechado quando o processo termina ou
+// quando o cliente fica para trás; detach desconecta antes disso.
+func (m *Manager) Attach(id string) (history []byte, output <-chan []byte, detach func(), err error) {
+	s, ok := m.get(id)
+	if !ok {
+		return nil, nil, nil, ErrNotFound
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	history = append([]byte(nil), s.buf...)
+	ch := make(chan []byte, subscriberBuffer)
+	if s.info.Exited {
+		close(ch)
+		return history, ch, func() {}, nil
+	}
+	s.subs[ch] = struct{}{}
+	return history, ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.subs[ch]; ok {
+			close(ch)
+			delete(s.subs, ch)
+		}
+	}, nil
+}
+
+// Write envia a entrada digitada para o processo.
+func (m *Manager) Write(id string, data []byte) error {
+	s, ok := m.get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	if s.exited() {
+		return ErrExited
+	}
+	_, err := s.proc.Write(data)
+	return err
+}
+
+// Resize ajusta o tamanho do terminal e registra a mudança na gravação.
+func (m *Manager) Resize(id string, cols, rows int) error {
+	s, ok := m.get(id)
+	if !ok {
+		return ErrNotFound
+	}
+	if cols <= 0 || rows <= 0 || cols > 1000 || rows > 1000 {
+		return fmt.Errorf("tamanho de terminal inválido: %dx%d", cols, rows)
+	}
+	if s.exited() {
+		return ErrExited
+	}
+	if err := s.proc.Resize(cols, rows); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	if s.rec != nil {
+		s.rec.resize(cols, rows)
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// Done devolve um canal fechado quando o processo da sessão termina.
+func (m *Manager) Done(id string) (<-chan struct{}, bool) {
+	s, ok := m.get(id)
+	if !ok {
+		return nil, false
+	}
+	return s.done, true
+}
+
+// Close encerra o processo (se ainda estiver rodando) e remove a sessão.
+func (m *Manager) Close(id string) error {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	if !ok {
+		return ErrNotFound
+	}
+	s.close()
+	return nil
+}
+
+// CloseAll encerra todas as sessões (ao desligar o servidor).
+func (m *Manager) CloseAll() {
+	m.mu.Lock()
+	list := m.sessions
+	m.sessions = map[string]*session{}
+	m.mu.Unlock()
+	for _, s := range list {
+		s.close()
+	}
+}
+
+func (s *session) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	s.mu.Unlock()
+	_ = s.proc.Close()
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func (s *session) exited() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.info.Exited
+}
+
+func newID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,170 @@
+package terminal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeProcess devolve o que recebe (como o eco de um terminal) e, depois
+// de Close, termina com código 7.
+type fakeProcess struct {
+	r *io.PipeReader
+	w *io.PipeWriter
+}
+
+func newFake() *fakeProcess {
+	r, w := io.Pipe()
+	return &fakeProcess{r: r, w: w}
+}
+
+func (p *fakeProcess) Read(b []byte) (int, error)  { return p.r.Read(b) }
+func (p *fakeProcess) Write(b []byte) (int, error) { return p.w.Write(b) }
+func (p *fakeProcess) Wait() (int, error)          { return 7, nil }
+func (p *fakeProcess) Close() error                { return p.w.Close() }
+func (p *fakeProcess) Resize(int, int) error       { return nil }
+
+func next(t *testing.T, ch <-chan []byte) string {
+	t.Helper()
+	select {
+	case b, ok := <-ch:
+		if !ok {
+			return "<fechado>"
+		}
+		return string(b)
+	case <-time.After(5 * time.Second):
+		t.Fatal("sem saída")
+		return ""
+	}
+}
+
+func TestSessionAttachAndRecord(t *testing.T) {
+	dir := t.TempDir()
+	m := New(dir)
+	proc := newFake()
+	s, err := m.Start(Options{Kind: KindLocal, Title: "teste", Cols: 80, Rows: 24, Record: true}, proc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hist, out, detach, err := m.Attach(s.ID)
+	if err != nil || len(hist) != 0 {
+		t.Fatalf("attach: %v %q", err, hist)
+	}
+	if err := m.Write(s.ID, []byte("olá ")); err != nil {
+		t.Fatal(err)
+	}
+	if got := next(t, out); got != "olá " {
+		t.Fatalf("saída = %q", got)
+	}
+	detach()
+	if err := m.Resize(s.ID, 100, 30); err != nil {
+		t.Fatal(err)
+	}
+	// Caractere partido entre duas leituras.
+	m.Write(s.ID, []byte("é")[:1])
+	m.Write(s.ID, []byte("é")[1:])
+
+	// Quem conecta depois recebe o histórico.
+	var hist2 []byte
+	for range 100 {
+		hist2, out, _, _ = m.Attach(s.ID)
+		if string(hist2) == "olá é" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if string(hist2) != "olá é" {
+		t.Fatalf("histórico = %q", hist2)
+	}
+
+	m.Close(s.ID)
+	if got := next(t, out); got != "<fechado>" {
+		t.Fatalf("depois do fim = %q", got)
+	}
+	if _, ok := m.Get(s.ID); ok {
+		t.Fatal("sessão continua listada depois de Close")
+	}
+
+	recs, err := m.Recordings()
+	if err != nil || len(recs) != 1 || recs[0].Name != s.Recording || recs[0].Title != "teste" || recs[0].Cols != 80 {
+		t.Fatalf("gravações = %v %+v", err, recs)
+	}
+	path, err := m.RecordingPath(s.Recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := os.Open(path)
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	var events []string
+	for sc.Scan() {
+		var ev []any
+		if json.Unmarshal(sc.Bytes(), &ev) == nil && len(ev) == 3 {
+			events = append(events, ev[1].(string)+":"+ev[2].(string))
+		}
+	}
+	if strings.Join(events, "|") != "o:olá |r:100x30|o:é" {
+		t.Fatalf("eventos = %q", events)
+	}
+
+	if _, err := m.RecordingPath("../segredo.cast"); err != ErrBadRecording {
+		t.Fatalf("path traversal: %v", err)
+	}
+	if err := m.RemoveRecording(s.Recording); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, s.Recording)); !os.IsNotExist(err) {
+		t.Fatalf("gravação continua no disco: %v", err)
+	}
+}
+
+func TestExitStatus(t *testing.T) {
+	m := New(t.TempDir())
+	proc := newFake()
+	s, _ := m.Start(Options{Kind: KindSSH}, proc)
+	done, _ := m.Done(s.ID)
+	proc.Close()
+	<-done
+	got, _ := m.Get(s.ID)
+	if !got.Exited || got.ExitCode != 7 {
+		t.Fatalf("sessão = %+v", got)
+	}
+	if err := m.Write(s.ID, []byte("x")); err != ErrExited {
+		t.Fatalf("escrever depois do fim: %v", err)
+	}
+}
+
+func TestLocalShell(t *testing.T) {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		t.Skip("sem PTY neste sistema")
+	}
+	t.Setenv("SHELL", "/bin/sh")
+	dir := t.TempDir()
+	m := New(t.TempDir())
+	proc, err := StartLocal(dir, 80, 24)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := m.Start(Options{Kind: KindLocal, Target: dir}, proc)
+	done, _ := m.Done(s.ID)
+	m.Write(s.ID, []byte("pwd; exit 3\n"))
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("o shell não terminou")
+	}
+	got, _ := m.Get(s.ID)
+	hist, _, _, _ := m.Attach(s.ID)
+	real, _ := filepath.EvalSymlinks(dir)
+	if got.ExitCode != 3 || !(bytes.Contains(hist, []byte(dir)) || bytes.Contains(hist, []byte(real))) {
+		t.Fatalf("código %d, saída %q", got.ExitCode, hist)
+	}
+}
@@ -0,0 +1,77 @@
+package vault
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// boxMagic identifica blobs cifrados com SealBytes. O formato é
+// magic | salt (16) | nonce | ciphertext+tag, com a chave derivada por scrypt.
+var boxMagic = []byte("FDEVBOX1")
+
+const boxSaltLen = 16
+
+// ErrNotSealedBox indica que os dados não foram produzidos por SealBytes.
+var ErrNotSealedBox = errors.New("arquivo não é um pacote cifrado do FactoryDev")
+
+// SealBytes cifra data com uma passphrase avulsa, independente do vault
+// mestre. Usado para arquivos que saem da máquina (export de perfil).
+func SealBytes(passphrase string, data []byte) ([]byte, error) {
+	if len(passphrase) < 8 {
+		return nil, fmt.Errorf("passphrase deve ter ao menos 8 caracteres")
+	}
+	salt := make([]byte, boxSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("gerar salt: %w", err)
+	}
+	key, err := deriveKey(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("gerar nonce: %w", err)
+	}
+
+	out := make([]byte, 0, len(boxMagic)+len(salt)+len(nonce)+len(data)+gcm.Overhead())
+	out = append(out, boxMagic...)
+	out = append(out, salt...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, data, boxMagic), nil
+}
+
+// OpenBytes decifra um blob produzido por SealBytes. Passphrase errada (ou
+// arquivo adulterado) retorna ErrWrongPassphrase.
+func OpenBytes(passphrase string, blob []byte) ([]byte, error) {
+	if !bytes.HasPrefix(blob, boxMagic) {
+		return nil, ErrNotSealedBox
+	}
+	rest := blob[len(boxMagic):]
+	if len(rest) < boxSaltLen {
+		return nil, fmt.Errorf("pacote cifrado truncado")
+	}
+	salt, rest := rest[:boxSaltLen], rest[boxSaltLen:]
+	key, err := deriveKey(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return nil, fmt.Errorf("pacote cifrado truncado")
+	}
+	nonce, ct := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ct, boxMagic)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return plain, nil
+}
@@ -0,0 +1,355 @@
+// Package vault guarda segredos (senhas, tokens, valores de .env) cifrados
+// com uma chave derivada da passphrase mestra. Os valores selados ficam no
+// próprio state como strings "vault:v1:<base64>"; a chave só existe em
+// memória enquanto o vault está desbloqueado.
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	sealedPrefix = "vault:v1:"
+	checkValue   = "factorydev-vault"
+
+	// Parâmetros scrypt recomendados para uso interativo.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+	keyLen  = 32
+)
+
+var (
+	ErrLocked          = errors.New("vault bloqueado")
+	ErrNotInitialized  = errors.New("vault não configurado")
+	ErrAlreadyExists   = errors.New("vault já configurado")
+	ErrWrongPassphrase = errors.New("passphrase incorreta")
+)
+
+// header é o conteúdo de ~/.fdev/vault.json: parâmetros do KDF e um valor
+// de verificação selado, usado para validar a passphrase no unlock.
+type header struct {
+	Version   int       `json:"version"`
+	KDF       string    `json:"kdf"`
+	N         int       `json:"n"`
+	R         int       `json:"r"`
+	P         int       `json:"p"`
+	Salt      string    `json:"salt"`
+	Check     string    `json:"check"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Vault mantém a chave derivada em memória e a descarta após IdleTimeout
+// sem uso.
+type Vault struct {
+	path string
+	idle time.Duration
+
+	mu       sync.Mutex
+	key      []byte
+	lastUsed time.Time
+	timer    *time.Timer
+}
+
+// Status resume o estado do vault para a UI.
+type Status struct {
+	Initialized bool
+	Locked      bool
+	IdleTimeout time.Duration
+	LocksAt     time.Time
+}
+
+func New(path string, idle time.Duration) *Vault {
+	return &Vault{path: path, idle: idle}
+}
+
+// IsSealed indica se s é um valor selado pelo vault.
+func IsSealed(s string) bool {
+	return strings.HasPrefix(s, sealedPrefix)
+}
+
+// Initialized indica se já existe uma passphrase mestra configurada.
+func (v *Vault) Initialized() bool {
+	_, err := os.Stat(v.path)
+	return err == nil
+}
+
+// Locked indica se a chave não está disponível em memória.
+func (v *Vault) Locked() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.key == nil
+}
+
+func (v *Vault) Status() Status {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	st := Status{
+		Initialized: v.Initialized(),
+		Locked:      v.key == nil,
+		IdleTimeout: v.idle,
+	}
+	if v.key != nil && v.idle > 0 {
+		st.LocksAt = v.lastUsed.Add(v.idle)
+	}
+	return st
+}
+
+// Init configura a passphrase mestra e deixa o vault desbloqueado.
+func (v *Vault) Init(passphrase string) error {
+	if v.Initialized() {
+		return ErrAlreadyExists
+	}
+	if len(passphrase) < 8 {
+		return fmt.Errorf("passphrase deve ter ao menos 8 caracteres")
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("gerar salt: %w", err)
+	}
+	key, err := deriveKey(passphrase, salt, scryptN, scryptR, scryptP)
+	if err != nil {
+		return err
+	}
+	check, err := seal(key, checkValue)
+	if err != nil {
+		return err
+	}
+
+	h := header{
+		Version:   1,
+		KDF:       "scrypt",
+		N:         scryptN,
+		R:         scryptR,
+		P:         scryptP,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Check:     check,
+		CreatedAt: time.Now(),
+	}
+	if err := writeHeader(v.path, h); err != nil {
+		return err
+	}
+
+	v.mu.Lock()
+	v.setKeyLocked(key)
+	v.mu.Unlock()
+	return nil
+}
+
+// Unlock deriva a chave a partir da passphrase e a mantém em memória.
+func (v *Vault) Unlock(passphrase string) error {
+	h, err := readHeader(v.path)
+	if err != nil {
+		return err
+	}
+	salt, err := base64.StdEncoding.DecodeString(h.Salt)
+	if err != nil {
+		return fmt.Errorf("salt inválido: %w", err)
+	}
+	key, err := deriveKey(passphrase, salt, h.N, h.R, h.P)
+	if err != nil {
+		return err
+	}
+	got, err := open(key, h.Check)
+	if err != nil || subtle.ConstantTimeCompare([]byte(got), []byte(checkValue)) != 1 {
+		return ErrWrongPassphrase
+	}
+
+	v.mu.Lock()
+	v.setKeyLocked(key)
+	v.mu.Unlock()
+	return nil
+}
+
+// Lock descarta a chave da memória.
+func (v *Vault) Lock() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for i := range v.key {
+		v.key[i] = 0
+	}
+	v.key = nil
+	if v.timer != nil {
+		v.timer.Stop()
+		v.timer = nil
+	}
+}
+
+// Seal cifra plain. Sem vault configurado o valor é devolvido como está,
+// preservando o comportamento de quem ainda não definiu passphrase.
+func (v *Vault) Seal(plain string) (string, error) {
+	if plain == "" || IsSealed(plain) || !v.Initialized() {
+		return plain, nil
+	}
+	key, err := v.useKey()
+	if err != nil {
+		return "", err
+	}
+	return seal(key, plain)
+}
+
+// Reveal decifra um valor selado. Valores em texto puro são devolvidos sem
+// alteração.
+func (v *Vault) Reveal(s string) (string, error) {
+	if !IsSealed(s) {
+		return s, nil
+	}
+	key, err := v.useKey()
+	if err != nil {
+		return "", err
+	}
+	return open(key, s)
+}
+
+// SealMap sela todos os valores de m, devolvendo um novo map.
+func (v *Vault) SealMap(m map[string]string) (map[string]string, error) {
+	return mapValues(m, v.Seal)
+}
+
+// RevealMap decifra todos os valores de m, devolvendo um novo map.
+func (v *Vault) RevealMap(m map[string]string) (map[string]string, error) {
+	return mapValues(m, v.Reveal)
+}
+
+// ── Internos ────────────────────────────────────────────────────────
+
+func (v *Vault) useKey() ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.key == nil {
+		return nil, ErrLocked
+	}
+	v.touchLocked()
+	// Cópia: Lock zera v.key e pode rodar durante o uso pelo chamador.
+	return append([]byte(nil), v.key...), nil
+}
+
+func (v *Vault) setKeyLocked(key []byte) {
+	v.key = key
+	v.touchLocked()
+}
+
+// touchLocked renova o prazo de re-lock automático. Exige v.mu.
+func (v *Vault) touchLocked() {
+	v.lastUsed = time.Now()
+	if v.idle <= 0 {
+		return
+	}
+	if v.timer == nil {
+		v.timer = time.AfterFunc(v.idle, v.Lock)
+		return
+	}
+	v.timer.Reset(v.idle)
+}
+
+func mapValues(m map[string]string, fn func(string) (string, error)) (map[string]string, error) {
+	if m == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(m))
+	for k, val := range m {
+		s, err := fn(val)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = s
+	}
+	return out, nil
+}
+
+func deriveKey(passphrase string, salt []byte, n, r, p int) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("derivar chave: %w", err)
+	}
+	return key, nil
+}
+
+func seal(key []byte, plain string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("gerar nonce: %w", err)
+	}
+	out := gcm.Seal(nonce, nonce, []byte(plain), nil)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(out), nil
+}
+
+func open(key []byte, sealed string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("valor selado inválido: %w", err)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return "", fmt.Errorf("valor selado truncado")
+	}
+	nonce, ct := raw[:gcm.NonceSize()], raw[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ct, nil)
+	if err != nil {
+		return "", fmt.Errorf("decifrar segredo: %w", err)
+	}
+	return string(plain), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("criar cifra: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func readHeader(path string) (header, error) {
+	var h header
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, ErrNotInitialized
+	}
+	if err != nil {
+		return h, fmt.Errorf("ler vault: %w", err)
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return h, fmt.Errorf("parse vault: %w", err)
+	}
+	return h, nil
+}
+
+func writeHeader(path string, h header) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serializar vault: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("criar pasta vault: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("escrever vault: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("rename vault: %w", err)
+	}
+	return nil
+}
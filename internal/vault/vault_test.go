@@ -0,0 +1,52 @@
+package vault
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestVaultSealRevealAndLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.json")
+	v := New(path, 0)
+
+	// Sem vault configurado, Seal é passthrough.
+	if got, err := v.Seal("segredo"); err != nil || got != "segredo" {
+		t.Fatalf("seal sem vault: got %q err %v", got, err)
+	}
+
+	if err := v.Init("correct horse"); err != nil {
+		t.Fatalf("init: %v", err)
+	}
+	sealed, err := v.Seal("s3nha")
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if !IsSealed(sealed) || sealed == "s3nha" {
+		t.Fatalf("valor não foi selado: %q", sealed)
+	}
+	if got, _ := v.Reveal(sealed); got != "s3nha" {
+		t.Fatalf("reveal: want s3nha got %q", got)
+	}
+
+	v.Lock()
+	if _, err := v.Reveal(sealed); !errors.Is(err, ErrLocked) {
+		t.Fatalf("reveal com vault bloqueado: want ErrLocked got %v", err)
+	}
+	if got, err := v.Reveal("texto puro"); err != nil || got != "texto puro" {
+		t.Fatalf("texto puro deve passar direto: got %q err %v", got, err)
+	}
+
+	// Nova instância simula reinício do processo.
+	v2 := New(path, 0)
+	if err := v2.Unlock("errada"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("want ErrWrongPassphrase got %v", err)
+	}
+	if err := v2.Unlock("correct horse"); err != nil {
+		t.Fatalf("unlock: %v", err)
+	}
+	m, err := v2.RevealMap(map[string]string{"k": sealed})
+	if err != nil || m["k"] != "s3nha" {
+		t.Fatalf("reveal map: %v %v", m, err)
+	}
+}
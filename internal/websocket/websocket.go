@@ -0,0 +1,268 @@
+// Package websocket é um servidor WebSocket (RFC 6455) mínimo, suficiente
+// para o terminal embutido: handshake, mensagens de texto e binárias,
+// fragmentação, ping/pong e fechamento. Não implementa extensões
+// (permessage-deflate) nem o lado cliente.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tipos de mensagem (opcodes).
+const (
+	TextMessage   = 1
+	BinaryMessage = 2
+	closeMessage  = 8
+	pingMessage   = 9
+	pongMessage   = 10
+)
+
+// MaxMessageSize limita o tamanho de uma mensagem recebida.
+const MaxMessageSize = 1 << 20
+
+// magic é o GUID do handshake (RFC 6455, seção 1.3).
+const magic = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+var (
+	// ErrClosed indica que o outro lado fechou a conexão.
+	ErrClosed = errors.New("websocket: conexão fechada")
+	// ErrBadOrigin indica handshake vindo de outra origem.
+	ErrBadOrigin = errors.New("websocket: origem não permitida")
+)
+
+// Conn é uma conexão WebSocket. ReadMessage deve ser chamado de uma
+// goroutine só; WriteMessage pode ser chamado de várias.
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+	wmu  sync.Mutex
+}
+
+// Upgrade valida o handshake, confere a origem (o Origin tem de apontar
+// para o próprio Host, contra cross-site WebSocket hijacking) e assume a
+// conexão. Em caso de erro a resposta HTTP já foi escrita.
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet ||
+		!headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "esperado upgrade para websocket", http.StatusBadRequest)
+		return nil, errors.New("websocket: requisição sem upgrade")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "versão de websocket não suportada", http.StatusUpgradeRequired)
+		return nil, errors.New("websocket: versão não suportada")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(w, "Sec-WebSocket-Key ausente", http.StatusBadRequest)
+		return nil, errors.New("websocket: chave ausente")
+	}
+	if !sameOrigin(r) {
+		http.Error(w, "origem não permitida", http.StatusForbidden)
+		return nil, ErrBadOrigin
+	}
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket não suportado", http.StatusInternalServerError)
+		return nil, fmt.Errorf("websocket: hijack: %w", err)
+	}
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+	_ = conn.SetDeadline(time.Time{})
+	if _, err := conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("websocket: handshake: %w", err)
+	}
+	return &Conn{conn: conn, br: rw.Reader}, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key + magic))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+func headerContains(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameOrigin exige Origin igual ao Host: o navegador sempre o envia no
+// handshake, e o WebSocket não passa pela checagem de origem do
+// middleware (é um GET).
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// ReadMessage devolve a próxima mensagem de texto ou binária, juntando os
+// fragmentos. Pings são respondidos; o fechamento pelo cliente é
+// confirmado e vira ErrClosed.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var (
+		typ int
+		msg []byte
+	)
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch op {
+		case pingMessage:
+			if err := c.writeFrame(pongMessage, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case pongMessage:
+			continue
+		case closeMessage:
+			_ = c.writeFrame(closeMessage, payload)
+			c.conn.Close()
+			return 0, nil, ErrClosed
+		case TextMessage, BinaryMessage:
+			if typ != 0 {
+				return 0, nil, c.fail("nova mensagem no meio de uma fragmentada")
+			}
+			typ = op
+		case 0: // continuação
+			if typ == 0 {
+				return 0, nil, c.fail("continuação sem mensagem inicial")
+			}
+		default:
+			return 0, nil, c.fail(fmt.Sprintf("opcode desconhecido %d", op))
+		}
+		if len(msg)+len(payload) > MaxMessageSize {
+			return 0, nil, c.fail("mensagem grande demais")
+		}
+		msg = append(msg, payload...)
+		if fin {
+			return typ, msg, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, op int, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return false, 0, nil, closedErr(err)
+	}
+	fin = head[0]&0x80 != 0
+	op = int(head[0] & 0x0f)
+	if head[0]&0x70 != 0 {
+		return false, 0, nil, c.fail("bits reservados sem extensão negociada")
+	}
+	if head[1]&0x80 == 0 {
+		return false, 0, nil, c.fail("frame do cliente sem máscara")
+	}
+	n := uint64(head[1] & 0x7f)
+	switch n {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, closedErr(err)
+		}
+		n = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return false, 0, nil, closedErr(err)
+		}
+		n = binary.BigEndian.Uint64(ext[:])
+	}
+	if n > MaxMessageSize {
+		return false, 0, nil, c.fail("frame grande demais")
+	}
+	if op >= closeMessage && (n > 125 || !fin) {
+		return false, 0, nil, c.fail("frame de controle inválido")
+	}
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return false, 0, nil, closedErr(err)
+	}
+	payload = make([]byte, n)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return false, 0, nil, closedErr(err)
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
+
+// WriteMessage envia uma mensagem num frame só (o servidor não mascara).
+func (c *Conn) WriteMessage(typ int, data []byte) error {
+	return c.writeFrame(typ, data)
+}
+
+func (c *Conn) writeFrame(op int, data []byte) error {
+	head := make([]byte, 2, 10)
+	head[0] = 0x80 | byte(op)
+	switch n := len(data); {
+	case n < 126:
+		head[1] = byte(n)
+	case n <= 0xffff:
+		head[1] = 126
+		head = binary.BigEndian.AppendUint16(head, uint16(n))
+	default:
+		head[1] = 127
+		head = binary.BigEndian.AppendUint64(head, uint64(n))
+	}
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	if _, err := c.conn.Write(append(head, data...)); err != nil {
+		return closedErr(err)
+	}
+	return nil
+}
+
+// Close envia o frame de fechamento (código 1000 com o motivo) e fecha a
+// conexão.
+func (c *Conn) Close(reason string) error {
+	payload := binary.BigEndian.AppendUint16(nil, 1000)
+	if len(reason) > 123 {
+		reason = reason[:123]
+	}
+	_ = c.writeFrame(closeMessage, append(payload, reason...))
+	return c.conn.Close()
+}
+
+// fail fecha a conexão por erro de protocolo (código 1002).
+func (c *Conn) fail(msg string) error {
+	_ = c.writeFrame(closeMessage, binary.BigEndian.AppendUint16(nil, 1002))
+	c.conn.Close()
+	return errors.New("websocket: " + msg)
+}
+
+func closedErr(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return ErrClosed
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// dial faz o handshake como um navegador e devolve a conexão crua.
+func dial(t *testing.T, srv *httptest.Server, origin string) (net.Conn, *bufio.Reader, *http.Response) {
+	t.Helper()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	conn, err := net.Dial("tcp", host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	req := "GET / HTTP/1.1\r\nHost: " + host + "\r\nUpgrade: websocket\r\nConnection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\nOrigin: " + origin + "\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, br, resp
+}
+
+// frame monta um frame do cliente (mascarado).
+func frame(fin bool, op int, payload string) []byte {
+	b0 := byte(op)
+	if fin {
+		b0 |= 0x80
+	}
+	out := []byte{b0}
+	switch n := len(payload); {
+	case n < 126:
+		out = append(out, 0x80|byte(n))
+	default:
+		out = append(out, 0x80|126)
+		out = binary.BigEndian.AppendUint16(out, uint16(n))
+	}
+	mask := []byte{1, 2, 3, 4}
+	out = append(out, mask...)
+	for i := range len(payload) {
+		out = append(out, payload[i]^mask[i%4])
+	}
+	return out
+}
+
+func readServerFrame(t *testing.T, br *bufio.Reader) (int, string) {
+	t.Helper()
+	head := make([]byte, 2)
+	if _, err := br.Read(head); err != nil {
+		t.Fatal(err)
+	}
+	n := int(head[1] & 0x7f)
+	if n == 126 {
+		ext := make([]byte, 2)
+		br.Read(ext)
+		n = int(binary.BigEndian.Uint16(ext))
+	}
+	buf := make([]byte, n)
+	for read := 0; read < n; {
+		m, err := br.Read(buf[read:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		read += m
+	}
+	return int(head[0] & 0x0f), string(buf)
+}
+
+func TestEcho(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		for {
+			typ, msg, err := c.ReadMessage()
+			if err != nil {
+				return
+			}
+			c.WriteMessage(typ, append([]byte("eco: "), msg...))
+		}
+	}))
+	defer srv.Close()
+
+	conn, br, resp := dial(t, srv, srv.URL)
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("handshake = %d %v", resp.StatusCode, resp.Header)
+	}
+
+	conn.Write(frame(true, TextMessage, "olá"))
+	if op, msg := readServerFrame(t, br); op != TextMessage || msg != "eco: olá" {
+		t.Fatalf("eco = %d %q", op, msg)
+	}
+
+	// Fragmentada, com um ping no meio.
+	conn.Write(frame(false, BinaryMessage, "par"))
+	conn.Write(frame(true, pingMessage, "p"))
+	if op, msg := readServerFrame(t, br); op != pongMessage || msg != "p" {
+		t.Fatalf("pong = %d %q", op, msg)
+	}
+	conn.Write(frame(true, 0, strings.Repeat("te", 100)))
+	if op, msg := readServerFrame(t, br); op != BinaryMessage || msg != "eco: par"+strings.Repeat("te", 100) {
+		t.Fatalf("fragmentada = %d %q", op, msg)
+	}
+
+	conn.Write(frame(true, closeMessage, ""))
+	if op, _ := readServerFrame(t, br); op != closeMessage {
+		t.Fatalf("fechamento = %d", op)
+	}
+}
+
+func TestRejectsOtherOrigin(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := Upgrade(w, r); err != ErrBadOrigin {
+			t.Errorf("err = %v", err)
+		}
+	}))
+	defer srv.Close()
+	if _, _, resp := dial(t, srv, "https://evil.example"); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("status = %d", resp.StatusCode)
+	}
+}
@@ -6,6 +6,8 @@ function inferPage() {
   if (p.startsWith('/tools/repos')) return 'repos'
   if (p.startsWith('/tools/git')) return 'git'
   if (p.startsWith('/tools/servers')) return 'servers'
+  if (p.startsWith('/tools/known-hosts')) return 'knownhosts'
+  if (p.startsWith('/tools/terminal')) return 'terminal'
   if (p.startsWith('/tools/envs')) return 'envs'
   if (p.startsWith('/tools/aliases')) return 'aliases'
   if (p.startsWith('/tools/installer')) return 'installer'
@@ -15,6 +17,14 @@ function inferPage() {
   if (p.startsWith('/tools/mcp')) return 'mcp'
   if (p.startsWith('/tools/db')) return 'database'
   if (p.startsWith('/tools/api')) return 'api'
+  if (p.startsWith('/tools/vault')) return 'vault'
+  if (p.startsWith('/tools/workspaces')) return 'workspaces'
+  if (p.startsWith('/tools/activity')) return 'activity'
+  if (p.startsWith('/tools/jobs')) return 'jobs'
+  if (p.startsWith('/tools/schedules')) return 'schedules'
+  if (p.startsWith('/tools/commands')) return 'commands'
+  if (p.startsWith('/tools/logs')) return 'logs'
+  if (p.startsWith('/tools/settings')) return 'settings'
   if (p.startsWith('/tools/system')) return 'system'
   if (p.startsWith('/tools/docker')) return 'docker'
   if (p.startsWith('/doctor')) return 'doctor'
@@ -58,12 +68,22 @@ var refreshRoutes = {
   repos:   '/tools/repos',
   git:     '/tools/git',
   servers: '/tools/servers',
+  knownhosts: '/tools/known-hosts',
+  terminal:  '/tools/terminal',
   envs:    '/tools/envs',
   aliases:   '/tools/aliases',
   installer: '/tools/installer',
+  firewall:  '/tools/firewall',
   api:       '/tools/api',
   mcp:       '/tools/mcp',
   database:  '/tools/db',
+  vault:     '/tools/vault',
+  workspaces: '/tools/workspaces',
+  activity:  '/tools/activity',
+  jobs:      '/tools/jobs',
+  schedules: '/tools/schedules',
+  commands:  '/tools/commands',
+  logs:      '/tools/logs',
   docker:    '/tools/docker'
 }
 
@@ -93,6 +113,82 @@ document.body.addEventListener('refreshImages', function () {
   }
 })
 
+// ── Eventos em tempo real (SSE) ───────────────────────────────
+// Uma única conexão /events com os tópicos dos elementos [data-sse-topic]
+// presentes na página; é refeita quando esse conjunto muda. Para cada evento:
+//   - "log": acrescenta a linha ao [data-sse-log] do elemento;
+//   - com html e data-sse-swap="innerHTML|outerHTML": troca o conteúdo;
+//   - demais: dispara "sse-<tipo>" no elemento (ex: hx-trigger="sse-done").
+// O polling dos templates continua como reserva, em intervalo longo.
+var sseSource = null
+var sseTopics = ''
+var sseTimer = null
+
+function sseSync() {
+  clearTimeout(sseTimer)
+  sseTimer = setTimeout(function () {
+    var set = {}
+    document.querySelectorAll('[data-sse-topic]').forEach(function (el) {
+      set[el.getAttribute('data-sse-topic')] = true
+    })
+    var topics = Object.keys(set).sort()
+    var key = topics.join(',')
+    if (key === sseTopics && (sseSource || !topics.length)) return
+    sseTopics = key
+    if (sseSource) { sseSource.close(); sseSource = null }
+    if (!topics.length || !window.EventSource) return
+    var qs = topics.map(function (t) { return 'topic=' + encodeURIComponent(t) }).join('&')
+    sseSource = new EventSource('/events?' + qs)
+    sseSource.onmessage = function (msg) {
+      try { sseDispatch(JSON.parse(msg.data)) } catch (_) {}
+    }
+  }, 50)
+}
+
+function sseDispatch(ev) {
+  var sel = '[data-sse-topic="' + CSS.escape(ev.topic) + '"]'
+  document.querySelectorAll(sel).forEach(function (el) {
+    if (ev.type === 'log') {
+      var log = el.hasAttribute('data-sse-log') ? el : el.querySelector('[data-sse-log]')
+      if (!log) return
+      var stick = log.scrollTop + log.clientHeight >= log.scrollHeight - 4
+      log.textContent += ev.data.line + '\n'
+      if (stick) log.scrollTop = log.scrollHeight
+      return
+    }
+    var swap = el.getAttribute('data-sse-swap')
+    if (ev.html && swap) {
+      if (swap === 'outerHTML') {
+        var tpl = document.createElement('template')
+        tpl.innerHTML = ev.html.trim()
+        var next = tpl.content.firstElementChild
+        if (!next) return
+        el.replaceWith(next)
+        if (window.htmx) htmx.process(next)
+      } else {
+        el.innerHTML = ev.html
+        if (window.htmx) htmx.process(el)
+      }
+      return
+    }
+    if (window.htmx) htmx.trigger(el, 'sse-' + ev.type, ev.data)
+  })
+}
+
+document.body.addEventListener('htmx:load', sseSync)
+document.body.addEventListener('htmx:afterSettle', sseSync)
+sseSync()
+
+// ── CSRF: devolve o token do cookie fdev_csrf em toda requisição HTMX ──
+function csrfToken() {
+  const m = document.cookie.match(/(?:^|;\s*)fdev_csrf=([^;]+)/)
+  return m ? decodeURIComponent(m[1]) : ''
+}
+document.body.addEventListener('htmx:configRequest', function (e) {
+  const token = csrfToken()
+  if (token) e.detail.headers['X-CSRF-Token'] = token
+})
+
 // ── HTMX 2.x: permitir swap em 422 (erros de validação) ───────
 document.body.addEventListener('htmx:beforeSwap', function (e) {
   if (e.detail.xhr.status === 422) {
@@ -0,0 +1,180 @@
+// ── Terminal embutido (xterm.js sobre WebSocket) ───────────────
+// Cada .fdev-terminal-pane[data-session] vira um xterm conectado a
+// /tools/terminal/sessions/{id}/ws. Mensagens binárias são a saída do
+// processo; a entrada e os redimensionamentos vão como JSON.
+var fdevTerminal = (function () {
+  var panes = {}   // id → { el, term, fit, ws, exited, observer }
+  var replayTimer = null
+  var replayTerm = null
+
+  function newTerm() {
+    var term = new Terminal({
+      cursorBlink: true,
+      fontFamily: '"IBM Plex Mono", Menlo, monospace',
+      fontSize: 13,
+      scrollback: 5000,
+      theme: { background: '#1f1d1a', foreground: '#f4f1ea' }
+    })
+    var fit = new FitAddon.FitAddon()
+    term.loadAddon(fit)
+    return { term: term, fit: fit }
+  }
+
+  function socketURL(id) {
+    var proto = location.protocol === 'https:' ? 'wss:' : 'ws:'
+    return proto + '//' + location.host + '/tools/terminal/sessions/' + encodeURIComponent(id) + '/ws'
+  }
+
+  function send(p, msg) {
+    if (p.ws && p.ws.readyState === WebSocket.OPEN) p.ws.send(JSON.stringify(msg))
+  }
+
+  function resize(p) {
+    if (p.el.hidden || !p.el.offsetParent) return
+    p.fit.fit()
+    send(p, { type: 'resize', cols: p.term.cols, rows: p.term.rows })
+  }
+
+  function connect(id, p) {
+    var ws = new WebSocket(socketURL(id))
+    ws.binaryType = 'arraybuffer'
+    p.ws = ws
+    ws.onopen = function () {
+      // O histórico chega de novo a cada conexão.
+      p.term.reset()
+      resize(p)
+    }
+    ws.onmessage = function (ev) {
+      if (typeof ev.data !== 'string') {
+        p.term.write(new Uint8Array(ev.data))
+        return
+      }
+      var msg = JSON.parse(ev.data)
+      if (msg.type === 'exit') {
+        p.exited = true
+        p.term.write('\r\n\x1b[2m[processo terminou com código ' + msg.code +
+          (msg.error ? ': ' + msg.error : '') + ']\x1b[0m\r\n')
+      }
+    }
+    ws.onclose = function (ev) {
+      if (panes[id] !== p || p.exited || !document.body.contains(p.el)) return
+      if (ev.reason === 'sessão encerrada') {
+        p.term.write('\r\n\x1b[2m[sessão encerrada]\x1b[0m\r\n')
+        return
+      }
+      // Queda ou cliente atrasado: reconecta e recebe o histórico.
+      setTimeout(function () { if (panes[id] === p) connect(id, p) }, 1000)
+    }
+  }
+
+  function mount(el) {
+    var id = el.dataset.session
+    if (panes[id] && panes[id].el === el) return
+    if (panes[id]) dispose(id)
+    var t = newTerm()
+    var p = { el: el, term: t.term, fit: t.fit, ws: null, exited: false }
+    panes[id] = p
+    p.term.open(el)
+    p.term.onData(function (data) { send(p, { type: 'input', data: data }) })
+    p.observer = new ResizeObserver(function () { resize(p) })
+    p.observer.observe(el)
+    connect(id, p)
+  }
+
+  function dispose(id) {
+    var p = panes[id]
+    delete panes[id]
+    if (!p) return
+    p.observer.disconnect()
+    if (p.ws) p.ws.close()
+    p.term.dispose()
+  }
+
+  function show(id) {
+    document.querySelectorAll('.fdev-terminal-tab').forEach(function (tab) {
+      tab.classList.toggle('active', tab.dataset.session === id)
+    })
+    document.querySelectorAll('.fdev-terminal-pane[data-session]').forEach(function (el) {
+      el.hidden = el.dataset.session !== id
+    })
+    var p = panes[id]
+    if (p) {
+      resize(p)
+      p.term.focus()
+    }
+    var url = new URL(location.href)
+    url.searchParams.set('session', id)
+    history.replaceState(history.state, '', url)
+  }
+
+  // Monta os painéis novos e descarta os que saíram da página.
+  function sync() {
+    if (typeof Terminal === 'undefined') return
+    Object.keys(panes).forEach(function (id) {
+      if (!document.body.contains(panes[id].el)) dispose(id)
+    })
+    document.querySelectorAll('.fdev-terminal-pane[data-session]').forEach(mount)
+    var active = document.querySelector('.fdev-terminal-tab.active')
+    if (active && panes[active.dataset.session]) panes[active.dataset.session].term.focus()
+  }
+
+  document.body.addEventListener('click', function (e) {
+    if (e.target.closest('.fdev-terminal-close')) return
+    var tab = e.target.closest('.fdev-terminal-tab')
+    if (tab) show(tab.dataset.session)
+  })
+
+  // ── Reprodução de gravações (asciicast v2) ────────────────────
+  function stopReplay() {
+    clearTimeout(replayTimer)
+    if (replayTerm) replayTerm.term.dispose()
+    replayTerm = null
+    var box = document.getElementById('terminal-replay')
+    if (box) box.hidden = true
+  }
+
+  function replay(name, title) {
+    stopReplay()
+    var box = document.getElementById('terminal-replay')
+    if (!box) return
+    fetch('/tools/terminal/recordings/' + encodeURIComponent(name))
+      .then(function (r) {
+        if (!r.ok) throw new Error('Gravação não encontrada')
+        return r.text()
+      })
+      .then(function (text) {
+        var lines = text.split('\n').filter(Boolean)
+        var header = JSON.parse(lines.shift())
+        var events = lines.map(function (l) { return JSON.parse(l) })
+        box.hidden = false
+        document.getElementById('terminal-replay-title').textContent = '▶ ' + (title || name)
+        replayTerm = newTerm()
+        replayTerm.term.resize(header.width, header.height)
+        replayTerm.term.open(box.querySelector('.fdev-terminal-pane'))
+        box.scrollIntoView({ behavior: 'smooth' })
+
+        // Pausas longas são encurtadas para 2s.
+        var i = 0
+        function step() {
+          if (!replayTerm || i >= events.length) return
+          var ev = events[i++]
+          if (ev[1] === 'o') replayTerm.term.write(ev[2])
+          if (ev[1] === 'r') {
+            var size = ev[2].split('x')
+            replayTerm.term.resize(+size[0], +size[1])
+          }
+          if (i < events.length) {
+            var wait = Math.min(events[i][0] - ev[0], 2)
+            replayTimer = setTimeout(step, wait * 1000)
+          }
+        }
+        step()
+      })
+      .catch(function (err) { showToastMsg(err.message, 'error') })
+  }
+
+  document.body.addEventListener('htmx:afterSettle', sync)
+  sync()
+
+  return { show: show, replay: replay, stopReplay: stopReplay }
+})()